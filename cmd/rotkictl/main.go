@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/rotki-demo/internal/cli"
+	"github.com/rotki-demo/internal/config"
+	"github.com/rotki-demo/internal/logger"
+)
+
+// rotkictl 是管理部署的命令行工具：数据库迁移、链、钱包和地址管理、手动同步、RPC 节点检查、快照压缩和 API key
+func main() {
+	// 配置文件路径：--config 优先，其次 ROTKI_CONFIG，默认 config.yaml；标志需放在命令之前
+	configPath := flag.String("config", config.DefaultPath(), "path to the YAML config file (env "+config.EnvPrefix+"_CONFIG)")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), cli.Usage)
+	}
+	flag.Parse()
+	args := flag.Args()
+
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config %s: %v\n", *configPath, err)
+		os.Exit(1)
+	}
+
+	if err := logger.InitLogger(&cfg.Log); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize logger: %v\n", err)
+		os.Exit(1)
+	}
+
+	code := cli.Run(cfg, args)
+	logger.Sync()
+	os.Exit(code)
+}
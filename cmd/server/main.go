@@ -2,20 +2,31 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/rotki-demo/internal/api/handler"
+	"github.com/rotki-demo/internal/api/middleware"
 	"github.com/rotki-demo/internal/api/router"
+	"github.com/rotki-demo/internal/cli"
 	"github.com/rotki-demo/internal/config"
 	"github.com/rotki-demo/internal/database"
+	"github.com/rotki-demo/internal/events"
 	"github.com/rotki-demo/internal/logger"
+	"github.com/rotki-demo/internal/metrics"
+	"github.com/rotki-demo/internal/notifier"
+	"github.com/rotki-demo/internal/provider"
 	"github.com/rotki-demo/internal/provider/debank"
 	"github.com/rotki-demo/internal/repository"
 	"github.com/rotki-demo/internal/service"
+	"github.com/rotki-demo/internal/tracing"
 	"go.uber.org/zap"
 
 	_ "github.com/rotki-demo/docs" // 导入 Swagger 文档
@@ -37,33 +48,75 @@ import (
 
 // @schemes http https
 func main() {
-	// 加载配置
-	cfg, err := config.LoadConfig("config.yaml")
+	// os.Exit 不会执行 defer，因此只在 run 返回后调用，保证日志、链路追踪和后台服务在退出前刷新和停止
+	os.Exit(run())
+}
+
+// run 启动服务器或执行管理子命令，返回进程退出码
+func run() int {
+	// 配置文件路径：--config 优先，其次 ROTKI_CONFIG，默认 config.yaml；标志需放在子命令之前
+	configPath := flag.String("config", config.DefaultPath(), "path to the YAML config file (env "+config.EnvPrefix+"_CONFIG)")
+	flag.Parse()
+	args := flag.Args()
+
+	// 加载并校验配置
+	cfg, err := config.LoadConfig(*configPath)
 	if err != nil {
-		fmt.Printf("Failed to load config: %v\n", err)
-		os.Exit(1)
+		fmt.Printf("Failed to load config %s: %v\n", *configPath, err)
+		return 1
 	}
 
 	// 初始化日志
 	if err := logger.InitLogger(&cfg.Log); err != nil {
 		fmt.Printf("Failed to initialize logger: %v\n", err)
-		os.Exit(1)
+		return 1
 	}
 	defer logger.Sync()
 
+	// 管理子命令（与 rotkictl 相同，保留以兼容已有脚本）：只执行管理操作后退出
+	if len(args) > 0 && cli.IsCommand(args[0]) {
+		return cli.Run(cfg, args)
+	}
+
+	// 服务器需要访问数据提供者，管理子命令只在用到时检查
+	if err := cfg.ValidateProvider(); err != nil {
+		fmt.Printf("Failed to load config %s: %v\n", *configPath, err)
+		return 1
+	}
+
 	logger.Info("Starting Rotki Demo application")
 
+	// 根上下文在收到 SIGINT/SIGTERM 时取消，后台同步据此停止开始新的工作
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// 初始化链路追踪，退出时刷新未导出的 span
+	shutdownTracing, err := tracing.Init(context.Background(), &cfg.Tracing)
+	if err != nil {
+		logger.Error("Failed to initialize tracing", zap.Error(err))
+		return 1
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Warn("Failed to flush traces", zap.Error(err))
+		}
+	}()
+
 	// 初始化数据库
 	if err := database.InitDatabase(&cfg.Database); err != nil {
-		logger.Fatal("Failed to initialize database", zap.Error(err))
+		logger.Error("Failed to initialize database", zap.Error(err))
+		return 1
 	}
 
-	// 运行迁移
-	// 注意：迁移由 migrations/001_initial_schema.sql 中的 SQL 文件处理
-	// 为避免与手动模式冲突，禁用 AutoMigrate
-	// if err := database.AutoMigrate(); err != nil {
-	// 	logger.Fatal("Failed to run migrations", zap.Error(err))
-	// }
+	// 运行迁移（migrations/ 中嵌入的版本化 SQL 文件）
+	if cfg.Database.AutoMigrate {
+		if err := database.RunMigrations(); err != nil {
+			logger.Error("Failed to run migrations", zap.Error(err))
+			return 1
+		}
+	}
 
 	// 初始化仓储层
 	db := database.GetDB()
@@ -71,8 +124,35 @@ func main() {
 	addressRepo := repository.NewAddressRepository(db)
 	tokenRepo := repository.NewTokenRepository(db)
 	protocolRepo := repository.NewProtocolRepository(db)
+	positionRepo := repository.NewProtocolPositionRepository(db)
 	chainRepo := repository.NewChainRepository(db)
 	rpcNodeRepo := repository.NewRPCNodeRepository(db)
+	tokenRuleRepo := repository.NewTokenRuleRepository(db)
+	tokenOverrideRepo := repository.NewTokenOverrideRepository(db)
+	healthRepo := repository.NewHealthRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+	snapshotRepo := repository.NewSnapshotRepository(db)
+	alertRuleRepo := repository.NewAlertRuleRepository(db)
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+	userRepo := repository.NewUserRepository(db)
+	auditLogRepo := repository.NewAuditLogRepository(db)
+
+	// 注册数据库连接池和钱包总价值指标
+	if cfg.Metrics.Enabled {
+		sqlDB, err := db.DB()
+		if err != nil {
+			logger.Error("Failed to get database instance", zap.Error(err))
+			return 1
+		}
+		if err := metrics.RegisterDBStats(sqlDB, cfg.Database.Driver); err != nil {
+			logger.Error("Failed to register database metrics", zap.Error(err))
+			return 1
+		}
+		if err := metrics.RegisterPortfolio(tokenRepo); err != nil {
+			logger.Error("Failed to register portfolio metrics", zap.Error(err))
+			return 1
+		}
+	}
 
 	// 从 chains.json 初始化所有链
 	chainInitializer := service.NewChainInitializer(chainRepo)
@@ -81,7 +161,35 @@ func main() {
 	}
 
 	// 初始化数据提供者
-	dataProvider := debank.NewDeBankProvider(&cfg.DeBank)
+	debankProvider := debank.NewDeBankProvider(&cfg.DeBank)
+	dataProvider := provider.NewTracedProvider(debankProvider)
+
+	// 初始化代币分类器（用户忽略/白名单 + 数据库规则 + 配置规则 + 内置规则）
+	tokenClassifier, err := service.NewTokenClassifier(tokenRuleRepo, tokenOverrideRepo, tokenRepo, cfg.TokenRules)
+	if err != nil {
+		logger.Error("Failed to initialize token classifier", zap.Error(err))
+		return 1
+	}
+	tokenOverrideService := service.NewTokenOverrideService(tokenOverrideRepo, tokenClassifier)
+
+	// 初始化通知渠道和健康因子监控
+	alertNotifier, err := notifier.New(cfg.Notifier)
+	if err != nil {
+		logger.Error("Failed to initialize notifier", zap.Error(err))
+		return 1
+	}
+	healthMonitor := service.NewHealthMonitor(healthRepo, alertNotifier, cfg.Alerts)
+	balanceAlertService := service.NewBalanceAlertService(alertRuleRepo, snapshotRepo, tokenRepo, alertNotifier, cfg.Alerts)
+
+	// 初始化出站 webhook（事件先写入投递队列，由后台进程异步投递）
+	webhookService := service.NewWebhookService(webhookRepo, cfg.Webhooks)
+	if cfg.Webhooks.Enabled {
+		webhookService.Start()
+		defer webhookService.Stop()
+	}
+
+	// 初始化进程内事件总线，事件转发给 webhook 并推送到实时事件流
+	eventBus := events.NewBus(cfg.Events.BufferSize, webhookService)
 
 	// 初始化同步服务
 	syncService := service.NewSyncService(
@@ -90,52 +198,146 @@ func main() {
 		addressRepo,
 		tokenRepo,
 		protocolRepo,
+		positionRepo,
 		chainRepo,
+		tokenClassifier,
+		healthMonitor,
+		balanceAlertService,
+		eventBus,
 		cfg.Sync.GetSyncInterval(),
 		cfg.Sync.BatchSize,
 	)
 
-	// 如果启用则启动同步服务
+	// 如果启用则启动同步服务；新地址的后台同步不依赖定时同步，关闭时总是等待
 	if cfg.Sync.Enabled {
-		syncService.Start()
-		defer syncService.Stop()
+		syncService.Start(ctx)
 	}
+	defer syncService.Stop(cfg.Sync.GetDrainTimeout())
 
 	// 初始化 RPC 节点服务
-	rpcNodeService := service.NewRPCNodeService(rpcNodeRepo, logger.GetLogger())
+	rpcNodeService := service.NewRPCNodeService(rpcNodeRepo, eventBus, logger.GetLogger())
+
+	// 初始化用户和钱包访问控制
+	userService := service.NewUserService(userRepo, cfg.Auth.GetSessionTTL())
+	accessService := service.NewAccessService(walletRepo)
 
 	// 初始化处理器
-	walletHandler := handler.NewWalletHandler(walletRepo)
-	addressHandler := handler.NewAddressHandler(addressRepo, tokenRepo, protocolRepo, syncService)
-	chainHandler := handler.NewChainHandler(chainRepo)
+	walletHandler := handler.NewWalletHandler(walletRepo, userRepo, accessService)
+	addressHandler := handler.NewAddressHandler(addressRepo, tokenRepo, protocolRepo, syncService, accessService, eventBus)
+	chainHandler := handler.NewChainHandler(chainRepo, chainInitializer, dataProvider)
 	rpcNodeHandler := handler.NewRPCNodeHandler(rpcNodeService, logger.GetLogger())
+	tokenRuleHandler := handler.NewTokenRuleHandler(tokenRuleRepo, tokenClassifier)
+	tokenOverrideHandler := handler.NewTokenOverrideHandler(tokenOverrideRepo, tokenOverrideService, accessService)
+	positionHandler := handler.NewPositionHandler(positionRepo, addressRepo, accessService)
+	healthHandler := handler.NewHealthHandler(healthRepo, accessService, healthMonitor)
+	webhookHandler := handler.NewWebhookHandler(webhookRepo, walletRepo, webhookService)
+	alertRuleHandler := handler.NewAlertRuleHandler(alertRuleRepo, addressRepo, balanceAlertService, accessService)
+	eventHandler := handler.NewEventHandler(eventBus, accessService, cfg.Events.GetHeartbeatInterval())
+	userHandler := handler.NewUserHandler(userRepo, userService)
+	probeService := service.NewProbeService(db, dataProvider, syncService, cfg.Sync, chainRepo, rpcNodeRepo, cfg.Probes)
+	probeHandler := handler.NewProbeHandler(probeService)
+
+	// 监听配置文件，同步间隔、速率限制、日志级别和代币分类规则的修改无需重启
+	configService := service.NewConfigService(cfg, syncService, debankProvider, tokenClassifier)
+	configService.Watch()
+	configHandler := handler.NewConfigHandler(configService)
+
+	// 初始化审计日志
+	auditService := service.NewAuditService(auditLogRepo, cfg.Audit)
+	auditService.Start()
+	defer auditService.Stop()
+	auditHandler := handler.NewAuditHandler(auditService)
 
-	// 设置路由
-	r := router.SetupRouter(walletHandler, addressHandler, chainHandler, rpcNodeHandler)
+	// 初始化备份导出和恢复
+	backupService := service.NewBackupService(repository.NewBackupRepository(db), cfg.Database.Driver)
+	backupHandler := handler.NewBackupHandler(backupService, tokenClassifier)
+
+	// 初始化交易历史同步，数据源为 DeBank 或已配置的 RPC 节点
+	transactionRepo := repository.NewTransactionRepository(db)
+	historySource := provider.TransactionHistoryProvider(dataProvider)
+	if cfg.Transactions.Source == config.TransactionSourceRPC {
+		historySource = service.NewRPCHistoryProvider(rpcNodeService)
+	}
+	transactionService := service.NewTransactionService(historySource, transactionRepo, addressRepo, walletRepo, tokenRepo, cfg.Transactions)
+	if cfg.Transactions.Enabled {
+		transactionService.Start()
+		defer transactionService.Stop()
+	}
+	transactionHandler := handler.NewTransactionHandler(transactionRepo, addressRepo, accessService)
+
+	// 初始化 API key 和登录会话认证
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo)
+	if !cfg.Auth.Enabled {
+		logger.Warn("API authentication is disabled (auth.allow_insecure); anyone who can reach the server has full access")
+	}
+	auth := middleware.NewAuth(apiKeyService, userService, userRepo, cfg.Auth.Enabled)
+
+	// 设置路由，访问日志由 zap 记录；debug 模式下 gin 仍会在启动时打印路由表
+	gin.SetMode(cfg.Server.Mode)
+	r := router.SetupRouter(
+		cfg.Server.CORS,
+		cfg.Metrics,
+		cfg.Tracing,
+		auth,
+		auditService,
+		walletHandler,
+		addressHandler,
+		chainHandler,
+		rpcNodeHandler,
+		tokenRuleHandler,
+		tokenOverrideHandler,
+		positionHandler,
+		healthHandler,
+		webhookHandler,
+		alertRuleHandler,
+		eventHandler,
+		userHandler,
+		auditHandler,
+		probeHandler,
+		configHandler,
+		backupHandler,
+		transactionHandler,
+	)
 
 	// 启动服务器
 	serverAddr := fmt.Sprintf(":%d", cfg.Server.Port)
 	logger.Info("Starting HTTP server", zap.String("address", serverAddr))
 
-	// 优雅关闭
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	srv := &http.Server{
+		Addr:              serverAddr,
+		Handler:           r,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	// 事件流是长连接，关闭时结束所有订阅，否则 Shutdown 会一直等到超时
+	srv.RegisterOnShutdown(eventBus.CloseSubscribers)
 
+	serverFailed := make(chan struct{})
 	go func() {
-		if err := r.Run(serverAddr); err != nil {
-			logger.Fatal("Failed to start server", zap.Error(err))
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			// 不使用 Fatal，让下面的关闭流程和 defer 正常执行，退出码由 run 返回
+			logger.Error("HTTP server failed", zap.Error(err))
+			close(serverFailed)
+			stop()
 		}
 	}()
 
 	// 等待中断信号
-	<-quit
-	logger.Info("Shutting down server...")
+	<-ctx.Done()
+	stop()
+	logger.Info("Shutting down server...", zap.Duration("timeout", cfg.Server.GetShutdownTimeout()))
 
-	// 给未完成的请求 5 秒时间完成
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	// 停止接受新连接，等待进行中的请求完成；随后 defer 依次停止审计、同步（等待进行中的同步）、webhook 和链路追踪
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.GetShutdownTimeout())
 	defer cancel()
-
-	// 等待上下文超时
-	<-ctx.Done()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.Warn("HTTP server did not shut down cleanly", zap.Error(err))
+	}
 	logger.Info("Server stopped")
+
+	select {
+	case <-serverFailed:
+		return 1
+	default:
+		return 0
+	}
 }
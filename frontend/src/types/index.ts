@@ -22,6 +22,9 @@ export interface Token {
   logo_url?: string
   protocol_id?: string  // 如果来自协议，标记协议ID
   is_debt?: boolean     // 是否是债务代币
+  is_verified?: boolean
+  is_core?: boolean
+  classification?: 'normal' | 'spam' | 'receipt' | 'hidden'  // 只有 include_hidden=true 时才会返回非 normal 代币
 }
 
 export interface Protocol {
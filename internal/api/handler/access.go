@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rotki-demo/internal/logger"
+	"github.com/rotki-demo/internal/models"
+	"github.com/rotki-demo/internal/repository"
+	"github.com/rotki-demo/internal/service"
+	"go.uber.org/zap"
+)
+
+// principalFromContext 返回认证中间件写入的调用方，未启用认证时返回 nil
+func principalFromContext(c *gin.Context) *service.Principal {
+	if v, ok := c.Get(service.PrincipalContextKey); ok {
+		if principal, ok := v.(*service.Principal); ok {
+			return principal
+		}
+	}
+	return nil
+}
+
+// walletScope 返回调用方可以访问的钱包范围，出错时写入 500 响应并返回 false
+func walletScope(c *gin.Context, access *service.AccessService) (repository.WalletScope, bool) {
+	scope, err := access.Scope(principalFromContext(c))
+	if err != nil {
+		logger.Error("Failed to resolve wallet scope", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve wallet access"})
+		return repository.WalletScope{}, false
+	}
+	return scope, true
+}
+
+// authorizeWallet 加载钱包并检查调用方权限，失败时写入 404/403/500 响应并返回 false
+func authorizeWallet(c *gin.Context, access *service.AccessService, walletID uint, required string) (*models.Wallet, bool) {
+	wallet, err := access.AuthorizeWallet(principalFromContext(c), walletID, required)
+	if err != nil {
+		writeAccessError(c, err, "Wallet not found", required)
+		return nil, false
+	}
+	return wallet, true
+}
+
+// authorizeAddress 加载地址并检查调用方对其所属钱包的权限，失败时写入响应并返回 false
+func authorizeAddress(c *gin.Context, access *service.AccessService, addressRepo *repository.AddressRepository, addressID uint, required string) (*models.Address, bool) {
+	address, err := addressRepo.GetByID(addressID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Address not found"})
+		return nil, false
+	}
+	if _, err := access.AuthorizeWallet(principalFromContext(c), address.WalletID, required); err != nil {
+		writeAccessError(c, err, "Address not found", required)
+		return nil, false
+	}
+	return address, true
+}
+
+// writeAccessError 把访问检查的错误转换为响应，无权查看的资源按不存在处理
+func writeAccessError(c *gin.Context, err error, notFound, required string) {
+	switch {
+	case errors.Is(err, service.ErrWalletNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": notFound})
+	case errors.Is(err, service.ErrAccessDenied):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions on wallet", "required_access": required})
+	default:
+		logger.Error("Failed to check wallet access", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check wallet access"})
+	}
+}
+
+// requireUnrestricted 检查调用方是否可以管理全局资源（不属于任何钱包的配置），否则写入 403 响应
+func requireUnrestricted(c *gin.Context) bool {
+	if principalFromContext(c).Unrestricted() {
+		return true
+	}
+	c.JSON(http.StatusForbidden, gin.H{"error": "Only administrators can manage global settings"})
+	return false
+}
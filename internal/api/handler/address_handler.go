@@ -1,14 +1,17 @@
 package handler
 
 import (
-	"context"
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/rotki-demo/internal/events"
+	"github.com/rotki-demo/internal/metrics"
 	"github.com/rotki-demo/internal/models"
 	"github.com/rotki-demo/internal/repository"
 	"github.com/rotki-demo/internal/service"
+	"gorm.io/gorm"
 )
 
 // AddressHandler 处理地址相关的 HTTP 请求
@@ -17,6 +20,8 @@ type AddressHandler struct {
 	tokenRepo    *repository.TokenRepository
 	protocolRepo *repository.ProtocolRepository
 	syncService  *service.SyncService
+	access       *service.AccessService
+	publisher    events.Publisher
 }
 
 // NewAddressHandler 创建一个新的地址处理器
@@ -25,12 +30,16 @@ func NewAddressHandler(
 	tokenRepo *repository.TokenRepository,
 	protocolRepo *repository.ProtocolRepository,
 	syncService *service.SyncService,
+	access *service.AccessService,
+	publisher events.Publisher,
 ) *AddressHandler {
 	return &AddressHandler{
 		addressRepo:  addressRepo,
 		tokenRepo:    tokenRepo,
 		protocolRepo: protocolRepo,
 		syncService:  syncService,
+		access:       access,
+		publisher:    publisher,
 	}
 }
 
@@ -48,6 +57,11 @@ type UpdateAddressRequest struct {
 	Tags  models.StringSlice `json:"tags"`
 }
 
+// includeHidden 判断请求是否要求返回 spam/receipt/hidden 分类的代币
+func includeHidden(c *gin.Context) bool {
+	return c.Query("include_hidden") == "true"
+}
+
 // CreateAddress 创建一个新的地址
 // @Summary      创建地址
 // @Description  创建一个新的区块链地址
@@ -57,6 +71,9 @@ type UpdateAddressRequest struct {
 // @Param        address  body      CreateAddressRequest  true  "地址信息"
 // @Success      201      {object}  github_com_rotki-demo_internal_models.Address
 // @Failure      400      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      409      {object}  map[string]string
 // @Failure      500      {object}  map[string]string
 // @Router       /addresses [post]
 func (h *AddressHandler) CreateAddress(c *gin.Context) {
@@ -66,6 +83,10 @@ func (h *AddressHandler) CreateAddress(c *gin.Context) {
 		return
 	}
 
+	if _, ok := authorizeWallet(c, h.access, req.WalletID, models.SharePermissionEdit); !ok {
+		return
+	}
+
 	if req.ChainType == "" {
 		req.ChainType = "EVM"
 	}
@@ -78,22 +99,25 @@ func (h *AddressHandler) CreateAddress(c *gin.Context) {
 	}
 
 	if err := h.addressRepo.Create(address); err != nil {
+		// 唯一约束只在钱包内生效，冲突说明该钱包已包含此地址，不会泄露其他用户的钱包
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Address already exists in this wallet"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create address"})
 		return
 	}
+	h.publisher.Publish(events.New(events.AddressAdded, address.WalletID, address.ID, address))
 
 	// 在后台触发新地址的即时同步
-	// 使用后台上下文而不是请求上下文以避免取消
-	go func() {
-		ctx := context.Background()
-		_ = h.syncService.SyncAddress(ctx, address.ID)
-	}()
+	// 不使用请求上下文以避免响应返回后被取消，关闭时由同步服务等待完成
+	h.syncService.SyncAddressInBackground(address.ID)
 
 	c.JSON(http.StatusCreated, address)
 }
 
 // GetAddress 根据 ID 获取地址
-// GET /api/v1/addresses/:id
+// GET /api/v1/addresses/:id?include_hidden=true
 func (h *AddressHandler) GetAddress(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
@@ -101,14 +125,13 @@ func (h *AddressHandler) GetAddress(c *gin.Context) {
 		return
 	}
 
-	address, err := h.addressRepo.GetByID(uint(id))
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Address not found"})
+	address, ok := authorizeAddress(c, h.access, h.addressRepo, uint(id), models.SharePermissionView)
+	if !ok {
 		return
 	}
 
 	// 获取此地址的代币
-	tokens, err := h.tokenRepo.GetByAddressID(address.ID)
+	tokens, err := h.tokenRepo.GetByAddressID(address.ID, includeHidden(c))
 	if err == nil {
 		address.Tokens = tokens
 	}
@@ -123,7 +146,7 @@ func (h *AddressHandler) GetAddress(c *gin.Context) {
 }
 
 // ListAddresses 获取所有地址
-// GET /api/v1/addresses
+// GET /api/v1/addresses?include_hidden=true
 func (h *AddressHandler) ListAddresses(c *gin.Context) {
 	// 检查是否按钱包过滤
 	walletIDStr := c.Query("wallet_id")
@@ -137,9 +160,16 @@ func (h *AddressHandler) ListAddresses(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid wallet ID"})
 			return
 		}
+		if _, ok := authorizeWallet(c, h.access, uint(walletID), models.SharePermissionView); !ok {
+			return
+		}
 		addresses, err = h.addressRepo.GetByWalletID(uint(walletID))
 	} else {
-		addresses, err = h.addressRepo.List()
+		scope, ok := walletScope(c, h.access)
+		if !ok {
+			return
+		}
+		addresses, err = h.addressRepo.List(scope)
 	}
 
 	if err != nil {
@@ -149,7 +179,7 @@ func (h *AddressHandler) ListAddresses(c *gin.Context) {
 
 	// 补充代币和协议信息
 	for i := range addresses {
-		tokens, err := h.tokenRepo.GetByAddressID(addresses[i].ID)
+		tokens, err := h.tokenRepo.GetByAddressID(addresses[i].ID, includeHidden(c))
 		if err == nil {
 			addresses[i].Tokens = tokens
 		}
@@ -172,9 +202,8 @@ func (h *AddressHandler) UpdateAddress(c *gin.Context) {
 		return
 	}
 
-	address, err := h.addressRepo.GetByID(uint(id))
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Address not found"})
+	address, ok := authorizeAddress(c, h.access, h.addressRepo, uint(id), models.SharePermissionEdit)
+	if !ok {
 		return
 	}
 
@@ -193,7 +222,7 @@ func (h *AddressHandler) UpdateAddress(c *gin.Context) {
 	}
 
 	// 获取代币和协议用于响应
-	tokens, err := h.tokenRepo.GetByAddressID(address.ID)
+	tokens, err := h.tokenRepo.GetByAddressID(address.ID, includeHidden(c))
 	if err == nil {
 		address.Tokens = tokens
 	}
@@ -215,10 +244,19 @@ func (h *AddressHandler) DeleteAddress(c *gin.Context) {
 		return
 	}
 
+	address, ok := authorizeAddress(c, h.access, h.addressRepo, uint(id), models.SharePermissionEdit)
+	if !ok {
+		return
+	}
+
 	if err := h.addressRepo.Delete(uint(id)); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete address"})
 		return
 	}
+	metrics.ForgetAddress(address.ID)
+	// 事件只包含地址本身，不包含预加载的钱包和代币
+	address.Wallet, address.Tokens = nil, nil
+	h.publisher.Publish(events.New(events.AddressRemoved, address.WalletID, address.ID, address))
 
 	c.JSON(http.StatusOK, gin.H{"message": "Address deleted successfully"})
 }
@@ -232,6 +270,10 @@ func (h *AddressHandler) RefreshAddress(c *gin.Context) {
 		return
 	}
 
+	if _, ok := authorizeAddress(c, h.access, h.addressRepo, uint(id), models.SharePermissionEdit); !ok {
+		return
+	}
+
 	if err := h.syncService.SyncAddress(c.Request.Context(), uint(id)); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh address"})
 		return
@@ -245,7 +287,7 @@ func (h *AddressHandler) RefreshAddress(c *gin.Context) {
 	}
 
 	// 获取代币和协议
-	tokens, err := h.tokenRepo.GetByAddressID(address.ID)
+	tokens, err := h.tokenRepo.GetByAddressID(address.ID, includeHidden(c))
 	if err == nil {
 		address.Tokens = tokens
 	}
@@ -267,6 +309,10 @@ func (h *AddressHandler) RefreshWallet(c *gin.Context) {
 		return
 	}
 
+	if _, ok := authorizeWallet(c, h.access, uint(id), models.SharePermissionEdit); !ok {
+		return
+	}
+
 	if err := h.syncService.SyncWallet(c.Request.Context(), uint(id)); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh wallet"})
 		return
@@ -0,0 +1,342 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rotki-demo/internal/models"
+	"github.com/rotki-demo/internal/repository"
+	"github.com/rotki-demo/internal/service"
+)
+
+// AlertRuleHandler 处理余额变化告警规则相关的 HTTP 请求
+type AlertRuleHandler struct {
+	ruleRepo     *repository.AlertRuleRepository
+	addressRepo  *repository.AddressRepository
+	alertService *service.BalanceAlertService
+	access       *service.AccessService
+}
+
+// NewAlertRuleHandler 创建一个新的告警规则处理器
+func NewAlertRuleHandler(
+	ruleRepo *repository.AlertRuleRepository,
+	addressRepo *repository.AddressRepository,
+	alertService *service.BalanceAlertService,
+	access *service.AccessService,
+) *AlertRuleHandler {
+	return &AlertRuleHandler{
+		ruleRepo:     ruleRepo,
+		addressRepo:  addressRepo,
+		alertService: alertService,
+		access:       access,
+	}
+}
+
+// AlertRuleRequest 表示创建或更新告警规则的请求
+type AlertRuleRequest struct {
+	Name             string   `json:"name" binding:"required"`
+	RuleType         string   `json:"rule_type" binding:"required"` // net_worth_drop、token_balance_change、token_disappeared、new_debt
+	ScopeType        string   `json:"scope_type"`                   // all（默认）、wallet、address、tag
+	ScopeID          uint     `json:"scope_id"`
+	ScopeTag         string   `json:"scope_tag"`
+	ChainID          string   `json:"chain_id"`
+	TokenID          string   `json:"token_id"`
+	ThresholdPercent *float64 `json:"threshold_percent"`
+	ThresholdUSD     *float64 `json:"threshold_usd"`
+	CooldownMinutes  *int     `json:"cooldown_minutes"` // 省略时使用配置中的默认值
+	Enabled          *bool    `json:"enabled"`
+}
+
+// apply 将请求字段写入规则
+func (req *AlertRuleRequest) apply(rule *models.AlertRule, defaultCooldown int) {
+	rule.Name = req.Name
+	rule.RuleType = req.RuleType
+	rule.ScopeType = req.ScopeType
+	if rule.ScopeType == "" {
+		rule.ScopeType = models.AlertScopeAll
+	}
+	rule.ScopeID = req.ScopeID
+	rule.ScopeTag = req.ScopeTag
+	rule.ChainID = req.ChainID
+	rule.TokenID = strings.ToLower(req.TokenID)
+	rule.ThresholdPercent = req.ThresholdPercent
+	rule.ThresholdUSD = req.ThresholdUSD
+	rule.CooldownMinutes = defaultCooldown
+	if req.CooldownMinutes != nil {
+		rule.CooldownMinutes = *req.CooldownMinutes
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+}
+
+// authorizeRule 检查调用方对规则作用范围的权限。
+// 作用于所有钱包或标签的规则所有人可见，但只有管理员可以修改
+func (h *AlertRuleHandler) authorizeRule(c *gin.Context, rule *models.AlertRule, required string) bool {
+	notFound := "Alert rule not found"
+	var walletID uint
+	switch rule.ScopeType {
+	case models.AlertScopeWallet:
+		walletID = rule.ScopeID
+		if rule.ID == 0 {
+			notFound = "Wallet not found"
+		}
+	case models.AlertScopeAddress:
+		if rule.ID == 0 {
+			notFound = "Address not found"
+		}
+		address, err := h.addressRepo.GetByID(rule.ScopeID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": notFound})
+			return false
+		}
+		walletID = address.WalletID
+	default:
+		if required == models.SharePermissionView {
+			return true
+		}
+		return requireUnrestricted(c)
+	}
+
+	if _, err := h.access.AuthorizeWallet(principalFromContext(c), walletID, required); err != nil {
+		writeAccessError(c, err, notFound, required)
+		return false
+	}
+	return true
+}
+
+// ListAlertRules 获取告警规则
+// @Summary      获取余额告警规则列表
+// @Description  获取调用方可访问的钱包和地址上的规则，以及作用于所有钱包或标签的规则
+// @Tags         alerts
+// @Accept       json
+// @Produce      json
+// @Success      200  {array}   github_com_rotki-demo_internal_models.AlertRule
+// @Failure      500  {object}  map[string]string
+// @Router       /alert-rules [get]
+func (h *AlertRuleHandler) ListAlertRules(c *gin.Context) {
+	scope, ok := walletScope(c, h.access)
+	if !ok {
+		return
+	}
+
+	rules, err := h.ruleRepo.List(scope)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve alert rules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// GetAlertRule 根据 ID 获取告警规则
+// @Summary      获取余额告警规则
+// @Description  根据 ID 获取余额变化告警规则
+// @Tags         alerts
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "规则 ID"
+// @Success      200  {object}  github_com_rotki-demo_internal_models.AlertRule
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /alert-rules/{id} [get]
+func (h *AlertRuleHandler) GetAlertRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rule ID"})
+		return
+	}
+
+	rule, err := h.ruleRepo.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Alert rule not found"})
+		return
+	}
+	if !h.authorizeRule(c, rule, models.SharePermissionView) {
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// CreateAlertRule 创建告警规则
+// @Summary      创建余额告警规则
+// @Description  创建在每次同步后与上一次资产快照比较的告警规则，可限定到钱包、地址或标签，并设置冷却时间避免重复告警
+// @Tags         alerts
+// @Accept       json
+// @Produce      json
+// @Param        rule  body      AlertRuleRequest  true  "规则信息"
+// @Success      201   {object}  github_com_rotki-demo_internal_models.AlertRule
+// @Failure      400   {object}  map[string]string
+// @Failure      403   {object}  map[string]string
+// @Failure      404   {object}  map[string]string
+// @Failure      500   {object}  map[string]string
+// @Router       /alert-rules [post]
+func (h *AlertRuleHandler) CreateAlertRule(c *gin.Context) {
+	var req AlertRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule := &models.AlertRule{Enabled: true}
+	req.apply(rule, h.alertService.DefaultCooldown())
+
+	if err := service.ValidateAlertRule(rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !h.authorizeRule(c, rule, models.SharePermissionEdit) {
+		return
+	}
+
+	if err := h.ruleRepo.Create(rule); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create alert rule"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// UpdateAlertRule 更新告警规则
+// @Summary      更新余额告警规则
+// @Description  替换告警规则的所有字段
+// @Tags         alerts
+// @Accept       json
+// @Produce      json
+// @Param        id    path      int               true  "规则 ID"
+// @Param        rule  body      AlertRuleRequest  true  "规则信息"
+// @Success      200   {object}  github_com_rotki-demo_internal_models.AlertRule
+// @Failure      400   {object}  map[string]string
+// @Failure      403   {object}  map[string]string
+// @Failure      404   {object}  map[string]string
+// @Failure      500   {object}  map[string]string
+// @Router       /alert-rules/{id} [put]
+func (h *AlertRuleHandler) UpdateAlertRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rule ID"})
+		return
+	}
+
+	rule, err := h.ruleRepo.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Alert rule not found"})
+		return
+	}
+	// 原范围和新范围都需要修改权限，避免把规则移出或移入无权访问的钱包
+	if !h.authorizeRule(c, rule, models.SharePermissionEdit) {
+		return
+	}
+
+	var req AlertRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.apply(rule, h.alertService.DefaultCooldown())
+
+	if err := service.ValidateAlertRule(rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !h.authorizeRule(c, rule, models.SharePermissionEdit) {
+		return
+	}
+
+	if err := h.ruleRepo.Update(rule); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update alert rule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// DeleteAlertRule 删除告警规则
+// @Summary      删除余额告警规则
+// @Description  删除告警规则及其告警记录
+// @Tags         alerts
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "规则 ID"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /alert-rules/{id} [delete]
+func (h *AlertRuleHandler) DeleteAlertRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rule ID"})
+		return
+	}
+
+	rule, err := h.ruleRepo.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Alert rule not found"})
+		return
+	}
+	if !h.authorizeRule(c, rule, models.SharePermissionEdit) {
+		return
+	}
+
+	if err := h.ruleRepo.Delete(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete alert rule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Alert rule deleted successfully"})
+}
+
+// ListAlerts 获取余额告警历史
+// @Summary      获取余额告警历史
+// @Description  按时间倒序获取已触发的余额变化告警及其投递结果
+// @Tags         alerts
+// @Accept       json
+// @Produce      json
+// @Param        rule_id     query     int     false  "规则 ID"
+// @Param        wallet_id   query     int     false  "钱包 ID"
+// @Param        address_id  query     int     false  "地址 ID"
+// @Param        rule_type   query     string  false  "规则类型"
+// @Param        limit       query     int     false  "返回条数（默认 100，最大 1000）"
+// @Success      200         {array}   github_com_rotki-demo_internal_models.AlertEvent
+// @Failure      400         {object}  map[string]string
+// @Failure      500         {object}  map[string]string
+// @Router       /alerts [get]
+func (h *AlertRuleHandler) ListAlerts(c *gin.Context) {
+	var filter repository.AlertEventFilter
+	var err error
+	if filter.RuleID, err = parseUintQuery(c, "rule_id"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rule ID"})
+		return
+	}
+	if filter.WalletID, err = parseUintQuery(c, "wallet_id"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid wallet ID"})
+		return
+	}
+	if filter.AddressID, err = parseUintQuery(c, "address_id"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid address ID"})
+		return
+	}
+	filter.RuleType = c.Query("rule_type")
+
+	limit, ok := parseLimit(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+		return
+	}
+	if filter.Scope, ok = walletScope(c, h.access); !ok {
+		return
+	}
+
+	events, err := h.ruleRepo.ListEvents(filter, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve alerts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rotki-demo/internal/repository"
+	"github.com/rotki-demo/internal/service"
+)
+
+// AuditHandler 处理审计日志查询
+type AuditHandler struct {
+	auditService *service.AuditService
+}
+
+// NewAuditHandler 创建一个新的审计日志处理器
+func NewAuditHandler(auditService *service.AuditService) *AuditHandler {
+	return &AuditHandler{auditService: auditService}
+}
+
+// parseTimeQuery 解析可选的 RFC 3339 时间查询参数，未设置时返回 nil
+func parseTimeQuery(c *gin.Context, key string) (*time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// ListAuditLog 查询审计日志
+// @Summary      查询审计日志
+// @Description  按时间倒序获取修改类 API 请求的审计记录（操作者、路由、目标实体、修改前后状态及变化字段），需要 admin。
+// @Description  翻页时把上一页最后一条记录的 ID 作为 before_id 传入。
+// @Tags         audit
+// @Produce      json
+// @Param        entity_type  query     string  false  "实体类型（wallet、address、rpc_node 等）"
+// @Param        entity_id    query     string  false  "实体 ID"
+// @Param        actor        query     string  false  "操作者（如 user:alice、apikey:ci）"
+// @Param        method       query     string  false  "请求方法（POST、PUT、DELETE）"
+// @Param        since        query     string  false  "开始时间（RFC 3339，包含）"
+// @Param        until        query     string  false  "结束时间（RFC 3339，不包含）"
+// @Param        before_id    query     int     false  "只返回 ID 小于该值的记录"
+// @Param        limit        query     int     false  "返回条数（默认 100，最大 1000）"
+// @Success      200          {array}   github_com_rotki-demo_internal_models.AuditLog
+// @Failure      400          {object}  map[string]string
+// @Failure      500          {object}  map[string]string
+// @Router       /audit-log [get]
+func (h *AuditHandler) ListAuditLog(c *gin.Context) {
+	filter := repository.AuditLogFilter{
+		EntityType: c.Query("entity_type"),
+		EntityID:   c.Query("entity_id"),
+		Actor:      c.Query("actor"),
+		Method:     strings.ToUpper(c.Query("method")),
+	}
+
+	var err error
+	if filter.Since, err = parseTimeQuery(c, "since"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid since, expected RFC 3339 time"})
+		return
+	}
+	if filter.Until, err = parseTimeQuery(c, "until"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid until, expected RFC 3339 time"})
+		return
+	}
+	if filter.BeforeID, err = parseUintQuery(c, "before_id"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid before_id"})
+		return
+	}
+	limit, ok := parseLimit(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+		return
+	}
+
+	entries, err := h.auditService.List(filter, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve audit log"})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
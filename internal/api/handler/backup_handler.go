@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rotki-demo/internal/logger"
+	"github.com/rotki-demo/internal/service"
+	"go.uber.org/zap"
+)
+
+// maxRestoreSize 是上传备份的最大字节数
+const maxRestoreSize = 512 << 20
+
+// BackupHandler 处理备份导出和恢复
+type BackupHandler struct {
+	backupService *service.BackupService
+	classifier    *service.TokenClassifier
+}
+
+// NewBackupHandler 创建一个新的备份处理器
+func NewBackupHandler(backupService *service.BackupService, classifier *service.TokenClassifier) *BackupHandler {
+	return &BackupHandler{
+		backupService: backupService,
+		classifier:    classifier,
+	}
+}
+
+// ExportBackup 导出备份
+// @Summary      导出备份
+// @Description  导出与数据库类型无关的备份（用户、API key、链、RPC 节点、钱包、地址、告警规则、健康因子阈值、代币规则和忽略/白名单、webhook 以及资产快照），需要 admin。
+// @Description  备份包含密码哈希、API key 哈希和 webhook 签名密钥，请妥善保管。代币余额和持仓不在备份中，恢复后重新同步。
+// @Tags         admin
+// @Produce      json
+// @Produce      application/zip
+// @Param        format     query     string  false  "json（默认）或 zip"
+// @Param        snapshots  query     bool    false  "是否包含资产快照，默认 true"
+// @Success      200        {object}  github_com_rotki-demo_internal_service.BackupArchive
+// @Failure      400        {object}  map[string]string
+// @Failure      500        {object}  map[string]string
+// @Router       /admin/backup [get]
+func (h *BackupHandler) ExportBackup(c *gin.Context) {
+	format := c.DefaultQuery("format", service.BackupFormatJSON)
+	if format != service.BackupFormatJSON && format != service.BackupFormatZIP {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or zip"})
+		return
+	}
+
+	archive, err := h.backupService.Export(c.Request.Context(), service.BackupExportOptions{
+		Snapshots: c.DefaultQuery("snapshots", "true") != "false",
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export backup: " + err.Error()})
+		return
+	}
+
+	contentType := "application/json"
+	if format == service.BackupFormatZIP {
+		contentType = "application/zip"
+	}
+	filename := fmt.Sprintf("rotki-backup-%s.%s", archive.Manifest.CreatedAt.Format("20060102-150405"), format)
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+	if err := service.WriteBackup(c.Writer, archive, format); err != nil {
+		// 响应头已发送，只能记录错误
+		logger.FromContext(c.Request.Context()).Error("Failed to write backup", zap.Error(err))
+	}
+}
+
+// RestoreBackup 恢复备份
+// @Summary      恢复备份
+// @Description  将 JSON 或 ZIP 备份（请求体，或 multipart 的 file 字段）恢复到已迁移到最新版本、尚无钱包等业务数据的数据库，需要 admin。
+// @Description  所有记录重新分配 ID 并重写引用；用户按用户名与已有用户合并（保留已有用户的密码），链按 ID 覆盖。整个恢复在一个事务中执行。
+// @Tags         admin
+// @Accept       json
+// @Accept       application/zip
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        file  formData  file  false  "备份文件"
+// @Success      200   {object}  github_com_rotki-demo_internal_service.BackupRestoreResult
+// @Failure      400   {object}  map[string]string
+// @Failure      409   {object}  map[string]string
+// @Failure      500   {object}  map[string]string
+// @Router       /admin/restore [post]
+func (h *BackupHandler) RestoreBackup(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxRestoreSize)
+
+	// 只有 multipart 请求才解析表单，其他请求（包括 curl --data-binary 默认的表单类型）直接读取请求体
+	body := io.Reader(c.Request.Body)
+	if c.ContentType() == "multipart/form-data" {
+		file, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+			return
+		}
+		f, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read uploaded file"})
+			return
+		}
+		defer f.Close()
+		body = f
+	}
+
+	archive, err := service.ReadBackup(body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	start := time.Now()
+	result, err := h.backupService.Restore(c.Request.Context(), archive)
+	switch {
+	case errors.Is(err, service.ErrBackupInvalid):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, service.ErrBackupTargetNotReady):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore backup: " + err.Error()})
+		return
+	}
+	logger.FromContext(c.Request.Context()).Info("Backup restored",
+		zap.Any("restored", result.Restored),
+		zap.Duration("duration", time.Since(start)))
+
+	// 恢复了代币规则和忽略/白名单，重新加载分类器
+	if err := h.classifier.Reload(); err != nil {
+		_ = c.Error(err)
+	}
+	if err := h.classifier.ReloadOverrides(); err != nil {
+		_ = c.Error(err)
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -1,35 +1,79 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/rotki-demo/internal/models"
+	"github.com/rotki-demo/internal/provider"
 	"github.com/rotki-demo/internal/repository"
+	"github.com/rotki-demo/internal/service"
+	"gorm.io/gorm"
 )
 
 // ChainHandler 处理链相关的 HTTP 请求
 type ChainHandler struct {
-	chainRepo *repository.ChainRepository
+	chainRepo        *repository.ChainRepository
+	chainInitializer *service.ChainInitializer
+	dataProvider     provider.DataProvider
 }
 
 // NewChainHandler 创建一个新的链处理器
-func NewChainHandler(chainRepo *repository.ChainRepository) *ChainHandler {
+func NewChainHandler(
+	chainRepo *repository.ChainRepository,
+	chainInitializer *service.ChainInitializer,
+	dataProvider provider.DataProvider,
+) *ChainHandler {
 	return &ChainHandler{
-		chainRepo: chainRepo,
+		chainRepo:        chainRepo,
+		chainInitializer: chainInitializer,
+		dataProvider:     dataProvider,
 	}
 }
 
-// ListChains 获取所有链
+// CreateChainRequest 表示创建链的请求
+type CreateChainRequest struct {
+	ID            string `json:"id" binding:"required"`
+	Name          string `json:"name" binding:"required"`
+	ChainType     string `json:"chain_type"`
+	LogoURL       string `json:"logo_url"`
+	NativeTokenID string `json:"native_token_id"`
+	NetworkID     int64  `json:"network_id"`
+	ExplorerURL   string `json:"explorer_url"`
+	IsActive      *bool  `json:"is_active"`
+}
+
+// UpdateChainRequest 表示更新链的请求
+type UpdateChainRequest struct {
+	Name          string `json:"name"`
+	ChainType     string `json:"chain_type"`
+	LogoURL       string `json:"logo_url"`
+	NativeTokenID string `json:"native_token_id"`
+	NetworkID     *int64 `json:"network_id"`
+	ExplorerURL   string `json:"explorer_url"`
+	IsActive      *bool  `json:"is_active"`
+}
+
+// ListChains 获取链列表
 // @Summary      获取链列表
-// @Description  获取所有支持的区块链列表
+// @Description  获取已激活（支持）的区块链列表，all=true 时返回注册表中的所有链
 // @Tags         chains
 // @Accept       json
 // @Produce      json
+// @Param        all  query     bool  false  "是否包含未激活的链"
 // @Success      200  {array}   github_com_rotki-demo_internal_models.Chain
 // @Failure      500  {object}  map[string]string
 // @Router       /chains [get]
 func (h *ChainHandler) ListChains(c *gin.Context) {
-	chains, err := h.chainRepo.List()
+	var chains []models.Chain
+	var err error
+	if c.Query("all") == "true" {
+		chains, err = h.chainRepo.ListAll()
+	} else {
+		chains, err = h.chainRepo.List()
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve chains"})
 		return
@@ -37,3 +81,216 @@ func (h *ChainHandler) ListChains(c *gin.Context) {
 
 	c.JSON(http.StatusOK, chains)
 }
+
+// GetChain 根据 ID 获取链
+// @Summary      获取链
+// @Description  根据 ID 获取链详情
+// @Tags         chains
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "链 ID"
+// @Success      200  {object}  github_com_rotki-demo_internal_models.Chain
+// @Failure      404  {object}  map[string]string
+// @Router       /chains/{id} [get]
+func (h *ChainHandler) GetChain(c *gin.Context) {
+	chain, err := h.chainRepo.GetByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Chain not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, chain)
+}
+
+// CreateChain 创建一个新链
+// @Summary      创建链
+// @Description  向链注册表添加一个新链，未指定 is_active 时默认激活
+// @Tags         chains
+// @Accept       json
+// @Produce      json
+// @Param        chain  body      CreateChainRequest  true  "链信息"
+// @Success      201    {object}  github_com_rotki-demo_internal_models.Chain
+// @Failure      400    {object}  map[string]string
+// @Failure      409    {object}  map[string]string
+// @Failure      500    {object}  map[string]string
+// @Router       /chains [post]
+func (h *ChainHandler) CreateChain(c *gin.Context) {
+	var req CreateChainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	chainID := strings.ToLower(strings.TrimSpace(req.ID))
+	if chainID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Chain ID is required"})
+		return
+	}
+
+	if _, err := h.chainRepo.GetByID(chainID); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Chain already exists"})
+		return
+	}
+
+	chain := &models.Chain{
+		ID:            chainID,
+		Name:          req.Name,
+		ChainType:     req.ChainType,
+		LogoURL:       req.LogoURL,
+		NativeTokenID: req.NativeTokenID,
+		NetworkID:     req.NetworkID,
+		ExplorerURL:   req.ExplorerURL,
+		IsActive:      true,
+	}
+	if chain.ChainType == "" {
+		chain.ChainType = "EVM"
+	}
+	if req.IsActive != nil {
+		chain.IsActive = *req.IsActive
+	}
+
+	if err := h.chainRepo.Create(chain); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create chain"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, chain)
+}
+
+// UpdateChain 更新链
+// @Summary      更新链
+// @Description  更新链元数据或激活状态
+// @Tags         chains
+// @Accept       json
+// @Produce      json
+// @Param        id     path      string              true  "链 ID"
+// @Param        chain  body      UpdateChainRequest  true  "链信息"
+// @Success      200    {object}  github_com_rotki-demo_internal_models.Chain
+// @Failure      400    {object}  map[string]string
+// @Failure      404    {object}  map[string]string
+// @Failure      500    {object}  map[string]string
+// @Router       /chains/{id} [put]
+func (h *ChainHandler) UpdateChain(c *gin.Context) {
+	chain, err := h.chainRepo.GetByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Chain not found"})
+		return
+	}
+
+	var req UpdateChainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Name != "" {
+		chain.Name = req.Name
+	}
+	if req.ChainType != "" {
+		chain.ChainType = req.ChainType
+	}
+	if req.LogoURL != "" {
+		chain.LogoURL = req.LogoURL
+	}
+	if req.NativeTokenID != "" {
+		chain.NativeTokenID = req.NativeTokenID
+	}
+	if req.NetworkID != nil {
+		chain.NetworkID = *req.NetworkID
+	}
+	if req.ExplorerURL != "" {
+		chain.ExplorerURL = req.ExplorerURL
+	}
+	if req.IsActive != nil {
+		chain.IsActive = *req.IsActive
+	}
+
+	if err := h.chainRepo.Update(chain); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update chain"})
+		return
+	}
+
+	c.JSON(http.StatusOK, chain)
+}
+
+// DeleteChain 删除链
+// @Summary      删除链
+// @Description  从注册表中删除链；仍有代币引用的链只能停用
+// @Tags         chains
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "链 ID"
+// @Success      200  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /chains/{id} [delete]
+func (h *ChainHandler) DeleteChain(c *gin.Context) {
+	chainID := c.Param("id")
+	if _, err := h.chainRepo.GetByID(chainID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Chain not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve chain"})
+		return
+	}
+
+	tokenCount, err := h.chainRepo.CountTokens(chainID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check chain usage"})
+		return
+	}
+	if tokenCount > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Chain is referenced by tokens; set is_active to false instead"})
+		return
+	}
+
+	if err := h.chainRepo.Delete(chainID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete chain"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Chain deleted successfully"})
+}
+
+// SyncChains 重新同步链元数据
+// @Summary      同步链元数据
+// @Description  从 chains.json（source=file）或数据提供者的链列表（source=provider）重新同步链元数据，不修改激活状态
+// @Tags         chains
+// @Accept       json
+// @Produce      json
+// @Param        source  query     string  false  "同步来源：file（默认）或 provider"
+// @Success      200     {object}  map[string]interface{}
+// @Failure      400     {object}  map[string]string
+// @Failure      404     {object}  map[string]string
+// @Failure      502     {object}  map[string]string
+// @Router       /chains/sync [post]
+func (h *ChainHandler) SyncChains(c *gin.Context) {
+	source := c.DefaultQuery("source", "file")
+
+	switch source {
+	case "file":
+		path, ok := service.FindDefaultChainsFile()
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "chains.json not found"})
+			return
+		}
+		if err := h.chainInitializer.InitializeAllChains(path); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sync chains: " + err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "Chains synced successfully", "source": source})
+
+	case "provider":
+		count, err := h.chainInitializer.SyncFromProvider(c.Request.Context(), h.dataProvider)
+		if err != nil {
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to sync chains: " + err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "Chains synced successfully", "source": source, "count": count})
+
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid source, must be 'file' or 'provider'"})
+	}
+}
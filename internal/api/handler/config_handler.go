@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rotki-demo/internal/service"
+)
+
+// ConfigHandler 处理运行时配置查询
+type ConfigHandler struct {
+	configService *service.ConfigService
+}
+
+// NewConfigHandler 创建一个新的配置处理器
+func NewConfigHandler(configService *service.ConfigService) *ConfigHandler {
+	return &ConfigHandler{configService: configService}
+}
+
+// GetConfig 获取当前生效的配置
+// @Summary      获取当前生效的配置
+// @Description  获取当前生效的配置（键名与配置文件相同，密码、API key 和请求头的值已隐藏）以及热加载状态，需要 admin。
+// @Description  pending_restart 列出配置文件中已修改但需要重启才能生效的键，last_error 为最近一次被拒绝的修改的原因。
+// @Tags         admin
+// @Produce      json
+// @Success      200  {object}  github_com_rotki-demo_internal_service.ConfigStatus
+// @Router       /admin/config [get]
+func (h *ConfigHandler) GetConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, h.configService.Status())
+}
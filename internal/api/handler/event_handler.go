@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rotki-demo/internal/events"
+	"github.com/rotki-demo/internal/logger"
+	"github.com/rotki-demo/internal/models"
+	"github.com/rotki-demo/internal/service"
+	"go.uber.org/zap"
+)
+
+// EventHandler 通过 Server-Sent Events 推送实时事件
+type EventHandler struct {
+	bus       *events.Bus
+	access    *service.AccessService
+	heartbeat time.Duration
+}
+
+// NewEventHandler 创建一个新的事件流处理器
+func NewEventHandler(bus *events.Bus, access *service.AccessService, heartbeat time.Duration) *EventHandler {
+	if heartbeat <= 0 {
+		heartbeat = 15 * time.Second
+	}
+	return &EventHandler{
+		bus:       bus,
+		access:    access,
+		heartbeat: heartbeat,
+	}
+}
+
+// ListEventTypes 获取事件流可订阅的事件类型
+// @Summary      获取实时事件类型
+// @Description  获取 /events 可订阅的所有事件类型，包括只推送到事件流的 sync.progress 和 rpc_node.status_changed
+// @Tags         events
+// @Accept       json
+// @Produce      json
+// @Success      200  {array}  string
+// @Router       /events/types [get]
+func (h *EventHandler) ListEventTypes(c *gin.Context) {
+	c.JSON(http.StatusOK, events.StreamTypes())
+}
+
+// StreamEvents 以 SSE 推送实时事件
+// @Summary      订阅实时事件
+// @Description  以 Server-Sent Events 推送同步进度、地址同步完成（含新的总价值）、RPC 节点状态变化等事件。
+// @Description  每条消息的 event 为事件类型，data 为事件 JSON。按钱包或地址过滤时仍会收到全局事件（RPC 节点状态、定时同步进度）。
+// @Description  普通用户只会收到自己拥有或被共享的钱包的事件。
+// @Tags         events
+// @Produce      text/event-stream
+// @Param        wallet_id   query     int     false  "钱包 ID"
+// @Param        address_id  query     int     false  "地址 ID"
+// @Param        types       query     string  false  "事件类型，逗号分隔"
+// @Success      200         {string}  string  "事件流"
+// @Failure      400         {object}  map[string]string
+// @Failure      404         {object}  map[string]string
+// @Router       /events [get]
+func (h *EventHandler) StreamEvents(c *gin.Context) {
+	var filter events.Filter
+	var err error
+	if filter.WalletID, err = parseUintQuery(c, "wallet_id"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid wallet ID"})
+		return
+	}
+	if filter.AddressID, err = parseUintQuery(c, "address_id"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid address ID"})
+		return
+	}
+	if filter.WalletID != 0 {
+		if _, ok := authorizeWallet(c, h.access, filter.WalletID, models.SharePermissionView); !ok {
+			return
+		}
+	}
+	scope, ok := walletScope(c, h.access)
+	if !ok {
+		return
+	}
+	filter.Restricted, filter.Wallets = scope.Restricted, scope.WalletIDs
+	if raw := c.Query("types"); raw != "" {
+		for _, t := range strings.Split(raw, ",") {
+			t = strings.TrimSpace(t)
+			if t == "" {
+				continue
+			}
+			if !events.IsValidStreamType(t) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unknown event type %q", t)})
+				return
+			}
+			filter.Types = append(filter.Types, t)
+		}
+	}
+
+	sub := h.bus.Subscribe(filter)
+	defer sub.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // 关闭 nginx 缓冲
+	c.Status(http.StatusOK)
+
+	// 先发送一条注释，让客户端立即确认连接已建立
+	fmt.Fprint(c.Writer, ": connected\n\n")
+	c.Writer.Flush()
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	ctx := c.Request.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := fmt.Fprint(c.Writer, ": ping\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case event, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				logger.Warn("Failed to encode event", zap.String("event_type", event.Type), zap.Error(err))
+				continue
+			}
+			if _, err := fmt.Fprintf(c.Writer, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
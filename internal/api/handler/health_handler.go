@@ -0,0 +1,342 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rotki-demo/internal/models"
+	"github.com/rotki-demo/internal/repository"
+	"github.com/rotki-demo/internal/service"
+)
+
+// HealthHandler 处理健康因子监控和告警相关的 HTTP 请求
+type HealthHandler struct {
+	healthRepo *repository.HealthRepository
+	access     *service.AccessService
+	monitor    *service.HealthMonitor
+}
+
+// NewHealthHandler 创建一个新的健康因子处理器
+func NewHealthHandler(
+	healthRepo *repository.HealthRepository,
+	access *service.AccessService,
+	monitor *service.HealthMonitor,
+) *HealthHandler {
+	return &HealthHandler{
+		healthRepo: healthRepo,
+		access:     access,
+		monitor:    monitor,
+	}
+}
+
+// HealthThresholdRequest 表示创建或更新告警阈值的请求
+type HealthThresholdRequest struct {
+	WalletID    uint    `json:"wallet_id"`    // 0 或省略表示所有钱包
+	ProtocolID  string  `json:"protocol_id"`  // 空表示所有协议
+	PositionKey string  `json:"position_key"` // 空表示协议下所有持仓
+	Threshold   float64 `json:"threshold" binding:"required,gt=0"`
+	DropPercent float64 `json:"drop_percent" binding:"gte=0,lte=100"`
+}
+
+// TestNotificationRequest 表示发送测试通知的请求
+type TestNotificationRequest struct {
+	Message string `json:"message"`
+}
+
+// parseLimit 解析 limit 查询参数（默认 100，最大 1000）
+func parseLimit(c *gin.Context) (int, bool) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 {
+		return 0, false
+	}
+	if limit > 1000 {
+		limit = 1000
+	}
+	return limit, true
+}
+
+// parseUintQuery 解析可选的无符号整数查询参数，未设置时返回 0
+func parseUintQuery(c *gin.Context, key string) (uint, error) {
+	value := c.Query(key)
+	if value == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseUint(value, 10, 32)
+	return uint(id), err
+}
+
+// authorizeThreshold 检查调用方能否修改作用于钱包的阈值，全局阈值只有管理员可以修改
+func (h *HealthHandler) authorizeThreshold(c *gin.Context, walletID uint) bool {
+	if walletID == 0 {
+		return requireUnrestricted(c)
+	}
+	_, ok := authorizeWallet(c, h.access, walletID, models.SharePermissionEdit)
+	return ok
+}
+
+// ListHealthThresholds 获取告警阈值
+// @Summary      获取健康因子告警阈值
+// @Description  获取用户设置的健康因子告警阈值；没有匹配的设置时使用配置文件中的默认值
+// @Tags         health
+// @Accept       json
+// @Produce      json
+// @Success      200  {array}   github_com_rotki-demo_internal_models.HealthThreshold
+// @Failure      500  {object}  map[string]string
+// @Router       /health-thresholds [get]
+func (h *HealthHandler) ListHealthThresholds(c *gin.Context) {
+	scope, ok := walletScope(c, h.access)
+	if !ok {
+		return
+	}
+
+	thresholds, err := h.healthRepo.ListThresholds(scope)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve health thresholds"})
+		return
+	}
+
+	c.JSON(http.StatusOK, thresholds)
+}
+
+// CreateHealthThreshold 创建告警阈值
+// @Summary      创建健康因子告警阈值
+// @Description  按钱包、协议或持仓设置告警阈值，匹配时最具体的设置优先
+// @Tags         health
+// @Accept       json
+// @Produce      json
+// @Param        threshold  body      HealthThresholdRequest  true  "阈值设置"
+// @Success      201        {object}  github_com_rotki-demo_internal_models.HealthThreshold
+// @Failure      400        {object}  map[string]string
+// @Failure      403        {object}  map[string]string
+// @Failure      404        {object}  map[string]string
+// @Failure      500        {object}  map[string]string
+// @Router       /health-thresholds [post]
+func (h *HealthHandler) CreateHealthThreshold(c *gin.Context) {
+	var req HealthThresholdRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !h.authorizeThreshold(c, req.WalletID) {
+		return
+	}
+
+	threshold := &models.HealthThreshold{
+		WalletID:    req.WalletID,
+		ProtocolID:  req.ProtocolID,
+		PositionKey: req.PositionKey,
+		Threshold:   req.Threshold,
+		DropPercent: req.DropPercent,
+	}
+	if err := h.healthRepo.CreateThreshold(threshold); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create health threshold"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, threshold)
+}
+
+// UpdateHealthThreshold 更新告警阈值
+// @Summary      更新健康因子告警阈值
+// @Description  更新告警阈值和下降百分比，作用范围（钱包、协议、持仓）不可修改
+// @Tags         health
+// @Accept       json
+// @Produce      json
+// @Param        id         path      int                     true  "阈值 ID"
+// @Param        threshold  body      HealthThresholdRequest  true  "阈值设置"
+// @Success      200        {object}  github_com_rotki-demo_internal_models.HealthThreshold
+// @Failure      400        {object}  map[string]string
+// @Failure      403        {object}  map[string]string
+// @Failure      404        {object}  map[string]string
+// @Failure      500        {object}  map[string]string
+// @Router       /health-thresholds/{id} [put]
+func (h *HealthHandler) UpdateHealthThreshold(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid threshold ID"})
+		return
+	}
+
+	threshold, err := h.healthRepo.GetThresholdByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Health threshold not found"})
+		return
+	}
+	if !h.authorizeThreshold(c, threshold.WalletID) {
+		return
+	}
+
+	var req HealthThresholdRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	threshold.Threshold = req.Threshold
+	threshold.DropPercent = req.DropPercent
+
+	if err := h.healthRepo.UpdateThreshold(threshold); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update health threshold"})
+		return
+	}
+
+	c.JSON(http.StatusOK, threshold)
+}
+
+// DeleteHealthThreshold 删除告警阈值
+// @Summary      删除健康因子告警阈值
+// @Description  根据 ID 删除告警阈值
+// @Tags         health
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "阈值 ID"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /health-thresholds/{id} [delete]
+func (h *HealthHandler) DeleteHealthThreshold(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid threshold ID"})
+		return
+	}
+
+	threshold, err := h.healthRepo.GetThresholdByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Health threshold not found"})
+		return
+	}
+	if !h.authorizeThreshold(c, threshold.WalletID) {
+		return
+	}
+
+	if err := h.healthRepo.DeleteThreshold(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete health threshold"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Health threshold deleted successfully"})
+}
+
+// ListHealthAlerts 获取已触发的告警
+// @Summary      获取健康因子告警
+// @Description  按时间倒序获取已触发的清算风险告警及其投递结果
+// @Tags         health
+// @Accept       json
+// @Produce      json
+// @Param        wallet_id   query     int     false  "钱包 ID"
+// @Param        address_id  query     int     false  "地址 ID"
+// @Param        alert_type  query     string  false  "告警类型（below_threshold、sharp_drop）"
+// @Param        limit       query     int     false  "返回条数（默认 100，最大 1000）"
+// @Success      200         {array}   github_com_rotki-demo_internal_models.HealthAlert
+// @Failure      400         {object}  map[string]string
+// @Failure      500         {object}  map[string]string
+// @Router       /health-alerts [get]
+func (h *HealthHandler) ListHealthAlerts(c *gin.Context) {
+	walletID, err := parseUintQuery(c, "wallet_id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid wallet ID"})
+		return
+	}
+	addressID, err := parseUintQuery(c, "address_id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid address ID"})
+		return
+	}
+	limit, ok := parseLimit(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+		return
+	}
+
+	scope, ok := walletScope(c, h.access)
+	if !ok {
+		return
+	}
+
+	alerts, err := h.healthRepo.ListAlerts(repository.HealthAlertFilter{
+		WalletID:  walletID,
+		AddressID: addressID,
+		AlertType: c.Query("alert_type"),
+		Scope:     scope,
+	}, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve health alerts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, alerts)
+}
+
+// ListHealthHistory 获取健康因子历史
+// @Summary      获取健康因子历史
+// @Description  按时间倒序获取每次同步记录的借贷持仓健康因子
+// @Tags         health
+// @Accept       json
+// @Produce      json
+// @Param        address_id    query     int     false  "地址 ID"
+// @Param        protocol_id   query     string  false  "协议 ID"
+// @Param        chain_id      query     string  false  "链 ID"
+// @Param        position_key  query     string  false  "持仓标识（池子 ID 或持仓名称）"
+// @Param        limit         query     int     false  "返回条数（默认 100，最大 1000）"
+// @Success      200           {array}   github_com_rotki-demo_internal_models.HealthRateRecord
+// @Failure      400           {object}  map[string]string
+// @Failure      500           {object}  map[string]string
+// @Router       /health-history [get]
+func (h *HealthHandler) ListHealthHistory(c *gin.Context) {
+	addressID, err := parseUintQuery(c, "address_id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid address ID"})
+		return
+	}
+	limit, ok := parseLimit(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+		return
+	}
+
+	scope, ok := walletScope(c, h.access)
+	if !ok {
+		return
+	}
+
+	records, err := h.healthRepo.ListHistory(repository.HealthHistoryFilter{
+		AddressID:   addressID,
+		ProtocolID:  c.Query("protocol_id"),
+		ChainID:     c.Query("chain_id"),
+		PositionKey: c.Query("position_key"),
+		Scope:       scope,
+	}, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve health history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, records)
+}
+
+// TestNotification 发送测试通知
+// @Summary      发送测试通知
+// @Description  通过所有已配置的通知渠道（stdout、webhook、smtp）发送一条测试通知，用于本地验证配置
+// @Tags         health
+// @Accept       json
+// @Produce      json
+// @Param        notification  body      TestNotificationRequest  false  "通知内容"
+// @Success      200           {object}  map[string]string
+// @Failure      502           {object}  map[string]string
+// @Router       /notifications/test [post]
+func (h *HealthHandler) TestNotification(c *gin.Context) {
+	var req TestNotificationRequest
+	_ = c.ShouldBindJSON(&req)
+
+	if err := h.monitor.SendTestNotification(c.Request.Context(), req.Message); err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to deliver notification: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Test notification sent"})
+}
@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rotki-demo/internal/models"
+	"github.com/rotki-demo/internal/repository"
+	"github.com/rotki-demo/internal/service"
+)
+
+// PositionHandler 处理协议持仓相关的 HTTP 请求
+type PositionHandler struct {
+	positionRepo *repository.ProtocolPositionRepository
+	addressRepo  *repository.AddressRepository
+	access       *service.AccessService
+}
+
+// NewPositionHandler 创建一个新的持仓处理器
+func NewPositionHandler(
+	positionRepo *repository.ProtocolPositionRepository,
+	addressRepo *repository.AddressRepository,
+	access *service.AccessService,
+) *PositionHandler {
+	return &PositionHandler{
+		positionRepo: positionRepo,
+		addressRepo:  addressRepo,
+		access:       access,
+	}
+}
+
+// ListPositions 查询协议持仓
+// @Summary      查询协议持仓
+// @Description  跨地址查询协议持仓明细（包括 supply/borrow/reward 代币），可按协议、链、仓位类型、地址或钱包过滤
+// @Tags         positions
+// @Accept       json
+// @Produce      json
+// @Param        protocol_id    query     string  false  "协议 ID"
+// @Param        chain_id       query     string  false  "链 ID"
+// @Param        position_type  query     string  false  "仓位类型（lending、staking、liquidity 等）"
+// @Param        address_id     query     int     false  "地址 ID"
+// @Param        wallet_id      query     int     false  "钱包 ID"
+// @Success      200            {array}   github_com_rotki-demo_internal_models.ProtocolPosition
+// @Failure      400            {object}  map[string]string
+// @Failure      500            {object}  map[string]string
+// @Router       /positions [get]
+func (h *PositionHandler) ListPositions(c *gin.Context) {
+	filter := repository.PositionFilter{
+		ProtocolID:   c.Query("protocol_id"),
+		ChainID:      c.Query("chain_id"),
+		PositionType: c.Query("position_type"),
+	}
+
+	if addressIDStr := c.Query("address_id"); addressIDStr != "" {
+		addressID, err := strconv.ParseUint(addressIDStr, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid address ID"})
+			return
+		}
+		filter.AddressID = uint(addressID)
+	}
+
+	if walletIDStr := c.Query("wallet_id"); walletIDStr != "" {
+		walletID, err := strconv.ParseUint(walletIDStr, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid wallet ID"})
+			return
+		}
+		filter.WalletID = uint(walletID)
+	}
+
+	scope, ok := walletScope(c, h.access)
+	if !ok {
+		return
+	}
+	filter.Scope = scope
+
+	positions, err := h.positionRepo.List(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve positions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, positions)
+}
+
+// GetPosition 根据 ID 获取协议持仓
+// @Summary      获取协议持仓
+// @Description  根据 ID 获取协议持仓及其代币
+// @Tags         positions
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "持仓 ID"
+// @Success      200  {object}  github_com_rotki-demo_internal_models.ProtocolPosition
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /positions/{id} [get]
+func (h *PositionHandler) GetPosition(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid position ID"})
+		return
+	}
+
+	position, err := h.positionRepo.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Position not found"})
+		return
+	}
+	address, err := h.addressRepo.GetByID(position.AddressID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Position not found"})
+		return
+	}
+	if _, err := h.access.AuthorizeWallet(principalFromContext(c), address.WalletID, models.SharePermissionView); err != nil {
+		writeAccessError(c, err, "Position not found", models.SharePermissionView)
+		return
+	}
+
+	c.JSON(http.StatusOK, position)
+}
+
+// ListAddressPositions 获取地址的协议持仓
+// @Summary      获取地址的协议持仓
+// @Description  获取指定地址的所有协议持仓明细
+// @Tags         addresses
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "地址 ID"
+// @Success      200  {array}   github_com_rotki-demo_internal_models.ProtocolPosition
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /addresses/{id}/positions [get]
+func (h *PositionHandler) ListAddressPositions(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid address ID"})
+		return
+	}
+
+	if _, ok := authorizeAddress(c, h.access, h.addressRepo, uint(id), models.SharePermissionView); !ok {
+		return
+	}
+
+	positions, err := h.positionRepo.List(repository.PositionFilter{AddressID: uint(id)})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve positions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, positions)
+}
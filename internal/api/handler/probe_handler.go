@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rotki-demo/internal/service"
+)
+
+// ProbeHandler 提供 Kubernetes 存活和就绪探针
+// 探针位于 /api/v1 之外且不需要认证，响应只包含组件状态，不包含业务数据
+type ProbeHandler struct {
+	service *service.ProbeService
+}
+
+// NewProbeHandler 创建一个新的探针处理器
+func NewProbeHandler(service *service.ProbeService) *ProbeHandler {
+	return &ProbeHandler{service: service}
+}
+
+// Livez 存活探针：同步循环卡住时返回 503，由编排系统重启进程
+// GET /livez
+func (h *ProbeHandler) Livez(c *gin.Context) {
+	h.respond(c, h.service.Liveness(c.Request.Context()))
+}
+
+// Readyz 就绪探针：返回各组件的检查结果，必需组件失败时返回 503，停止向本实例转发流量
+// GET /readyz
+func (h *ProbeHandler) Readyz(c *gin.Context) {
+	h.respond(c, h.service.Readiness(c.Request.Context()))
+}
+
+// respond 按整体状态写出报告
+func (h *ProbeHandler) respond(c *gin.Context, report *service.ProbeReport) {
+	status := http.StatusOK
+	if !report.Healthy() {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
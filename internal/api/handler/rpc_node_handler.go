@@ -3,6 +3,7 @@ package handler
 import (
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rotki-demo/internal/models"
@@ -90,25 +91,34 @@ func (h *RPCNodeHandler) GetRPCNode(c *gin.Context) {
 
 // ListRPCNodes 处理获取所有 RPC 节点
 // @Summary      获取 RPC 节点列表
-// @Description  获取所有 RPC 节点，可按链 ID 过滤
+// @Description  获取所有 RPC 节点，可按链 ID 和能力过滤
 // @Tags         rpc-nodes
 // @Produce      json
-// @Param        chain_id  query     string  false  "按链 ID 过滤"
-// @Success      200       {array}   github_com_rotki-demo_internal_models.RPCNode
-// @Failure      500       {object}  map[string]string
+// @Param        chain_id    query     string  false  "按链 ID 过滤"
+// @Param        capability  query     string  false  "要求的能力，逗号分隔（archive、trace、debug、websocket）"
+// @Success      200         {array}   github_com_rotki-demo_internal_models.RPCNode
+// @Failure      400         {object}  map[string]string
+// @Failure      500         {object}  map[string]string
 // @Router       /rpc-nodes [get]
 func (h *RPCNodeHandler) ListRPCNodes(c *gin.Context) {
 	chainID := c.Query("chain_id")
 
-	var nodes []models.RPCNode
-	var err error
-
-	if chainID != "" {
-		nodes, err = h.service.GetByChainID(c.Request.Context(), chainID)
-	} else {
-		nodes, err = h.service.GetAll(c.Request.Context())
+	var capabilities []string
+	for _, value := range c.QueryArray("capability") {
+		for _, capability := range strings.Split(value, ",") {
+			capability = strings.TrimSpace(capability)
+			if capability == "" {
+				continue
+			}
+			if !models.IsValidRPCCapability(capability) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid capability: " + capability})
+				return
+			}
+			capabilities = append(capabilities, capability)
+		}
 	}
 
+	nodes, err := h.service.GetByChainIDWithCapabilities(c.Request.Context(), chainID, capabilities...)
 	if err != nil {
 		h.logger.Error("Failed to list RPC nodes", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve RPC nodes"})
@@ -171,6 +181,24 @@ func (h *RPCNodeHandler) UpdateRPCNode(c *gin.Context) {
 	req.ID = uint(id)
 	req.CreatedAt = existing.CreatedAt
 
+	// 能力由探测得出，不允许通过更新接口修改；地址变化后旧结果不再适用，
+	// 清空后下一次连接检查会重新探测
+	if req.URL == existing.URL && req.WSURL == existing.WSURL {
+		req.IsArchive = existing.IsArchive
+		req.SupportsTrace = existing.SupportsTrace
+		req.SupportsDebug = existing.SupportsDebug
+		req.SupportsWebSocket = existing.SupportsWebSocket
+		req.MaxLogsBlockRange = existing.MaxLogsBlockRange
+		req.CapabilitiesCheckedAt = existing.CapabilitiesCheckedAt
+	} else {
+		req.IsArchive = false
+		req.SupportsTrace = false
+		req.SupportsDebug = false
+		req.SupportsWebSocket = false
+		req.MaxLogsBlockRange = 0
+		req.CapabilitiesCheckedAt = nil
+	}
+
 	if err := h.service.Update(c.Request.Context(), &req); err != nil {
 		h.logger.Error("Failed to update RPC node", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update RPC node"})
@@ -235,6 +263,34 @@ func (h *RPCNodeHandler) CheckRPCNodeConnection(c *gin.Context) {
 	})
 }
 
+// CheckRPCNodeCapabilities 处理探测特定 RPC 节点的能力
+// @Summary Detect RPC node capabilities
+// @Description 探测节点的归档状态、eth_getLogs 范围上限、trace/debug 支持和 WebSocket 可用性
+// @Tags rpc-nodes
+// @Produce json
+// @Param id path int true "RPC node ID"
+// @Success 200 {object} models.RPCNode
+// @Router /api/v1/rpc-nodes/{id}/capabilities [post]
+func (h *RPCNodeHandler) CheckRPCNodeCapabilities(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	node, err := h.service.CheckCapabilities(c.Request.Context(), uint(id))
+	if err != nil {
+		h.logger.Warn("Capability detection failed",
+			zap.Uint64("node_id", id),
+			zap.Error(err))
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, node)
+}
+
 // CheckAllRPCNodeConnections 处理测试所有 RPC 节点的连接
 // @Summary Check all RPC node connections
 // @Tags rpc-nodes
@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rotki-demo/internal/api/middleware"
+	"github.com/rotki-demo/internal/models"
+	"github.com/rotki-demo/internal/repository"
+	"github.com/rotki-demo/internal/service"
+	"gorm.io/gorm"
+)
+
+// TokenOverrideHandler 处理代币忽略/白名单相关的 HTTP 请求
+type TokenOverrideHandler struct {
+	overrideRepo    *repository.TokenOverrideRepository
+	overrideService *service.TokenOverrideService
+	access          *service.AccessService
+}
+
+// NewTokenOverrideHandler 创建一个新的代币覆盖处理器
+func NewTokenOverrideHandler(
+	overrideRepo *repository.TokenOverrideRepository,
+	overrideService *service.TokenOverrideService,
+	access *service.AccessService,
+) *TokenOverrideHandler {
+	return &TokenOverrideHandler{
+		overrideRepo:    overrideRepo,
+		overrideService: overrideService,
+		access:          access,
+	}
+}
+
+// authorizeOverride 检查调用方能否修改钱包的覆盖设置，全局设置只有管理员可以修改
+func (h *TokenOverrideHandler) authorizeOverride(c *gin.Context, walletID uint) bool {
+	if walletID == 0 {
+		return requireUnrestricted(c)
+	}
+	_, ok := authorizeWallet(c, h.access, walletID, models.SharePermissionEdit)
+	return ok
+}
+
+// SetTokenOverrideRequest 表示设置代币忽略/白名单的请求
+type SetTokenOverrideRequest struct {
+	ChainID  string `json:"chain_id" binding:"required"`
+	TokenID  string `json:"token_id" binding:"required"`
+	WalletID uint   `json:"wallet_id"` // 0 或省略表示全局
+	Action   string `json:"action" binding:"required,oneof=ignore show"`
+	Reason   string `json:"reason"`
+}
+
+// parseOverrideFilter 从查询参数解析过滤条件
+func parseOverrideFilter(c *gin.Context) (repository.TokenOverrideFilter, error) {
+	filter := repository.TokenOverrideFilter{
+		ChainID: c.Query("chain_id"),
+		TokenID: strings.ToLower(c.Query("token_id")),
+	}
+	if walletIDStr := c.Query("wallet_id"); walletIDStr != "" {
+		walletID, err := strconv.ParseUint(walletIDStr, 10, 32)
+		if err != nil {
+			return filter, err
+		}
+		id := uint(walletID)
+		filter.WalletID = &id
+	}
+	return filter, nil
+}
+
+// ListTokenOverrides 获取代币忽略/白名单
+// @Summary      获取代币忽略/白名单
+// @Description  获取用户设置的代币忽略/白名单，wallet_id=0 表示全局设置
+// @Tags         token-overrides
+// @Accept       json
+// @Produce      json
+// @Param        chain_id   query     string  false  "链 ID"
+// @Param        token_id   query     string  false  "代币 ID"
+// @Param        wallet_id  query     int     false  "钱包 ID（0 表示全局）"
+// @Success      200        {array}   github_com_rotki-demo_internal_models.TokenOverride
+// @Failure      400        {object}  map[string]string
+// @Failure      500        {object}  map[string]string
+// @Router       /token-overrides [get]
+func (h *TokenOverrideHandler) ListTokenOverrides(c *gin.Context) {
+	filter, err := parseOverrideFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid wallet ID"})
+		return
+	}
+	var ok bool
+	if filter.Scope, ok = walletScope(c, h.access); !ok {
+		return
+	}
+
+	overrides, err := h.overrideRepo.List(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve token overrides"})
+		return
+	}
+
+	c.JSON(http.StatusOK, overrides)
+}
+
+// SetTokenOverride 设置代币忽略/白名单
+// @Summary      设置代币忽略/白名单
+// @Description  将 (chain_id, token_id) 标记为忽略（ignore）或始终显示（show），可以全局或按钱包设置；已存在时更新
+// @Tags         token-overrides
+// @Accept       json
+// @Produce      json
+// @Param        override  body      SetTokenOverrideRequest  true  "覆盖设置"
+// @Success      200       {object}  github_com_rotki-demo_internal_models.TokenOverride
+// @Failure      400       {object}  map[string]string
+// @Failure      403       {object}  map[string]string
+// @Failure      404       {object}  map[string]string
+// @Failure      500       {object}  map[string]string
+// @Router       /token-overrides [post]
+func (h *TokenOverrideHandler) SetTokenOverride(c *gin.Context) {
+	var req SetTokenOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !h.authorizeOverride(c, req.WalletID) {
+		return
+	}
+
+	override, err := h.overrideService.Set(req.ChainID, req.TokenID, req.WalletID, req.Action, req.Reason, middleware.Actor(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save token override"})
+		return
+	}
+
+	c.JSON(http.StatusOK, override)
+}
+
+// DeleteTokenOverride 删除代币忽略/白名单
+// @Summary      删除代币忽略/白名单
+// @Description  删除覆盖设置，代币恢复按分类规则处理
+// @Tags         token-overrides
+// @Accept       json
+// @Produce      json
+// @Param        id      path      int     true   "覆盖设置 ID"
+// @Param        reason  query     string  false  "删除原因（记录到审计）"
+// @Success      200     {object}  map[string]string
+// @Failure      400     {object}  map[string]string
+// @Failure      403     {object}  map[string]string
+// @Failure      404     {object}  map[string]string
+// @Failure      500     {object}  map[string]string
+// @Router       /token-overrides/{id} [delete]
+func (h *TokenOverrideHandler) DeleteTokenOverride(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid override ID"})
+		return
+	}
+
+	override, err := h.overrideRepo.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Token override not found"})
+		return
+	}
+	if !h.authorizeOverride(c, override.WalletID) {
+		return
+	}
+
+	if err := h.overrideService.Remove(uint(id), c.Query("reason"), middleware.Actor(c)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Token override not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete token override"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Token override deleted successfully"})
+}
+
+// ListTokenOverrideAudits 获取忽略/白名单变更审计
+// @Summary      获取忽略/白名单审计记录
+// @Description  按时间倒序获取代币忽略/白名单的变更记录（谁在何时修改了什么）
+// @Tags         token-overrides
+// @Accept       json
+// @Produce      json
+// @Param        chain_id   query     string  false  "链 ID"
+// @Param        token_id   query     string  false  "代币 ID"
+// @Param        wallet_id  query     int     false  "钱包 ID（0 表示全局）"
+// @Param        limit      query     int     false  "返回条数（默认 100，最大 1000）"
+// @Success      200        {array}   github_com_rotki-demo_internal_models.TokenOverrideAudit
+// @Failure      400        {object}  map[string]string
+// @Failure      500        {object}  map[string]string
+// @Router       /token-overrides/audit [get]
+func (h *TokenOverrideHandler) ListTokenOverrideAudits(c *gin.Context) {
+	filter, err := parseOverrideFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid wallet ID"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+		return
+	}
+	if limit > 1000 {
+		limit = 1000
+	}
+	var ok bool
+	if filter.Scope, ok = walletScope(c, h.access); !ok {
+		return
+	}
+
+	audits, err := h.overrideRepo.ListAudits(filter, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve audit records"})
+		return
+	}
+
+	c.JSON(http.StatusOK, audits)
+}
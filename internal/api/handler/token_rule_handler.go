@@ -0,0 +1,248 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rotki-demo/internal/models"
+	"github.com/rotki-demo/internal/repository"
+	"github.com/rotki-demo/internal/service"
+)
+
+// TokenRuleHandler 处理代币分类规则相关的 HTTP 请求
+type TokenRuleHandler struct {
+	ruleRepo   *repository.TokenRuleRepository
+	classifier *service.TokenClassifier
+}
+
+// NewTokenRuleHandler 创建一个新的代币规则处理器
+func NewTokenRuleHandler(ruleRepo *repository.TokenRuleRepository, classifier *service.TokenClassifier) *TokenRuleHandler {
+	return &TokenRuleHandler{
+		ruleRepo:   ruleRepo,
+		classifier: classifier,
+	}
+}
+
+// TokenRuleRequest 表示创建或更新代币规则的请求
+type TokenRuleRequest struct {
+	Name           string   `json:"name" binding:"required"`
+	Classification string   `json:"classification" binding:"required"`
+	Priority       int      `json:"priority"`
+	ChainID        string   `json:"chain_id"`
+	SymbolPattern  string   `json:"symbol_pattern"`
+	NamePattern    string   `json:"name_pattern"`
+	TextPattern    string   `json:"text_pattern"`
+	TokenIDs       []string `json:"token_ids"`
+	MaxPrice       *float64 `json:"max_price"`
+	MaxUSDValue    *float64 `json:"max_usd_value"`
+	IsVerified     *bool    `json:"is_verified"`
+	IsCore         *bool    `json:"is_core"`
+	Enabled        *bool    `json:"enabled"`
+}
+
+// apply 将请求字段写入规则
+func (req *TokenRuleRequest) apply(rule *models.TokenRule) {
+	rule.Name = req.Name
+	rule.Classification = req.Classification
+	rule.Priority = req.Priority
+	rule.ChainID = req.ChainID
+	rule.SymbolPattern = req.SymbolPattern
+	rule.NamePattern = req.NamePattern
+	rule.TextPattern = req.TextPattern
+	rule.TokenIDs = models.StringSlice(req.TokenIDs)
+	rule.MaxPrice = req.MaxPrice
+	rule.MaxUSDValue = req.MaxUSDValue
+	rule.IsVerified = req.IsVerified
+	rule.IsCore = req.IsCore
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+}
+
+// ListTokenRules 获取数据库中的代币规则
+// @Summary      获取代币规则列表
+// @Description  获取通过 API 管理的代币分类规则；effective=true 时返回合并配置和内置规则后的生效规则
+// @Tags         token-rules
+// @Accept       json
+// @Produce      json
+// @Param        effective  query     bool  false  "是否返回生效规则"
+// @Success      200        {array}   github_com_rotki-demo_internal_models.TokenRule
+// @Failure      500        {object}  map[string]string
+// @Router       /token-rules [get]
+func (h *TokenRuleHandler) ListTokenRules(c *gin.Context) {
+	if c.Query("effective") == "true" {
+		c.JSON(http.StatusOK, h.classifier.Rules())
+		return
+	}
+
+	rules, err := h.ruleRepo.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve token rules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// GetTokenRule 根据 ID 获取代币规则
+// @Summary      获取代币规则
+// @Description  根据 ID 获取代币分类规则
+// @Tags         token-rules
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "规则 ID"
+// @Success      200  {object}  github_com_rotki-demo_internal_models.TokenRule
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /token-rules/{id} [get]
+func (h *TokenRuleHandler) GetTokenRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rule ID"})
+		return
+	}
+
+	rule, err := h.ruleRepo.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Token rule not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// CreateTokenRule 创建代币规则
+// @Summary      创建代币规则
+// @Description  创建代币分类规则，所有已设置的条件都满足时匹配；新规则对之后的同步生效
+// @Tags         token-rules
+// @Accept       json
+// @Produce      json
+// @Param        rule  body      TokenRuleRequest  true  "规则信息"
+// @Success      201   {object}  github_com_rotki-demo_internal_models.TokenRule
+// @Failure      400   {object}  map[string]string
+// @Failure      500   {object}  map[string]string
+// @Router       /token-rules [post]
+func (h *TokenRuleHandler) CreateTokenRule(c *gin.Context) {
+	var req TokenRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule := &models.TokenRule{Enabled: true}
+	req.apply(rule)
+
+	if err := service.ValidateTokenRule(rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.ruleRepo.Create(rule); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create token rule"})
+		return
+	}
+
+	h.reload(c)
+	c.JSON(http.StatusCreated, rule)
+}
+
+// UpdateTokenRule 更新代币规则
+// @Summary      更新代币规则
+// @Description  替换代币分类规则的所有字段
+// @Tags         token-rules
+// @Accept       json
+// @Produce      json
+// @Param        id    path      int               true  "规则 ID"
+// @Param        rule  body      TokenRuleRequest  true  "规则信息"
+// @Success      200   {object}  github_com_rotki-demo_internal_models.TokenRule
+// @Failure      400   {object}  map[string]string
+// @Failure      404   {object}  map[string]string
+// @Failure      500   {object}  map[string]string
+// @Router       /token-rules/{id} [put]
+func (h *TokenRuleHandler) UpdateTokenRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rule ID"})
+		return
+	}
+
+	rule, err := h.ruleRepo.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Token rule not found"})
+		return
+	}
+
+	var req TokenRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.apply(rule)
+
+	if err := service.ValidateTokenRule(rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.ruleRepo.Update(rule); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update token rule"})
+		return
+	}
+
+	h.reload(c)
+	c.JSON(http.StatusOK, rule)
+}
+
+// DeleteTokenRule 删除代币规则
+// @Summary      删除代币规则
+// @Description  根据 ID 删除代币分类规则
+// @Tags         token-rules
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "规则 ID"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /token-rules/{id} [delete]
+func (h *TokenRuleHandler) DeleteTokenRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rule ID"})
+		return
+	}
+
+	if err := h.ruleRepo.Delete(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete token rule"})
+		return
+	}
+
+	h.reload(c)
+	c.JSON(http.StatusOK, gin.H{"message": "Token rule deleted successfully"})
+}
+
+// ReclassifyTokens 使用当前规则重新分类已存储的代币
+// @Summary      重新分类代币
+// @Description  使用当前生效的规则重新分类所有已存储的钱包代币，无需等待下一次同步
+// @Tags         token-rules
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]string
+// @Router       /token-rules/reclassify [post]
+func (h *TokenRuleHandler) ReclassifyTokens(c *gin.Context) {
+	changed, err := h.classifier.ReclassifyStoredTokens()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reclassify tokens: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tokens reclassified successfully", "changed": changed})
+}
+
+// reload 在规则变更后重新加载分类器，失败时保留旧规则
+func (h *TokenRuleHandler) reload(c *gin.Context) {
+	if err := h.classifier.Reload(); err != nil {
+		_ = c.Error(err)
+	}
+}
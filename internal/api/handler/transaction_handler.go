@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rotki-demo/internal/models"
+	"github.com/rotki-demo/internal/repository"
+	"github.com/rotki-demo/internal/service"
+)
+
+// TransactionPage 是一页交易历史
+type TransactionPage struct {
+	Transactions []models.Transaction `json:"transactions"`
+	Total        int64                `json:"total"`
+	Page         int                  `json:"page"`
+	PageSize     int                  `json:"page_size"`
+}
+
+// TransactionHandler 处理交易历史相关的 HTTP 请求
+type TransactionHandler struct {
+	txRepo      *repository.TransactionRepository
+	addressRepo *repository.AddressRepository
+	access      *service.AccessService
+}
+
+// NewTransactionHandler 创建一个新的交易历史处理器
+func NewTransactionHandler(
+	txRepo *repository.TransactionRepository,
+	addressRepo *repository.AddressRepository,
+	access *service.AccessService,
+) *TransactionHandler {
+	return &TransactionHandler{
+		txRepo:      txRepo,
+		addressRepo: addressRepo,
+		access:      access,
+	}
+}
+
+// ListAddressTransactions 获取地址的交易历史
+// @Summary      获取地址的交易历史
+// @Description  按区块时间倒序分页获取地址已同步的交易（代币转入/转出、手续费、对手方和协议），可按链和时间过滤。
+// @Description  交易由后台按 transactions 配置增量同步，未启用时列表为空。
+// @Tags         addresses
+// @Produce      json
+// @Param        id         path      int     true   "地址 ID"
+// @Param        chain_id   query     string  false  "链 ID"
+// @Param        since      query     string  false  "开始时间（RFC 3339，包含）"
+// @Param        until      query     string  false  "结束时间（RFC 3339，不包含）"
+// @Param        page       query     int     false  "页码（从 1 开始，默认 1）"
+// @Param        page_size  query     int     false  "每页条数（默认 50，最大 500）"
+// @Success      200        {object}  TransactionPage
+// @Failure      400        {object}  map[string]string
+// @Failure      404        {object}  map[string]string
+// @Failure      500        {object}  map[string]string
+// @Router       /addresses/{id}/transactions [get]
+func (h *TransactionHandler) ListAddressTransactions(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid address ID"})
+		return
+	}
+
+	filter := repository.TransactionFilter{
+		AddressID: uint(id),
+		ChainID:   c.Query("chain_id"),
+	}
+	if filter.Since, err = parseTimeQuery(c, "since"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid since, expected RFC 3339 time"})
+		return
+	}
+	if filter.Until, err = parseTimeQuery(c, "until"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid until, expected RFC 3339 time"})
+		return
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page"})
+		return
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "50"))
+	if err != nil || pageSize <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page_size"})
+		return
+	}
+	if pageSize > 500 {
+		pageSize = 500
+	}
+
+	if _, ok := authorizeAddress(c, h.access, h.addressRepo, uint(id), models.SharePermissionView); !ok {
+		return
+	}
+
+	transactions, total, err := h.txRepo.WithContext(c.Request.Context()).List(filter, (page-1)*pageSize, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve transactions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, TransactionPage{
+		Transactions: transactions,
+		Total:        total,
+		Page:         page,
+		PageSize:     pageSize,
+	})
+}
@@ -0,0 +1,370 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rotki-demo/internal/api/middleware"
+	"github.com/rotki-demo/internal/models"
+	"github.com/rotki-demo/internal/repository"
+	"github.com/rotki-demo/internal/service"
+	"gorm.io/gorm"
+)
+
+// UserHandler 处理登录会话和用户管理相关的 HTTP 请求
+type UserHandler struct {
+	userRepo    *repository.UserRepository
+	userService *service.UserService
+}
+
+// NewUserHandler 创建一个新的用户处理器
+func NewUserHandler(userRepo *repository.UserRepository, userService *service.UserService) *UserHandler {
+	return &UserHandler{
+		userRepo:    userRepo,
+		userService: userService,
+	}
+}
+
+// LoginRequest 表示登录请求
+type LoginRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// LoginResponse 表示登录结果，token 只在登录时返回一次
+type LoginResponse struct {
+	Token     string       `json:"token"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	User      *models.User `json:"user"`
+}
+
+// ChangePasswordRequest 表示修改自己密码的请求
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+// CreateUserRequest 表示创建用户的请求
+type CreateUserRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+	Role     string `json:"role"` // 默认为 operator
+}
+
+// UpdateUserRequest 表示更新用户的请求，省略的字段保持不变
+type UpdateUserRequest struct {
+	Role     *string `json:"role"`
+	Disabled *bool   `json:"disabled"`
+	Password string  `json:"password"` // 重置密码，会注销该用户的所有会话
+}
+
+// Login 使用用户名和密码登录
+// @Summary      登录
+// @Description  校验用户名和密码并创建登录会话，返回的 token 通过 "Authorization: Bearer <token>" 使用
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        credentials  body      LoginRequest  true  "用户名和密码"
+// @Success      200          {object}  LoginResponse
+// @Failure      400          {object}  map[string]string
+// @Failure      401          {object}  map[string]string
+// @Failure      500          {object}  map[string]string
+// @Router       /auth/login [post]
+func (h *UserHandler) Login(c *gin.Context) {
+	var req LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, session, token, err := h.userService.Login(req.Username, req.Password)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCredentials) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid username or password"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in"})
+		return
+	}
+
+	// 登录接口不经过认证中间件，在这里设置操作者以便审计日志记录登录的用户
+	c.Set("actor", "user:"+user.Username)
+	c.JSON(http.StatusOK, LoginResponse{
+		Token:     token,
+		ExpiresAt: session.ExpiresAt,
+		User:      user,
+	})
+}
+
+// Logout 注销当前会话
+// @Summary      注销
+// @Description  删除当前请求使用的登录会话
+// @Tags         auth
+// @Produce      json
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /auth/logout [post]
+func (h *UserHandler) Logout(c *gin.Context) {
+	token := middleware.ExtractToken(c)
+	if !service.IsSessionToken(token) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request is not authenticated with a session token"})
+		return
+	}
+
+	if err := h.userService.Logout(token); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+// GetCurrentUser 获取当前调用方
+// @Summary      获取当前调用方
+// @Description  返回当前请求的调用方（用户或 API key）及其角色；属于用户时同时返回用户信息
+// @Tags         auth
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]string
+// @Router       /auth/me [get]
+func (h *UserHandler) GetCurrentUser(c *gin.Context) {
+	principal := principalFromContext(c)
+	response := gin.H{"principal": principal}
+	if principal != nil && principal.UserID != 0 {
+		user, err := h.userRepo.GetByID(principal.UserID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user"})
+			return
+		}
+		response["user"] = user
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ChangePassword 修改自己的密码
+// @Summary      修改密码
+// @Description  校验当前密码后修改密码，修改后该用户的所有会话都会失效，需要重新登录
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        password  body      ChangePasswordRequest  true  "当前密码和新密码"
+// @Success      200       {object}  map[string]string
+// @Failure      400       {object}  map[string]string
+// @Failure      403       {object}  map[string]string
+// @Failure      500       {object}  map[string]string
+// @Router       /auth/password [put]
+func (h *UserHandler) ChangePassword(c *gin.Context) {
+	principal := principalFromContext(c)
+	if principal == nil || principal.UserID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request is not authenticated as a user"})
+		return
+	}
+
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := service.ValidatePassword(req.NewPassword); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.userRepo.GetByID(principal.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user"})
+		return
+	}
+	if !h.userService.CheckPassword(user, req.CurrentPassword) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Current password is incorrect"})
+		return
+	}
+
+	if err := h.userService.SetPassword(user, req.NewPassword); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change password"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password changed, please log in again"})
+}
+
+// ListUsers 获取所有用户
+// @Summary      获取用户列表
+// @Description  获取所有用户账号，需要 admin
+// @Tags         users
+// @Produce      json
+// @Success      200  {array}   github_com_rotki-demo_internal_models.User
+// @Failure      500  {object}  map[string]string
+// @Router       /users [get]
+func (h *UserHandler) ListUsers(c *gin.Context) {
+	users, err := h.userRepo.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve users"})
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+// CreateUser 创建用户
+// @Summary      创建用户
+// @Description  创建用户账号，需要 admin
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        user  body      CreateUserRequest  true  "用户信息"
+// @Success      201   {object}  github_com_rotki-demo_internal_models.User
+// @Failure      400   {object}  map[string]string
+// @Failure      409   {object}  map[string]string
+// @Failure      500   {object}  map[string]string
+// @Router       /users [post]
+func (h *UserHandler) CreateUser(c *gin.Context) {
+	var req CreateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Role == "" {
+		req.Role = models.RoleOperator
+	}
+	if err := service.ValidateUser(req.Username, req.Role); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := service.ValidatePassword(req.Password); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, err := h.userRepo.GetByUsername(req.Username); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Username already exists"})
+		return
+	}
+
+	user, err := h.userService.CreateUser(req.Username, req.Password, req.Role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, user)
+}
+
+// UpdateUser 更新用户
+// @Summary      更新用户
+// @Description  修改用户角色、禁用状态或重置密码，需要 admin
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        id    path      int                true  "用户 ID"
+// @Param        user  body      UpdateUserRequest  true  "用户信息"
+// @Success      200   {object}  github_com_rotki-demo_internal_models.User
+// @Failure      400   {object}  map[string]string
+// @Failure      404   {object}  map[string]string
+// @Failure      500   {object}  map[string]string
+// @Router       /users/{id} [put]
+func (h *UserHandler) UpdateUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	user, err := h.userRepo.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	var req UpdateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	self := isCurrentUser(c, user.ID)
+	if req.Role != nil {
+		if !models.IsValidRole(*req.Role) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role"})
+			return
+		}
+		if self && *req.Role != user.Role {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot change your own role"})
+			return
+		}
+		user.Role = *req.Role
+	}
+	if req.Disabled != nil {
+		if self && *req.Disabled {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot disable your own account"})
+			return
+		}
+		user.Disabled = *req.Disabled
+	}
+
+	if req.Password != "" {
+		if err := service.ValidatePassword(req.Password); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		// SetPassword 同时保存其他字段
+		if err := h.userService.SetPassword(user, req.Password); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+			return
+		}
+	} else if err := h.userRepo.Update(user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// DeleteUser 删除用户
+// @Summary      删除用户
+// @Description  删除用户及其会话、API key 和钱包共享，用户拥有的钱包保留但不再有所有者，需要 admin
+// @Tags         users
+// @Produce      json
+// @Param        id   path      int  true  "用户 ID"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /users/{id} [delete]
+func (h *UserHandler) DeleteUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	if isCurrentUser(c, uint(id)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot delete your own account"})
+		return
+	}
+
+	if _, err := h.userRepo.GetByID(uint(id)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user"})
+		return
+	}
+
+	if err := h.userRepo.Delete(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User deleted successfully"})
+}
+
+// isCurrentUser 判断用户是否为当前调用方
+func isCurrentUser(c *gin.Context, userID uint) bool {
+	principal := principalFromContext(c)
+	return principal != nil && principal.UserID == userID
+}
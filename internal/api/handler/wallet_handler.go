@@ -1,23 +1,31 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/rotki-demo/internal/metrics"
 	"github.com/rotki-demo/internal/models"
 	"github.com/rotki-demo/internal/repository"
+	"github.com/rotki-demo/internal/service"
+	"gorm.io/gorm"
 )
 
 // WalletHandler 处理钱包相关的 HTTP 请求
 type WalletHandler struct {
 	walletRepo *repository.WalletRepository
+	userRepo   *repository.UserRepository
+	access     *service.AccessService
 }
 
 // NewWalletHandler 创建一个新的钱包处理器
-func NewWalletHandler(walletRepo *repository.WalletRepository) *WalletHandler {
+func NewWalletHandler(walletRepo *repository.WalletRepository, userRepo *repository.UserRepository, access *service.AccessService) *WalletHandler {
 	return &WalletHandler{
 		walletRepo: walletRepo,
+		userRepo:   userRepo,
+		access:     access,
 	}
 }
 
@@ -37,6 +45,13 @@ type UpdateWalletRequest struct {
 	EnabledChains []string `json:"enabled_chains"`
 }
 
+// ShareWalletRequest 表示共享钱包的请求，username 和 user_id 二选一
+type ShareWalletRequest struct {
+	Username   string `json:"username"`
+	UserID     uint   `json:"user_id"`
+	Permission string `json:"permission" binding:"required,oneof=view edit"`
+}
+
 // CreateWallet 创建一个新的钱包
 // @Summary      创建钱包
 // @Description  创建一个新的钱包
@@ -61,6 +76,11 @@ func (h *WalletHandler) CreateWallet(c *gin.Context) {
 		Tags:          models.StringSlice(req.Tags),
 		EnabledChains: models.StringSlice(req.EnabledChains),
 	}
+	// 用户创建的钱包归自己所有，服务 key 创建的钱包没有所有者
+	if principal := principalFromContext(c); principal != nil && principal.UserID != 0 {
+		ownerID := principal.UserID
+		wallet.OwnerID = &ownerID
+	}
 
 	if err := h.walletRepo.Create(wallet); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create wallet"})
@@ -88,9 +108,8 @@ func (h *WalletHandler) GetWallet(c *gin.Context) {
 		return
 	}
 
-	wallet, err := h.walletRepo.GetByID(uint(id))
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Wallet not found"})
+	wallet, ok := authorizeWallet(c, h.access, uint(id), models.SharePermissionView)
+	if !ok {
 		return
 	}
 
@@ -99,7 +118,7 @@ func (h *WalletHandler) GetWallet(c *gin.Context) {
 
 // ListWallets 获取所有钱包
 // @Summary      获取钱包列表
-// @Description  获取所有钱包列表
+// @Description  获取调用方拥有或被共享的钱包列表
 // @Tags         wallets
 // @Accept       json
 // @Produce      json
@@ -107,7 +126,12 @@ func (h *WalletHandler) GetWallet(c *gin.Context) {
 // @Failure      500  {object}  map[string]string
 // @Router       /wallets [get]
 func (h *WalletHandler) ListWallets(c *gin.Context) {
-	wallets, err := h.walletRepo.List()
+	scope, ok := walletScope(c, h.access)
+	if !ok {
+		return
+	}
+
+	wallets, err := h.walletRepo.List(scope)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve wallets"})
 		return
@@ -126,6 +150,7 @@ func (h *WalletHandler) ListWallets(c *gin.Context) {
 // @Param        wallet  body      UpdateWalletRequest   true  "钱包信息"
 // @Success      200     {object}  github_com_rotki-demo_internal_models.Wallet
 // @Failure      400     {object}  map[string]string
+// @Failure      403     {object}  map[string]string
 // @Failure      404     {object}  map[string]string
 // @Failure      500     {object}  map[string]string
 // @Router       /wallets/{id} [put]
@@ -136,9 +161,8 @@ func (h *WalletHandler) UpdateWallet(c *gin.Context) {
 		return
 	}
 
-	wallet, err := h.walletRepo.GetByID(uint(id))
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Wallet not found"})
+	wallet, ok := authorizeWallet(c, h.access, uint(id), models.SharePermissionEdit)
+	if !ok {
 		return
 	}
 
@@ -176,13 +200,15 @@ func (h *WalletHandler) UpdateWallet(c *gin.Context) {
 
 // DeleteWallet 删除钱包
 // @Summary      删除钱包
-// @Description  根据 ID 删除钱包
+// @Description  根据 ID 删除钱包，只有所有者可以删除
 // @Tags         wallets
 // @Accept       json
 // @Produce      json
 // @Param        id   path      int  true  "钱包 ID"
 // @Success      200  {object}  map[string]string
 // @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
 // @Failure      500  {object}  map[string]string
 // @Router       /wallets/{id} [delete]
 func (h *WalletHandler) DeleteWallet(c *gin.Context) {
@@ -192,10 +218,156 @@ func (h *WalletHandler) DeleteWallet(c *gin.Context) {
 		return
 	}
 
+	wallet, ok := authorizeWallet(c, h.access, uint(id), service.AccessOwner)
+	if !ok {
+		return
+	}
+
 	if err := h.walletRepo.Delete(uint(id)); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete wallet"})
 		return
 	}
+	// 地址随钱包级联删除
+	for _, address := range wallet.Addresses {
+		metrics.ForgetAddress(address.ID)
+	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Wallet deleted successfully"})
 }
+
+// ListShares 获取钱包的共享列表
+// @Summary      获取钱包共享列表
+// @Description  获取钱包共享给了哪些用户以及各自的权限
+// @Tags         wallets
+// @Produce      json
+// @Param        id   path      int  true  "钱包 ID"
+// @Success      200  {array}   github_com_rotki-demo_internal_models.WalletShare
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /wallets/{id}/shares [get]
+func (h *WalletHandler) ListShares(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid wallet ID"})
+		return
+	}
+
+	if _, ok := authorizeWallet(c, h.access, uint(id), models.SharePermissionView); !ok {
+		return
+	}
+
+	shares, err := h.walletRepo.ListShares(uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve shares"})
+		return
+	}
+
+	c.JSON(http.StatusOK, shares)
+}
+
+// ShareWallet 把钱包共享给其他用户，已共享时更新权限
+// @Summary      共享钱包
+// @Description  把钱包以 view 或 edit 权限共享给其他用户，只有所有者可以操作
+// @Tags         wallets
+// @Accept       json
+// @Produce      json
+// @Param        id     path      int                 true  "钱包 ID"
+// @Param        share  body      ShareWalletRequest  true  "共享信息"
+// @Success      200    {object}  github_com_rotki-demo_internal_models.WalletShare
+// @Failure      400    {object}  map[string]string
+// @Failure      403    {object}  map[string]string
+// @Failure      404    {object}  map[string]string
+// @Failure      500    {object}  map[string]string
+// @Router       /wallets/{id}/shares [post]
+func (h *WalletHandler) ShareWallet(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid wallet ID"})
+		return
+	}
+
+	var req ShareWalletRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Username == "" && req.UserID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "username or user_id is required"})
+		return
+	}
+
+	wallet, ok := authorizeWallet(c, h.access, uint(id), service.AccessOwner)
+	if !ok {
+		return
+	}
+
+	var user *models.User
+	if req.UserID != 0 {
+		user, err = h.userRepo.GetByID(req.UserID)
+	} else {
+		user, err = h.userRepo.GetByUsername(req.Username)
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to look up user"})
+		return
+	}
+	if wallet.OwnerID != nil && *wallet.OwnerID == user.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot share a wallet with its owner"})
+		return
+	}
+
+	share := &models.WalletShare{
+		WalletID:   wallet.ID,
+		UserID:     user.ID,
+		Permission: req.Permission,
+	}
+	if err := h.walletRepo.SaveShare(share); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to share wallet"})
+		return
+	}
+	share.User = user
+
+	c.JSON(http.StatusOK, share)
+}
+
+// UnshareWallet 取消钱包对某个用户的共享
+// @Summary      取消共享钱包
+// @Description  取消钱包对指定用户的共享，只有所有者可以操作
+// @Tags         wallets
+// @Produce      json
+// @Param        id       path      int  true  "钱包 ID"
+// @Param        user_id  path      int  true  "用户 ID"
+// @Success      200      {object}  map[string]string
+// @Failure      400      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /wallets/{id}/shares/{user_id} [delete]
+func (h *WalletHandler) UnshareWallet(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid wallet ID"})
+		return
+	}
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if _, ok := authorizeWallet(c, h.access, uint(id), service.AccessOwner); !ok {
+		return
+	}
+
+	if err := h.walletRepo.DeleteShare(uint(id), uint(userID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove share"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Wallet share removed successfully"})
+}
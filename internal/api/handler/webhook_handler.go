@@ -0,0 +1,400 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rotki-demo/internal/events"
+	"github.com/rotki-demo/internal/models"
+	"github.com/rotki-demo/internal/repository"
+	"github.com/rotki-demo/internal/service"
+)
+
+// WebhookHandler 处理出站 webhook 相关的 HTTP 请求
+type WebhookHandler struct {
+	webhookRepo    *repository.WebhookRepository
+	walletRepo     *repository.WalletRepository
+	webhookService *service.WebhookService
+}
+
+// NewWebhookHandler 创建一个新的 webhook 处理器
+func NewWebhookHandler(
+	webhookRepo *repository.WebhookRepository,
+	walletRepo *repository.WalletRepository,
+	webhookService *service.WebhookService,
+) *WebhookHandler {
+	return &WebhookHandler{
+		webhookRepo:    webhookRepo,
+		walletRepo:     walletRepo,
+		webhookService: webhookService,
+	}
+}
+
+// WebhookEndpointRequest 表示创建或更新 webhook 端点的请求
+type WebhookEndpointRequest struct {
+	Name               string   `json:"name" binding:"required"`
+	URL                string   `json:"url" binding:"required"`
+	Secret             string   `json:"secret"`               // 创建时省略则自动生成；更新时省略则保持不变
+	Events             []string `json:"events"`               // 为空表示订阅全部事件
+	WalletID           uint     `json:"wallet_id"`            // 0 或省略表示所有钱包
+	ValueChangePercent float64  `json:"value_change_percent"` // 0 表示使用配置默认值
+	Enabled            *bool    `json:"enabled"`
+}
+
+// WebhookEndpointResponse 是创建端点后的响应，只在此时返回签名密钥
+type WebhookEndpointResponse struct {
+	*models.WebhookEndpoint
+	Secret string `json:"secret"`
+}
+
+// validate 校验请求字段
+func (req *WebhookEndpointRequest) validate() error {
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid url: %s", req.URL)
+	}
+	for _, eventType := range req.Events {
+		if !events.IsValidType(eventType) {
+			return fmt.Errorf("unknown event type: %s", eventType)
+		}
+	}
+	if req.ValueChangePercent < 0 {
+		return fmt.Errorf("value_change_percent must not be negative")
+	}
+	return nil
+}
+
+// apply 将请求字段写入端点
+func (req *WebhookEndpointRequest) apply(endpoint *models.WebhookEndpoint) {
+	endpoint.Name = req.Name
+	endpoint.URL = req.URL
+	endpoint.Events = models.StringSlice(req.Events)
+	endpoint.WalletID = req.WalletID
+	endpoint.ValueChangePercent = req.ValueChangePercent
+	if req.Secret != "" {
+		endpoint.Secret = req.Secret
+	}
+	if req.Enabled != nil {
+		endpoint.Enabled = *req.Enabled
+	}
+}
+
+// ListWebhookEventTypes 获取可订阅的事件类型
+// @Summary      获取 webhook 事件类型
+// @Description  获取所有可订阅的事件类型
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Success      200  {array}  string
+// @Router       /webhooks/event-types [get]
+func (h *WebhookHandler) ListWebhookEventTypes(c *gin.Context) {
+	c.JSON(http.StatusOK, events.Types())
+}
+
+// ListWebhooks 获取所有 webhook 端点
+// @Summary      获取 webhook 端点列表
+// @Description  获取所有 webhook 端点（不包含签名密钥）
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Success      200  {array}   github_com_rotki-demo_internal_models.WebhookEndpoint
+// @Failure      500  {object}  map[string]string
+// @Router       /webhooks [get]
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	endpoints, err := h.webhookRepo.ListEndpoints()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve webhooks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, endpoints)
+}
+
+// GetWebhook 根据 ID 获取 webhook 端点
+// @Summary      获取 webhook 端点
+// @Description  根据 ID 获取 webhook 端点（不包含签名密钥）
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "端点 ID"
+// @Success      200  {object}  github_com_rotki-demo_internal_models.WebhookEndpoint
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /webhooks/{id} [get]
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	endpoint, ok := h.loadEndpoint(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, endpoint)
+}
+
+// CreateWebhook 创建 webhook 端点
+// @Summary      创建 webhook 端点
+// @Description  注册接收事件的 URL。每个请求都带有 X-Webhook-Signature: sha256=HMAC-SHA256(secret, timestamp + "." + body)，timestamp 来自 X-Webhook-Timestamp。签名密钥只在创建时返回
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        webhook  body      WebhookEndpointRequest  true  "端点信息"
+// @Success      201      {object}  WebhookEndpointResponse
+// @Failure      400      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /webhooks [post]
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var req WebhookEndpointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := req.validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !h.checkWallet(c, req.WalletID) {
+		return
+	}
+
+	endpoint := &models.WebhookEndpoint{Enabled: true}
+	req.apply(endpoint)
+	if endpoint.Secret == "" {
+		secret, err := service.GenerateWebhookSecret()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate webhook secret"})
+			return
+		}
+		endpoint.Secret = secret
+	}
+
+	if err := h.webhookRepo.CreateEndpoint(endpoint); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, WebhookEndpointResponse{WebhookEndpoint: endpoint, Secret: endpoint.Secret})
+}
+
+// UpdateWebhook 更新 webhook 端点
+// @Summary      更新 webhook 端点
+// @Description  替换端点的所有字段；省略 secret 时保持原密钥
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        id       path      int                     true  "端点 ID"
+// @Param        webhook  body      WebhookEndpointRequest  true  "端点信息"
+// @Success      200      {object}  github_com_rotki-demo_internal_models.WebhookEndpoint
+// @Failure      400      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /webhooks/{id} [put]
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	endpoint, ok := h.loadEndpoint(c)
+	if !ok {
+		return
+	}
+
+	var req WebhookEndpointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := req.validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !h.checkWallet(c, req.WalletID) {
+		return
+	}
+	req.apply(endpoint)
+
+	if err := h.webhookRepo.UpdateEndpoint(endpoint); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update webhook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, endpoint)
+}
+
+// DeleteWebhook 删除 webhook 端点
+// @Summary      删除 webhook 端点
+// @Description  删除端点及其所有投递记录
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "端点 ID"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return
+	}
+
+	if err := h.webhookRepo.DeleteEndpoint(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete webhook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted successfully"})
+}
+
+// TestWebhook 向端点发送测试事件
+// @Summary      发送测试事件
+// @Description  向端点排队一条 webhook.test 事件，投递结果可在投递记录中查看
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "端点 ID"
+// @Success      202  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /webhooks/{id}/test [post]
+func (h *WebhookHandler) TestWebhook(c *gin.Context) {
+	endpoint, ok := h.loadEndpoint(c)
+	if !ok {
+		return
+	}
+
+	event, err := h.webhookService.SendTest(endpoint)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to queue test event"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Test event queued", "event_id": event.ID})
+}
+
+// ListWebhookDeliveries 获取投递记录
+// @Summary      获取 webhook 投递记录
+// @Description  按时间倒序获取投递记录；status=dead 返回死信列表
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        endpoint_id  query     int     false  "端点 ID"
+// @Param        status       query     string  false  "状态（pending、delivered、dead）"
+// @Param        event_type   query     string  false  "事件类型"
+// @Param        limit        query     int     false  "返回条数（默认 100，最大 1000）"
+// @Success      200          {array}   github_com_rotki-demo_internal_models.WebhookDelivery
+// @Failure      400          {object}  map[string]string
+// @Failure      500          {object}  map[string]string
+// @Router       /webhooks/deliveries [get]
+func (h *WebhookHandler) ListWebhookDeliveries(c *gin.Context) {
+	endpointID, err := parseUintQuery(c, "endpoint_id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid endpoint ID"})
+		return
+	}
+	limit, ok := parseLimit(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+		return
+	}
+
+	deliveries, err := h.webhookRepo.ListDeliveries(repository.WebhookDeliveryFilter{
+		EndpointID: endpointID,
+		Status:     c.Query("status"),
+		EventType:  c.Query("event_type"),
+	}, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve webhook deliveries"})
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// ReplayWebhookDelivery 重新投递单条记录
+// @Summary      重新投递
+// @Description  将投递记录重新放回队列（重置重试次数），可用于死信或已投递的事件
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "投递记录 ID"
+// @Success      202  {object}  github_com_rotki-demo_internal_models.WebhookDelivery
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /webhooks/deliveries/{id}/replay [post]
+func (h *WebhookHandler) ReplayWebhookDelivery(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid delivery ID"})
+		return
+	}
+
+	delivery, err := h.webhookRepo.GetDeliveryByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook delivery not found"})
+		return
+	}
+
+	if err := h.webhookService.Replay(delivery); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to replay webhook delivery"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, delivery)
+}
+
+// ReplayDeadLetters 重新投递死信列表
+// @Summary      重新投递死信
+// @Description  将死信列表中的记录全部重新放回队列，可按端点过滤
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        endpoint_id  query     int  false  "端点 ID"
+// @Success      202          {object}  map[string]interface{}
+// @Failure      400          {object}  map[string]string
+// @Failure      500          {object}  map[string]string
+// @Router       /webhooks/deliveries/replay-dead [post]
+func (h *WebhookHandler) ReplayDeadLetters(c *gin.Context) {
+	endpointID, err := parseUintQuery(c, "endpoint_id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid endpoint ID"})
+		return
+	}
+
+	count, err := h.webhookService.ReplayDeadLetters(endpointID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to replay dead letters"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Dead letters requeued", "requeued": count})
+}
+
+// loadEndpoint 根据路径参数加载端点，失败时写入错误响应
+func (h *WebhookHandler) loadEndpoint(c *gin.Context) (*models.WebhookEndpoint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return nil, false
+	}
+
+	endpoint, err := h.webhookRepo.GetEndpointByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return nil, false
+	}
+	return endpoint, true
+}
+
+// checkWallet 检查钱包是否存在，walletID 为 0 时跳过
+func (h *WebhookHandler) checkWallet(c *gin.Context, walletID uint) bool {
+	if walletID == 0 {
+		return true
+	}
+	if _, err := h.walletRepo.GetByID(walletID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wallet not found"})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,159 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rotki-demo/internal/logger"
+	"github.com/rotki-demo/internal/models"
+	"github.com/rotki-demo/internal/repository"
+	"github.com/rotki-demo/internal/service"
+	"go.uber.org/zap"
+)
+
+// APIKeyHeader 是 Authorization 之外可用于传递 API key 的请求头
+const APIKeyHeader = "X-API-Key"
+
+// Auth 实现 API key / 登录会话认证和基于角色的访问控制
+type Auth struct {
+	keys     *service.APIKeyService
+	users    *service.UserService
+	userRepo *repository.UserRepository
+	enabled  bool
+}
+
+// NewAuth 创建认证中间件，enabled 为 false 时所有请求都放行
+func NewAuth(keys *service.APIKeyService, users *service.UserService, userRepo *repository.UserRepository, enabled bool) *Auth {
+	return &Auth{
+		keys:     keys,
+		users:    users,
+		userRepo: userRepo,
+		enabled:  enabled,
+	}
+}
+
+// Authenticate 校验请求中的 API key 或会话 token，并把调用方和操作者写入上下文
+// 凭证可以通过 "Authorization: Bearer <token>" 或 X-API-Key 请求头传递；
+// 浏览器的 EventSource 无法设置请求头，因此事件流请求也接受 api_key 查询参数。
+// 启用认证时操作者只取自认证身份，X-Actor 请求头被忽略
+func (a *Auth) Authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !a.enabled {
+			c.Set("actor", insecureActor(c))
+			c.Next()
+			return
+		}
+
+		token := ExtractToken(c)
+		if token == "" {
+			c.Header("WWW-Authenticate", `Bearer realm="api"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Missing API key"})
+			return
+		}
+
+		principal, err := a.resolve(token)
+		if err != nil {
+			if !errors.Is(err, service.ErrInvalidAPIKey) && !errors.Is(err, service.ErrInvalidSession) {
+				logger.Error("Failed to authenticate request", zap.Error(err))
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to authenticate"})
+				return
+			}
+			message := "Invalid API key"
+			if service.IsSessionToken(token) {
+				message = "Invalid or expired session"
+			}
+			c.Header("WWW-Authenticate", `Bearer realm="api", error="invalid_token"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": message})
+			return
+		}
+
+		c.Set(service.PrincipalContextKey, principal)
+		c.Set("actor", principal.Name)
+		c.Next()
+	}
+}
+
+// resolve 根据 token 类型查找会话或 API key
+func (a *Auth) resolve(token string) (*service.Principal, error) {
+	if service.IsSessionToken(token) {
+		user, err := a.users.ValidateSession(token)
+		if err != nil {
+			return nil, err
+		}
+		return &service.Principal{UserID: user.ID, Name: "user:" + user.Username, Role: user.Role}, nil
+	}
+
+	key, err := a.keys.Authenticate(token)
+	if err != nil {
+		return nil, err
+	}
+	principal := &service.Principal{Name: "apikey:" + key.Name, Role: key.Role}
+	if key.UserID != nil {
+		// 属于用户的 key 只能访问该用户的钱包，权限不超过用户自己的角色
+		user, err := a.userRepo.GetByID(*key.UserID)
+		if err != nil || user.Disabled {
+			return nil, service.ErrInvalidAPIKey
+		}
+		principal.UserID = user.ID
+		if !models.RoleAllows(user.Role, key.Role) {
+			principal.Role = user.Role
+		}
+	}
+	return principal, nil
+}
+
+// Require 按请求方法检查角色：GET/HEAD 需要 readRole，其他方法需要 writeRole
+func (a *Auth) Require(readRole, writeRole string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !a.enabled {
+			c.Next()
+			return
+		}
+
+		required := writeRole
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			required = readRole
+		}
+
+		principal := PrincipalFromContext(c)
+		if principal == nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Missing API key"})
+			return
+		}
+		if !models.RoleAllows(principal.Role, required) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions", "required_role": required})
+			return
+		}
+		c.Next()
+	}
+}
+
+// PrincipalFromContext 返回认证通过的调用方，未启用认证时返回 nil
+func PrincipalFromContext(c *gin.Context) *service.Principal {
+	if v, ok := c.Get(service.PrincipalContextKey); ok {
+		if principal, ok := v.(*service.Principal); ok {
+			return principal
+		}
+	}
+	return nil
+}
+
+// ExtractToken 从请求头或事件流的查询参数中读取 API key 或会话 token
+func ExtractToken(c *gin.Context) string {
+	if auth := c.GetHeader("Authorization"); auth != "" {
+		scheme, token, found := strings.Cut(auth, " ")
+		if found && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+		return ""
+	}
+	if key := c.GetHeader(APIKeyHeader); key != "" {
+		return strings.TrimSpace(key)
+	}
+	if c.Request.Method == http.MethodGet && strings.Contains(c.GetHeader("Accept"), "text/event-stream") {
+		return c.Query("api_key")
+	}
+	return ""
+}
@@ -0,0 +1,154 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rotki-demo/internal/models"
+	"github.com/rotki-demo/internal/repository"
+	"github.com/rotki-demo/internal/service"
+)
+
+func TestRoleAllows(t *testing.T) {
+	tests := []struct {
+		role, required string
+		want           bool
+	}{
+		{models.RoleAdmin, models.RoleOperator, true},
+		{models.RoleOperator, models.RoleOperator, true},
+		{models.RoleOperator, models.RoleAdmin, false},
+		{models.RoleReadOnly, models.RoleReadOnly, true},
+		{models.RoleReadOnly, models.RoleOperator, false},
+		{"", models.RoleReadOnly, false},
+		{"root", "", false},
+	}
+	for _, tt := range tests {
+		if got := models.RoleAllows(tt.role, tt.required); got != tt.want {
+			t.Errorf("RoleAllows(%q, %q) = %t, want %t", tt.role, tt.required, got, tt.want)
+		}
+	}
+}
+
+// newAuthRouter 返回与 API 相同方式组装的路由：普通资源读需要 read_only、写需要 operator，管理接口需要 admin
+func newAuthRouter(auth *Auth) *gin.Engine {
+	router := gin.New()
+	api := router.Group("/api/v1", auth.Authenticate())
+	ok := func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"actor": Actor(c)}) }
+	wallets := api.Group("/wallets", auth.Require(models.RoleReadOnly, models.RoleOperator))
+	wallets.GET("", ok)
+	wallets.POST("", ok)
+	admin := api.Group("/admin", auth.Require(models.RoleAdmin, models.RoleAdmin))
+	admin.GET("/config", ok)
+	return router
+}
+
+func TestAuthRequire(t *testing.T) {
+	db := newTestDB(t)
+	userRepo := repository.NewUserRepository(db)
+	users := service.NewUserService(userRepo, time.Hour)
+	keys := service.NewAPIKeyService(repository.NewAPIKeyRepository(db))
+
+	createKey := func(name, role string, userID *uint) (*models.APIKey, string) {
+		key, raw, err := keys.Create(name, role, 0, userID)
+		if err != nil {
+			t.Fatalf("create key %s: %v", name, err)
+		}
+		return key, raw
+	}
+	_, readOnly := createKey("reader", models.RoleReadOnly, nil)
+	_, operator := createKey("operator", models.RoleOperator, nil)
+	_, admin := createKey("admin", models.RoleAdmin, nil)
+	revokedKey, revoked := createKey("revoked", models.RoleAdmin, nil)
+	if err := keys.Revoke(revokedKey.ID); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	expiredKey, expired := createKey("expired", models.RoleAdmin, nil)
+	if err := db.Model(expiredKey).Update("expires_at", time.Now().Add(-time.Minute)).Error; err != nil {
+		t.Fatalf("expire key: %v", err)
+	}
+
+	// 属于只读用户的 admin key 权限不超过用户自己的角色
+	alice, err := users.CreateUser("alice", "correct horse battery", models.RoleReadOnly)
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	_, userKey := createKey("alice-admin", models.RoleAdmin, &alice.ID)
+	bob, err := users.CreateUser("bob", "correct horse battery", models.RoleAdmin)
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	_, disabledUserKey := createKey("bob-admin", models.RoleAdmin, &bob.ID)
+	if err := db.Model(bob).Update("disabled", true).Error; err != nil {
+		t.Fatalf("disable user: %v", err)
+	}
+
+	router := newAuthRouter(NewAuth(keys, users, userRepo, true))
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		header map[string]string
+		want   int
+	}{
+		{name: "missing key", method: http.MethodGet, path: "/api/v1/wallets", want: http.StatusUnauthorized},
+		{name: "unknown key", method: http.MethodGet, path: "/api/v1/wallets", header: map[string]string{APIKeyHeader: "rk_unknown"}, want: http.StatusUnauthorized},
+		{name: "read only can read", method: http.MethodGet, path: "/api/v1/wallets", header: map[string]string{"Authorization": "Bearer " + readOnly}, want: http.StatusOK},
+		{name: "read only cannot write", method: http.MethodPost, path: "/api/v1/wallets", header: map[string]string{"Authorization": "Bearer " + readOnly}, want: http.StatusForbidden},
+		{name: "operator can write", method: http.MethodPost, path: "/api/v1/wallets", header: map[string]string{APIKeyHeader: operator}, want: http.StatusOK},
+		{name: "operator cannot read admin", method: http.MethodGet, path: "/api/v1/admin/config", header: map[string]string{APIKeyHeader: operator}, want: http.StatusForbidden},
+		{name: "admin can read admin", method: http.MethodGet, path: "/api/v1/admin/config", header: map[string]string{APIKeyHeader: admin}, want: http.StatusOK},
+		{name: "revoked key", method: http.MethodGet, path: "/api/v1/wallets", header: map[string]string{APIKeyHeader: revoked}, want: http.StatusUnauthorized},
+		{name: "expired key", method: http.MethodGet, path: "/api/v1/wallets", header: map[string]string{APIKeyHeader: expired}, want: http.StatusUnauthorized},
+		{name: "user key capped at user role", method: http.MethodPost, path: "/api/v1/wallets", header: map[string]string{APIKeyHeader: userKey}, want: http.StatusForbidden},
+		{name: "user key can still read", method: http.MethodGet, path: "/api/v1/wallets", header: map[string]string{APIKeyHeader: userKey}, want: http.StatusOK},
+		{name: "key of disabled user", method: http.MethodGet, path: "/api/v1/wallets", header: map[string]string{APIKeyHeader: disabledUserKey}, want: http.StatusUnauthorized},
+		{name: "non-bearer authorization", method: http.MethodGet, path: "/api/v1/wallets", header: map[string]string{"Authorization": "Basic " + admin}, want: http.StatusUnauthorized},
+		{name: "query key only for event streams", method: http.MethodGet, path: "/api/v1/wallets?api_key=" + admin, want: http.StatusUnauthorized},
+		{name: "query key on event stream", method: http.MethodGet, path: "/api/v1/wallets?api_key=" + admin, header: map[string]string{"Accept": "text/event-stream"}, want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			for name, value := range tt.header {
+				req.Header.Set(name, value)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d (body %s)", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAuthDisabled(t *testing.T) {
+	router := newAuthRouter(NewAuth(nil, nil, nil, false))
+
+	tests := []struct {
+		name      string
+		actor     string
+		wantActor string
+	}{
+		{name: "actor header", actor: "alice", wantActor: `{"actor":"alice"}`},
+		{name: "client ip", wantActor: `{"actor":"192.0.2.1"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/config", nil)
+			if tt.actor != "" {
+				req.Header.Set(ActorHeader, tt.actor)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != http.StatusOK || w.Body.String() != tt.wantActor {
+				t.Fatalf("response = %d %s, want 200 %s", w.Code, w.Body.String(), tt.wantActor)
+			}
+		})
+	}
+}
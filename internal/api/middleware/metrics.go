@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rotki-demo/internal/metrics"
+)
+
+// unmatchedRoute 是没有匹配到路由的请求使用的标签值，避免任意路径产生新的序列
+const unmatchedRoute = "unmatched"
+
+// Metrics 记录每个请求的方法、路由模板、状态码和耗时
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		metrics.ObserveHTTPRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
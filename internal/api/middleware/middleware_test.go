@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rotki-demo/internal/config"
+	"github.com/rotki-demo/internal/database"
+	"github.com/rotki-demo/internal/logger"
+	"gorm.io/gorm"
+)
+
+func TestMain(m *testing.M) {
+	// 中间件的 Info 日志和 gin 的调试输出在测试中没有意义
+	if err := logger.InitLogger(&config.LogConfig{Level: "warn", Output: "stdout"}); err != nil {
+		panic(err)
+	}
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
+
+// newTestDB 打开一个独立的 SQLite 内存数据库并应用所有迁移
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := database.Open(&config.DatabaseConfig{Driver: config.DriverSQLite, Path: ":memory:"})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	migrator, err := database.NewDefaultMigrator(db)
+	if err != nil {
+		t.Fatalf("NewDefaultMigrator: %v", err)
+	}
+	if err := migrator.Up(0); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rotki-demo/internal/logger"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader 是传递请求 ID 的请求头和响应头
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDContextKey 是 gin 上下文中保存请求 ID 的键
+const RequestIDContextKey = "request_id"
+
+// validRequestID 限制客户端传入的请求 ID，避免把任意内容写入日志和响应头
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID 读取或生成请求 ID，写入响应头、gin 上下文和请求的 context（日志、同步任务和数据库日志会带上它），
+// 并把它加入 JSON 错误响应的 request_id 字段，便于用户反馈问题时定位日志
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := strings.TrimSpace(c.GetHeader(RequestIDHeader))
+		if !validRequestID.MatchString(requestID) {
+			requestID = newRequestID()
+		}
+
+		c.Set(RequestIDContextKey, requestID)
+		c.Header(RequestIDHeader, requestID)
+		c.Request = c.Request.WithContext(logger.WithRequestID(c.Request.Context(), requestID))
+		trace.SpanFromContext(c.Request.Context()).SetAttributes(attribute.String("request_id", requestID))
+
+		writer := &errorBodyWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		writer.flush(requestID)
+	}
+}
+
+// GetRequestID 返回当前请求的 ID
+func GetRequestID(c *gin.Context) string {
+	return c.GetString(RequestIDContextKey)
+}
+
+// errorBodyWriter 缓存状态码 >= 400 的 JSON 响应，以便在请求结束时加入请求 ID
+type errorBodyWriter struct {
+	gin.ResponseWriter
+	body     bytes.Buffer
+	buffered bool
+}
+
+// Write 缓存错误响应，其他响应直接写出
+func (w *errorBodyWriter) Write(b []byte) (int, error) {
+	if w.shouldBuffer() {
+		w.buffered = true
+		return w.body.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// WriteString 缓存错误响应，其他响应直接写出
+func (w *errorBodyWriter) WriteString(s string) (int, error) {
+	if w.shouldBuffer() {
+		w.buffered = true
+		return w.body.WriteString(s)
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+// shouldBuffer 判断当前响应是否为尚未写出的 JSON 错误响应
+func (w *errorBodyWriter) shouldBuffer() bool {
+	if w.buffered {
+		return true
+	}
+	return !w.ResponseWriter.Written() && w.ResponseWriter.Status() >= 400 &&
+		strings.HasPrefix(w.ResponseWriter.Header().Get("Content-Type"), "application/json")
+}
+
+// flush 写出缓存的错误响应，响应体是 JSON 对象时加入 request_id 字段
+func (w *errorBodyWriter) flush(requestID string) {
+	if !w.buffered {
+		return
+	}
+
+	body := w.body.Bytes()
+	var object map[string]interface{}
+	if err := json.Unmarshal(body, &object); err == nil && object != nil {
+		if _, exists := object["request_id"]; !exists {
+			object["request_id"] = requestID
+			if encoded, err := json.Marshal(object); err == nil {
+				body = encoded
+			}
+		}
+	}
+	_, _ = w.ResponseWriter.Write(body)
+}
+
+// newRequestID 生成 32 位十六进制随机请求 ID
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -1,39 +1,121 @@
 package router
 
 import (
+	"net/http"
+	"slices"
+	"time"
+
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/rotki-demo/internal/api/handler"
+	"github.com/rotki-demo/internal/api/middleware"
+	appconfig "github.com/rotki-demo/internal/config"
+	"github.com/rotki-demo/internal/metrics"
+	"github.com/rotki-demo/internal/models"
+	"github.com/rotki-demo/internal/service"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 // SetupRouter 设置 HTTP 路由器
 func SetupRouter(
+	corsConfig appconfig.CORSConfig,
+	metricsConfig appconfig.MetricsConfig,
+	tracingConfig appconfig.TracingConfig,
+	auth *middleware.Auth,
+	auditService *service.AuditService,
 	walletHandler *handler.WalletHandler,
 	addressHandler *handler.AddressHandler,
 	chainHandler *handler.ChainHandler,
 	rpcNodeHandler *handler.RPCNodeHandler,
+	tokenRuleHandler *handler.TokenRuleHandler,
+	tokenOverrideHandler *handler.TokenOverrideHandler,
+	positionHandler *handler.PositionHandler,
+	healthHandler *handler.HealthHandler,
+	webhookHandler *handler.WebhookHandler,
+	alertRuleHandler *handler.AlertRuleHandler,
+	eventHandler *handler.EventHandler,
+	userHandler *handler.UserHandler,
+	auditHandler *handler.AuditHandler,
+	probeHandler *handler.ProbeHandler,
+	configHandler *handler.ConfigHandler,
+	backupHandler *handler.BackupHandler,
+	transactionHandler *handler.TransactionHandler,
 ) *gin.Engine {
-	router := gin.Default()
+	router := gin.New()
+
+	// 健康检查、探针和指标抓取频繁且无业务意义，不记录访问日志和 span
+	quietPaths := []string{"/health", "/livez", "/readyz", metricsConfig.Path}
+
+	// 链路追踪中间件：为每个请求创建根 span（或延续请求头中的 traceparent）
+	if tracingConfig.Enabled {
+		router.Use(otelgin.Middleware(tracingConfig.ServiceName, otelgin.WithFilter(func(r *http.Request) bool {
+			return !slices.Contains(quietPaths, r.URL.Path)
+		})))
+	}
+
+	// 结构化访问日志在请求 ID 中间件外层，记录的是加入请求 ID 之后的最终响应
+	router.Use(middleware.AccessLog(quietPaths...))
+	router.Use(middleware.RequestID())
 
-	// CORS 中间件
-	config := cors.DefaultConfig()
-	config.AllowOrigins = []string{"*"}
-	config.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
-	config.AllowHeaders = []string{"Origin", "Content-Type", "Authorization"}
-	router.Use(cors.New(config))
+	// 请求指标中间件，放在 Recovery 外层使 panic 的请求按恢复后的 500 统计，
+	// 并在 CORS、审计和认证之前以统计被它们拒绝的请求
+	if metricsConfig.Enabled {
+		router.Use(middleware.Metrics())
+	}
+	router.Use(middleware.Recovery())
+
+	// CORS 中间件，未配置来源时不允许跨域访问（浏览器只能从同源页面调用）
+	if len(corsConfig.AllowOrigins) > 0 {
+		config := cors.DefaultConfig()
+		if slices.Contains(corsConfig.AllowOrigins, "*") {
+			config.AllowAllOrigins = true
+		} else {
+			config.AllowOrigins = corsConfig.AllowOrigins
+		}
+		config.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
+		config.AllowHeaders = []string{"Origin", "Content-Type", "Authorization", middleware.APIKeyHeader, middleware.ActorHeader, middleware.RequestIDHeader}
+		config.ExposeHeaders = []string{middleware.RequestIDHeader}
+		config.MaxAge = time.Duration(corsConfig.MaxAge) * time.Second
+		router.Use(cors.New(config))
+	}
+
+	// 审计中间件：记录 /api/v1 下所有 POST/PUT/DELETE 请求
+	router.Use(middleware.Audit(auditService))
 
 	// 健康检查
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "ok"})
 	})
 
+	// Kubernetes 存活和就绪探针，返回各组件检查结果
+	router.GET("/livez", probeHandler.Livez)
+	router.GET("/readyz", probeHandler.Readyz)
+
+	// Prometheus 指标，启用认证时与其他只读接口一样需要 API key
+	if metricsConfig.Enabled {
+		router.GET(metricsConfig.Path, auth.Authenticate(), auth.Require(models.RoleReadOnly, models.RoleReadOnly), gin.WrapH(metrics.Handler()))
+	}
+
 	// Swagger API 文档
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	// API v1 路由
-	v1 := router.Group("/api/v1")
+	api := router.Group("/api/v1")
+
+	// 登录不需要认证，其余会话接口只要求已认证，与角色无关
+	api.POST("/auth/login", userHandler.Login)
+	session := api.Group("/auth", auth.Authenticate())
+	{
+		session.POST("/logout", userHandler.Logout)
+		session.GET("/me", userHandler.GetCurrentUser)
+		session.PUT("/password", userHandler.ChangePassword)
+	}
+
+	// API v1 路由：所有接口都需要 API key 或登录会话，读取需要 read_only，修改默认需要 operator
+	v1 := api.Group("", auth.Authenticate(), auth.Require(models.RoleReadOnly, models.RoleOperator))
+	// 修改 RPC 节点、链和代币分类规则需要 admin
+	adminWrites := auth.Require(models.RoleReadOnly, models.RoleAdmin)
 	{
 		// 钱包路由
 		wallets := v1.Group("/wallets")
@@ -44,6 +126,9 @@ func SetupRouter(
 			wallets.PUT("/:id", walletHandler.UpdateWallet)
 			wallets.DELETE("/:id", walletHandler.DeleteWallet)
 			wallets.POST("/:id/refresh", addressHandler.RefreshWallet)
+			wallets.GET("/:id/shares", walletHandler.ListShares)
+			wallets.POST("/:id/shares", walletHandler.ShareWallet)
+			wallets.DELETE("/:id/shares/:user_id", walletHandler.UnshareWallet)
 		}
 
 		// 地址路由
@@ -55,16 +140,111 @@ func SetupRouter(
 			addresses.PUT("/:id", addressHandler.UpdateAddress)
 			addresses.DELETE("/:id", addressHandler.DeleteAddress)
 			addresses.POST("/:id/refresh", addressHandler.RefreshAddress)
+			addresses.GET("/:id/positions", positionHandler.ListAddressPositions)
+			addresses.GET("/:id/transactions", transactionHandler.ListAddressTransactions)
+		}
+
+		// 协议持仓路由
+		positions := v1.Group("/positions")
+		{
+			positions.GET("", positionHandler.ListPositions)
+			positions.GET("/:id", positionHandler.GetPosition)
+		}
+
+		// 健康因子监控路由
+		healthThresholds := v1.Group("/health-thresholds")
+		{
+			healthThresholds.POST("", healthHandler.CreateHealthThreshold)
+			healthThresholds.GET("", healthHandler.ListHealthThresholds)
+			healthThresholds.PUT("/:id", healthHandler.UpdateHealthThreshold)
+			healthThresholds.DELETE("/:id", healthHandler.DeleteHealthThreshold)
+		}
+		v1.GET("/health-alerts", healthHandler.ListHealthAlerts)
+		v1.GET("/health-history", healthHandler.ListHealthHistory)
+		v1.POST("/notifications/test", healthHandler.TestNotification)
+
+		// 余额变化告警路由
+		alertRules := v1.Group("/alert-rules")
+		{
+			alertRules.POST("", alertRuleHandler.CreateAlertRule)
+			alertRules.GET("", alertRuleHandler.ListAlertRules)
+			alertRules.GET("/:id", alertRuleHandler.GetAlertRule)
+			alertRules.PUT("/:id", alertRuleHandler.UpdateAlertRule)
+			alertRules.DELETE("/:id", alertRuleHandler.DeleteAlertRule)
+		}
+		v1.GET("/alerts", alertRuleHandler.ListAlerts)
+
+		// 出站 webhook 路由（端点 URL 可能包含凭证，读取也需要 admin）
+		webhooks := v1.Group("/webhooks", auth.Require(models.RoleAdmin, models.RoleAdmin))
+		{
+			webhooks.POST("", webhookHandler.CreateWebhook)
+			webhooks.GET("", webhookHandler.ListWebhooks)
+			webhooks.GET("/event-types", webhookHandler.ListWebhookEventTypes)
+			webhooks.GET("/deliveries", webhookHandler.ListWebhookDeliveries)
+			webhooks.POST("/deliveries/replay-dead", webhookHandler.ReplayDeadLetters)
+			webhooks.POST("/deliveries/:id/replay", webhookHandler.ReplayWebhookDelivery)
+			webhooks.GET("/:id", webhookHandler.GetWebhook)
+			webhooks.PUT("/:id", webhookHandler.UpdateWebhook)
+			webhooks.DELETE("/:id", webhookHandler.DeleteWebhook)
+			webhooks.POST("/:id/test", webhookHandler.TestWebhook)
+		}
+
+		// 用户管理路由
+		users := v1.Group("/users", auth.Require(models.RoleAdmin, models.RoleAdmin))
+		{
+			users.POST("", userHandler.CreateUser)
+			users.GET("", userHandler.ListUsers)
+			users.PUT("/:id", userHandler.UpdateUser)
+			users.DELETE("/:id", userHandler.DeleteUser)
 		}
 
+		// 审计日志路由（包含所有钱包的修改记录，需要 admin）
+		v1.GET("/audit-log", auth.Require(models.RoleAdmin, models.RoleAdmin), auditHandler.ListAuditLog)
+
+		// 当前生效的配置（敏感值已隐藏，仍包含内部地址等信息，需要 admin）
+		v1.GET("/admin/config", auth.Require(models.RoleAdmin, models.RoleAdmin), configHandler.GetConfig)
+
+		// 与数据库类型无关的备份导出和恢复（包含密码哈希等敏感数据，需要 admin）
+		v1.GET("/admin/backup", auth.Require(models.RoleAdmin, models.RoleAdmin), backupHandler.ExportBackup)
+		v1.POST("/admin/restore", auth.Require(models.RoleAdmin, models.RoleAdmin), backupHandler.RestoreBackup)
+
+		// 实时事件流路由
+		v1.GET("/events", eventHandler.StreamEvents)
+		v1.GET("/events/types", eventHandler.ListEventTypes)
+
 		// 链路由
-		chains := v1.Group("/chains")
+		chains := v1.Group("/chains", adminWrites)
 		{
+			chains.POST("", chainHandler.CreateChain)
 			chains.GET("", chainHandler.ListChains)
+			chains.POST("/sync", chainHandler.SyncChains)
+			chains.GET("/:id", chainHandler.GetChain)
+			chains.PUT("/:id", chainHandler.UpdateChain)
+			chains.DELETE("/:id", chainHandler.DeleteChain)
+		}
+
+		// 代币分类规则路由
+		tokenRules := v1.Group("/token-rules", adminWrites)
+		{
+			tokenRules.POST("", tokenRuleHandler.CreateTokenRule)
+			tokenRules.GET("", tokenRuleHandler.ListTokenRules)
+			tokenRules.POST("/reclassify", tokenRuleHandler.ReclassifyTokens)
+			tokenRules.GET("/:id", tokenRuleHandler.GetTokenRule)
+			tokenRules.PUT("/:id", tokenRuleHandler.UpdateTokenRule)
+			tokenRules.DELETE("/:id", tokenRuleHandler.DeleteTokenRule)
+		}
+
+		// 代币忽略/白名单路由
+		tokenOverrides := v1.Group("/token-overrides")
+		{
+			tokenOverrides.POST("", tokenOverrideHandler.SetTokenOverride)
+			tokenOverrides.GET("", tokenOverrideHandler.ListTokenOverrides)
+			tokenOverrides.GET("/audit", tokenOverrideHandler.ListTokenOverrideAudits)
+			tokenOverrides.DELETE("/:id", tokenOverrideHandler.DeleteTokenOverride)
 		}
 
 		// RPC 节点路由
-		rpcNodes := v1.Group("/rpc-nodes")
+		rpcNodes := v1.Group("/rpc-nodes", adminWrites)
 		{
 			rpcNodes.POST("", rpcNodeHandler.CreateRPCNode)
 			rpcNodes.GET("", rpcNodeHandler.ListRPCNodes)
@@ -73,6 +253,7 @@ func SetupRouter(
 			rpcNodes.PUT("/:id", rpcNodeHandler.UpdateRPCNode)
 			rpcNodes.DELETE("/:id", rpcNodeHandler.DeleteRPCNode)
 			rpcNodes.POST("/:id/check", rpcNodeHandler.CheckRPCNodeConnection)
+			rpcNodes.POST("/:id/capabilities", rpcNodeHandler.CheckRPCNodeCapabilities)
 			rpcNodes.POST("/check-all", rpcNodeHandler.CheckAllRPCNodeConnections)
 		}
 	}
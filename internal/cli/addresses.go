@@ -0,0 +1,322 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/rotki-demo/internal/config"
+	"github.com/rotki-demo/internal/database"
+	"github.com/rotki-demo/internal/models"
+	"github.com/rotki-demo/internal/repository"
+	"gorm.io/gorm"
+)
+
+const addressesUsage = `Usage: rotkictl addresses <command> [args]
+
+Commands:
+  export [--wallet <id>] [--format csv|json] [--output <file>]
+                     导出地址（默认所有钱包，输出到标准输出）
+  import [--wallet <id>] [--format csv|json] <file>
+                     导入地址（<file> 为 - 时从标准输入读取）；指定 --wallet 时导入到该钱包，
+                     否则使用文件中的 wallet_id；目标钱包中已有的地址会被跳过。导入后可运行 sync wallet 同步数据
+
+文件格式：CSV 表头为 wallet_id,address,chain_type,label,tags（tags 以 ; 分隔）；JSON 为同名字段的对象数组。
+未指定 --format 时按文件扩展名判断，默认 csv
+`
+
+// csvHeader 是地址 CSV 文件的列
+var csvHeader = []string{"wallet_id", "address", "chain_type", "label", "tags"}
+
+// addressRecord 是导入导出文件中的一个地址
+type addressRecord struct {
+	WalletID  uint     `json:"wallet_id"`
+	Address   string   `json:"address"`
+	ChainType string   `json:"chain_type"`
+	Label     string   `json:"label"`
+	Tags      []string `json:"tags"`
+}
+
+// runAddressesCommand 执行 addresses 子命令并返回退出码
+func runAddressesCommand(cfg *config.Config, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, addressesUsage)
+		return 2
+	}
+
+	switch args[0] {
+	case "export", "import":
+	default:
+		fmt.Fprint(os.Stderr, addressesUsage)
+		return 2
+	}
+
+	fs := flag.NewFlagSet("addresses "+args[0], flag.ContinueOnError)
+	walletID := fs.Uint("wallet", 0, "wallet ID")
+	format := fs.String("format", "", "csv or json")
+	output := fs.String("output", "", "output file (export only), default stdout")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if *format == "" {
+		*format = formatFromPath(fs.Arg(0) + *output)
+	}
+	if *format != "csv" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "invalid format: %s\n", *format)
+		return 2
+	}
+
+	if err := database.InitDatabase(&cfg.Database); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize database: %v\n", err)
+		return 1
+	}
+
+	walletRepo := repository.NewWalletRepository(database.GetDB())
+	addressRepo := repository.NewAddressRepository(database.GetDB())
+
+	if args[0] == "export" {
+		return exportAddresses(addressRepo, uint(*walletID), *format, *output)
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprint(os.Stderr, addressesUsage)
+		return 2
+	}
+	return importAddresses(walletRepo, addressRepo, uint(*walletID), *format, fs.Arg(0))
+}
+
+// exportAddresses 将地址写入文件或标准输出
+func exportAddresses(addressRepo *repository.AddressRepository, walletID uint, format, output string) int {
+	var (
+		addresses []models.Address
+		err       error
+	)
+	if walletID != 0 {
+		addresses, err = addressRepo.GetByWalletID(walletID)
+	} else {
+		addresses, err = addressRepo.List(repository.WalletScope{})
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to list addresses: %v\n", err)
+		return 1
+	}
+
+	records := make([]addressRecord, 0, len(addresses))
+	for _, address := range addresses {
+		records = append(records, addressRecord{
+			WalletID:  address.WalletID,
+			Address:   address.Address,
+			ChainType: address.ChainType,
+			Label:     address.Label,
+			Tags:      address.Tags,
+		})
+	}
+
+	out := io.Writer(os.Stdout)
+	if output != "" {
+		file, err := os.Create(output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create %s: %v\n", output, err)
+			return 1
+		}
+		defer file.Close()
+		out = file
+	}
+
+	if format == "json" {
+		err = writeAddressesJSON(out, records)
+	} else {
+		err = writeAddressesCSV(out, records)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write addresses: %v\n", err)
+		return 1
+	}
+	if output != "" {
+		fmt.Printf("Exported %d addresses to %s\n", len(records), output)
+	}
+	return 0
+}
+
+// importAddresses 从文件导入地址，跳过已有的地址
+func importAddresses(walletRepo *repository.WalletRepository, addressRepo *repository.AddressRepository, walletID uint, format, path string) int {
+	in := io.Reader(os.Stdin)
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to open %s: %v\n", path, err)
+			return 1
+		}
+		defer file.Close()
+		in = file
+	}
+
+	var (
+		records []addressRecord
+		err     error
+	)
+	if format == "json" {
+		records, err = readAddressesJSON(in)
+	} else {
+		records, err = readAddressesCSV(in)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to read %s: %v\n", path, err)
+		return 1
+	}
+
+	// 地址在钱包内唯一，目标钱包中已存在的地址跳过
+	wallets := make(map[uint]bool)
+	imported, skipped := 0, 0
+	for i, record := range records {
+		if walletID != 0 {
+			record.WalletID = walletID
+		}
+		record.Address = strings.TrimSpace(record.Address)
+		if record.ChainType == "" {
+			record.ChainType = "EVM"
+		}
+		if record.WalletID == 0 || record.Address == "" {
+			fmt.Fprintf(os.Stderr, "Record %d: wallet_id and address are required\n", i+1)
+			return 1
+		}
+
+		if !wallets[record.WalletID] {
+			if _, err := walletRepo.GetByID(record.WalletID); err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					fmt.Fprintf(os.Stderr, "Record %d: wallet %d not found\n", i+1, record.WalletID)
+				} else {
+					fmt.Fprintf(os.Stderr, "Failed to get wallet %d: %v\n", record.WalletID, err)
+				}
+				return 1
+			}
+			wallets[record.WalletID] = true
+		}
+
+		_, err := addressRepo.GetByWalletAndAddress(record.WalletID, record.Address, record.ChainType)
+		if err == nil {
+			skipped++
+			continue
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			fmt.Fprintf(os.Stderr, "Failed to look up address %s: %v\n", record.Address, err)
+			return 1
+		}
+
+		address := &models.Address{
+			WalletID:  record.WalletID,
+			Address:   record.Address,
+			ChainType: record.ChainType,
+			Label:     record.Label,
+			Tags:      record.Tags,
+		}
+		if err := addressRepo.Create(address); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create address %s: %v\n", record.Address, err)
+			return 1
+		}
+		imported++
+	}
+
+	fmt.Printf("Imported %d addresses, skipped %d existing\n", imported, skipped)
+	return 0
+}
+
+// formatFromPath 根据文件扩展名判断格式，默认 csv
+func formatFromPath(path string) string {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return "json"
+	}
+	return "csv"
+}
+
+// writeAddressesCSV 以 CSV 格式写入地址
+func writeAddressesCSV(w io.Writer, records []addressRecord) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, record := range records {
+		row := []string{
+			strconv.FormatUint(uint64(record.WalletID), 10),
+			record.Address,
+			record.ChainType,
+			record.Label,
+			strings.Join(record.Tags, ";"),
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeAddressesJSON 以 JSON 数组写入地址
+func writeAddressesJSON(w io.Writer, records []addressRecord) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(records)
+}
+
+// readAddressesCSV 读取 CSV 地址文件，按表头定位列，只有 address 列是必需的
+func readAddressesCSV(r io.Reader) ([]addressRecord, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	columns := make(map[string]int, len(rows[0]))
+	for i, name := range rows[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["address"]; !ok {
+		return nil, errors.New("missing address column")
+	}
+	field := func(row []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	records := make([]addressRecord, 0, len(rows)-1)
+	for line, row := range rows[1:] {
+		record := addressRecord{
+			Address:   field(row, "address"),
+			ChainType: field(row, "chain_type"),
+			Label:     field(row, "label"),
+		}
+		if value := field(row, "wallet_id"); value != "" {
+			id, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid wallet_id %q", line+2, value)
+			}
+			record.WalletID = uint(id)
+		}
+		for _, tag := range strings.Split(field(row, "tags"), ";") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				record.Tags = append(record.Tags, tag)
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// readAddressesJSON 读取 JSON 地址数组
+func readAddressesJSON(r io.Reader) ([]addressRecord, error) {
+	var records []addressRecord
+	if err := json.NewDecoder(r).Decode(&records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
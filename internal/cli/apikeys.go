@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rotki-demo/internal/config"
+	"github.com/rotki-demo/internal/database"
+	"github.com/rotki-demo/internal/models"
+	"github.com/rotki-demo/internal/repository"
+	"github.com/rotki-demo/internal/service"
+)
+
+const apiKeysUsage = `Usage: rotkictl apikeys <command> [args]
+
+Commands:
+  create --name <name> --role <role> [--expires-in <duration>] [--user <username>]
+                     创建 API key（role：read_only、operator、admin；如 --expires-in 720h），明文只显示一次；
+                     指定 --user 时 key 只能访问该用户的钱包，权限不超过用户的角色
+  list               显示所有 API key
+  revoke <id>        吊销 API key
+`
+
+// runAPIKeysCommand 执行 apikeys 子命令并返回退出码
+func runAPIKeysCommand(cfg *config.Config, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, apiKeysUsage)
+		return 2
+	}
+
+	if err := database.InitDatabase(&cfg.Database); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize database: %v\n", err)
+		return 1
+	}
+
+	keyService := service.NewAPIKeyService(repository.NewAPIKeyRepository(database.GetDB()))
+	userRepo := repository.NewUserRepository(database.GetDB())
+
+	switch args[0] {
+	case "create":
+		fs := flag.NewFlagSet("apikeys create", flag.ContinueOnError)
+		name := fs.String("name", "", "key name")
+		role := fs.String("role", models.RoleReadOnly, "read_only, operator or admin")
+		expiresIn := fs.Duration("expires-in", 0, "lifetime, 0 means never expires")
+		username := fs.String("user", "", "bind the key to a user")
+		if err := fs.Parse(args[1:]); err != nil {
+			return 2
+		}
+
+		var userID *uint
+		if *username != "" {
+			user, err := userRepo.GetByUsername(*username)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to find user %s: %v\n", *username, err)
+				return 1
+			}
+			userID = &user.ID
+		}
+
+		key, raw, err := keyService.Create(*name, *role, *expiresIn, userID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create API key: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Created API key %d (%s, role %s)\n", key.ID, key.Name, key.Role)
+		if key.ExpiresAt != nil {
+			fmt.Printf("Expires at %s\n", key.ExpiresAt.Format("2006-01-02 15:04:05"))
+		}
+		fmt.Printf("\n%s\n\nStore it now; it cannot be shown again.\n", raw)
+
+	case "list":
+		keys, err := keyService.List()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to list API keys: %v\n", err)
+			return 1
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tPREFIX\tROLE\tUSER\tSTATUS\tLAST USED")
+		for _, key := range keys {
+			status := "active"
+			if key.RevokedAt != nil {
+				status = "revoked"
+			} else if !key.Active(time.Now()) {
+				status = "expired"
+			}
+			lastUsed := ""
+			if key.LastUsedAt != nil {
+				lastUsed = key.LastUsedAt.Format("2006-01-02 15:04:05")
+			}
+			owner := ""
+			if key.UserID != nil {
+				owner = strconv.FormatUint(uint64(*key.UserID), 10)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s…\t%s\t%s\t%s\t%s\n", key.ID, key.Name, key.KeyPrefix, key.Role, owner, status, lastUsed)
+		}
+		_ = w.Flush()
+
+	case "revoke":
+		if len(args) < 2 {
+			fmt.Fprint(os.Stderr, apiKeysUsage)
+			return 2
+		}
+		id, err := strconv.ParseUint(args[1], 10, 32)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid id: %s\n", args[1])
+			return 2
+		}
+		if err := keyService.Revoke(uint(id)); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to revoke API key: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Revoked API key %d\n", id)
+
+	default:
+		fmt.Fprint(os.Stderr, apiKeysUsage)
+		return 2
+	}
+
+	return 0
+}
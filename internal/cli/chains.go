@@ -0,0 +1,205 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rotki-demo/internal/config"
+	"github.com/rotki-demo/internal/database"
+	"github.com/rotki-demo/internal/models"
+	"github.com/rotki-demo/internal/provider/debank"
+	"github.com/rotki-demo/internal/repository"
+	"github.com/rotki-demo/internal/service"
+	"gorm.io/gorm"
+)
+
+const chainsUsage = `Usage: rotkictl chains <command> [args]
+
+Commands:
+  add --id <id> --name <name> [--type <type>] [--logo <url>] [--native-token <id>] [--network-id <n>] [--explorer <url>] [--inactive]
+                       添加并激活链；链已存在时只更新指定的字段并激活（--inactive 登记但不激活）
+  list [--all]         显示已激活的链（--all 包括未激活的链）
+  sync [file [path]]   从 chains.json 重新同步链元数据（默认使用内置路径）
+  sync provider        从数据提供者的链列表重新同步链元数据
+`
+
+// runChainsCommand 执行 chains 子命令并返回退出码
+func runChainsCommand(cfg *config.Config, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, chainsUsage)
+		return 2
+	}
+
+	if err := database.InitDatabase(&cfg.Database); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize database: %v\n", err)
+		return 1
+	}
+
+	chainRepo := repository.NewChainRepository(database.GetDB())
+
+	switch args[0] {
+	case "add":
+		return addChain(chainRepo, args[1:])
+	case "list":
+		return listChains(chainRepo, args[1:])
+	case "sync":
+		return syncChains(cfg, chainRepo, args[1:])
+	default:
+		fmt.Fprint(os.Stderr, chainsUsage)
+		return 2
+	}
+}
+
+// addChain 添加链或更新已有链的元数据
+func addChain(chainRepo *repository.ChainRepository, args []string) int {
+	fs := flag.NewFlagSet("chains add", flag.ContinueOnError)
+	id := fs.String("id", "", "chain ID, e.g. linea")
+	name := fs.String("name", "", "display name")
+	chainType := fs.String("type", "EVM", "chain type")
+	logo := fs.String("logo", "", "logo URL")
+	nativeToken := fs.String("native-token", "", "native token ID")
+	networkID := fs.Int64("network-id", 0, "numeric (EIP-155) chain ID, 0 means unknown")
+	explorer := fs.String("explorer", "", "block explorer URL")
+	inactive := fs.Bool("inactive", false, "register the chain without activating it")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	chainID := strings.ToLower(strings.TrimSpace(*id))
+	if chainID == "" {
+		fmt.Fprint(os.Stderr, chainsUsage)
+		return 2
+	}
+
+	chain, err := chainRepo.GetByID(chainID)
+	created := errors.Is(err, gorm.ErrRecordNotFound)
+	if err != nil && !created {
+		fmt.Fprintf(os.Stderr, "Failed to get chain %s: %v\n", chainID, err)
+		return 1
+	}
+	if created && *name == "" {
+		fmt.Fprintln(os.Stderr, "--name is required for a new chain")
+		return 2
+	}
+	if created {
+		chain = &models.Chain{ID: chainID, ChainType: *chainType, IsActive: true}
+	}
+
+	// 更新已有链时只修改命令行中指定的字段
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "name":
+			chain.Name = *name
+		case "type":
+			chain.ChainType = *chainType
+		case "logo":
+			chain.LogoURL = *logo
+		case "native-token":
+			chain.NativeTokenID = *nativeToken
+		case "network-id":
+			chain.NetworkID = *networkID
+		case "explorer":
+			chain.ExplorerURL = *explorer
+		}
+	})
+	chain.IsActive = !*inactive
+
+	if created {
+		err = chainRepo.Create(chain)
+	} else {
+		err = chainRepo.Update(chain)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to save chain %s: %v\n", chainID, err)
+		return 1
+	}
+
+	action := "Updated"
+	if created {
+		action = "Added"
+	}
+	fmt.Printf("%s chain %s (%s, active: %t)\n", action, chain.ID, chain.Name, chain.IsActive)
+	return 0
+}
+
+// listChains 显示链列表
+func listChains(chainRepo *repository.ChainRepository, args []string) int {
+	fs := flag.NewFlagSet("chains list", flag.ContinueOnError)
+	all := fs.Bool("all", false, "include inactive chains")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	list := chainRepo.List
+	if *all {
+		list = chainRepo.ListAll
+	}
+	chains, err := list()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to list chains: %v\n", err)
+		return 1
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tTYPE\tNETWORK ID\tNATIVE TOKEN\tACTIVE")
+	for _, chain := range chains {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%t\n", chain.ID, chain.Name, chain.ChainType, chain.NetworkID, chain.NativeTokenID, chain.IsActive)
+	}
+	_ = w.Flush()
+	return 0
+}
+
+// syncChains 从 chains.json 或数据提供者重新同步链元数据
+func syncChains(cfg *config.Config, chainRepo *repository.ChainRepository, args []string) int {
+	source := "file"
+	if len(args) > 0 {
+		source = args[0]
+	}
+
+	chainInitializer := service.NewChainInitializer(chainRepo)
+
+	switch source {
+	case "file":
+		path := ""
+		if len(args) > 1 {
+			path = args[1]
+		} else if found, ok := service.FindDefaultChainsFile(); ok {
+			path = found
+		} else {
+			fmt.Fprintln(os.Stderr, "chains.json not found in any default location")
+			return 1
+		}
+		if err := chainInitializer.InitializeAllChains(path); err != nil {
+			fmt.Fprintf(os.Stderr, "Chain sync failed: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Synced chains from %s\n", path)
+
+	case "provider":
+		if err := cfg.ValidateProvider(); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			return 1
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+
+		count, err := chainInitializer.SyncFromProvider(ctx, debank.NewDeBankProvider(&cfg.DeBank))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Chain sync failed: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Synced %d chains from provider\n", count)
+
+	default:
+		fmt.Fprint(os.Stderr, chainsUsage)
+		return 2
+	}
+
+	return 0
+}
@@ -0,0 +1,62 @@
+// Package cli 实现管理命令（rotkictl 以及服务器二进制的同名子命令），
+// 直接复用配置、仓储和服务层，运维无需 curl 或手写 SQL 即可管理部署
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rotki-demo/internal/config"
+)
+
+// Usage 是 rotkictl 的总体用法
+const Usage = `Usage: rotkictl [--config <path>] <command> [args]
+
+Commands:
+  migrate     数据库迁移（status、up、down、baseline）
+  chains      链管理（add、list、sync）
+  wallets     钱包管理（list、create）
+  addresses   地址导入导出（import、export）
+  sync        立即同步地址数据（address、wallet、all）
+  rpc-nodes   RPC 节点连接检查（check）
+  snapshots   资产快照维护（compact）
+  backup      备份导出和恢复（export、import）
+  users       用户管理（create、list、passwd）
+  apikeys     API key 管理（create、list、revoke）
+
+Run "rotkictl <command>" without arguments to see its usage.
+`
+
+// commands 是所有管理命令，每个命令接收子命令参数并返回进程退出码
+var commands = map[string]func(cfg *config.Config, args []string) int{
+	"migrate":   runMigrateCommand,
+	"chains":    runChainsCommand,
+	"wallets":   runWalletsCommand,
+	"addresses": runAddressesCommand,
+	"sync":      runSyncCommand,
+	"rpc-nodes": runRPCNodesCommand,
+	"snapshots": runSnapshotsCommand,
+	"backup":    runBackupCommand,
+	"users":     runUsersCommand,
+	"apikeys":   runAPIKeysCommand,
+}
+
+// IsCommand 判断 name 是否为管理命令
+func IsCommand(name string) bool {
+	_, ok := commands[name]
+	return ok
+}
+
+// Run 执行 args[0] 指定的管理命令并返回退出码，参数错误时返回 2
+func Run(cfg *config.Config, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, Usage)
+		return 2
+	}
+	run, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command: %s\n\n%s", args[0], Usage)
+		return 2
+	}
+	return run(cfg, args[1:])
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rotki-demo/internal/config"
+	"github.com/rotki-demo/internal/database"
+	"github.com/rotki-demo/internal/logger"
+	"github.com/rotki-demo/internal/models"
+	"github.com/rotki-demo/internal/repository"
+	"github.com/rotki-demo/internal/service"
+)
+
+const rpcNodesUsage = `Usage: rotkictl rpc-nodes <command> [args]
+
+Commands:
+  check [--chain <id>] [--probe]
+                     检查已启用 RPC 节点的连接，更新后的状态会保存到数据库；从未探测能力或探测结果
+                     超过 24 小时的节点会重新探测，--probe 对所有已连接的节点强制重新探测；
+                     任意节点无法连接时退出码为 1
+`
+
+// runRPCNodesCommand 执行 rpc-nodes 子命令并返回退出码
+func runRPCNodesCommand(cfg *config.Config, args []string) int {
+	if len(args) == 0 || args[0] != "check" {
+		fmt.Fprint(os.Stderr, rpcNodesUsage)
+		return 2
+	}
+
+	fs := flag.NewFlagSet("rpc-nodes check", flag.ContinueOnError)
+	chainID := fs.String("chain", "", "only check nodes of this chain")
+	probe := fs.Bool("probe", false, "re-detect capabilities of every connected node")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	if err := database.InitDatabase(&cfg.Database); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize database: %v\n", err)
+		return 1
+	}
+
+	ctx := context.Background()
+	rpcNodeService := service.NewRPCNodeService(repository.NewRPCNodeRepository(database.GetDB()), newEventBus(cfg, database.GetDB()), logger.GetLogger())
+
+	var (
+		nodes []models.RPCNode
+		err   error
+	)
+	if *chainID != "" {
+		nodes, err = rpcNodeService.GetByChainID(ctx, *chainID)
+	} else {
+		nodes, err = rpcNodeService.GetAll(ctx)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to list RPC nodes: %v\n", err)
+		return 1
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tCHAIN\tNAME\tURL\tSTATUS\tARCHIVE\tTRACE\tDEBUG\tWEBSOCKET")
+	failed := 0
+	for _, node := range nodes {
+		if !node.IsEnabled {
+			continue
+		}
+		connected, err := rpcNodeService.CheckConnection(ctx, node.ID)
+		status := "connected"
+		if err != nil {
+			status = "error: " + err.Error()
+		} else if !connected {
+			status = "disconnected"
+		}
+		if err != nil || !connected {
+			failed++
+		}
+
+		// 重新读取节点以显示刚探测到的能力
+		checkedAt := node.CapabilitiesCheckedAt
+		if updated, err := rpcNodeService.GetByID(ctx, node.ID); err == nil {
+			node = *updated
+		}
+		// 连接检查已因结果过期重新探测时不再重复探测；部分探测没有结论时其余结果仍已保存，只提示不计为失败
+		if *probe && err == nil && connected && timesEqual(checkedAt, node.CapabilitiesCheckedAt) {
+			if _, err := rpcNodeService.CheckCapabilities(ctx, node.ID); err != nil {
+				fmt.Fprintf(os.Stderr, "Node %d: capability detection failed: %v\n", node.ID, err)
+			}
+			if updated, err := rpcNodeService.GetByID(ctx, node.ID); err == nil {
+				node = *updated
+			}
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%t\t%t\t%t\t%t\n", node.ID, node.ChainID, node.Name, node.URL, status,
+			node.IsArchive, node.SupportsTrace, node.SupportsDebug, node.SupportsWebSocket)
+	}
+	_ = w.Flush()
+
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "%d node(s) not connected\n", failed)
+		return 1
+	}
+	return 0
+}
+
+// timesEqual 比较两个可能为空的时间
+func timesEqual(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
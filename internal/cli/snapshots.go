@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/rotki-demo/internal/config"
+	"github.com/rotki-demo/internal/database"
+	"github.com/rotki-demo/internal/repository"
+	"github.com/rotki-demo/internal/service"
+)
+
+const snapshotsUsage = `Usage: rotkictl snapshots <command> [args]
+
+Commands:
+  compact [--keep-all <duration>] [--keep-hourly <duration>] [--dry-run]
+                     压缩资产快照：--keep-all（默认 168h）内的快照全部保留，--keep-hourly（默认 720h）内每小时保留一个，
+                     更早的每天保留一个（均保留时间段内最新的快照）；--dry-run 只显示将删除的数量
+`
+
+// runSnapshotsCommand 执行 snapshots 子命令并返回退出码
+func runSnapshotsCommand(cfg *config.Config, args []string) int {
+	if len(args) == 0 || args[0] != "compact" {
+		fmt.Fprint(os.Stderr, snapshotsUsage)
+		return 2
+	}
+
+	fs := flag.NewFlagSet("snapshots compact", flag.ContinueOnError)
+	keepAll := fs.Duration("keep-all", 7*24*time.Hour, "keep every snapshot newer than this")
+	keepHourly := fs.Duration("keep-hourly", 30*24*time.Hour, "keep one snapshot per hour newer than this, one per day before")
+	dryRun := fs.Bool("dry-run", false, "only report how many snapshots would be deleted")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	if err := database.InitDatabase(&cfg.Database); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize database: %v\n", err)
+		return 1
+	}
+
+	snapshotService := service.NewSnapshotService(repository.NewSnapshotRepository(database.GetDB()))
+	result, err := snapshotService.Compact(time.Now(), service.SnapshotCompactOptions{
+		KeepAll:    *keepAll,
+		KeepHourly: *keepHourly,
+		DryRun:     *dryRun,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Snapshot compaction failed: %v\n", err)
+		if result == nil {
+			return 1
+		}
+	}
+
+	action := "Deleted"
+	if *dryRun {
+		action = "Would delete"
+	}
+	fmt.Printf("%s %d of %d snapshots older than %s (%d addresses)\n", action, result.Deleted, result.Scanned, *keepAll, result.Addresses)
+	if err != nil {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,171 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+
+	"github.com/rotki-demo/internal/config"
+	"github.com/rotki-demo/internal/database"
+	"github.com/rotki-demo/internal/events"
+	"github.com/rotki-demo/internal/notifier"
+	"github.com/rotki-demo/internal/provider"
+	"github.com/rotki-demo/internal/provider/debank"
+	"github.com/rotki-demo/internal/repository"
+	"github.com/rotki-demo/internal/service"
+	"gorm.io/gorm"
+)
+
+const syncUsage = `Usage: rotkictl sync <command> [args]
+
+Commands:
+  address <id>       立即同步地址
+  wallet <id>        立即同步钱包中的所有地址
+  all                立即同步所有地址（不考虑上次同步时间）
+
+同步与服务器使用相同的数据提供者、代币分类、健康因子和余额告警；产生的 webhook 事件写入投递队列，由服务器进程投递。
+任意地址同步失败时退出码为 1
+`
+
+// runSyncCommand 执行 sync 子命令并返回退出码
+func runSyncCommand(cfg *config.Config, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, syncUsage)
+		return 2
+	}
+
+	var id uint
+	switch args[0] {
+	case "address", "wallet":
+		if len(args) < 2 {
+			fmt.Fprint(os.Stderr, syncUsage)
+			return 2
+		}
+		n, err := strconv.ParseUint(args[1], 10, 32)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid id: %s\n", args[1])
+			return 2
+		}
+		id = uint(n)
+	case "all":
+	default:
+		fmt.Fprint(os.Stderr, syncUsage)
+		return 2
+	}
+
+	if err := cfg.ValidateProvider(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	if err := database.InitDatabase(&cfg.Database); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize database: %v\n", err)
+		return 1
+	}
+
+	bus := newEventBus(cfg, database.GetDB())
+	syncService, err := newSyncService(cfg, database.GetDB(), bus)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize sync service: %v\n", err)
+		return 1
+	}
+
+	// Ctrl+C 取消进行中的请求，sync all 不再开始新的批次
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// 通过事件总线逐个输出地址的同步结果
+	sub := bus.Subscribe(events.Filter{Types: []string{events.SyncCompleted, events.SyncFailed}})
+	var (
+		wg               sync.WaitGroup
+		synced, failures int
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for event := range sub.C {
+			result, _ := event.Data.(service.SyncResult)
+			if event.Type == events.SyncFailed {
+				failures++
+				fmt.Printf("FAILED  address %d (%s): %s\n", result.AddressID, result.Address, result.Error)
+				continue
+			}
+			synced++
+			value := 0.0
+			if result.AddressUSDValue != nil {
+				value = *result.AddressUSDValue
+			}
+			fmt.Printf("OK      address %d (%s): $%.2f\n", result.AddressID, result.Address, value)
+		}
+	}()
+
+	switch args[0] {
+	case "address":
+		err = syncService.SyncAddress(ctx, id)
+	case "wallet":
+		err = syncService.SyncWallet(ctx, id)
+	case "all":
+		err = syncService.SyncAll(ctx)
+	}
+
+	sub.Close()
+	wg.Wait()
+
+	if err != nil && failures == 0 {
+		fmt.Fprintf(os.Stderr, "Sync failed: %v\n", err)
+		return 1
+	}
+	if dropped := sub.Dropped(); dropped > 0 {
+		fmt.Fprintf(os.Stderr, "%d results were not shown (increase events.buffer_size)\n", dropped)
+	}
+	fmt.Printf("Synced %d addresses, %d failed\n", synced, failures)
+	if failures > 0 {
+		return 1
+	}
+	return 0
+}
+
+// newEventBus 创建与服务器相同的事件总线，事件转发到 webhook 投递队列（不在本进程投递）
+func newEventBus(cfg *config.Config, db *gorm.DB) *events.Bus {
+	webhookService := service.NewWebhookService(repository.NewWebhookRepository(db), cfg.Webhooks)
+	return events.NewBus(cfg.Events.BufferSize, webhookService)
+}
+
+// newSyncService 按服务器的方式组装同步服务，不启动定时同步
+func newSyncService(cfg *config.Config, db *gorm.DB, bus *events.Bus) (*service.SyncService, error) {
+	walletRepo := repository.NewWalletRepository(db)
+	addressRepo := repository.NewAddressRepository(db)
+	tokenRepo := repository.NewTokenRepository(db)
+	snapshotRepo := repository.NewSnapshotRepository(db)
+	tokenOverrideRepo := repository.NewTokenOverrideRepository(db)
+
+	tokenClassifier, err := service.NewTokenClassifier(repository.NewTokenRuleRepository(db), tokenOverrideRepo, tokenRepo, cfg.TokenRules)
+	if err != nil {
+		return nil, fmt.Errorf("token classifier: %w", err)
+	}
+	alertNotifier, err := notifier.New(cfg.Notifier)
+	if err != nil {
+		return nil, fmt.Errorf("notifier: %w", err)
+	}
+	healthMonitor := service.NewHealthMonitor(repository.NewHealthRepository(db), alertNotifier, cfg.Alerts)
+	balanceAlertService := service.NewBalanceAlertService(repository.NewAlertRuleRepository(db), snapshotRepo, tokenRepo, alertNotifier, cfg.Alerts)
+
+	return service.NewSyncService(
+		provider.NewTracedProvider(debank.NewDeBankProvider(&cfg.DeBank)),
+		walletRepo,
+		addressRepo,
+		tokenRepo,
+		repository.NewProtocolRepository(db),
+		repository.NewProtocolPositionRepository(db),
+		repository.NewChainRepository(db),
+		tokenClassifier,
+		healthMonitor,
+		balanceAlertService,
+		bus,
+		cfg.Sync.GetSyncInterval(),
+		cfg.Sync.BatchSize,
+	), nil
+}
@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rotki-demo/internal/config"
+	"github.com/rotki-demo/internal/database"
+	"github.com/rotki-demo/internal/models"
+	"github.com/rotki-demo/internal/repository"
+)
+
+const walletsUsage = `Usage: rotkictl wallets <command> [args]
+
+Commands:
+  list               显示所有钱包
+  create --name <name> [--description <text>] [--tags <a,b>] [--chains <eth,bsc>] [--owner <username>]
+                     创建钱包（--chains 为空表示所有链）；省略 --owner 时钱包没有所有者，只有管理员和服务 key 可以访问
+`
+
+// runWalletsCommand 执行 wallets 子命令并返回退出码
+func runWalletsCommand(cfg *config.Config, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, walletsUsage)
+		return 2
+	}
+
+	if err := database.InitDatabase(&cfg.Database); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize database: %v\n", err)
+		return 1
+	}
+
+	walletRepo := repository.NewWalletRepository(database.GetDB())
+	userRepo := repository.NewUserRepository(database.GetDB())
+
+	switch args[0] {
+	case "list":
+		wallets, err := walletRepo.List(repository.WalletScope{})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to list wallets: %v\n", err)
+			return 1
+		}
+		users, err := userRepo.List()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to list users: %v\n", err)
+			return 1
+		}
+		usernames := make(map[uint]string, len(users))
+		for _, user := range users {
+			usernames[user.ID] = user.Username
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tOWNER\tSTATUS\tADDRESSES\tCHAINS\tTAGS")
+		for _, wallet := range wallets {
+			owner := ""
+			if wallet.OwnerID != nil {
+				owner = usernames[*wallet.OwnerID]
+			}
+			chains := strings.Join(wallet.EnabledChains, ",")
+			if chains == "" {
+				chains = "all"
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%d\t%s\t%s\n", wallet.ID, wallet.Name, owner, wallet.Status, len(wallet.Addresses), chains, strings.Join(wallet.Tags, ","))
+		}
+		_ = w.Flush()
+
+	case "create":
+		fs := flag.NewFlagSet("wallets create", flag.ContinueOnError)
+		name := fs.String("name", "", "wallet name")
+		description := fs.String("description", "", "wallet description")
+		tags := fs.String("tags", "", "comma-separated tags")
+		chains := fs.String("chains", "", "comma-separated enabled chain IDs, empty means all chains")
+		owner := fs.String("owner", "", "username of the owner")
+		if err := fs.Parse(args[1:]); err != nil {
+			return 2
+		}
+		if strings.TrimSpace(*name) == "" {
+			fmt.Fprint(os.Stderr, walletsUsage)
+			return 2
+		}
+
+		wallet := &models.Wallet{
+			Name:          *name,
+			Description:   *description,
+			Tags:          splitList(*tags),
+			EnabledChains: splitList(*chains),
+		}
+		if *owner != "" {
+			user, err := userRepo.GetByUsername(*owner)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to find user %s: %v\n", *owner, err)
+				return 1
+			}
+			wallet.OwnerID = &user.ID
+		}
+
+		if err := walletRepo.Create(wallet); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create wallet: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Created wallet %d (%s)\n", wallet.ID, wallet.Name)
+
+	default:
+		fmt.Fprint(os.Stderr, walletsUsage)
+		return 2
+	}
+
+	return 0
+}
+
+// splitList 拆分逗号分隔的列表，去掉空白和空项
+func splitList(value string) models.StringSlice {
+	var items models.StringSlice
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
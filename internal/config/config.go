@@ -2,6 +2,10 @@ package config
 
 import (
 	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
@@ -9,28 +13,131 @@ import (
 
 // Config 表示应用程序配置
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Database DatabaseConfig `mapstructure:"database"`
-	Redis    RedisConfig    `mapstructure:"redis"`
-	DeBank   DeBankConfig   `mapstructure:"debank"`
-	Sync     SyncConfig     `mapstructure:"sync"`
-	Log      LogConfig      `mapstructure:"log"`
+	Server       ServerConfig       `mapstructure:"server"`
+	Database     DatabaseConfig     `mapstructure:"database"`
+	Redis        RedisConfig        `mapstructure:"redis"`
+	DeBank       DeBankConfig       `mapstructure:"debank"`
+	Sync         SyncConfig         `mapstructure:"sync"`
+	Transactions TransactionsConfig `mapstructure:"transactions"`
+	Log          LogConfig          `mapstructure:"log"`
+	TokenRules   TokenRulesConfig   `mapstructure:"token_rules"`
+	Alerts       AlertsConfig       `mapstructure:"alerts"`
+	Notifier     NotifierConfig     `mapstructure:"notifier"`
+	Webhooks     WebhooksConfig     `mapstructure:"webhooks"`
+	Events       EventsConfig       `mapstructure:"events"`
+	Auth         AuthConfig         `mapstructure:"auth"`
+	Audit        AuditConfig        `mapstructure:"audit"`
+	Metrics      MetricsConfig      `mapstructure:"metrics"`
+	Tracing      TracingConfig      `mapstructure:"tracing"`
+	Probes       ProbesConfig       `mapstructure:"probes"`
 }
 
 type ServerConfig struct {
-	Port int    `mapstructure:"port"`
-	Mode string `mapstructure:"mode"`
+	Port            int        `mapstructure:"port"`
+	Mode            string     `mapstructure:"mode"`
+	CORS            CORSConfig `mapstructure:"cors"`
+	ShutdownTimeout int        `mapstructure:"shutdown_timeout"` // 关闭时等待进行中请求完成的最长时间（秒）
+}
+
+// GetShutdownTimeout 以持续时间形式返回关闭超时
+func (c *ServerConfig) GetShutdownTimeout() time.Duration {
+	return time.Duration(c.ShutdownTimeout) * time.Second
+}
+
+// CORSConfig 跨域配置
+type CORSConfig struct {
+	AllowOrigins []string `mapstructure:"allow_origins"` // 允许的来源，为空时不允许跨域访问，["*"] 表示允许所有来源
+	MaxAge       int      `mapstructure:"max_age"`       // 预检请求结果的缓存时间（秒）
+}
+
+// AuthConfig API key 认证配置
+type AuthConfig struct {
+	Enabled       bool `mapstructure:"enabled"`        // 关闭时所有 API 无需认证，必须同时设置 allow_insecure
+	AllowInsecure bool `mapstructure:"allow_insecure"` // 显式允许关闭认证，只用于本地开发
+	SessionTTL    int  `mapstructure:"session_ttl"`    // 登录会话有效期（小时）
+}
+
+// AuditConfig 审计日志配置
+type AuditConfig struct {
+	Enabled         bool `mapstructure:"enabled"`          // 是否记录修改类 API 请求
+	RetentionDays   int  `mapstructure:"retention_days"`   // 保留天数，0 表示永久保留
+	CleanupInterval int  `mapstructure:"cleanup_interval"` // 清理过期记录的间隔（小时）
+}
+
+// MetricsConfig Prometheus 指标配置
+type MetricsConfig struct {
+	Enabled bool   `mapstructure:"enabled"` // 是否暴露指标端点
+	Path    string `mapstructure:"path"`    // 指标端点路径；启用认证时需要 read_only 及以上的 API key
+}
+
+// 链路追踪导出方式
+const (
+	TracingExporterOTLP   = "otlp"
+	TracingExporterStdout = "stdout"
+)
+
+// TracingConfig OpenTelemetry 链路追踪配置
+type TracingConfig struct {
+	Enabled     bool              `mapstructure:"enabled"`
+	Exporter    string            `mapstructure:"exporter"`     // otlp（OTLP/HTTP）或 stdout（本地调试）
+	Endpoint    string            `mapstructure:"endpoint"`     // OTLP 接收端 host:port，为空时使用 OTEL_EXPORTER_OTLP_ENDPOINT 或 localhost:4318
+	Insecure    bool              `mapstructure:"insecure"`     // 使用 HTTP 而不是 HTTPS 连接接收端
+	Headers     map[string]string `mapstructure:"headers"`      // 发送给接收端的额外请求头，如认证 token
+	ServiceName string            `mapstructure:"service_name"` // 上报的 service.name
+	SampleRatio float64           `mapstructure:"sample_ratio"` // 根 span 采样比例（0-1），子 span 跟随父 span
+}
+
+// ProbesConfig /livez 和 /readyz 探针配置
+type ProbesConfig struct {
+	Timeout               int      `mapstructure:"timeout"`                 // 每项检查的超时（秒）
+	ProviderCheckInterval int      `mapstructure:"provider_check_interval"` // 数据提供者检查结果的缓存时间（秒），避免每次探测都请求提供者
+	SyncStaleAfter        int      `mapstructure:"sync_stale_after"`        // 同步循环超过此时间（秒）没有心跳视为卡住
+	Required              []string `mapstructure:"required"`                // 失败时 /readyz 返回 503 的组件，其余组件失败只标记为 degraded
+}
+
+// GetTimeout 以持续时间形式返回每项检查的超时
+func (c *ProbesConfig) GetTimeout() time.Duration {
+	return time.Duration(c.Timeout) * time.Second
+}
+
+// GetProviderCheckInterval 以持续时间形式返回提供者检查结果的缓存时间
+func (c *ProbesConfig) GetProviderCheckInterval() time.Duration {
+	return time.Duration(c.ProviderCheckInterval) * time.Second
+}
+
+// GetSyncStaleAfter 以持续时间形式返回同步心跳过期时间
+func (c *ProbesConfig) GetSyncStaleAfter() time.Duration {
+	return time.Duration(c.SyncStaleAfter) * time.Second
+}
+
+// GetRetention 以持续时间形式返回保留时长，0 表示永久保留
+func (c *AuditConfig) GetRetention() time.Duration {
+	return time.Duration(c.RetentionDays) * 24 * time.Hour
+}
+
+// GetCleanupInterval 以持续时间形式返回清理间隔
+func (c *AuditConfig) GetCleanupInterval() time.Duration {
+	return time.Duration(c.CleanupInterval) * time.Hour
+}
+
+// GetSessionTTL 以持续时间形式返回会话有效期
+func (c *AuthConfig) GetSessionTTL() time.Duration {
+	return time.Duration(c.SessionTTL) * time.Hour
 }
 
 type DatabaseConfig struct {
+	Driver       string `mapstructure:"driver"` // mysql、postgres 或 sqlite
+	Path         string `mapstructure:"path"`   // SQLite 数据库文件路径，":memory:" 表示内存数据库
 	Host         string `mapstructure:"host"`
 	Port         int    `mapstructure:"port"`
 	Username     string `mapstructure:"username"`
 	Password     string `mapstructure:"password"`
 	Database     string `mapstructure:"database"`
 	Charset      string `mapstructure:"charset"`
+	SSLMode      string `mapstructure:"sslmode"` // 仅 PostgreSQL：disable、require、verify-full 等
 	MaxIdleConns int    `mapstructure:"max_idle_conns"`
 	MaxOpenConns int    `mapstructure:"max_open_conns"`
+	AutoMigrate  bool   `mapstructure:"auto_migrate"` // 启动时自动应用待执行迁移
 }
 
 type RedisConfig struct {
@@ -55,9 +162,34 @@ type RateLimitConfig struct {
 }
 
 type SyncConfig struct {
-	Enabled   bool `mapstructure:"enabled"`
-	Interval  int  `mapstructure:"interval"`
-	BatchSize int  `mapstructure:"batch_size"`
+	Enabled      bool `mapstructure:"enabled"`
+	Interval     int  `mapstructure:"interval"`
+	BatchSize    int  `mapstructure:"batch_size"`
+	DrainTimeout int  `mapstructure:"drain_timeout"` // 关闭时等待进行中同步完成的最长时间（秒），超时后取消
+}
+
+// 交易历史数据源
+const (
+	TransactionSourceDeBank = "debank"
+	TransactionSourceRPC    = "rpc"
+)
+
+// TransactionsConfig 交易历史同步配置
+type TransactionsConfig struct {
+	Enabled      bool   `mapstructure:"enabled"`       // 是否定期同步交易历史（DeBank 每次请求都消耗额度）
+	Source       string `mapstructure:"source"`        // debank（history_list）或 rpc（通过已配置的 RPC 节点扫描 ERC-20 Transfer 日志）
+	Interval     int    `mapstructure:"interval"`      // 同步间隔（秒）
+	BackfillDays int    `mapstructure:"backfill_days"` // 首次同步回溯的天数
+}
+
+// GetInterval 以持续时间形式返回交易同步间隔
+func (c *TransactionsConfig) GetInterval() time.Duration {
+	return time.Duration(c.Interval) * time.Second
+}
+
+// GetBackfill 以持续时间形式返回首次同步的回溯时长
+func (c *TransactionsConfig) GetBackfill() time.Duration {
+	return time.Duration(c.BackfillDays) * 24 * time.Hour
 }
 
 type LogConfig struct {
@@ -66,41 +198,233 @@ type LogConfig struct {
 	FilePath string `mapstructure:"file_path"`
 }
 
-// LoadConfig 从文件加载配置
+// TokenRulesConfig 代币分类规则配置
+type TokenRulesConfig struct {
+	UseDefaults bool              `mapstructure:"use_defaults"` // 是否启用内置的垃圾/凭证代币规则
+	Rules       []TokenRuleConfig `mapstructure:"rules"`
+}
+
+// TokenRuleConfig 配置文件中的一条分类规则，字段含义与 models.TokenRule 相同
+type TokenRuleConfig struct {
+	Name           string   `mapstructure:"name"`
+	Classification string   `mapstructure:"classification"`
+	Priority       int      `mapstructure:"priority"`
+	ChainID        string   `mapstructure:"chain_id"`
+	SymbolPattern  string   `mapstructure:"symbol_pattern"`
+	NamePattern    string   `mapstructure:"name_pattern"`
+	TextPattern    string   `mapstructure:"text_pattern"`
+	TokenIDs       []string `mapstructure:"token_ids"`
+	MaxPrice       *float64 `mapstructure:"max_price"`
+	MaxUSDValue    *float64 `mapstructure:"max_usd_value"`
+	IsVerified     *bool    `mapstructure:"is_verified"`
+	IsCore         *bool    `mapstructure:"is_core"`
+}
+
+// AlertsConfig 告警默认值：健康因子阈值可被 health_thresholds 表中的设置覆盖，冷却时间可被规则覆盖
+type AlertsConfig struct {
+	HealthEnabled     bool    `mapstructure:"health_enabled"`
+	HealthThreshold   float64 `mapstructure:"health_threshold"`    // 健康因子跌破该值时告警
+	HealthDropPercent float64 `mapstructure:"health_drop_percent"` // 两次同步间下降超过该百分比时告警，0 表示不检查
+	DefaultCooldown   int     `mapstructure:"default_cooldown"`    // 余额告警规则未设置冷却时间时使用的默认值（分钟）
+}
+
+// NotifierConfig 告警通知渠道配置
+type NotifierConfig struct {
+	Channels []string              `mapstructure:"channels"` // stdout、webhook、smtp
+	Webhook  WebhookNotifierConfig `mapstructure:"webhook"`
+	SMTP     SMTPNotifierConfig    `mapstructure:"smtp"`
+}
+
+// WebhookNotifierConfig 以 JSON POST 发送通知
+type WebhookNotifierConfig struct {
+	URL     string            `mapstructure:"url"`
+	Headers map[string]string `mapstructure:"headers"`
+	Timeout int               `mapstructure:"timeout"`
+}
+
+// SMTPNotifierConfig 通过邮件发送通知
+type SMTPNotifierConfig struct {
+	Host     string   `mapstructure:"host"`
+	Port     int      `mapstructure:"port"`
+	Username string   `mapstructure:"username"`
+	Password string   `mapstructure:"password"`
+	From     string   `mapstructure:"from"`
+	To       []string `mapstructure:"to"`
+}
+
+// WebhooksConfig 出站 webhook 投递配置
+type WebhooksConfig struct {
+	Enabled            bool    `mapstructure:"enabled"`              // 是否启动投递进程，关闭时事件仍会排队
+	PollInterval       int     `mapstructure:"poll_interval"`        // 检查待投递事件的间隔（秒）
+	MaxAttempts        int     `mapstructure:"max_attempts"`         // 超过该次数后移入死信列表
+	RetryBaseDelay     int     `mapstructure:"retry_base_delay"`     // 第一次重试的等待时间（秒），之后指数增长
+	Timeout            int     `mapstructure:"timeout"`              // 单次请求超时（秒）
+	ValueChangePercent float64 `mapstructure:"value_change_percent"` // 端点未设置时 wallet.value_changed 的默认阈值
+}
+
+// GetPollInterval 以持续时间形式返回检查间隔
+func (c *WebhooksConfig) GetPollInterval() time.Duration {
+	return time.Duration(c.PollInterval) * time.Second
+}
+
+// GetRetryBaseDelay 以持续时间形式返回重试基础等待时间
+func (c *WebhooksConfig) GetRetryBaseDelay() time.Duration {
+	return time.Duration(c.RetryBaseDelay) * time.Second
+}
+
+// GetTimeout 以持续时间形式返回请求超时
+func (c *WebhooksConfig) GetTimeout() time.Duration {
+	return time.Duration(c.Timeout) * time.Second
+}
+
+// EventsConfig 实时事件流（/api/v1/events）配置
+type EventsConfig struct {
+	BufferSize        int `mapstructure:"buffer_size"`        // 每个订阅者的事件缓冲区大小，缓冲区满时丢弃新事件
+	HeartbeatInterval int `mapstructure:"heartbeat_interval"` // 心跳间隔（秒），防止代理关闭空闲连接
+}
+
+// GetHeartbeatInterval 以持续时间形式返回心跳间隔
+func (c *EventsConfig) GetHeartbeatInterval() time.Duration {
+	return time.Duration(c.HeartbeatInterval) * time.Second
+}
+
+// DefaultConfigPath 是未指定 --config 且未设置 ROTKI_CONFIG 时使用的配置文件
+const DefaultConfigPath = "config.yaml"
+
+// DefaultPath 返回未指定 --config 时的配置文件路径：优先 ROTKI_CONFIG，其次 DefaultConfigPath
+func DefaultPath() string {
+	if path := os.Getenv(EnvPrefix + "_CONFIG"); path != "" {
+		return path
+	}
+	return DefaultConfigPath
+}
+
+// LoadConfig 从文件加载配置，环境变量（ROTKI_ 前缀，如 ROTKI_DEBANK_API_KEY）和 <环境变量名>_FILE 指向的文件优先于配置文件，
+// 加载后校验所有配置项
 func LoadConfig(configPath string) (*Config, error) {
 	viper.SetConfigFile(configPath)
 	viper.SetConfigType("yaml")
+	viper.SetEnvPrefix(EnvPrefix)
+	viper.SetEnvKeyReplacer(envKeyReplacer)
 	viper.AutomaticEnv()
 
+	keys := configKeys(reflect.TypeOf(Config{}), "")
+	if err := bindEnvs(viper.GetViper(), keys); err != nil {
+		return nil, err
+	}
+
 	// 设置默认值
 	viper.SetDefault("server.port", 8080)
 	viper.SetDefault("server.mode", "debug")
+	viper.SetDefault("server.shutdown_timeout", 15)
+	viper.SetDefault("server.cors.allow_origins", []string{})
+	viper.SetDefault("server.cors.max_age", 43200)
+	viper.SetDefault("database.driver", "mysql")
+	viper.SetDefault("database.path", "rotki.db")
 	viper.SetDefault("database.charset", "utf8mb4")
+	viper.SetDefault("database.sslmode", "disable")
 	viper.SetDefault("database.max_idle_conns", 10)
 	viper.SetDefault("database.max_open_conns", 100)
+	viper.SetDefault("database.auto_migrate", true)
 	viper.SetDefault("redis.cache_ttl", 300)
+	viper.SetDefault("debank.base_url", "https://pro-openapi.debank.com")
+	viper.SetDefault("debank.rate_limit.requests_per_second", 5)
+	viper.SetDefault("debank.rate_limit.burst", 10)
 	viper.SetDefault("debank.cache_ttl", 60)
 	viper.SetDefault("debank.timeout", 30)
 	viper.SetDefault("sync.enabled", true)
 	viper.SetDefault("sync.interval", 300)
 	viper.SetDefault("sync.batch_size", 10)
+	viper.SetDefault("sync.drain_timeout", 30)
+	viper.SetDefault("transactions.enabled", false)
+	viper.SetDefault("transactions.source", TransactionSourceDeBank)
+	viper.SetDefault("transactions.interval", 1800)
+	viper.SetDefault("transactions.backfill_days", 90)
 	viper.SetDefault("log.level", "info")
 	viper.SetDefault("log.output", "stdout")
+	viper.SetDefault("token_rules.use_defaults", true)
+	viper.SetDefault("alerts.health_enabled", true)
+	viper.SetDefault("alerts.health_threshold", 1.2)
+	viper.SetDefault("alerts.health_drop_percent", 10)
+	viper.SetDefault("alerts.default_cooldown", 60)
+	viper.SetDefault("notifier.channels", []string{"stdout"})
+	viper.SetDefault("notifier.webhook.timeout", 10)
+	viper.SetDefault("notifier.smtp.port", 587)
+	viper.SetDefault("webhooks.enabled", true)
+	viper.SetDefault("webhooks.poll_interval", 5)
+	viper.SetDefault("webhooks.max_attempts", 8)
+	viper.SetDefault("webhooks.retry_base_delay", 30)
+	viper.SetDefault("webhooks.timeout", 10)
+	viper.SetDefault("webhooks.value_change_percent", 5)
+	viper.SetDefault("events.buffer_size", 64)
+	viper.SetDefault("events.heartbeat_interval", 15)
+	viper.SetDefault("auth.enabled", true)
+	viper.SetDefault("auth.allow_insecure", false)
+	viper.SetDefault("auth.session_ttl", 168)
+	viper.SetDefault("audit.enabled", true)
+	viper.SetDefault("audit.retention_days", 90)
+	viper.SetDefault("audit.cleanup_interval", 24)
+	viper.SetDefault("metrics.enabled", true)
+	viper.SetDefault("metrics.path", "/metrics")
+	viper.SetDefault("tracing.enabled", false)
+	viper.SetDefault("tracing.exporter", TracingExporterOTLP)
+	viper.SetDefault("tracing.insecure", true)
+	viper.SetDefault("tracing.service_name", "rotki-demo")
+	viper.SetDefault("tracing.sample_ratio", 1.0)
+	viper.SetDefault("probes.timeout", 3)
+	viper.SetDefault("probes.provider_check_interval", 60)
+	viper.SetDefault("probes.sync_stale_after", 900)
+	viper.SetDefault("probes.required", []string{"database", "migrations"})
+
+	return read(keys)
+}
 
+// read 读取配置文件，应用 _FILE 密钥文件，解析并校验配置
+func read(keys []string) (*Config, error) {
 	if err := viper.ReadInConfig(); err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
+	if err := applySecretFiles(viper.GetViper(), keys); err != nil {
+		return nil, err
+	}
 
 	var config Config
 	if err := viper.Unmarshal(&config); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
 
 	return &config, nil
 }
 
+// 支持的数据库驱动
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
 // GetDSN 返回数据库连接字符串
 func (c *DatabaseConfig) GetDSN() string {
+	if c.Driver == DriverSQLite {
+		// 启用外键（级联删除依赖它），WAL 和忙等待减少并发同步时的锁冲突
+		return c.Path + "?_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)"
+	}
+
+	if c.Driver == DriverPostgres {
+		// 使用 URL 形式以正确转义密码中的特殊字符
+		u := url.URL{
+			Scheme:   "postgres",
+			User:     url.UserPassword(c.Username, c.Password),
+			Host:     fmt.Sprintf("%s:%d", c.Host, c.Port),
+			Path:     "/" + c.Database,
+			RawQuery: "sslmode=" + url.QueryEscape(c.SSLMode),
+		}
+		return u.String()
+	}
+
 	return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=%s&parseTime=True&loc=Local",
 		c.Username,
 		c.Password,
@@ -111,6 +435,11 @@ func (c *DatabaseConfig) GetDSN() string {
 	)
 }
 
+// IsInMemory 判断是否为 SQLite 内存数据库
+func (c *DatabaseConfig) IsInMemory() bool {
+	return c.Driver == DriverSQLite && (c.Path == ":memory:" || strings.Contains(c.Path, "mode=memory"))
+}
+
 // GetAddr 返回 Redis 地址
 func (c *RedisConfig) GetAddr() string {
 	return fmt.Sprintf("%s:%d", c.Host, c.Port)
@@ -126,7 +455,17 @@ func (c *DeBankConfig) GetTimeout() time.Duration {
 	return time.Duration(c.Timeout) * time.Second
 }
 
+// GetTimeout 以持续时间形式返回 webhook 超时
+func (c *WebhookNotifierConfig) GetTimeout() time.Duration {
+	return time.Duration(c.Timeout) * time.Second
+}
+
 // GetSyncInterval 以持续时间形式返回同步间隔
 func (c *SyncConfig) GetSyncInterval() time.Duration {
 	return time.Duration(c.Interval) * time.Second
 }
+
+// GetDrainTimeout 以持续时间形式返回关闭时等待同步完成的超时
+func (c *SyncConfig) GetDrainTimeout() time.Duration {
+	return time.Duration(c.DrainTimeout) * time.Second
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// minimalConfig 是通过校验所需的最少配置，其他键使用默认值
+const minimalConfig = `
+database:
+  driver: sqlite
+  path: test.db
+debank:
+  api_key: test-key
+`
+
+// loadTestConfig 把 content 写入临时配置文件并加载；LoadConfig 使用全局 viper，每次加载前重置
+func loadTestConfig(t *testing.T, content string) (*Config, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	return LoadConfig(path)
+}
+
+// mustLoadTestConfig 加载必须有效的配置
+func mustLoadTestConfig(t *testing.T, content string) *Config {
+	t.Helper()
+	cfg, err := loadTestConfig(t, content)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	return cfg
+}
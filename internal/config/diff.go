@@ -0,0 +1,33 @@
+package config
+
+import "reflect"
+
+// Diff 返回两份配置中取值不同的配置键（如 sync.interval），切片和 map 作为一个键比较
+func Diff(a, b *Config) []string {
+	return diffStruct(reflect.ValueOf(*a), reflect.ValueOf(*b), "")
+}
+
+// diffStruct 按 mapstructure 标签逐字段比较结构体
+func diffStruct(a, b reflect.Value, prefix string) []string {
+	var keys []string
+	for i := 0; i < a.NumField(); i++ {
+		field := a.Type().Field(i)
+		tag := field.Tag.Get("mapstructure")
+		if tag == "" || tag == "-" {
+			continue
+		}
+
+		key := tag
+		if prefix != "" {
+			key = prefix + "." + tag
+		}
+		if field.Type.Kind() == reflect.Struct {
+			keys = append(keys, diffStruct(a.Field(i), b.Field(i), key)...)
+			continue
+		}
+		if !reflect.DeepEqual(a.Field(i).Interface(), b.Field(i).Interface()) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	base := mustLoadTestConfig(t, minimalConfig)
+
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		want   []string
+	}{
+		{
+			name:   "unchanged",
+			modify: func(c *Config) {},
+		},
+		{
+			name: "nested keys",
+			modify: func(c *Config) {
+				c.Sync.Interval++
+				c.DeBank.RateLimit.Burst++
+			},
+			want: []string{"debank.rate_limit.burst", "sync.interval"},
+		},
+		{
+			name: "slice compared as one key",
+			modify: func(c *Config) {
+				c.TokenRules.Rules = []TokenRuleConfig{{Classification: "spam", SymbolPattern: "x"}}
+				c.Notifier.Channels = append([]string{}, "stdout", "webhook")
+			},
+			want: []string{"token_rules.rules", "notifier.channels"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := *base
+			tt.modify(&next)
+			got := Diff(base, &next)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Diff() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
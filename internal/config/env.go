@@ -0,0 +1,80 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// EnvPrefix 是环境变量覆盖配置时使用的前缀，如 ROTKI_DEBANK_API_KEY 覆盖 debank.api_key
+const EnvPrefix = "ROTKI"
+
+// SecretFileSuffix 是从文件读取配置值的环境变量后缀，如 ROTKI_DATABASE_PASSWORD_FILE=/run/secrets/db_password
+const SecretFileSuffix = "_FILE"
+
+// envKeyReplacer 将配置键中的 . 映射为环境变量中的 _
+var envKeyReplacer = strings.NewReplacer(".", "_")
+
+// EnvName 返回配置键对应的环境变量名
+func EnvName(key string) string {
+	return EnvPrefix + "_" + strings.ToUpper(envKeyReplacer.Replace(key))
+}
+
+// configKeys 返回配置结构体中所有叶子配置键（如 debank.api_key），嵌套结构体展开，切片和 map 作为一个键
+func configKeys(t reflect.Type, prefix string) []string {
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("mapstructure")
+		if tag == "" || tag == "-" {
+			continue
+		}
+
+		key := tag
+		if prefix != "" {
+			key = prefix + "." + tag
+		}
+		if field.Type.Kind() == reflect.Struct {
+			keys = append(keys, configKeys(field.Type, key)...)
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// bindEnvs 为所有配置键绑定环境变量
+// AutomaticEnv 只对配置文件或默认值中出现过的键生效，显式绑定后配置文件中没有的键（如 debank.api_key）也能通过环境变量设置
+func bindEnvs(v *viper.Viper, keys []string) error {
+	for _, key := range keys {
+		if err := v.BindEnv(key); err != nil {
+			return fmt.Errorf("failed to bind env for %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// applySecretFiles 对设置了 <环境变量名>_FILE 的配置键，从文件读取值（去掉末尾换行），
+// 用于 Docker/Kubernetes secrets，避免把密码和 API key 直接放在环境变量或配置文件中
+func applySecretFiles(v *viper.Viper, keys []string) error {
+	for _, key := range keys {
+		name := EnvName(key)
+		path, ok := os.LookupEnv(name + SecretFileSuffix)
+		if !ok || path == "" {
+			continue
+		}
+		if _, set := os.LookupEnv(name); set {
+			return fmt.Errorf("both %s and %s%s are set", name, name, SecretFileSuffix)
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s%s: %w", name, SecretFileSuffix, err)
+		}
+		v.Set(key, strings.TrimRight(string(content), "\r\n"))
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigEnvOverrides(t *testing.T) {
+	const file = `
+database:
+  driver: sqlite
+  path: test.db
+sync:
+  batch_size: 5
+`
+	writeSecret := func(t *testing.T, content string) string {
+		path := filepath.Join(t.TempDir(), "secret")
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("write secret: %v", err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name    string
+		env     func(t *testing.T) map[string]string
+		check   func(t *testing.T, cfg *Config)
+		wantErr string
+	}{
+		{
+			name: "file values and defaults",
+			env:  func(t *testing.T) map[string]string { return nil },
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Sync.BatchSize != 5 || cfg.Sync.Interval != 300 || cfg.DeBank.APIKey != "" {
+					t.Fatalf("batch_size %d, interval %d, api_key %q; want 5, the default 300 and no key", cfg.Sync.BatchSize, cfg.Sync.Interval, cfg.DeBank.APIKey)
+				}
+			},
+		},
+		{
+			name: "env overrides the file and sets keys missing from it",
+			env: func(t *testing.T) map[string]string {
+				return map[string]string{"ROTKI_SYNC_BATCH_SIZE": "7", "ROTKI_DEBANK_API_KEY": "from-env"}
+			},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Sync.BatchSize != 7 || cfg.DeBank.APIKey != "from-env" {
+					t.Fatalf("batch_size %d, api_key %q; want 7 and from-env", cfg.Sync.BatchSize, cfg.DeBank.APIKey)
+				}
+			},
+		},
+		{
+			name: "_FILE reads the value without the trailing newline",
+			env: func(t *testing.T) map[string]string {
+				return map[string]string{"ROTKI_DEBANK_API_KEY_FILE": writeSecret(t, "from-file\n")}
+			},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.DeBank.APIKey != "from-file" {
+					t.Fatalf("api_key = %q, want from-file", cfg.DeBank.APIKey)
+				}
+			},
+		},
+		{
+			name: "env and _FILE together are rejected",
+			env: func(t *testing.T) map[string]string {
+				return map[string]string{"ROTKI_DEBANK_API_KEY": "from-env", "ROTKI_DEBANK_API_KEY_FILE": writeSecret(t, "from-file")}
+			},
+			wantErr: "both ROTKI_DEBANK_API_KEY and ROTKI_DEBANK_API_KEY_FILE are set",
+		},
+		{
+			name: "missing _FILE",
+			env: func(t *testing.T) map[string]string {
+				return map[string]string{"ROTKI_DATABASE_PASSWORD_FILE": filepath.Join(t.TempDir(), "missing")}
+			},
+			wantErr: "failed to read ROTKI_DATABASE_PASSWORD_FILE",
+		},
+		{
+			name: "invalid env value fails validation",
+			env: func(t *testing.T) map[string]string {
+				return map[string]string{"ROTKI_SYNC_BATCH_SIZE": "0"}
+			},
+			wantErr: "sync.batch_size: must be greater than 0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env(t) {
+				t.Setenv(name, value)
+			}
+			cfg, err := loadTestConfig(t, file)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadConfig error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadConfig: %v", err)
+			}
+			tt.check(t, cfg)
+		})
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	if got := EnvName("debank.rate_limit.burst"); got != "ROTKI_DEBANK_RATE_LIMIT_BURST" {
+		t.Fatalf("EnvName() = %s, want ROTKI_DEBANK_RATE_LIMIT_BURST", got)
+	}
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"net/url"
+	"reflect"
+	"slices"
+)
+
+// RedactedValue 替换敏感配置值
+const RedactedValue = "[REDACTED]"
+
+// secretKeys 是值需要隐藏的配置键；map 类型的键（请求头）隐藏每个值
+var secretKeys = []string{
+	"database.password",
+	"redis.password",
+	"debank.api_key",
+	"notifier.webhook.headers",
+	"notifier.smtp.password",
+	"tracing.headers",
+}
+
+// secretURLKeys 是可能在用户信息、路径或查询参数中包含令牌的地址（如 Slack webhook），只保留协议和主机
+var secretURLKeys = []string{
+	"notifier.webhook.url",
+}
+
+// Redacted 返回以配置文件键名组织的配置，密码、API key 和请求头的值被替换为 RedactedValue（为空时保持为空）
+func (c *Config) Redacted() map[string]interface{} {
+	return redactStruct(reflect.ValueOf(*c), "")
+}
+
+// redactStruct 按 mapstructure 标签把结构体转换为 map，并隐藏敏感键
+func redactStruct(value reflect.Value, prefix string) map[string]interface{} {
+	result := make(map[string]interface{}, value.NumField())
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		tag := field.Tag.Get("mapstructure")
+		if tag == "" || tag == "-" {
+			continue
+		}
+
+		key := tag
+		if prefix != "" {
+			key = prefix + "." + tag
+		}
+		fieldValue := value.Field(i)
+
+		switch {
+		case field.Type.Kind() == reflect.Struct:
+			result[tag] = redactStruct(fieldValue, key)
+		case slices.Contains(secretKeys, key):
+			result[tag] = redactValue(fieldValue)
+		case slices.Contains(secretURLKeys, key):
+			result[tag] = RedactURL(fieldValue.String())
+		case field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.Struct:
+			items := make([]map[string]interface{}, fieldValue.Len())
+			for j := range items {
+				items[j] = redactStruct(fieldValue.Index(j), key)
+			}
+			result[tag] = items
+		default:
+			result[tag] = fieldValue.Interface()
+		}
+	}
+	return result
+}
+
+// redactValue 隐藏字符串或 map 中每个值
+func redactValue(value reflect.Value) interface{} {
+	if value.Kind() == reflect.Map {
+		redacted := make(map[string]string, value.Len())
+		iter := value.MapRange()
+		for iter.Next() {
+			redacted[iter.Key().String()] = RedactedValue
+		}
+		return redacted
+	}
+	if value.IsZero() {
+		return value.Interface()
+	}
+	return RedactedValue
+}
+
+// RedactURL 去掉地址中的用户信息、路径、查询参数和片段，这些部分可能包含 API key；
+// 有被去掉的部分时追加 /[REDACTED]，无法解析的非空地址整体隐藏
+func RedactURL(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return RedactedValue
+	}
+	redacted := u.Scheme + "://" + u.Host
+	if u.User != nil || (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" {
+		redacted += "/" + RedactedValue
+	}
+	return redacted
+}
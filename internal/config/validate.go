@@ -0,0 +1,211 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// 可选值列表，与使用这些配置的包保持一致
+var (
+	validServerModes     = []string{"debug", "release", "test"}
+	validDrivers         = []string{DriverMySQL, DriverPostgres, DriverSQLite}
+	validLogLevels       = []string{"debug", "info", "warn", "error"}
+	validLogOutputs      = []string{"stdout", "file"}
+	validNotifierChannel = []string{"stdout", "webhook", "smtp"}
+	validExporters       = []string{TracingExporterOTLP, TracingExporterStdout}
+	validProbeComponents = []string{"database", "migrations", "provider", "sync", "rpc_nodes"}
+	validTxSources       = []string{TransactionSourceDeBank, TransactionSourceRPC}
+	validClassifications = []string{"normal", "spam", "receipt", "hidden"}
+)
+
+// exampleAPIKey 是 config.yaml.example 中的 API key 占位符
+const exampleAPIKey = "YOUR_DEBANK_API_KEY_HERE"
+
+// validator 收集配置错误，使启动时一次报告所有问题
+type validator struct {
+	errs []error
+}
+
+// check 在条件不满足时记录错误，key 为配置键
+func (v *validator) check(ok bool, key, format string, args ...interface{}) {
+	if !ok {
+		v.errs = append(v.errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+	}
+}
+
+// positive 检查数值大于 0
+func (v *validator) positive(key string, value int) {
+	v.check(value > 0, key, "must be greater than 0, got %d", value)
+}
+
+// nonNegative 检查数值不小于 0
+func (v *validator) nonNegative(key string, value int) {
+	v.check(value >= 0, key, "must not be negative, got %d", value)
+}
+
+// port 检查端口号范围
+func (v *validator) port(key string, value int) {
+	v.check(value > 0 && value <= 65535, key, "must be a port between 1 and 65535, got %d", value)
+}
+
+// oneOf 检查取值在可选值列表中
+func (v *validator) oneOf(key, value string, allowed []string) {
+	v.check(slices.Contains(allowed, value), key, "must be one of %s, got %q", strings.Join(allowed, ", "), value)
+}
+
+// required 检查字符串非空
+func (v *validator) required(key, value string) {
+	v.check(strings.TrimSpace(value) != "", key, "is required (set it in the config file, %s or %s%s)", EnvName(key), EnvName(key), SecretFileSuffix)
+}
+
+// httpURL 检查是 http 或 https 地址
+func (v *validator) httpURL(key, value string) {
+	u, err := url.Parse(value)
+	v.check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "", key, "must be an http(s) URL, got %q", value)
+}
+
+// pattern 检查非空的正则表达式可以编译
+func (v *validator) pattern(key, value string) {
+	if value == "" {
+		return
+	}
+	_, err := regexp.Compile(value)
+	v.check(err == nil, key, "is not a valid regular expression: %v", err)
+}
+
+// Validate 检查配置取值，返回包含所有问题的错误（每行一个），而不是在运行中途失败
+func (c *Config) Validate() error {
+	v := &validator{}
+
+	// 服务器
+	v.port("server.port", c.Server.Port)
+	v.oneOf("server.mode", c.Server.Mode, validServerModes)
+	v.nonNegative("server.shutdown_timeout", c.Server.ShutdownTimeout)
+	v.nonNegative("server.cors.max_age", c.Server.CORS.MaxAge)
+
+	// 数据库
+	v.oneOf("database.driver", c.Database.Driver, validDrivers)
+	switch c.Database.Driver {
+	case DriverSQLite:
+		v.required("database.path", c.Database.Path)
+	case DriverMySQL, DriverPostgres:
+		v.required("database.host", c.Database.Host)
+		v.port("database.port", c.Database.Port)
+		v.required("database.username", c.Database.Username)
+		v.required("database.database", c.Database.Database)
+	}
+	v.nonNegative("database.max_idle_conns", c.Database.MaxIdleConns)
+	v.nonNegative("database.max_open_conns", c.Database.MaxOpenConns)
+
+	// DeBank；API key 由 ValidateProvider 检查
+	v.httpURL("debank.base_url", c.DeBank.BaseURL)
+	v.positive("debank.rate_limit.requests_per_second", c.DeBank.RateLimit.RequestsPerSecond)
+	v.positive("debank.rate_limit.burst", c.DeBank.RateLimit.Burst)
+	v.positive("debank.timeout", c.DeBank.Timeout)
+
+	// 同步
+	v.positive("sync.interval", c.Sync.Interval)
+	v.positive("sync.batch_size", c.Sync.BatchSize)
+	v.nonNegative("sync.drain_timeout", c.Sync.DrainTimeout)
+	v.oneOf("transactions.source", c.Transactions.Source, validTxSources)
+	v.positive("transactions.interval", c.Transactions.Interval)
+	v.positive("transactions.backfill_days", c.Transactions.BackfillDays)
+
+	// 日志
+	v.oneOf("log.level", c.Log.Level, validLogLevels)
+	v.oneOf("log.output", c.Log.Output, validLogOutputs)
+	if c.Log.Output == "file" {
+		v.required("log.file_path", c.Log.FilePath)
+	}
+
+	// 代币分类规则：无效的规则使整个文件被拒绝，而不是在加载时被跳过
+	for i, rule := range c.TokenRules.Rules {
+		key := fmt.Sprintf("token_rules.rules[%d]", i)
+		v.oneOf(key+".classification", rule.Classification, validClassifications)
+		v.pattern(key+".symbol_pattern", rule.SymbolPattern)
+		v.pattern(key+".name_pattern", rule.NamePattern)
+		v.pattern(key+".text_pattern", rule.TextPattern)
+		v.check(rule.ChainID != "" || rule.SymbolPattern != "" || rule.NamePattern != "" || rule.TextPattern != "" ||
+			len(rule.TokenIDs) > 0 || rule.MaxPrice != nil || rule.MaxUSDValue != nil || rule.IsVerified != nil || rule.IsCore != nil,
+			key, "must have at least one condition")
+	}
+
+	// 告警和通知
+	v.check(c.Alerts.HealthThreshold > 0, "alerts.health_threshold", "must be greater than 0, got %g", c.Alerts.HealthThreshold)
+	v.check(c.Alerts.HealthDropPercent >= 0 && c.Alerts.HealthDropPercent <= 100, "alerts.health_drop_percent", "must be between 0 and 100, got %g", c.Alerts.HealthDropPercent)
+	v.nonNegative("alerts.default_cooldown", c.Alerts.DefaultCooldown)
+	channels := make([]string, 0, len(c.Notifier.Channels))
+	for _, channel := range c.Notifier.Channels {
+		channel = strings.ToLower(strings.TrimSpace(channel))
+		v.oneOf("notifier.channels", channel, validNotifierChannel)
+		channels = append(channels, channel)
+	}
+	if slices.Contains(channels, "webhook") {
+		v.httpURL("notifier.webhook.url", c.Notifier.Webhook.URL)
+	}
+	if slices.Contains(channels, "smtp") {
+		v.required("notifier.smtp.host", c.Notifier.SMTP.Host)
+		v.port("notifier.smtp.port", c.Notifier.SMTP.Port)
+		v.required("notifier.smtp.from", c.Notifier.SMTP.From)
+		v.check(len(c.Notifier.SMTP.To) > 0, "notifier.smtp.to", "at least one recipient is required")
+	}
+
+	// 出站 webhook 和事件流
+	v.positive("webhooks.poll_interval", c.Webhooks.PollInterval)
+	v.positive("webhooks.max_attempts", c.Webhooks.MaxAttempts)
+	v.nonNegative("webhooks.retry_base_delay", c.Webhooks.RetryBaseDelay)
+	v.positive("webhooks.timeout", c.Webhooks.Timeout)
+	v.positive("events.buffer_size", c.Events.BufferSize)
+	v.positive("events.heartbeat_interval", c.Events.HeartbeatInterval)
+
+	// 认证和审计；关闭认证必须显式确认，避免漏写 auth 配置时服务完全开放
+	v.check(c.Auth.Enabled || c.Auth.AllowInsecure, "auth.enabled",
+		"is false; anyone who can reach the server would have full access. Set auth.allow_insecure: true (%s=true) to run without authentication for local development",
+		EnvName("auth.allow_insecure"))
+	v.positive("auth.session_ttl", c.Auth.SessionTTL)
+	v.nonNegative("audit.retention_days", c.Audit.RetentionDays)
+	if c.Audit.RetentionDays > 0 {
+		v.positive("audit.cleanup_interval", c.Audit.CleanupInterval)
+	}
+
+	// 可观测性
+	if c.Metrics.Enabled {
+		v.check(strings.HasPrefix(c.Metrics.Path, "/"), "metrics.path", "must start with /, got %q", c.Metrics.Path)
+	}
+	if c.Tracing.Enabled {
+		v.oneOf("tracing.exporter", c.Tracing.Exporter, validExporters)
+		v.required("tracing.service_name", c.Tracing.ServiceName)
+	}
+	v.check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio", "must be between 0 and 1, got %g", c.Tracing.SampleRatio)
+
+	// 探针
+	v.positive("probes.timeout", c.Probes.Timeout)
+	v.nonNegative("probes.provider_check_interval", c.Probes.ProviderCheckInterval)
+	v.positive("probes.sync_stale_after", c.Probes.SyncStaleAfter)
+	for _, component := range c.Probes.Required {
+		v.oneOf("probes.required", component, validProbeComponents)
+	}
+
+	return v.err()
+}
+
+// ValidateProvider 检查访问 DeBank 所需的配置。只有服务器和调用数据提供者的命令（sync、chains sync provider）需要，
+// 迁移、用户、备份等管理命令在还没有 API key 的新部署上也能运行
+func (c *Config) ValidateProvider() error {
+	v := &validator{}
+	v.required("debank.api_key", c.DeBank.APIKey)
+	v.check(c.DeBank.APIKey != exampleAPIKey, "debank.api_key", "still has the placeholder value from config.yaml.example")
+	return v.err()
+}
+
+// err 返回包含所有问题的错误，没有问题时返回 nil
+func (v *validator) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("invalid configuration:\n%w", errors.Join(v.errs...))
+}
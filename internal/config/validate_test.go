@@ -0,0 +1,163 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(c *Config)
+		wantErr []string
+	}{
+		{name: "defaults", modify: func(c *Config) {}},
+		{
+			name: "all problems reported at once",
+			modify: func(c *Config) {
+				c.Server.Port = 0
+				c.Sync.BatchSize = 0
+				c.Log.Level = "verbose"
+			},
+			wantErr: []string{"server.port", "sync.batch_size", "log.level: must be one of debug, info, warn, error"},
+		},
+		{
+			name:    "mysql requires connection settings",
+			modify:  func(c *Config) { c.Database.Driver = DriverMySQL },
+			wantErr: []string{"database.host", "database.username", "database.database"},
+		},
+		{
+			name:    "webhook channel requires a url",
+			modify:  func(c *Config) { c.Notifier.Channels = []string{" Webhook "} },
+			wantErr: []string{"notifier.webhook.url"},
+		},
+		{
+			name: "auth disabled without opt-in",
+			modify: func(c *Config) {
+				c.Auth.Enabled = false
+				c.Auth.AllowInsecure = false
+			},
+			wantErr: []string{"auth.enabled"},
+		},
+		{
+			name: "auth disabled for local development",
+			modify: func(c *Config) {
+				c.Auth.Enabled = false
+				c.Auth.AllowInsecure = true
+			},
+		},
+		{
+			name:   "api key is not required by Validate",
+			modify: func(c *Config) { c.DeBank.APIKey = "" },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := mustLoadTestConfig(t, minimalConfig)
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Validate() = nil, want errors for %q", tt.wantErr)
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Validate() = %v, want error containing %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateProvider(t *testing.T) {
+	tests := []struct {
+		apiKey  string
+		wantErr string
+	}{
+		{apiKey: "", wantErr: "debank.api_key: is required"},
+		{apiKey: "  ", wantErr: "debank.api_key: is required"},
+		{apiKey: exampleAPIKey, wantErr: "placeholder"},
+		{apiKey: "real-key"},
+	}
+
+	for _, tt := range tests {
+		cfg := &Config{DeBank: DeBankConfig{APIKey: tt.apiKey}}
+		err := cfg.ValidateProvider()
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("ValidateProvider(%q) = %v, want nil", tt.apiKey, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("ValidateProvider(%q) = %v, want error containing %q", tt.apiKey, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateTokenRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    TokenRuleConfig
+		wantErr string
+	}{
+		{
+			name: "valid",
+			rule: TokenRuleConfig{Name: "airdrop", Classification: "spam", SymbolPattern: `(?i)claim`},
+		},
+		{
+			name:    "unknown classification",
+			rule:    TokenRuleConfig{Classification: "junk", SymbolPattern: "x"},
+			wantErr: "token_rules.rules[0].classification",
+		},
+		{
+			name:    "invalid pattern",
+			rule:    TokenRuleConfig{Classification: "spam", NamePattern: "("},
+			wantErr: "token_rules.rules[0].name_pattern",
+		},
+		{
+			name:    "no condition",
+			rule:    TokenRuleConfig{Classification: "hidden"},
+			wantErr: "token_rules.rules[0]: must have at least one condition",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := mustLoadTestConfig(t, minimalConfig)
+			cfg.TokenRules.Rules = []TokenRuleConfig{tt.rule}
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadConfigRejectsInvalidTokenRules(t *testing.T) {
+	// 热加载时同样经过 read，无效的规则使整个文件被拒绝
+	_, err := loadTestConfig(t, minimalConfig+`
+token_rules:
+  rules:
+    - name: broken
+      classification: spam
+      text_pattern: "[a-"
+`)
+	if err == nil || !strings.Contains(err.Error(), "token_rules.rules[0].text_pattern") {
+		t.Fatalf("LoadConfig error = %v, want invalid text_pattern", err)
+	}
+}
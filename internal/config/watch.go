@@ -0,0 +1,36 @@
+package config
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// watchMu 串行化文件变化触发的重新加载，编辑器保存时可能连续触发多个事件
+var watchMu sync.Mutex
+
+// Watch 监听 LoadConfig 加载的配置文件，文件变化时重新读取并校验（环境变量和 _FILE 同样生效）
+// 成功时调用 onChange；文件无法解析或校验失败时调用 onError，调用方应继续使用当前配置
+func Watch(onChange func(*Config), onError func(error)) {
+	keys := configKeys(reflect.TypeOf(Config{}), "")
+
+	viper.OnConfigChange(func(fsnotify.Event) {
+		watchMu.Lock()
+		defer watchMu.Unlock()
+
+		cfg, err := read(keys)
+		if err != nil {
+			onError(err)
+			return
+		}
+		onChange(cfg)
+	})
+	viper.WatchConfig()
+}
+
+// ConfigFile 返回当前使用的配置文件路径
+func ConfigFile() string {
+	return viper.ConfigFileUsed()
+}
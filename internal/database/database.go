@@ -4,13 +4,15 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/glebarez/sqlite"
 	"github.com/rotki-demo/internal/config"
 	"github.com/rotki-demo/internal/logger"
 	"github.com/rotki-demo/internal/models"
+	"github.com/rotki-demo/internal/tracing"
 	"go.uber.org/zap"
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	gormlogger "gorm.io/gorm/logger"
 )
 
 // DB 是全局数据库实例
@@ -18,32 +20,56 @@ var DB *gorm.DB
 
 // InitDatabase 初始化数据库连接
 func InitDatabase(cfg *config.DatabaseConfig) error {
-	dsn := cfg.GetDSN()
-
-	// 配置 GORM 日志记录器
-	logLevel := gormlogger.Info
-	gormLog := gormlogger.New(
-		&gormLoggerAdapter{},
-		gormlogger.Config{
-			SlowThreshold:             200 * time.Millisecond,
-			LogLevel:                  logLevel,
-			IgnoreRecordNotFoundError: true,
-			Colorful:                  false,
-		},
-	)
+	db, err := Open(cfg)
+	if err != nil {
+		return err
+	}
+
+	DB = db
+
+	if cfg.Driver == config.DriverSQLite {
+		logger.Info("Database connected successfully",
+			zap.String("driver", cfg.Driver),
+			zap.String("path", cfg.Path),
+		)
+	} else {
+		logger.Info("Database connected successfully",
+			zap.String("driver", cfg.Driver),
+			zap.String("host", cfg.Host),
+			zap.String("database", cfg.Database),
+		)
+	}
+
+	return nil
+}
+
+// Open 按配置的驱动打开数据库连接，不修改全局实例
+// 测试可以用 Driver: "sqlite", Path: ":memory:" 创建独立的内存数据库
+func Open(cfg *config.DatabaseConfig) (*gorm.DB, error) {
+	dialector, err := newDialector(cfg)
+	if err != nil {
+		return nil, err
+	}
 
-	// 打开数据库连接
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
-		Logger: gormLog,
+	// 打开数据库连接，GORM 日志写入 zap
+	// TranslateError 把各驱动的唯一键冲突统一转换为 gorm.ErrDuplicatedKey
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger:         newGormLogger(200 * time.Millisecond),
+		TranslateError: true,
 	})
 	if err != nil {
-		return fmt.Errorf("failed to connect to database: %w", err)
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	// 注册链路追踪插件，只记录上下文中带有 span 的查询
+	if err := db.Use(tracing.NewGormPlugin()); err != nil {
+		return nil, fmt.Errorf("failed to register tracing plugin: %w", err)
 	}
 
 	// 获取底层 sql.DB
 	sqlDB, err := db.DB()
 	if err != nil {
-		return fmt.Errorf("failed to get database instance: %w", err)
+		return nil, fmt.Errorf("failed to get database instance: %w", err)
 	}
 
 	// 设置连接池设置
@@ -51,14 +77,28 @@ func InitDatabase(cfg *config.DatabaseConfig) error {
 	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
 	sqlDB.SetConnMaxLifetime(time.Hour)
 
-	DB = db
+	// 每个 SQLite 内存连接都是独立的数据库，只能使用单个连接
+	if cfg.IsInMemory() {
+		sqlDB.SetMaxOpenConns(1)
+		sqlDB.SetMaxIdleConns(1)
+		sqlDB.SetConnMaxLifetime(0)
+	}
 
-	logger.Info("Database connected successfully",
-		zap.String("host", cfg.Host),
-		zap.String("database", cfg.Database),
-	)
+	return db, nil
+}
 
-	return nil
+// newDialector 根据驱动名称创建 GORM 方言
+func newDialector(cfg *config.DatabaseConfig) (gorm.Dialector, error) {
+	switch cfg.Driver {
+	case config.DriverMySQL, "":
+		return mysql.Open(cfg.GetDSN()), nil
+	case config.DriverPostgres:
+		return postgres.Open(cfg.GetDSN()), nil
+	case config.DriverSQLite:
+		return sqlite.Open(cfg.GetDSN()), nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver: %s", cfg.Driver)
+	}
 }
 
 // AutoMigrate 运行数据库迁移
@@ -89,10 +129,3 @@ func AutoMigrate() error {
 func GetDB() *gorm.DB {
 	return DB
 }
-
-// gormLoggerAdapter 将 zap 日志记录器适配到 GORM 日志记录器接口
-type gormLoggerAdapter struct{}
-
-func (l *gormLoggerAdapter) Printf(format string, args ...interface{}) {
-	logger.Debug(fmt.Sprintf(format, args...))
-}
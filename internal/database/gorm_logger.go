@@ -0,0 +1,81 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rotki-demo/internal/logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// gormLogger 将 GORM 日志写入 zap，并带上上下文中的请求 ID、同步任务 ID 和 trace ID
+// 失败的查询记录为 error，慢查询记录为 warn，其余查询只在 debug 级别记录
+type gormLogger struct {
+	level         gormlogger.LogLevel
+	slowThreshold time.Duration
+}
+
+// newGormLogger 创建 GORM 日志记录器
+func newGormLogger(slowThreshold time.Duration) gormlogger.Interface {
+	return &gormLogger{
+		level:         gormlogger.Info,
+		slowThreshold: slowThreshold,
+	}
+}
+
+// LogMode 实现 gormlogger.Interface
+func (l *gormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	copied := *l
+	copied.level = level
+	return &copied
+}
+
+// Info 实现 gormlogger.Interface
+func (l *gormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Info {
+		logger.FromContext(ctx).Info(fmt.Sprintf(msg, args...))
+	}
+}
+
+// Warn 实现 gormlogger.Interface
+func (l *gormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Warn {
+		logger.FromContext(ctx).Warn(fmt.Sprintf(msg, args...))
+	}
+}
+
+// Error 实现 gormlogger.Interface
+func (l *gormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Error {
+		logger.FromContext(ctx).Error(fmt.Sprintf(msg, args...))
+	}
+}
+
+// Trace 实现 gormlogger.Interface，记录每条 SQL 的耗时和影响行数
+func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	failed := err != nil && !errors.Is(err, gorm.ErrRecordNotFound)
+	slow := l.slowThreshold > 0 && elapsed > l.slowThreshold
+
+	log := logger.FromContext(ctx)
+	switch {
+	case failed && l.level >= gormlogger.Error:
+		sql, rows := fc()
+		log.Error("Database query failed", zap.String("sql", sql), zap.Int64("rows", rows), zap.Duration("elapsed", elapsed), zap.Error(err))
+	case slow && l.level >= gormlogger.Warn:
+		sql, rows := fc()
+		log.Warn("Slow database query", zap.String("sql", sql), zap.Int64("rows", rows), zap.Duration("elapsed", elapsed), zap.Duration("threshold", l.slowThreshold))
+	case l.level >= gormlogger.Info && log.Core().Enabled(zapcore.DebugLevel):
+		sql, rows := fc()
+		log.Debug("Database query", zap.String("sql", sql), zap.Int64("rows", rows), zap.Duration("elapsed", elapsed))
+	}
+}
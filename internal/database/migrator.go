@@ -0,0 +1,568 @@
+package database
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/rotki-demo/internal/logger"
+	"github.com/rotki-demo/migrations"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// migrationFilePattern 匹配 NNN_description.up.sql / NNN_description.down.sql
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// foreignKeysPragmaPattern 匹配 SQLite 迁移脚本中切换外键检查的语句
+var foreignKeysPragmaPattern = regexp.MustCompile(`(?i)^PRAGMA\s+foreign_keys\s*=\s*(\w+)$`)
+
+// 迁移器支持的方言，与 GORM Dialector.Name() 一致
+const (
+	dialectMySQL    = "mysql"
+	dialectPostgres = "postgres"
+	dialectSQLite   = "sqlite"
+)
+
+// SchemaMigration 记录已应用的迁移
+type SchemaMigration struct {
+	Version   uint      `gorm:"primaryKey;autoIncrement:false" json:"version"`
+	Name      string    `gorm:"type:varchar(255);not null" json:"name"`
+	Checksum  string    `gorm:"type:varchar(64);not null" json:"checksum"`
+	AppliedAt time.Time `gorm:"not null" json:"applied_at"`
+}
+
+// TableName 覆盖表名
+func (SchemaMigration) TableName() string { return "schema_migrations" }
+
+// Migration 表示一个版本化迁移
+type Migration struct {
+	Version  uint
+	Name     string
+	UpSQL    string
+	DownSQL  string
+	Checksum string // up 脚本的 SHA-256
+}
+
+// MigrationStatus 表示迁移的应用状态
+type MigrationStatus struct {
+	Version   uint       `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+	Modified  bool       `json:"modified"` // 已应用但文件校验和已变化
+	Missing   bool       `json:"missing"`  // 已应用但文件已不存在
+}
+
+// Migrator 负责加载和执行版本化迁移
+// PostgreSQL 和 SQLite 支持事务性 DDL，每个迁移脚本和对应的 schema_migrations 记录在同一事务中执行，
+// 失败时整体回滚；MySQL 的 DDL 会隐式提交，脚本中途失败会留下已执行的语句，需要手动处理后重试
+type Migrator struct {
+	db         *gorm.DB
+	dialect    string
+	migrations []Migration
+}
+
+// NewMigrator 从文件系统的指定目录加载迁移
+func NewMigrator(db *gorm.DB, fsys fs.FS, dir string) (*Migrator, error) {
+	migrationList, err := loadMigrations(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, dialect: db.Dialector.Name(), migrations: migrationList}, nil
+}
+
+// NewDefaultMigrator 使用与连接方言匹配的嵌入迁移创建迁移器
+func NewDefaultMigrator(db *gorm.DB) (*Migrator, error) {
+	return NewMigrator(db, migrations.FS, db.Dialector.Name())
+}
+
+// loadMigrations 读取目录中的迁移文件并按版本排序
+func loadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations directory: %w", err)
+	}
+
+	byVersion := make(map[uint]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseUint(match[1], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", entry.Name(), err)
+		}
+
+		content, err := fs.ReadFile(fsys, dir+"/"+entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[uint(version)]
+		if !ok {
+			m = &Migration{Version: uint(version), Name: match[2]}
+			byVersion[uint(version)] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("duplicate migration version %d: %s and %s", version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.UpSQL = string(content)
+			sum := sha256.Sum256(content)
+			m.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			m.DownSQL = string(content)
+		}
+	}
+
+	result := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Checksum == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up script", m.Version, m.Name)
+		}
+		result = append(result, *m)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Version < result[j].Version })
+
+	return result, nil
+}
+
+// ensureTable 创建 schema_migrations 表
+func (m *Migrator) ensureTable() error {
+	return m.db.AutoMigrate(&SchemaMigration{})
+}
+
+// applied 返回已应用迁移，按版本索引
+func (m *Migrator) applied() (map[uint]SchemaMigration, error) {
+	if err := m.ensureTable(); err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	var records []SchemaMigration
+	if err := m.db.Order("version").Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+
+	result := make(map[uint]SchemaMigration, len(records))
+	for _, record := range records {
+		result[record.Version] = record
+	}
+	return result, nil
+}
+
+// Status 返回所有迁移的状态
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if record, ok := applied[migration.Version]; ok {
+			appliedAt := record.AppliedAt
+			status.Applied = true
+			status.AppliedAt = &appliedAt
+			status.Modified = record.Checksum != migration.Checksum
+			delete(applied, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+
+	// 数据库中有记录但文件已删除的迁移
+	for _, record := range applied {
+		appliedAt := record.AppliedAt
+		statuses = append(statuses, MigrationStatus{
+			Version:   record.Version,
+			Name:      record.Name,
+			Applied:   true,
+			AppliedAt: &appliedAt,
+			Missing:   true,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+
+	return statuses, nil
+}
+
+// Pending 返回尚未应用的迁移数量
+func (m *Migrator) Pending() (int, error) {
+	statuses, err := m.Status()
+	if err != nil {
+		return 0, err
+	}
+	pending := 0
+	for _, status := range statuses {
+		if !status.Applied {
+			pending++
+		}
+	}
+	return pending, nil
+}
+
+// Up 按顺序应用所有待执行迁移，target 为 0 表示应用到最新版本
+func (m *Migrator) Up(target uint) error {
+	applied, err := m.applied()
+	if err != nil {
+		return err
+	}
+
+	// 已有业务表但没有迁移记录的数据库需要先标记基线，避免重复建表
+	if len(applied) == 0 && m.db.Migrator().HasTable("wallets") {
+		return fmt.Errorf("database has existing tables but no migration history; run `migrate baseline <version>` first")
+	}
+
+	if err := m.verifyChecksums(applied); err != nil {
+		return err
+	}
+
+	count := 0
+	for _, migration := range m.migrations {
+		if target != 0 && migration.Version > target {
+			break
+		}
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		logger.Info("Applying migration",
+			zap.Uint("version", migration.Version),
+			zap.String("name", migration.Name),
+		)
+
+		record := SchemaMigration{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			Checksum:  migration.Checksum,
+			AppliedAt: time.Now(),
+		}
+		err := m.runScript(migration.UpSQL, func(tx *gorm.DB) error {
+			if err := tx.Create(&record).Error; err != nil {
+				return fmt.Errorf("failed to record migration %d: %w", migration.Version, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("migration %d_%s failed: %w", migration.Version, migration.Name, err)
+		}
+		count++
+	}
+
+	logger.Info("Database migrations completed", zap.Int("applied", count))
+	return nil
+}
+
+// Down 回滚最近应用的 steps 个迁移；其中任一迁移没有 down 脚本时不回滚任何迁移并返回错误
+func (m *Migrator) Down(steps int) error {
+	applied, err := m.applied()
+	if err != nil {
+		return err
+	}
+
+	byVersion := make(map[uint]Migration, len(m.migrations))
+	for _, migration := range m.migrations {
+		byVersion[migration.Version] = migration
+	}
+
+	versions := make([]uint, 0, len(applied))
+	for version := range applied {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+
+	if steps > len(versions) {
+		steps = len(versions)
+	}
+
+	// 先检查所有要回滚的迁移，避免回滚到一半才发现无法继续
+	rollback := make([]Migration, 0, steps)
+	for _, version := range versions[:steps] {
+		migration, ok := byVersion[version]
+		if !ok {
+			return fmt.Errorf("cannot roll back migration %d: file not found", version)
+		}
+		// 没有 down 脚本时删除迁移记录会让下次 up 在已有的表上重新执行；
+		// 只有注释的脚本是有意的空操作（如 006 保留已被引用的链数据）
+		if strings.TrimSpace(migration.DownSQL) == "" {
+			return fmt.Errorf("cannot roll back migration %d_%s: migration %d is irreversible (no down script)", version, migration.Name, version)
+		}
+		rollback = append(rollback, migration)
+	}
+
+	for _, migration := range rollback {
+		version := migration.Version
+		logger.Info("Rolling back migration",
+			zap.Uint("version", migration.Version),
+			zap.String("name", migration.Name),
+		)
+
+		err := m.runScript(migration.DownSQL, func(tx *gorm.DB) error {
+			if err := tx.Delete(&SchemaMigration{}, version).Error; err != nil {
+				return fmt.Errorf("failed to remove migration record %d: %w", version, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("rollback of %d_%s failed: %w", migration.Version, migration.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// Baseline 将 version 及之前的迁移标记为已应用而不执行
+// 用于接管在迁移工具引入前手动建表的数据库
+func (m *Migrator) Baseline(version uint) error {
+	applied, err := m.applied()
+	if err != nil {
+		return err
+	}
+
+	for _, migration := range m.migrations {
+		if migration.Version > version {
+			break
+		}
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		record := SchemaMigration{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			Checksum:  migration.Checksum,
+			AppliedAt: time.Now(),
+		}
+		if err := m.db.Create(&record).Error; err != nil {
+			return fmt.Errorf("failed to record migration %d: %w", migration.Version, err)
+		}
+	}
+
+	return nil
+}
+
+// verifyChecksums 确认已应用迁移的文件未被修改
+func (m *Migrator) verifyChecksums(applied map[uint]SchemaMigration) error {
+	for _, migration := range m.migrations {
+		record, ok := applied[migration.Version]
+		if ok && record.Checksum != migration.Checksum {
+			return fmt.Errorf("checksum mismatch for applied migration %d_%s; migration files must not be edited after they are applied",
+				migration.Version, migration.Name)
+		}
+	}
+	return nil
+}
+
+// runScript 在同一个连接上执行迁移脚本，然后调用 record 更新 schema_migrations
+// PostgreSQL 和 SQLite 中两者在同一事务内；MySQL 的 DDL 会隐式提交，只能依次执行
+func (m *Migrator) runScript(script string, record func(tx *gorm.DB) error) error {
+	statements := splitStatements(script, m.dialect)
+
+	return m.db.Connection(func(conn *gorm.DB) error {
+		// 每次调用使用新的语句，避免 Raw/Exec 的状态残留到后续查询
+		conn = conn.Session(&gorm.Session{NewDB: true})
+		if m.dialect == dialectMySQL {
+			if err := execStatements(conn, statements); err != nil {
+				return err
+			}
+			return record(conn)
+		}
+
+		// SQLite 在事务内忽略 PRAGMA foreign_keys：按官方重建表步骤在事务外关闭外键检查，提交前检查外键，提交后恢复
+		checkForeignKeys := false
+		if m.dialect == dialectSQLite {
+			var disable bool
+			statements, disable = extractForeignKeysPragmas(statements)
+			if disable {
+				var enabled bool
+				if err := conn.Raw("PRAGMA foreign_keys").Scan(&enabled).Error; err != nil {
+					return fmt.Errorf("failed to read foreign_keys: %w", err)
+				}
+				if err := conn.Exec("PRAGMA foreign_keys = OFF").Error; err != nil {
+					return fmt.Errorf("failed to disable foreign keys: %w", err)
+				}
+				if enabled {
+					defer conn.Exec("PRAGMA foreign_keys = ON")
+					checkForeignKeys = true
+				}
+			}
+		}
+
+		return conn.Transaction(func(tx *gorm.DB) error {
+			if err := execStatements(tx, statements); err != nil {
+				return err
+			}
+			if checkForeignKeys {
+				var violations []map[string]interface{}
+				if err := tx.Raw("PRAGMA foreign_key_check").Scan(&violations).Error; err != nil {
+					return fmt.Errorf("failed to check foreign keys: %w", err)
+				}
+				if len(violations) > 0 {
+					return fmt.Errorf("script leaves %d foreign key violations, first: %v", len(violations), violations[0])
+				}
+			}
+			return record(tx)
+		})
+	})
+}
+
+// execStatements 逐条执行 SQL 语句
+func execStatements(db *gorm.DB, statements []string) error {
+	for _, stmt := range statements {
+		if err := db.Exec(stmt).Error; err != nil {
+			return fmt.Errorf("%w\nstatement: %s", err, stmt)
+		}
+	}
+	return nil
+}
+
+// extractForeignKeysPragmas 移除脚本中的 PRAGMA foreign_keys 语句，返回脚本是否需要关闭外键检查
+func extractForeignKeysPragmas(statements []string) ([]string, bool) {
+	result := make([]string, 0, len(statements))
+	disable := false
+	for _, stmt := range statements {
+		match := foreignKeysPragmaPattern.FindStringSubmatch(stmt)
+		if match == nil {
+			result = append(result, stmt)
+			continue
+		}
+		switch strings.ToLower(match[1]) {
+		case "off", "0", "false", "no":
+			disable = true
+		}
+	}
+	return result, disable
+}
+
+// splitStatements 按分号拆分 SQL 脚本，跳过注释并忽略字符串、标识符和 PostgreSQL 美元引用中的分号
+// 注释和引号规则按方言处理：# 行注释和反斜杠转义只在 MySQL 中有效，$tag$ 引用和嵌套块注释只在 PostgreSQL 中有效
+func splitStatements(script, dialect string) []string {
+	var statements []string
+	var current strings.Builder
+
+	flush := func() {
+		stmt := strings.TrimSpace(current.String())
+		if stmt != "" {
+			statements = append(statements, stmt)
+		}
+		current.Reset()
+	}
+
+	runes := []rune(script)
+	var quote rune
+	for i := 0; i < len(runes); i++ {
+		ch := runes[i]
+
+		if quote != 0 {
+			current.WriteRune(ch)
+			if ch == '\\' && dialect == dialectMySQL && quote != '`' && i+1 < len(runes) {
+				i++
+				current.WriteRune(runes[i])
+				continue
+			}
+			if ch == quote {
+				quote = 0
+			}
+			continue
+		}
+
+		switch {
+		case ch == '\'' || ch == '"' || (ch == '`' && dialect != dialectPostgres):
+			quote = ch
+			current.WriteRune(ch)
+		case ch == '$' && dialect == dialectPostgres && dollarQuoteTag(runes, i) != "":
+			// 美元引用：原样复制到相同的结束标记（函数体等）
+			tag := dollarQuoteTag(runes, i)
+			current.WriteString(tag)
+			i += len([]rune(tag))
+			for i < len(runes) && !hasRunesAt(runes, i, tag) {
+				current.WriteRune(runes[i])
+				i++
+			}
+			if i < len(runes) {
+				current.WriteString(tag)
+				i += len([]rune(tag))
+			}
+			i--
+		case ch == '-' && i+1 < len(runes) && runes[i+1] == '-', ch == '#' && dialect == dialectMySQL:
+			// 行注释：跳到行尾
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			current.WriteRune('\n')
+		case ch == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			// 块注释，PostgreSQL 允许嵌套
+			depth := 1
+			i += 2
+			for i < len(runes) && depth > 0 {
+				switch {
+				case dialect == dialectPostgres && runes[i] == '/' && i+1 < len(runes) && runes[i+1] == '*':
+					depth++
+					i++
+				case runes[i] == '*' && i+1 < len(runes) && runes[i+1] == '/':
+					depth--
+					i++
+				}
+				i++
+			}
+			i--
+			current.WriteRune(' ')
+		case ch == ';':
+			flush()
+		default:
+			current.WriteRune(ch)
+		}
+	}
+	flush()
+
+	return statements
+}
+
+// dollarQuoteTag 返回从 i 开始的 PostgreSQL 美元引用标记（$$ 或 $tag$），不是标记时返回空字符串
+// 标记名不能以数字开头，以免与 $1 等参数占位符混淆
+func dollarQuoteTag(runes []rune, i int) string {
+	j := i + 1
+	for j < len(runes) && (runes[j] == '_' || unicode.IsLetter(runes[j]) || (j > i+1 && unicode.IsDigit(runes[j]))) {
+		j++
+	}
+	if j < len(runes) && runes[j] == '$' {
+		return string(runes[i : j+1])
+	}
+	return ""
+}
+
+// hasRunesAt 判断 runes 从 i 开始是否为 s
+func hasRunesAt(runes []rune, i int, s string) bool {
+	for _, r := range s {
+		if i >= len(runes) || runes[i] != r {
+			return false
+		}
+		i++
+	}
+	return true
+}
+
+// RunMigrations 将数据库迁移到最新版本
+func RunMigrations() error {
+	if DB == nil {
+		return fmt.Errorf("database not initialized")
+	}
+
+	migrator, err := NewDefaultMigrator(DB)
+	if err != nil {
+		return err
+	}
+	return migrator.Up(0)
+}
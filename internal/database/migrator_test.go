@@ -0,0 +1,326 @@
+package database
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/rotki-demo/internal/config"
+	"github.com/rotki-demo/internal/logger"
+	"gorm.io/gorm"
+)
+
+func TestMain(m *testing.M) {
+	// 迁移过程的 Info 日志在测试中没有意义
+	if err := logger.InitLogger(&config.LogConfig{Level: "warn", Output: "stdout"}); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+// openTestDB 打开一个独立的 SQLite 内存数据库
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := Open(&config.DatabaseConfig{Driver: config.DriverSQLite, Path: ":memory:"})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// testMigrations 是两个版本的最小迁移集合
+func testMigrations() fstest.MapFS {
+	return fstest.MapFS{
+		"sqlite/001_create_wallets.up.sql":   {Data: []byte("CREATE TABLE wallets (id INTEGER PRIMARY KEY, name TEXT NOT NULL);")},
+		"sqlite/001_create_wallets.down.sql": {Data: []byte("DROP TABLE wallets;")},
+		"sqlite/002_add_notes.up.sql":        {Data: []byte("CREATE TABLE notes (id INTEGER PRIMARY KEY, body TEXT); -- 备注\nINSERT INTO notes (body) VALUES ('a;b');")},
+		"sqlite/002_add_notes.down.sql":      {Data: []byte("DROP TABLE notes;")},
+	}
+}
+
+func newTestMigrator(t *testing.T, db *gorm.DB, fsys fstest.MapFS) *Migrator {
+	t.Helper()
+	migrator, err := NewMigrator(db, fsys, "sqlite")
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+	return migrator
+}
+
+func assertPending(t *testing.T, migrator *Migrator, want int) {
+	t.Helper()
+	pending, err := migrator.Pending()
+	if err != nil {
+		t.Fatalf("Pending: %v", err)
+	}
+	if pending != want {
+		t.Fatalf("pending = %d, want %d", pending, want)
+	}
+}
+
+func TestMigratorUpDownUp(t *testing.T) {
+	db := openTestDB(t)
+	migrator, err := NewDefaultMigrator(db)
+	if err != nil {
+		t.Fatalf("NewDefaultMigrator: %v", err)
+	}
+	total := len(migrator.migrations)
+
+	if err := migrator.Up(0); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	assertPending(t, migrator, 0)
+	for _, table := range []string{"wallets", "addresses", "tokens", "users", "audit_log", "transactions"} {
+		if !db.Migrator().HasTable(table) {
+			t.Errorf("table %s missing after Up", table)
+		}
+	}
+
+	// 全部回滚后只剩 schema_migrations
+	if err := migrator.Down(total); err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	assertPending(t, migrator, total)
+	var tables []string
+	if err := db.Raw("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name").Scan(&tables).Error; err != nil {
+		t.Fatalf("list tables: %v", err)
+	}
+	if !reflect.DeepEqual(tables, []string{"schema_migrations"}) {
+		t.Fatalf("tables after Down = %v, want only schema_migrations", tables)
+	}
+
+	// down 脚本必须完整撤销 up，才能再次应用
+	if err := migrator.Up(0); err != nil {
+		t.Fatalf("second Up: %v", err)
+	}
+	assertPending(t, migrator, 0)
+}
+
+func TestMigratorUpToTarget(t *testing.T) {
+	db := openTestDB(t)
+	migrator := newTestMigrator(t, db, testMigrations())
+
+	if err := migrator.Up(1); err != nil {
+		t.Fatalf("Up(1): %v", err)
+	}
+	assertPending(t, migrator, 1)
+	if db.Migrator().HasTable("notes") {
+		t.Fatal("notes created before its migration was applied")
+	}
+
+	if err := migrator.Up(0); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	var body string
+	if err := db.Raw("SELECT body FROM notes").Scan(&body).Error; err != nil {
+		t.Fatalf("read notes: %v", err)
+	}
+	if body != "a;b" {
+		t.Fatalf("body = %q, want %q", body, "a;b")
+	}
+}
+
+func TestMigratorChecksumMismatch(t *testing.T) {
+	db := openTestDB(t)
+	fsys := testMigrations()
+	if err := newTestMigrator(t, db, fsys).Up(1); err != nil {
+		t.Fatalf("Up(1): %v", err)
+	}
+
+	// 修改已应用的迁移文件
+	fsys["sqlite/001_create_wallets.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE wallets (id INTEGER PRIMARY KEY);")}
+	migrator := newTestMigrator(t, db, fsys)
+
+	statuses, err := migrator.Status()
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if !statuses[0].Applied || !statuses[0].Modified {
+		t.Fatalf("status of 001 = %+v, want applied and modified", statuses[0])
+	}
+
+	err = migrator.Up(0)
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("Up error = %v, want checksum mismatch", err)
+	}
+	if db.Migrator().HasTable("notes") {
+		t.Fatal("pending migration applied despite checksum mismatch")
+	}
+}
+
+func TestMigratorBaseline(t *testing.T) {
+	db := openTestDB(t)
+	// 迁移工具引入前手动建的表
+	if err := db.Exec("CREATE TABLE wallets (id INTEGER PRIMARY KEY, name TEXT NOT NULL)").Error; err != nil {
+		t.Fatalf("create wallets: %v", err)
+	}
+	migrator := newTestMigrator(t, db, testMigrations())
+
+	err := migrator.Up(0)
+	if err == nil || !strings.Contains(err.Error(), "baseline") {
+		t.Fatalf("Up error = %v, want baseline hint", err)
+	}
+
+	if err := migrator.Baseline(1); err != nil {
+		t.Fatalf("Baseline: %v", err)
+	}
+	assertPending(t, migrator, 1)
+	if err := migrator.Up(0); err != nil {
+		t.Fatalf("Up after baseline: %v", err)
+	}
+	assertPending(t, migrator, 0)
+	if !db.Migrator().HasTable("notes") {
+		t.Fatal("notes missing after Up")
+	}
+}
+
+func TestMigratorDownIrreversible(t *testing.T) {
+	tests := []struct {
+		name string
+		down *fstest.MapFile // nil 表示没有 down 脚本
+	}{
+		{name: "missing down script"},
+		{name: "empty down script", down: &fstest.MapFile{Data: []byte("\n")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := openTestDB(t)
+			fsys := testMigrations()
+			fsys["sqlite/003_purge_notes.up.sql"] = &fstest.MapFile{Data: []byte("DELETE FROM notes;")}
+			if tt.down != nil {
+				fsys["sqlite/003_purge_notes.down.sql"] = tt.down
+			}
+			migrator := newTestMigrator(t, db, fsys)
+			if err := migrator.Up(0); err != nil {
+				t.Fatalf("Up: %v", err)
+			}
+
+			// 002 可以回滚，但 003 不可逆，因此不回滚任何迁移
+			err := migrator.Down(2)
+			if err == nil || !strings.Contains(err.Error(), "migration 3 is irreversible") {
+				t.Fatalf("Down error = %v, want migration 3 is irreversible", err)
+			}
+			assertPending(t, migrator, 0)
+			if !db.Migrator().HasTable("notes") {
+				t.Fatal("notes dropped although the rollback was refused")
+			}
+		})
+	}
+}
+
+func TestMigratorFailedScriptRollsBack(t *testing.T) {
+	db := openTestDB(t)
+	fsys := testMigrations()
+	fsys["sqlite/003_broken.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE partial (id INTEGER PRIMARY KEY);\nINSERT INTO missing_table VALUES (1);")}
+	fsys["sqlite/003_broken.down.sql"] = &fstest.MapFile{Data: []byte("DROP TABLE partial;")}
+	migrator := newTestMigrator(t, db, fsys)
+
+	if err := migrator.Up(0); err == nil {
+		t.Fatal("Up succeeded, want error from 003")
+	}
+	// SQLite 的 DDL 是事务性的：失败的脚本不留下任何表，也没有迁移记录
+	if db.Migrator().HasTable("partial") {
+		t.Fatal("table from failed migration was not rolled back")
+	}
+	assertPending(t, migrator, 1)
+}
+
+func TestMigratorRebuildKeepsChildRows(t *testing.T) {
+	db := openTestDB(t)
+	fsys := fstest.MapFS{
+		"sqlite/001_init.up.sql": {Data: []byte(`
+CREATE TABLE parents (id INTEGER PRIMARY KEY, name TEXT UNIQUE);
+CREATE TABLE children (id INTEGER PRIMARY KEY, parent_id INTEGER NOT NULL REFERENCES parents(id) ON DELETE CASCADE);
+INSERT INTO parents (id, name) VALUES (1, 'a');
+INSERT INTO children (parent_id) VALUES (1);`)},
+		"sqlite/001_init.down.sql": {Data: []byte("DROP TABLE children; DROP TABLE parents;")},
+		// 按官方步骤重建父表，关闭外键检查时删除旧表不会级联删除子表
+		"sqlite/002_rebuild.up.sql": {Data: []byte(`
+PRAGMA foreign_keys = OFF;
+CREATE TABLE parents_new (id INTEGER PRIMARY KEY, name TEXT);
+INSERT INTO parents_new (id, name) SELECT id, name FROM parents;
+DROP TABLE parents;
+ALTER TABLE parents_new RENAME TO parents;
+PRAGMA foreign_keys = ON;`)},
+		"sqlite/002_rebuild.down.sql": {Data: []byte("SELECT 1;")},
+	}
+	migrator := newTestMigrator(t, db, fsys)
+	if err := migrator.Up(0); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+
+	var children int64
+	if err := db.Table("children").Count(&children).Error; err != nil {
+		t.Fatalf("count children: %v", err)
+	}
+	if children != 1 {
+		t.Fatalf("children = %d after rebuild, want 1", children)
+	}
+	var enabled bool
+	if err := db.Raw("PRAGMA foreign_keys").Scan(&enabled).Error; err != nil {
+		t.Fatalf("read foreign_keys: %v", err)
+	}
+	if !enabled {
+		t.Fatal("foreign keys left disabled after migration")
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect string
+		script  string
+		want    []string
+	}{
+		{
+			name:    "comments and quoted semicolons",
+			dialect: dialectSQLite,
+			script:  "-- 注释; 不拆分\nCREATE TABLE t (a TEXT DEFAULT 'x;y'); /* ; */ INSERT INTO \"t;\" VALUES (1);",
+			want:    []string{"CREATE TABLE t (a TEXT DEFAULT 'x;y')", "INSERT INTO \"t;\" VALUES (1)"},
+		},
+		{
+			name:    "mysql hash comment and backslash escape",
+			dialect: dialectMySQL,
+			script:  "# 注释; 不拆分\nINSERT INTO t VALUES ('it\\'s; fine');\nSELECT `a;b` FROM t;",
+			want:    []string{"INSERT INTO t VALUES ('it\\'s; fine')", "SELECT `a;b` FROM t"},
+		},
+		{
+			name:    "backslash is literal outside mysql",
+			dialect: dialectPostgres,
+			script:  "INSERT INTO t VALUES ('C:\\'); SELECT 1;",
+			want:    []string{"INSERT INTO t VALUES ('C:\\')", "SELECT 1"},
+		},
+		{
+			name:    "postgres dollar quoting",
+			dialect: dialectPostgres,
+			script:  "CREATE FUNCTION f() RETURNS trigger AS $body$ BEGIN NEW.a := 1; RETURN NEW; END; $body$ LANGUAGE plpgsql;\nSELECT $1;",
+			want:    []string{"CREATE FUNCTION f() RETURNS trigger AS $body$ BEGIN NEW.a := 1; RETURN NEW; END; $body$ LANGUAGE plpgsql", "SELECT $1"},
+		},
+		{
+			name:    "postgres nested block comment",
+			dialect: dialectPostgres,
+			script:  "/* outer /* inner; */ still comment; */ SELECT 1;",
+			want:    []string{"SELECT 1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitStatements(tt.script, tt.dialect)
+			for i := range got {
+				got[i] = strings.Join(strings.Fields(got[i]), " ")
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("splitStatements() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package events
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// Filter 限定订阅者接收的事件，零值表示接收所有事件
+type Filter struct {
+	WalletID  uint
+	AddressID uint
+	Types     []string
+
+	// Restricted 为 true 时只推送 Wallets 中钱包的事件（以及全局事件），在订阅时确定，之后的共享变化不影响已有订阅
+	Restricted bool
+	Wallets    []uint
+}
+
+// Match 检查事件是否满足过滤条件。
+// 不属于任何钱包或地址的全局事件（如 RPC 节点状态、定时同步进度）不受钱包和地址过滤影响
+func (f Filter) Match(event Event) bool {
+	if len(f.Types) > 0 && !containsType(f.Types, event.Type) {
+		return false
+	}
+	if f.Restricted && event.WalletID != 0 && !slices.Contains(f.Wallets, event.WalletID) {
+		return false
+	}
+	if f.WalletID != 0 && event.WalletID != 0 && event.WalletID != f.WalletID {
+		return false
+	}
+	if f.AddressID != 0 && event.AddressID != 0 && event.AddressID != f.AddressID {
+		return false
+	}
+	if f.AddressID != 0 && event.AddressID == 0 && event.WalletID != 0 && f.WalletID == 0 {
+		// 只按地址订阅时不推送钱包级事件，需要时同时指定钱包
+		return false
+	}
+	return true
+}
+
+// Subscription 表示一个事件订阅
+type Subscription struct {
+	C       <-chan Event
+	ch      chan Event
+	filter  Filter
+	bus     *Bus
+	dropped atomic.Uint64
+	once    sync.Once
+}
+
+// Dropped 返回因缓冲区已满而丢弃的事件数
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Close 取消订阅并关闭事件通道
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.bus.mu.Lock()
+		delete(s.bus.subscribers, s)
+		s.bus.mu.Unlock()
+		close(s.ch)
+	})
+}
+
+// Bus 是进程内的发布/订阅总线。
+// 发布的事件先转发给下游发布者（如 webhook 投递），再非阻塞地分发给订阅者，慢订阅者会丢弃事件而不会阻塞发布方
+type Bus struct {
+	mu          sync.RWMutex
+	subscribers map[*Subscription]struct{}
+	forward     []Publisher
+	bufferSize  int
+}
+
+// NewBus 创建一个新的事件总线，forward 中的发布者会收到每一个事件
+func NewBus(bufferSize int, forward ...Publisher) *Bus {
+	if bufferSize <= 0 {
+		bufferSize = 64
+	}
+	return &Bus{
+		subscribers: make(map[*Subscription]struct{}),
+		forward:     forward,
+		bufferSize:  bufferSize,
+	}
+}
+
+// Publish 实现 Publisher 接口
+func (b *Bus) Publish(event Event) {
+	for _, p := range b.forward {
+		p.Publish(event)
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for sub := range b.subscribers {
+		if !sub.filter.Match(event) {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+			sub.dropped.Add(1)
+		}
+	}
+}
+
+// Subscribe 注册一个订阅者，调用方必须在结束时调用 Close
+func (b *Bus) Subscribe(filter Filter) *Subscription {
+	ch := make(chan Event, b.bufferSize)
+	sub := &Subscription{C: ch, ch: ch, filter: filter, bus: b}
+
+	b.mu.Lock()
+	b.subscribers[sub] = struct{}{}
+	b.mu.Unlock()
+	return sub
+}
+
+// CloseSubscribers 关闭所有订阅，事件流连接随之结束，用于服务关闭时释放长连接
+func (b *Bus) CloseSubscribers() {
+	b.mu.RLock()
+	subs := make([]*Subscription, 0, len(b.subscribers))
+	for sub := range b.subscribers {
+		subs = append(subs, sub)
+	}
+	b.mu.RUnlock()
+
+	for _, sub := range subs {
+		sub.Close()
+	}
+}
+
+// SubscriberCount 返回当前订阅者数量
+func (b *Bus) SubscriberCount() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subscribers)
+}
+
+func containsType(types []string, eventType string) bool {
+	for _, t := range types {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package events
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// 事件类型
+const (
+	SyncCompleted      = "sync.completed"
+	SyncFailed         = "sync.failed"
+	AddressAdded       = "address.added"
+	AddressRemoved     = "address.removed"
+	WalletValueChanged = "wallet.value_changed"
+	TokenAppeared      = "token.appeared"
+	PositionOpened     = "position.opened"
+	PositionClosed     = "position.closed"
+	WebhookTest        = "webhook.test"
+
+	// 以下事件只推送到实时事件流，不投递到 webhook
+	SyncProgress         = "sync.progress"
+	RPCNodeStatusChanged = "rpc_node.status_changed"
+)
+
+// Types 返回所有可订阅的事件类型
+func Types() []string {
+	return []string{
+		SyncCompleted,
+		SyncFailed,
+		AddressAdded,
+		AddressRemoved,
+		WalletValueChanged,
+		TokenAppeared,
+		PositionOpened,
+		PositionClosed,
+	}
+}
+
+// StreamTypes 返回实时事件流可以订阅的事件类型
+func StreamTypes() []string {
+	return append(Types(), SyncProgress, RPCNodeStatusChanged)
+}
+
+// IsValidType 检查事件类型是否有效
+func IsValidType(eventType string) bool {
+	for _, t := range Types() {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidStreamType 检查实时事件流的事件类型是否有效
+func IsValidStreamType(eventType string) bool {
+	for _, t := range StreamTypes() {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Event 表示一次组合变化事件
+type Event struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	WalletID  uint        `json:"wallet_id,omitempty"`
+	AddressID uint        `json:"address_id,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// New 创建一个带有唯一 ID 的事件
+func New(eventType string, walletID, addressID uint, data interface{}) Event {
+	return Event{
+		ID:        newID(),
+		Type:      eventType,
+		WalletID:  walletID,
+		AddressID: addressID,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	}
+}
+
+// Publisher 发布事件
+type Publisher interface {
+	Publish(event Event)
+}
+
+// newID 生成 32 位十六进制随机 ID
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
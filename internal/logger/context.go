@@ -0,0 +1,50 @@
+package logger
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// fieldsKey 和 requestIDKey 是保存在上下文中的日志字段和请求 ID 的键
+type (
+	fieldsKey    struct{}
+	requestIDKey struct{}
+)
+
+// WithFields 返回附加了日志字段的上下文，FromContext 返回的日志记录器会带上这些字段
+func WithFields(ctx context.Context, fields ...zap.Field) context.Context {
+	existing, _ := ctx.Value(fieldsKey{}).([]zap.Field)
+	merged := make([]zap.Field, 0, len(existing)+len(fields))
+	merged = append(merged, existing...)
+	merged = append(merged, fields...)
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+// WithRequestID 返回带有请求 ID 的上下文，请求 ID 同时作为 request_id 字段写入日志
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	ctx = context.WithValue(ctx, requestIDKey{}, requestID)
+	return WithFields(ctx, zap.String("request_id", requestID))
+}
+
+// RequestIDFromContext 返回上下文中的请求 ID，没有时返回空字符串
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// FromContext 返回带有上下文日志字段的日志记录器，上下文中有 span 时附加 trace_id
+func FromContext(ctx context.Context) *zap.Logger {
+	l := GetLogger()
+	if ctx == nil {
+		return l
+	}
+	if fields, ok := ctx.Value(fieldsKey{}).([]zap.Field); ok {
+		l = l.With(fields...)
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		l = l.With(zap.String("trace_id", spanContext.TraceID().String()))
+	}
+	return l
+}
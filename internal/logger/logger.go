@@ -9,6 +9,9 @@ import (
 
 var log *zap.Logger
 
+// level 是日志记录器的级别，配置热加载时通过 SetLevel 修改，无需重建日志记录器
+var level = zap.NewAtomicLevelAt(zapcore.InfoLevel)
+
 // InitLogger 初始化日志记录器
 func InitLogger(cfg *config.LogConfig) error {
 	var zapConfig zap.Config
@@ -21,18 +24,8 @@ func InitLogger(cfg *config.LogConfig) error {
 	}
 
 	// 设置日志级别
-	level := zapcore.InfoLevel
-	switch cfg.Level {
-	case "debug":
-		level = zapcore.DebugLevel
-	case "info":
-		level = zapcore.InfoLevel
-	case "warn":
-		level = zapcore.WarnLevel
-	case "error":
-		level = zapcore.ErrorLevel
-	}
-	zapConfig.Level = zap.NewAtomicLevelAt(level)
+	SetLevel(cfg.Level)
+	zapConfig.Level = level
 
 	var err error
 	log, err = zapConfig.Build()
@@ -43,6 +36,25 @@ func InitLogger(cfg *config.LogConfig) error {
 	return nil
 }
 
+// SetLevel 修改日志级别（debug、info、warn、error），未知级别按 info 处理
+func SetLevel(name string) {
+	switch name {
+	case "debug":
+		level.SetLevel(zapcore.DebugLevel)
+	case "warn":
+		level.SetLevel(zapcore.WarnLevel)
+	case "error":
+		level.SetLevel(zapcore.ErrorLevel)
+	default:
+		level.SetLevel(zapcore.InfoLevel)
+	}
+}
+
+// Level 返回当前日志级别
+func Level() string {
+	return level.Level().String()
+}
+
 // GetLogger 返回日志记录器实例
 func GetLogger() *zap.Logger {
 	if log == nil {
@@ -0,0 +1,184 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace 是所有指标名称的前缀
+const namespace = "rotki"
+
+// 同步结果标签值
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route template and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method and route template.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	syncDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "sync_duration_seconds",
+		Help:      "Duration of address syncs by outcome.",
+		Buckets:   []float64{0.5, 1, 2.5, 5, 10, 20, 30, 60, 120},
+	}, []string{"outcome"})
+
+	addressSyncs = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "address_syncs_total",
+		Help:      "Address syncs by address ID and outcome.",
+	}, []string{"address_id", "outcome"})
+
+	addressLastSyncDuration = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "address_last_sync_duration_seconds",
+		Help:      "Duration of the most recent sync of each address.",
+	}, []string{"address_id"})
+
+	addressLastSyncSuccess = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "address_last_sync_success_timestamp_seconds",
+		Help:      "Unix time of the most recent successful sync of each address.",
+	}, []string{"address_id"})
+
+	syncQueueDepth = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "sync_queue_depth",
+		Help:      "Addresses waiting to be synced in running sync jobs.",
+	})
+
+	debankRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "debank_requests_total",
+		Help:      "DeBank API requests by endpoint and HTTP status (\"error\" when no response was received).",
+	}, []string{"endpoint", "status"})
+
+	debankRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "debank_request_duration_seconds",
+		Help:      "DeBank API request latency by endpoint, excluding rate limiter wait.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"endpoint"})
+
+	debankRateLimiterWait = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "debank_rate_limiter_wait_seconds",
+		Help:      "Time spent waiting for the DeBank rate limiter.",
+		Buckets:   []float64{0.001, 0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+	})
+
+	rpcNodeUp = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "rpc_node_up",
+		Help:      "Whether the last connection check of the RPC node succeeded (1) or failed (0).",
+	}, []string{"node_id", "chain_id", "name"})
+
+	rpcNodeLatency = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "rpc_node_latency_seconds",
+		Help:      "Latency of the last eth_blockNumber connection check of the RPC node.",
+	}, []string{"node_id", "chain_id", "name"})
+)
+
+// Handler 返回暴露默认注册表中所有指标的 HTTP 处理器
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// RegisterDBStats 注册数据库连接池指标（go_sql_*），每次抓取时读取 sql.DB.Stats()
+func RegisterDBStats(db *sql.DB, dbName string) error {
+	return prometheus.Register(collectors.NewDBStatsCollector(db, dbName))
+}
+
+// ObserveHTTPRequest 记录一次 HTTP 请求，route 为路由模板以避免按 ID 产生大量序列
+func ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	httpRequestDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+// ObserveSync 记录一次地址同步的耗时和结果
+func ObserveSync(addressID uint, duration time.Duration, err error) {
+	id := formatID(addressID)
+	outcome := OutcomeSuccess
+	if err != nil {
+		outcome = OutcomeFailure
+	} else {
+		addressLastSyncSuccess.WithLabelValues(id).SetToCurrentTime()
+	}
+	syncDuration.WithLabelValues(outcome).Observe(duration.Seconds())
+	addressSyncs.WithLabelValues(id, outcome).Inc()
+	addressLastSyncDuration.WithLabelValues(id).Set(duration.Seconds())
+}
+
+// ForgetAddress 删除已删除地址的指标序列
+func ForgetAddress(addressID uint) {
+	labels := prometheus.Labels{"address_id": formatID(addressID)}
+	addressSyncs.DeletePartialMatch(labels)
+	addressLastSyncDuration.DeletePartialMatch(labels)
+	addressLastSyncSuccess.DeletePartialMatch(labels)
+}
+
+// AddSyncQueue 调整同步队列深度，任务开始时加上地址数，每处理完一个地址减一
+func AddSyncQueue(delta int) {
+	syncQueueDepth.Add(float64(delta))
+}
+
+// ObserveDeBankRequest 记录一次 DeBank API 请求，status 为 0 表示没有收到响应
+func ObserveDeBankRequest(endpoint string, status int, duration time.Duration) {
+	label := "error"
+	if status != 0 {
+		label = strconv.Itoa(status)
+	}
+	debankRequests.WithLabelValues(endpoint, label).Inc()
+	debankRequestDuration.WithLabelValues(endpoint).Observe(duration.Seconds())
+}
+
+// ObserveRateLimiterWait 记录等待 DeBank 速率限制器的时间
+func ObserveRateLimiterWait(duration time.Duration) {
+	debankRateLimiterWait.Observe(duration.Seconds())
+}
+
+// SetRPCNodeStatus 记录 RPC 节点最近一次连接检查的结果和延迟
+func SetRPCNodeStatus(nodeID uint, chainID, name string, connected bool, latency time.Duration) {
+	// 节点名称可能被修改，先删除旧名称的序列
+	ForgetRPCNode(nodeID)
+
+	id := formatID(nodeID)
+	up := 0.0
+	if connected {
+		up = 1
+	}
+	rpcNodeUp.WithLabelValues(id, chainID, name).Set(up)
+	rpcNodeLatency.WithLabelValues(id, chainID, name).Set(latency.Seconds())
+}
+
+// ForgetRPCNode 删除 RPC 节点的指标序列
+func ForgetRPCNode(nodeID uint) {
+	labels := prometheus.Labels{"node_id": formatID(nodeID)}
+	rpcNodeUp.DeletePartialMatch(labels)
+	rpcNodeLatency.DeletePartialMatch(labels)
+}
+
+// formatID 将数据库 ID 转换为标签值
+func formatID(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
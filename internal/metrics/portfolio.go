@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rotki-demo/internal/logger"
+	"github.com/rotki-demo/internal/repository"
+	"go.uber.org/zap"
+)
+
+// portfolioCollector 在每次抓取时从数据库读取各钱包的总价值，
+// 不依赖同步是否发生过，服务重启后第一次抓取就有数据
+type portfolioCollector struct {
+	tokenRepo  *repository.TokenRepository
+	walletDesc *prometheus.Desc
+}
+
+// RegisterPortfolio 注册钱包总价值指标 rotki_wallet_usd_value
+func RegisterPortfolio(tokenRepo *repository.TokenRepository) error {
+	return prometheus.Register(&portfolioCollector{
+		tokenRepo: tokenRepo,
+		walletDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "wallet_usd_value"),
+			"Total USD value of each wallet, counting only tokens classified as normal.",
+			[]string{"wallet_id", "wallet"}, nil,
+		),
+	})
+}
+
+// Describe 实现 prometheus.Collector
+func (c *portfolioCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.walletDesc
+}
+
+// Collect 实现 prometheus.Collector，查询失败时不输出该指标
+func (c *portfolioCollector) Collect(ch chan<- prometheus.Metric) {
+	values, err := c.tokenRepo.GetTotalValuesByWallet()
+	if err != nil {
+		logger.Warn("Failed to collect wallet values for metrics", zap.Error(err))
+		return
+	}
+	for _, value := range values {
+		ch <- prometheus.MustNewConstMetric(c.walletDesc, prometheus.GaugeValue, value.USDValue, formatID(value.WalletID), value.Name)
+	}
+}
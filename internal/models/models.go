@@ -6,6 +6,19 @@ import (
 	"time"
 )
 
+// jsonBytes 提取 JSON 列的原始内容
+// MySQL 驱动返回 []byte，SQLite 驱动对 TEXT 列返回 string
+func jsonBytes(value interface{}) ([]byte, bool) {
+	switch v := value.(type) {
+	case []byte:
+		return v, true
+	case string:
+		return []byte(v), true
+	default:
+		return nil, false
+	}
+}
+
 // StringSlice 是用于将字符串数组存储为 JSON 的自定义类型
 type StringSlice []string
 
@@ -15,7 +28,7 @@ func (s *StringSlice) Scan(value interface{}) error {
 		*s = []string{}
 		return nil
 	}
-	bytes, ok := value.([]byte)
+	bytes, ok := jsonBytes(value)
 	if !ok {
 		return nil
 	}
@@ -33,7 +46,8 @@ func (s StringSlice) Value() (driver.Value, error) {
 // Wallet 表示可以包含多个地址的钱包
 type Wallet struct {
 	ID            uint        `gorm:"primaryKey" json:"id"`
-	Name          string      `gorm:"type:varchar(255);uniqueIndex;not null" json:"name"`
+	OwnerID       *uint       `gorm:"uniqueIndex:uk_wallets_owner_name,priority:1" json:"owner_id"` // 所有者，nil 表示升级前创建的钱包
+	Name          string      `gorm:"type:varchar(255);uniqueIndex:uk_wallets_owner_name,priority:2;not null" json:"name"`
 	Description   string      `gorm:"type:text" json:"description"`
 	Tags          StringSlice `gorm:"type:json" json:"tags"`                                     // 用户定义的标签
 	EnabledChains StringSlice `gorm:"type:json" json:"enabled_chains"`                           // 启用的链 ID 列表，空表示所有链
@@ -46,9 +60,9 @@ type Wallet struct {
 // Address 表示区块链地址
 type Address struct {
 	ID           uint        `gorm:"primaryKey" json:"id"`
-	WalletID     uint        `gorm:"not null;index" json:"wallet_id"`
-	Address      string      `gorm:"type:varchar(255);not null;index" json:"address"`
-	ChainType    string      `gorm:"type:varchar(50);not null;default:'EVM'" json:"chain_type"`
+	WalletID     uint        `gorm:"not null;index;uniqueIndex:uk_addresses_wallet_address_chain,priority:1" json:"wallet_id"`
+	Address      string      `gorm:"type:varchar(255);not null;index;uniqueIndex:uk_addresses_wallet_address_chain,priority:2" json:"address"` // 同一钱包内唯一
+	ChainType    string      `gorm:"type:varchar(50);not null;default:'EVM';uniqueIndex:uk_addresses_wallet_address_chain,priority:3" json:"chain_type"`
 	Label        string      `gorm:"type:varchar(255)" json:"label"`
 	Tags         StringSlice `gorm:"type:json" json:"tags"` // 用户定义的标签
 	LastSyncedAt *time.Time  `json:"last_synced_at,omitempty"`
@@ -71,7 +85,7 @@ func (j *JSONMap) Scan(value interface{}) error {
 		*j = make(JSONMap)
 		return nil
 	}
-	bytes, ok := value.([]byte)
+	bytes, ok := jsonBytes(value)
 	if !ok {
 		return nil
 	}
@@ -104,33 +118,112 @@ type Chain struct {
 	ChainType     string    `gorm:"not null;default:'EVM';index" json:"chain_type"`
 	LogoURL       string    `json:"logo_url"`
 	NativeTokenID string    `json:"native_token_id"`
-	IsActive      bool      `gorm:"default:true" json:"is_active"`
+	NetworkID     int64     `gorm:"not null;default:0" json:"network_id"`    // 数字链 ID（EIP-155），0 表示未知
+	ExplorerURL   string    `gorm:"type:varchar(512)" json:"explorer_url"`   // 区块浏览器地址
+	IsActive      bool      `gorm:"not null;default:false" json:"is_active"` // 是否为支持的链，未激活的链不会出现在 /chains 中
 	CreatedAt     time.Time `json:"created_at"`
 	UpdatedAt     time.Time `json:"updated_at"`
 }
 
 // Token 表示地址的代币余额
 type Token struct {
-	ID          uint      `gorm:"primaryKey" json:"id"`
-	AddressID   uint      `gorm:"not null;index;uniqueIndex:uk_address_chain_token" json:"address_id"`
-	ChainID     string    `gorm:"not null;index;uniqueIndex:uk_address_chain_token" json:"chain_id"`
-	TokenID     string    `gorm:"not null;uniqueIndex:uk_address_chain_token" json:"token_id"`
-	Symbol      string    `json:"symbol"`
-	Name        string    `json:"name"`
-	Decimals    int       `json:"decimals"`
-	LogoURL     string    `json:"logo_url"`
-	Balance     string    `gorm:"type:decimal(40,18)" json:"balance"` // 可以是负数（debt代币）
-	Price       float64   `gorm:"type:decimal(30,6)" json:"price"`
-	USDValue    float64   `gorm:"type:decimal(30,6)" json:"usd_value"`                  // 可以是负数
-	ProtocolID  string    `gorm:"type:varchar(100);index" json:"protocol_id,omitempty"` // 如果来自协议，记录协议ID
-	IsDebt      bool      `gorm:"default:false" json:"is_debt"`                         // 是否是债务代币
-	LastUpdated time.Time `gorm:"autoUpdateTime" json:"last_updated"`
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	AddressID      uint      `gorm:"not null;index;uniqueIndex:uk_address_chain_token_protocol" json:"address_id"`
+	ChainID        string    `gorm:"not null;index;uniqueIndex:uk_address_chain_token_protocol" json:"chain_id"`
+	TokenID        string    `gorm:"not null;uniqueIndex:uk_address_chain_token_protocol" json:"token_id"`
+	Symbol         string    `json:"symbol"`
+	Name           string    `json:"name"`
+	Decimals       int       `json:"decimals"`
+	LogoURL        string    `json:"logo_url"`
+	Balance        string    `gorm:"type:decimal(40,18)" json:"balance"` // 可以是负数（debt代币）
+	Price          float64   `gorm:"type:decimal(30,6)" json:"price"`
+	USDValue       float64   `gorm:"type:decimal(30,6)" json:"usd_value"`                                                              // 可以是负数
+	ProtocolID     string    `gorm:"type:varchar(100);index;uniqueIndex:uk_address_chain_token_protocol" json:"protocol_id,omitempty"` // 如果来自协议，记录协议ID
+	IsDebt         bool      `gorm:"default:false" json:"is_debt"`                                                                     // 是否是债务代币
+	IsVerified     bool      `gorm:"not null;default:false" json:"is_verified"`                                                        // 提供者是否验证过该代币
+	IsCore         bool      `gorm:"not null;default:false" json:"is_core"`                                                            // 是否为提供者认定的核心资产
+	Classification string    `gorm:"type:varchar(20);not null;default:normal;index" json:"classification"`                             // normal、spam、receipt、hidden
+	LastUpdated    time.Time `gorm:"autoUpdateTime" json:"last_updated"`
 
 	// 关系
 	Address *Address `gorm:"foreignKey:AddressID" json:"address,omitempty"`
 	Chain   *Chain   `gorm:"foreignKey:ChainID" json:"chain,omitempty"`
 }
 
+// 代币分类，只有 normal 代币默认在 API 中返回并计入总值
+const (
+	TokenClassificationNormal  = "normal"
+	TokenClassificationSpam    = "spam"
+	TokenClassificationReceipt = "receipt" // 协议凭证代币（aToken 等），已在协议持仓中展开
+	TokenClassificationHidden  = "hidden"
+)
+
+// IsValidTokenClassification 检查分类名称是否有效
+func IsValidTokenClassification(classification string) bool {
+	switch classification {
+	case TokenClassificationNormal, TokenClassificationSpam, TokenClassificationReceipt, TokenClassificationHidden:
+		return true
+	default:
+		return false
+	}
+}
+
+// TokenRule 表示一条代币分类规则
+// 所有已设置的条件都满足时规则匹配，按 priority 从小到大取第一条匹配规则的分类
+type TokenRule struct {
+	ID             uint        `gorm:"primaryKey" json:"id"`
+	Name           string      `gorm:"type:varchar(100);not null" json:"name"`
+	Classification string      `gorm:"type:varchar(20);not null" json:"classification"`
+	Priority       int         `gorm:"not null" json:"priority"`
+	ChainID        string      `gorm:"type:varchar(50)" json:"chain_id,omitempty"`            // 空表示所有链
+	SymbolPattern  string      `gorm:"type:varchar(255)" json:"symbol_pattern,omitempty"`     // 符号正则
+	NamePattern    string      `gorm:"type:varchar(255)" json:"name_pattern,omitempty"`       // 名称正则
+	TextPattern    string      `gorm:"type:varchar(255)" json:"text_pattern,omitempty"`       // 符号或名称任一匹配的正则
+	TokenIDs       StringSlice `gorm:"column:token_ids;type:json" json:"token_ids,omitempty"` // 合约地址列表（允许/拒绝列表）
+	MaxPrice       *float64    `gorm:"type:decimal(30,6)" json:"max_price,omitempty"`         // 价格不高于该值时匹配
+	MaxUSDValue    *float64    `gorm:"column:max_usd_value;type:decimal(30,6)" json:"max_usd_value,omitempty"`
+	IsVerified     *bool       `json:"is_verified,omitempty"` // 匹配提供者 is_verified 标志
+	IsCore         *bool       `json:"is_core,omitempty"`     // 匹配提供者 is_core 标志
+	Enabled        bool        `gorm:"not null" json:"enabled"`
+	CreatedAt      time.Time   `json:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+}
+
+// 用户对代币的覆盖操作
+const (
+	TokenOverrideIgnore = "ignore" // 始终隐藏
+	TokenOverrideShow   = "show"   // 始终显示，跳过分类规则
+)
+
+// TokenOverride 表示用户对 (chain_id, token_id) 的忽略/白名单设置
+// WalletID 为 0 表示全局生效，钱包级设置优先于全局设置
+type TokenOverride struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	ChainID   string    `gorm:"type:varchar(50);not null;uniqueIndex:uk_token_override" json:"chain_id"`
+	TokenID   string    `gorm:"type:varchar(255);not null;uniqueIndex:uk_token_override" json:"token_id"`
+	WalletID  uint      `gorm:"not null;uniqueIndex:uk_token_override;index" json:"wallet_id"`
+	Action    string    `gorm:"type:varchar(20);not null" json:"action"`
+	Reason    string    `gorm:"type:varchar(500)" json:"reason,omitempty"`
+	UpdatedBy string    `gorm:"type:varchar(255)" json:"updated_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TokenOverrideAudit 记录忽略/白名单的每次变更
+type TokenOverrideAudit struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	OverrideID uint      `gorm:"not null" json:"override_id"`
+	ChainID    string    `gorm:"type:varchar(50);not null" json:"chain_id"`
+	TokenID    string    `gorm:"type:varchar(255);not null" json:"token_id"`
+	WalletID   uint      `gorm:"not null" json:"wallet_id"`
+	Operation  string    `gorm:"type:varchar(20);not null" json:"operation"` // create、update、delete
+	OldAction  string    `gorm:"type:varchar(20)" json:"old_action,omitempty"`
+	NewAction  string    `gorm:"type:varchar(20)" json:"new_action,omitempty"`
+	Reason     string    `gorm:"type:varchar(500)" json:"reason,omitempty"`
+	Actor      string    `gorm:"type:varchar(255);not null" json:"actor"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
 // SyncJob 跟踪后台同步操作
 type SyncJob struct {
 	ID           uint       `gorm:"primaryKey" json:"id"`
@@ -157,13 +250,57 @@ type RPCNode struct {
 	LastChecked *time.Time `json:"last_checked,omitempty"`                          // 最后连接检查时间
 	Priority    int        `gorm:"not null;default:0" json:"priority"`              // 优先级更高的节点优先
 	Timeout     int        `gorm:"not null;default:30" json:"timeout"`              // 请求超时时间（秒）
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
+	WSURL       string     `gorm:"column:ws_url;type:varchar(500)" json:"ws_url"`   // WebSocket 端点 URL，空表示从 URL 推导
+
+	// 能力探测结果
+	IsArchive             bool       `gorm:"not null;default:false" json:"is_archive"`                                   // 是否可以查询历史区块状态
+	SupportsTrace         bool       `gorm:"not null;default:false" json:"supports_trace"`                               // 是否支持 trace_ 命名空间
+	SupportsDebug         bool       `gorm:"not null;default:false" json:"supports_debug"`                               // 是否支持 debug_ 命名空间
+	SupportsWebSocket     bool       `gorm:"column:supports_websocket;not null;default:false" json:"supports_websocket"` // WebSocket 端点是否可用
+	MaxLogsBlockRange     int        `gorm:"not null;default:0" json:"max_logs_block_range"`                             // eth_getLogs 允许的最大区块范围，0 表示未知
+	CapabilitiesCheckedAt *time.Time `json:"capabilities_checked_at,omitempty"`                                          // 最后能力探测时间
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 
 	// 关系
 	Chain *Chain `gorm:"foreignKey:ChainID" json:"chain,omitempty"`
 }
 
+// RPC 节点能力名称，用于按能力选择节点
+const (
+	RPCCapabilityArchive   = "archive"
+	RPCCapabilityTrace     = "trace"
+	RPCCapabilityDebug     = "debug"
+	RPCCapabilityWebSocket = "websocket"
+)
+
+// HasCapability 检查节点是否具备指定能力
+func (n *RPCNode) HasCapability(capability string) bool {
+	switch capability {
+	case RPCCapabilityArchive:
+		return n.IsArchive
+	case RPCCapabilityTrace:
+		return n.SupportsTrace
+	case RPCCapabilityDebug:
+		return n.SupportsDebug
+	case RPCCapabilityWebSocket:
+		return n.SupportsWebSocket
+	default:
+		return false
+	}
+}
+
+// IsValidRPCCapability 检查能力名称是否有效
+func IsValidRPCCapability(capability string) bool {
+	switch capability {
+	case RPCCapabilityArchive, RPCCapabilityTrace, RPCCapabilityDebug, RPCCapabilityWebSocket:
+		return true
+	default:
+		return false
+	}
+}
+
 // Protocol 表示 DeFi 协议持仓
 type Protocol struct {
 	ID            uint      `gorm:"primaryKey" json:"id"`
@@ -185,12 +322,407 @@ type Protocol struct {
 	Chain   *Chain   `gorm:"foreignKey:ChainID" json:"chain,omitempty"`
 }
 
+// ProtocolPosition 表示协议中的一个持仓（对应提供者的一个 portfolio item）
+type ProtocolPosition struct {
+	ID            uint        `gorm:"primaryKey" json:"id"`
+	AddressID     uint        `gorm:"not null;index" json:"address_id"`
+	ProtocolID    string      `gorm:"type:varchar(255);not null;index" json:"protocol_id"`
+	ChainID       string      `gorm:"type:varchar(50);not null;index" json:"chain_id"`
+	Name          string      `gorm:"type:varchar(255)" json:"name"`
+	PositionType  string      `gorm:"type:varchar(50);not null;index" json:"position_type"` // lending、staking、liquidity 等
+	DetailTypes   StringSlice `gorm:"type:json" json:"detail_types,omitempty"`              // 提供者返回的所有类型
+	PoolID        string      `gorm:"type:varchar(255)" json:"pool_id,omitempty"`
+	NetUSDValue   float64     `gorm:"type:decimal(30,6)" json:"net_usd_value"`
+	AssetUSDValue float64     `gorm:"type:decimal(30,6)" json:"asset_usd_value"`
+	DebtUSDValue  float64     `gorm:"type:decimal(30,6)" json:"debt_usd_value"`
+	HealthRate    *float64    `gorm:"type:decimal(30,6)" json:"health_rate,omitempty"` // 健康因子，nil 表示不适用
+	LastUpdated   time.Time   `gorm:"autoUpdateTime" json:"last_updated"`
+
+	// 关系
+	Tokens  []ProtocolPositionToken `gorm:"foreignKey:PositionID" json:"tokens,omitempty"`
+	Address *Address                `gorm:"foreignKey:AddressID" json:"address,omitempty"`
+}
+
+// PositionKey 返回持仓在协议内的稳定标识：提供者的池子 ID，缺省为持仓名称
+func (p *ProtocolPosition) PositionKey() string {
+	if p.PoolID != "" {
+		return p.PoolID
+	}
+	return p.Name
+}
+
+// 持仓代币的角色
+const (
+	PositionTokenSupply = "supply"
+	PositionTokenBorrow = "borrow"
+	PositionTokenReward = "reward"
+)
+
+// ProtocolPositionToken 表示持仓中的一个代币
+type ProtocolPositionToken struct {
+	ID         uint    `gorm:"primaryKey" json:"id"`
+	PositionID uint    `gorm:"not null;index" json:"position_id"`
+	Role       string  `gorm:"type:varchar(20);not null" json:"role"` // supply、borrow、reward
+	ChainID    string  `gorm:"type:varchar(50);not null" json:"chain_id"`
+	TokenID    string  `gorm:"type:varchar(255);not null" json:"token_id"`
+	Symbol     string  `gorm:"type:varchar(100)" json:"symbol"`
+	Name       string  `gorm:"type:varchar(255)" json:"name"`
+	Decimals   int     `json:"decimals"`
+	LogoURL    string  `gorm:"type:varchar(500)" json:"logo_url"`
+	Amount     float64 `gorm:"type:decimal(40,18)" json:"amount"` // 债务为负数
+	Price      float64 `gorm:"type:decimal(30,6)" json:"price"`
+	USDValue   float64 `gorm:"type:decimal(30,6)" json:"usd_value"`
+}
+
+// HealthRateRecord 记录一次同步时借贷持仓的健康因子
+type HealthRateRecord struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	AddressID   uint      `gorm:"not null" json:"address_id"`
+	ProtocolID  string    `gorm:"type:varchar(255);not null" json:"protocol_id"`
+	ChainID     string    `gorm:"type:varchar(50);not null" json:"chain_id"`
+	PositionKey string    `gorm:"type:varchar(255);not null" json:"position_key"`
+	HealthRate  float64   `gorm:"type:decimal(30,6);not null" json:"health_rate"`
+	RecordedAt  time.Time `gorm:"not null" json:"recorded_at"`
+}
+
+// HealthThreshold 表示健康因子告警阈值
+// WalletID 为 0、ProtocolID/PositionKey 为空表示不限，匹配时最具体的阈值优先
+type HealthThreshold struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	WalletID    uint      `gorm:"not null;uniqueIndex:uk_health_threshold" json:"wallet_id"`
+	ProtocolID  string    `gorm:"type:varchar(255);not null;uniqueIndex:uk_health_threshold" json:"protocol_id"`
+	PositionKey string    `gorm:"type:varchar(255);not null;uniqueIndex:uk_health_threshold" json:"position_key"`
+	Threshold   float64   `gorm:"type:decimal(30,6);not null" json:"threshold"`    // 健康因子跌破该值时告警
+	DropPercent float64   `gorm:"type:decimal(10,4);not null" json:"drop_percent"` // 两次同步间下降超过该百分比时告警，0 表示不检查
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// 健康因子告警类型
+const (
+	HealthAlertBelowThreshold = "below_threshold"
+	HealthAlertSharpDrop      = "sharp_drop"
+)
+
+// HealthAlert 表示一次已触发的健康因子告警
+type HealthAlert struct {
+	ID                 uint      `gorm:"primaryKey" json:"id"`
+	WalletID           uint      `gorm:"not null;index" json:"wallet_id"`
+	AddressID          uint      `gorm:"not null;index" json:"address_id"`
+	ProtocolID         string    `gorm:"type:varchar(255);not null" json:"protocol_id"`
+	ChainID            string    `gorm:"type:varchar(50);not null" json:"chain_id"`
+	PositionKey        string    `gorm:"type:varchar(255);not null" json:"position_key"`
+	AlertType          string    `gorm:"type:varchar(50);not null" json:"alert_type"`
+	HealthRate         float64   `gorm:"type:decimal(30,6);not null" json:"health_rate"`
+	PreviousHealthRate *float64  `gorm:"type:decimal(30,6)" json:"previous_health_rate,omitempty"`
+	Threshold          float64   `gorm:"type:decimal(30,6);not null" json:"threshold"`
+	Message            string    `gorm:"type:varchar(1000);not null" json:"message"`
+	Delivered          bool      `gorm:"not null" json:"delivered"`
+	DeliveryError      string    `gorm:"type:varchar(1000)" json:"delivery_error,omitempty"`
+	CreatedAt          time.Time `gorm:"index" json:"created_at"`
+}
+
+// WebhookEndpoint 表示接收组合变化事件的出站 webhook
+type WebhookEndpoint struct {
+	ID                 uint        `gorm:"primaryKey" json:"id"`
+	Name               string      `gorm:"type:varchar(255);not null" json:"name"`
+	URL                string      `gorm:"column:url;type:varchar(1000);not null" json:"url"`
+	Secret             string      `gorm:"type:varchar(255);not null" json:"-"`                     // 用于 HMAC-SHA256 签名，只在创建时返回
+	Events             StringSlice `gorm:"type:json" json:"events"`                                 // 订阅的事件类型，为空表示全部
+	WalletID           uint        `gorm:"not null" json:"wallet_id"`                               // 只接收该钱包的事件，0 表示全部
+	ValueChangePercent float64     `gorm:"type:decimal(10,4);not null" json:"value_change_percent"` // wallet.value_changed 的阈值，0 表示使用配置默认值
+	Enabled            bool        `gorm:"not null" json:"enabled"`
+	CreatedAt          time.Time   `json:"created_at"`
+	UpdatedAt          time.Time   `json:"updated_at"`
+}
+
+// Subscribes 检查端点是否订阅了事件类型
+func (e *WebhookEndpoint) Subscribes(eventType string) bool {
+	if len(e.Events) == 0 {
+		return true
+	}
+	for _, t := range e.Events {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// webhook 投递状态
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryDead      = "dead"
+)
+
+// RawJSON 是按原样输出的 JSON 文本
+type RawJSON string
+
+// MarshalJSON 实现 json.Marshaler 接口
+func (r RawJSON) MarshalJSON() ([]byte, error) {
+	if r == "" {
+		return []byte("null"), nil
+	}
+	return []byte(r), nil
+}
+
+// WebhookDelivery 表示一个事件到一个端点的投递，同时作为投递队列和死信列表
+type WebhookDelivery struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	EndpointID     uint       `gorm:"not null;index" json:"endpoint_id"`
+	EventID        string     `gorm:"type:varchar(64);not null" json:"event_id"`
+	EventType      string     `gorm:"type:varchar(50);not null" json:"event_type"`
+	Payload        RawJSON    `gorm:"type:text;not null" json:"payload"` // 签名时使用的原始 JSON
+	Status         string     `gorm:"type:varchar(20);not null" json:"status"`
+	Attempts       int        `gorm:"not null" json:"attempts"`
+	NextAttemptAt  time.Time  `gorm:"not null" json:"next_attempt_at"`
+	LastError      string     `gorm:"type:varchar(1000)" json:"last_error,omitempty"`
+	ResponseStatus int        `gorm:"not null" json:"response_status"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// 余额变化告警规则类型
+const (
+	AlertRuleNetWorthDrop       = "net_worth_drop"       // 净值相比上次快照下降超过百分比或金额
+	AlertRuleTokenBalanceChange = "token_balance_change" // 单个代币余额变化超过百分比或金额
+	AlertRuleTokenDisappeared   = "token_disappeared"    // 上次快照中的代币完全消失
+	AlertRuleNewDebt            = "new_debt"             // 出现新的债务
+)
+
+// 告警规则作用范围
+const (
+	AlertScopeAll     = "all"
+	AlertScopeWallet  = "wallet"
+	AlertScopeAddress = "address"
+	AlertScopeTag     = "tag"
+)
+
+// AlertRule 表示在每次同步后评估的余额变化告警规则
+type AlertRule struct {
+	ID               uint      `gorm:"primaryKey" json:"id"`
+	Name             string    `gorm:"type:varchar(255);not null" json:"name"`
+	RuleType         string    `gorm:"type:varchar(50);not null" json:"rule_type"`
+	ScopeType        string    `gorm:"type:varchar(20);not null" json:"scope_type"`
+	ScopeID          uint      `gorm:"not null" json:"scope_id"`                                     // scope_type 为 wallet/address 时的 ID
+	ScopeTag         string    `gorm:"type:varchar(255);not null" json:"scope_tag"`                  // scope_type 为 tag 时的标签
+	ChainID          string    `gorm:"type:varchar(50);not null" json:"chain_id"`                    // 只检查该链的代币，空表示全部
+	TokenID          string    `gorm:"type:varchar(255);not null" json:"token_id"`                   // 只检查该代币，空表示全部
+	ThresholdPercent *float64  `gorm:"type:decimal(10,4)" json:"threshold_percent"`                  // 变化百分比阈值
+	ThresholdUSD     *float64  `gorm:"column:threshold_usd;type:decimal(30,6)" json:"threshold_usd"` // 变化金额阈值，对 token_disappeared/new_debt 为最小金额
+	CooldownMinutes  int       `gorm:"not null" json:"cooldown_minutes"`                             // 同一地址两次告警的最小间隔
+	Enabled          bool      `gorm:"not null;index" json:"enabled"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// AlertEvent 表示一次已触发的余额变化告警
+type AlertEvent struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	RuleID        uint      `gorm:"not null" json:"rule_id"`
+	WalletID      uint      `gorm:"not null;index" json:"wallet_id"`
+	AddressID     uint      `gorm:"not null" json:"address_id"`
+	RuleType      string    `gorm:"type:varchar(50);not null" json:"rule_type"`
+	Message       string    `gorm:"type:varchar(1000);not null" json:"message"`
+	Details       JSONMap   `gorm:"type:json" json:"details,omitempty"`
+	Delivered     bool      `gorm:"not null" json:"delivered"`
+	DeliveryError string    `gorm:"type:varchar(1000)" json:"delivery_error,omitempty"`
+	CreatedAt     time.Time `gorm:"index" json:"created_at"`
+}
+
+// API key 角色，权限依次递增
+const (
+	RoleReadOnly = "read_only" // 只能读取
+	RoleOperator = "operator"  // 可以管理钱包、地址、告警并触发同步
+	RoleAdmin    = "admin"     // 可以修改 RPC 节点、链、代币规则和 webhook
+)
+
+// roleRanks 定义角色的权限顺序
+var roleRanks = map[string]int{
+	RoleReadOnly: 1,
+	RoleOperator: 2,
+	RoleAdmin:    3,
+}
+
+// IsValidRole 检查角色是否有效
+func IsValidRole(role string) bool {
+	_, ok := roleRanks[role]
+	return ok
+}
+
+// RoleAllows 检查 role 是否具有 required 所需的权限
+func RoleAllows(role, required string) bool {
+	return roleRanks[role] >= roleRanks[required] && roleRanks[role] > 0
+}
+
+// APIKey 表示一个 API key，数据库只保存 key 的 SHA-256 哈希
+type APIKey struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	Name       string     `gorm:"type:varchar(255);not null" json:"name"`
+	KeyPrefix  string     `gorm:"type:varchar(16);not null" json:"key_prefix"`
+	KeyHash    string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	Role       string     `gorm:"type:varchar(20);not null" json:"role"`
+	UserID     *uint      `json:"user_id,omitempty"` // 所属用户，nil 表示不限钱包的服务 key
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// Active 检查 key 是否未吊销且未过期
+func (k *APIKey) Active(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
+
+// User 表示本地用户账号
+type User struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	Username     string    `gorm:"type:varchar(100);not null;uniqueIndex" json:"username"`
+	PasswordHash string    `gorm:"type:varchar(255);not null" json:"-"`
+	Role         string    `gorm:"type:varchar(20);not null" json:"role"`
+	Disabled     bool      `gorm:"not null" json:"disabled"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// UserSession 表示一次登录会话，数据库只保存 token 的 SHA-256 哈希
+type UserSession struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"not null;index" json:"user_id"`
+	TokenHash  string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	ExpiresAt  time.Time  `gorm:"not null" json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+// 钱包共享权限
+const (
+	SharePermissionView = "view" // 只能查看钱包、地址和持仓
+	SharePermissionEdit = "edit" // 还可以修改钱包、管理地址并触发同步
+)
+
+// WalletShare 表示把钱包共享给其他用户
+type WalletShare struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	WalletID   uint      `gorm:"not null;uniqueIndex:uk_wallet_shares_wallet_user" json:"wallet_id"`
+	UserID     uint      `gorm:"not null;uniqueIndex:uk_wallet_shares_wallet_user;index" json:"user_id"`
+	Permission string    `gorm:"type:varchar(10);not null" json:"permission"`
+	CreatedAt  time.Time `json:"created_at"`
+
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+// AuditLog 记录一次修改类 API 请求（POST/PUT/DELETE）及其对实体的影响
+type AuditLog struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Actor       string    `gorm:"type:varchar(255);not null;index" json:"actor"`
+	Method      string    `gorm:"type:varchar(10);not null" json:"method"`
+	Route       string    `gorm:"type:varchar(255);not null" json:"route"` // 路由模板，如 /api/v1/wallets/:id
+	Path        string    `gorm:"type:varchar(500);not null" json:"path"`
+	EntityType  string    `gorm:"type:varchar(50);not null;index:idx_audit_log_entity" json:"entity_type"`
+	EntityID    string    `gorm:"type:varchar(100);not null;index:idx_audit_log_entity" json:"entity_id,omitempty"`
+	StatusCode  int       `gorm:"not null" json:"status_code"`
+	ClientIP    string    `gorm:"type:varchar(64);not null" json:"client_ip"`
+	BeforeState JSONMap   `gorm:"type:json" json:"before,omitempty"`
+	AfterState  JSONMap   `gorm:"type:json" json:"after,omitempty"`
+	Changes     JSONMap   `gorm:"type:json" json:"changes,omitempty"` // {"字段": {"from": 旧值, "to": 新值}}
+	CreatedAt   time.Time `gorm:"index" json:"created_at"`
+}
+
+// TokenTransfer 表示交易中的一笔代币转入或转出
+type TokenTransfer struct {
+	TokenID      string  `json:"token_id"` // 代币合约地址，原生代币为链的原生代币 ID
+	Symbol       string  `json:"symbol,omitempty"`
+	Amount       float64 `json:"amount"`
+	Counterparty string  `json:"counterparty,omitempty"` // 转入时为发送方，转出时为接收方
+}
+
+// TokenTransferList 是用于将代币转账列表存储为 JSON 的自定义类型
+type TokenTransferList []TokenTransfer
+
+// Scan 实现 sql.Scanner 接口
+func (l *TokenTransferList) Scan(value interface{}) error {
+	if value == nil {
+		*l = TokenTransferList{}
+		return nil
+	}
+	bytes, ok := jsonBytes(value)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, l)
+}
+
+// Value 实现 driver.Valuer 接口
+func (l TokenTransferList) Value() (driver.Value, error) {
+	if len(l) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(l)
+}
+
+// Transaction 表示地址在一条链上参与的交易
+type Transaction struct {
+	ID           uint              `gorm:"primaryKey" json:"id"`
+	AddressID    uint              `gorm:"not null;uniqueIndex:uk_transactions_address_chain_hash,priority:1;index:idx_transactions_address_time,priority:1" json:"address_id"`
+	ChainID      string            `gorm:"type:varchar(50);not null;uniqueIndex:uk_transactions_address_chain_hash,priority:2" json:"chain_id"`
+	TxHash       string            `gorm:"type:varchar(100);not null;uniqueIndex:uk_transactions_address_chain_hash,priority:3" json:"tx_hash"`
+	BlockNumber  uint64            `gorm:"not null;default:0" json:"block_number,omitempty"` // 0 表示提供者未返回
+	BlockTime    time.Time         `gorm:"not null;index:idx_transactions_address_time,priority:2" json:"block_time"`
+	Counterparty string            `gorm:"type:varchar(255)" json:"counterparty"`
+	Category     string            `gorm:"type:varchar(50)" json:"category"`     // send、receive、approve 等，空表示一般合约调用
+	ProtocolID   string            `gorm:"type:varchar(255)" json:"protocol_id"` // 交互的协议，未知时为空
+	TransfersIn  TokenTransferList `gorm:"type:json" json:"transfers_in"`
+	TransfersOut TokenTransferList `gorm:"type:json" json:"transfers_out"`
+	Fee          float64           `gorm:"type:decimal(40,18)" json:"fee"` // 原生代币计的手续费，只在地址发起交易时记录
+	FeeUSD       float64           `gorm:"type:decimal(30,6)" json:"fee_usd"`
+	Failed       bool              `gorm:"not null;default:false" json:"failed"`
+	Source       string            `gorm:"type:varchar(20);not null" json:"source"` // 数据来源：debank 或 rpc
+	CreatedAt    time.Time         `json:"created_at"`
+}
+
+// TransactionCursor 记录地址在一条链上交易同步的进度
+type TransactionCursor struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	AddressID uint      `gorm:"not null;uniqueIndex:uk_transaction_cursors_address_chain,priority:1" json:"address_id"`
+	ChainID   string    `gorm:"type:varchar(50);not null;uniqueIndex:uk_transaction_cursors_address_chain,priority:2" json:"chain_id"`
+	Source    string    `gorm:"type:varchar(20);not null" json:"source"`                     // 生成游标的提供者，切换提供者后游标失效，重新回溯
+	Cursor    string    `gorm:"column:next_cursor;type:varchar(100);not null" json:"cursor"` // 提供者定义的游标（如最新交易时间或已扫描的区块）
+	SyncedAt  time.Time `gorm:"not null" json:"synced_at"`
+}
+
 // TableName 覆盖表名
-func (Wallet) TableName() string        { return "wallets" }
-func (Address) TableName() string       { return "addresses" }
-func (AssetSnapshot) TableName() string { return "asset_snapshots" }
-func (Chain) TableName() string         { return "chains" }
-func (Token) TableName() string         { return "tokens" }
-func (SyncJob) TableName() string       { return "sync_jobs" }
-func (RPCNode) TableName() string       { return "rpc_nodes" }
-func (Protocol) TableName() string      { return "protocols" }
+func (Wallet) TableName() string                { return "wallets" }
+func (Address) TableName() string               { return "addresses" }
+func (AssetSnapshot) TableName() string         { return "asset_snapshots" }
+func (Chain) TableName() string                 { return "chains" }
+func (Token) TableName() string                 { return "tokens" }
+func (SyncJob) TableName() string               { return "sync_jobs" }
+func (RPCNode) TableName() string               { return "rpc_nodes" }
+func (Protocol) TableName() string              { return "protocols" }
+func (TokenRule) TableName() string             { return "token_rules" }
+func (TokenOverride) TableName() string         { return "token_overrides" }
+func (TokenOverrideAudit) TableName() string    { return "token_override_audits" }
+func (ProtocolPosition) TableName() string      { return "protocol_positions" }
+func (ProtocolPositionToken) TableName() string { return "protocol_position_tokens" }
+func (HealthRateRecord) TableName() string      { return "health_rate_history" }
+func (HealthThreshold) TableName() string       { return "health_thresholds" }
+func (HealthAlert) TableName() string           { return "health_alerts" }
+func (WebhookEndpoint) TableName() string       { return "webhook_endpoints" }
+func (WebhookDelivery) TableName() string       { return "webhook_deliveries" }
+func (AlertRule) TableName() string             { return "alert_rules" }
+func (AlertEvent) TableName() string            { return "alert_events" }
+func (APIKey) TableName() string                { return "api_keys" }
+func (User) TableName() string                  { return "users" }
+func (UserSession) TableName() string           { return "user_sessions" }
+func (WalletShare) TableName() string           { return "wallet_shares" }
+func (AuditLog) TableName() string              { return "audit_log" }
+func (Transaction) TableName() string           { return "transactions" }
+func (TransactionCursor) TableName() string     { return "transaction_cursors" }
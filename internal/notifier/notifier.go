@@ -0,0 +1,86 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rotki-demo/internal/config"
+)
+
+// 通知严重程度
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// Notification 表示一条待发送的通知
+type Notification struct {
+	Title    string            `json:"title"`
+	Message  string            `json:"message"`
+	Severity string            `json:"severity"`
+	Fields   map[string]string `json:"fields,omitempty"`
+	Time     time.Time         `json:"time"`
+}
+
+// Notifier 定义通知渠道接口
+type Notifier interface {
+	// Name 返回渠道名称
+	Name() string
+	// Notify 发送一条通知
+	Notify(ctx context.Context, n Notification) error
+}
+
+// Multi 将通知发送到多个渠道，任一渠道失败不影响其他渠道
+type Multi []Notifier
+
+// Name 返回所有渠道名称
+func (m Multi) Name() string {
+	names := make([]string, 0, len(m))
+	for _, n := range m {
+		names = append(names, n.Name())
+	}
+	return strings.Join(names, ",")
+}
+
+// Notify 依次发送到所有渠道，返回合并后的错误
+func (m Multi) Notify(ctx context.Context, n Notification) error {
+	if n.Time.IsZero() {
+		n.Time = time.Now()
+	}
+	var errs []error
+	for _, notifier := range m {
+		if err := notifier.Notify(ctx, n); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", notifier.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// New 根据配置创建通知渠道
+func New(cfg config.NotifierConfig) (Multi, error) {
+	notifiers := make(Multi, 0, len(cfg.Channels))
+	for _, channel := range cfg.Channels {
+		switch strings.ToLower(strings.TrimSpace(channel)) {
+		case "stdout":
+			notifiers = append(notifiers, NewStdoutNotifier(nil))
+		case "webhook":
+			if cfg.Webhook.URL == "" {
+				return nil, fmt.Errorf("notifier.webhook.url is required for webhook channel")
+			}
+			notifiers = append(notifiers, NewWebhookNotifier(cfg.Webhook))
+		case "smtp":
+			if cfg.SMTP.Host == "" || cfg.SMTP.From == "" || len(cfg.SMTP.To) == 0 {
+				return nil, fmt.Errorf("notifier.smtp.host, from and to are required for smtp channel")
+			}
+			notifiers = append(notifiers, NewSMTPNotifier(cfg.SMTP))
+		case "":
+		default:
+			return nil, fmt.Errorf("unknown notifier channel: %s", channel)
+		}
+	}
+	return notifiers, nil
+}
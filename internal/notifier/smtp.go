@@ -0,0 +1,81 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rotki-demo/internal/config"
+)
+
+// SMTPNotifier 通过邮件发送通知
+type SMTPNotifier struct {
+	cfg config.SMTPNotifierConfig
+}
+
+// NewSMTPNotifier 创建一个新的邮件通知渠道
+func NewSMTPNotifier(cfg config.SMTPNotifierConfig) *SMTPNotifier {
+	return &SMTPNotifier{cfg: cfg}
+}
+
+// Name 返回渠道名称
+func (s *SMTPNotifier) Name() string {
+	return "smtp"
+}
+
+// Notify 发送纯文本邮件；配置了用户名时使用 PLAIN 认证
+func (s *SMTPNotifier) Notify(ctx context.Context, n Notification) error {
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+
+	var auth smtp.Auth
+	if s.cfg.Username != "" {
+		auth = smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)
+	}
+
+	// net/smtp 不支持 context，在单独的 goroutine 中发送以便响应取消
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- smtp.SendMail(addr, auth, s.cfg.From, s.cfg.To, s.buildMessage(n))
+	}()
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			return fmt.Errorf("failed to send mail: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// buildMessage 构造邮件内容
+func (s *SMTPNotifier) buildMessage(n Notification) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", s.cfg.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(s.cfg.To, ", "))
+	fmt.Fprintf(&b, "Subject: [%s] %s\r\n", strings.ToUpper(n.Severity), n.Title)
+	fmt.Fprintf(&b, "Date: %s\r\n", n.Time.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(n.Message)
+	b.WriteString("\r\n")
+
+	keys := make([]string, 0, len(n.Fields))
+	for k := range n.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if len(keys) > 0 {
+		b.WriteString("\r\n")
+	}
+	for _, k := range keys {
+		fmt.Fprintf(&b, "%s: %s\r\n", k, n.Fields[k])
+	}
+	return []byte(b.String())
+}
@@ -0,0 +1,44 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+)
+
+// StdoutNotifier 将通知写到标准输出，便于本地测试
+type StdoutNotifier struct {
+	w io.Writer
+}
+
+// NewStdoutNotifier 创建一个新的标准输出通知渠道，w 为 nil 时使用 os.Stdout
+func NewStdoutNotifier(w io.Writer) *StdoutNotifier {
+	if w == nil {
+		w = os.Stdout
+	}
+	return &StdoutNotifier{w: w}
+}
+
+// Name 返回渠道名称
+func (s *StdoutNotifier) Name() string {
+	return "stdout"
+}
+
+// Notify 以单行文本输出通知
+func (s *StdoutNotifier) Notify(_ context.Context, n Notification) error {
+	keys := make([]string, 0, len(n.Fields))
+	for k := range n.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	line := fmt.Sprintf("[%s] [%s] %s: %s", n.Time.Format(time.RFC3339), n.Severity, n.Title, n.Message)
+	for _, k := range keys {
+		line += fmt.Sprintf(" %s=%s", k, n.Fields[k])
+	}
+	_, err := fmt.Fprintln(s.w, line)
+	return err
+}
@@ -0,0 +1,60 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/rotki-demo/internal/config"
+)
+
+// WebhookNotifier 以 JSON POST 将通知发送到配置的 URL
+type WebhookNotifier struct {
+	cfg    config.WebhookNotifierConfig
+	client *http.Client
+}
+
+// NewWebhookNotifier 创建一个新的 webhook 通知渠道
+func NewWebhookNotifier(cfg config.WebhookNotifierConfig) *WebhookNotifier {
+	return &WebhookNotifier{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.GetTimeout()},
+	}
+}
+
+// Name 返回渠道名称
+func (w *WebhookNotifier) Name() string {
+	return "webhook"
+}
+
+// Notify 发送通知，非 2xx 响应视为失败
+func (w *WebhookNotifier) Notify(ctx context.Context, n Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range w.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
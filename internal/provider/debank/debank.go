@@ -6,16 +6,24 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/rotki-demo/internal/config"
 	"github.com/rotki-demo/internal/logger"
+	"github.com/rotki-demo/internal/metrics"
 	"github.com/rotki-demo/internal/provider"
+	"github.com/rotki-demo/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"golang.org/x/time/rate"
 )
 
+// tracer 是 DeBank 请求 span 的埋点范围
+var tracer = tracing.Tracer("github.com/rotki-demo/internal/provider/debank")
+
 // DeBankProvider 使用 DeBank API 实现 DataProvider 接口
 type DeBankProvider struct {
 	config      *config.DeBankConfig
@@ -40,15 +48,35 @@ func NewDeBankProvider(cfg *config.DeBankConfig) *DeBankProvider {
 	}
 }
 
+// SetRateLimit 修改速率限制，配置热加载时调用，正在等待的请求按新速率继续
+func (d *DeBankProvider) SetRateLimit(cfg config.RateLimitConfig) {
+	d.rateLimiter.SetLimit(rate.Limit(cfg.RequestsPerSecond))
+	d.rateLimiter.SetBurst(cfg.Burst)
+}
+
 // GetName 返回提供者名称
 func (d *DeBankProvider) GetName() string {
 	return "debank"
 }
 
 // doRequest 执行带速率限制的 HTTP 请求
-func (d *DeBankProvider) doRequest(ctx context.Context, path string, params map[string]string) ([]byte, error) {
+func (d *DeBankProvider) doRequest(ctx context.Context, path string, params map[string]string) (body []byte, err error) {
+	// span 包含等待速率限制器的时间，等待时长单独记录为属性
+	ctx, span := tracer.Start(ctx, "DeBank GET "+path,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", "GET"),
+			attribute.String("url.path", path),
+		))
+	defer func() { tracing.End(span, err) }()
+
 	// 等待速率限制器
-	if err := d.rateLimiter.Wait(ctx); err != nil {
+	waitStart := time.Now()
+	err = d.rateLimiter.Wait(ctx)
+	waited := time.Since(waitStart)
+	metrics.ObserveRateLimiterWait(waited)
+	span.SetAttributes(attribute.Float64("rate_limiter.wait_seconds", waited.Seconds()))
+	if err != nil {
 		return nil, fmt.Errorf("rate limiter error: %w", err)
 	}
 
@@ -79,14 +107,18 @@ func (d *DeBankProvider) doRequest(ctx context.Context, path string, params map[
 	)
 
 	// 执行请求
+	start := time.Now()
 	resp, err := d.httpClient.Do(req)
 	if err != nil {
+		metrics.ObserveDeBankRequest(path, 0, time.Since(start))
 		return nil, fmt.Errorf("failed to execute request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// 读取响应体
-	body, err := io.ReadAll(resp.Body)
+	body, err = io.ReadAll(resp.Body)
+	metrics.ObserveDeBankRequest(path, resp.StatusCode, time.Since(start))
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
@@ -251,6 +283,45 @@ func (d *DeBankProvider) GetUsedChainList(ctx context.Context, address string) (
 	return result, nil
 }
 
+// GetChainList 返回 DeBank 支持的所有链
+func (d *DeBankProvider) GetChainList(ctx context.Context) ([]provider.ChainInfo, error) {
+	body, err := d.doRequest(ctx, "/v1/chain/list", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var chains []struct {
+		ChainID       string `json:"id"`
+		CommunityID   int64  `json:"community_id"`
+		Name          string `json:"name"`
+		LogoURL       string `json:"logo_url"`
+		NativeTokenID string `json:"native_token_id"`
+	}
+
+	if err := json.Unmarshal(body, &chains); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	result := make([]provider.ChainInfo, len(chains))
+	for i, chain := range chains {
+		result[i] = provider.ChainInfo{
+			ChainID:       chain.ChainID,
+			Name:          chain.Name,
+			LogoURL:       chain.LogoURL,
+			NativeTokenID: chain.NativeTokenID,
+			NetworkID:     chain.CommunityID,
+		}
+	}
+
+	return result, nil
+}
+
+// Ping 查询 API key 的剩余额度，该接口不消耗额度，可用于检查 DeBank 是否可达及 API key 是否有效
+func (d *DeBankProvider) Ping(ctx context.Context) error {
+	_, err := d.doRequest(ctx, "/v1/account/units", nil)
+	return err
+}
+
 // GetProtocolList 返回 DeFi 协议持仓
 func (d *DeBankProvider) GetProtocolList(ctx context.Context, address string, chainIDs []string) ([]provider.ProtocolInfo, error) {
 	params := map[string]string{
@@ -298,8 +369,12 @@ func (d *DeBankProvider) GetProtocolList(ctx context.Context, address string, ch
 			Detail         struct {
 				SupplyTokenList []debankToken `json:"supply_token_list"`
 				BorrowTokenList []debankToken `json:"borrow_token_list"`
+				RewardTokenList []debankToken `json:"reward_token_list"`
 				HealthRate      float64       `json:"health_rate"`
 			} `json:"detail"`
+			Pool struct {
+				ID string `json:"id"`
+			} `json:"pool"`
 		} `json:"portfolio_item_list"`
 	}
 
@@ -349,6 +424,12 @@ func (d *DeBankProvider) GetProtocolList(ctx context.Context, address string, ch
 				borrowTokens[k] = convertToken(token, proto.Chain, true)
 			}
 
+			// 转换 reward_token_list
+			rewardTokens := make([]provider.TokenDetail, len(item.Detail.RewardTokenList))
+			for k, token := range item.Detail.RewardTokenList {
+				rewardTokens[k] = convertToken(token, proto.Chain, false)
+			}
+
 			// 提取主要的 position type
 			positionType := "unknown"
 			if len(item.PositionType) > 0 {
@@ -358,12 +439,15 @@ func (d *DeBankProvider) GetProtocolList(ctx context.Context, address string, ch
 			portfolioItems[j] = provider.PortfolioItem{
 				Name:            item.Name,
 				PositionType:    positionType,
+				DetailTypes:     item.PositionType,
+				PoolID:          item.Pool.ID,
 				NetUSDValue:     item.Stats.NetUSDValue,
 				AssetUSDValue:   item.Stats.AssetUSDValue,
 				DebtUSDValue:    item.Stats.DebtUSDValue,
 				AssetTokenList:  assetTokens,
 				SupplyTokenList: supplyTokens,
 				BorrowTokenList: borrowTokens,
+				RewardTokenList: rewardTokens,
 				HealthRate:      item.Detail.HealthRate,
 			}
 		}
@@ -391,3 +475,196 @@ func (d *DeBankProvider) GetProtocolList(ctx context.Context, address string, ch
 
 	return result, nil
 }
+
+const (
+	// historyPageCount 是 history_list 每页的交易数（DeBank 允许的最大值）
+	historyPageCount = 20
+	// maxHistoryPages 是一次增量获取最多请求的页数，避免长时间未同步的地址消耗过多额度
+	maxHistoryPages = 50
+)
+
+// historyCursor 是 history_list 的翻页位置
+// 完整获取后游标只有最新交易的 Unix 时间；达到页数上限时还记录下次继续向旧翻页的起点和原来的停止时间，
+// 格式为 "<newest>,<before>,<stopAt>"，继续翻到 stopAt 后游标恢复为最新交易时间
+type historyCursor struct {
+	newest float64 // 已获取的最新交易时间，翻页完成后作为下次增量获取的停止时间
+	before float64 // 继续翻页的 start_time，0 表示没有未完成的翻页
+	stopAt float64 // 本轮翻页的停止时间（上次的游标或首次同步的 since）
+}
+
+// parseHistoryCursor 解析游标，空游标表示首次同步，回溯到 since
+func parseHistoryCursor(cursor string, since time.Time) (historyCursor, error) {
+	if cursor == "" {
+		start := float64(since.Unix())
+		return historyCursor{newest: start, stopAt: start}, nil
+	}
+
+	parts := strings.Split(cursor, ",")
+	if len(parts) != 1 && len(parts) != 3 {
+		return historyCursor{}, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	values := make([]float64, len(parts))
+	for i, part := range parts {
+		value, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return historyCursor{}, fmt.Errorf("invalid cursor %q: %w", cursor, err)
+		}
+		values[i] = value
+	}
+	if len(values) == 1 {
+		return historyCursor{newest: values[0], stopAt: values[0]}, nil
+	}
+	return historyCursor{newest: values[0], before: values[1], stopAt: values[2]}, nil
+}
+
+// String 返回保存到数据库的游标
+func (c historyCursor) String() string {
+	newest := strconv.FormatFloat(c.newest, 'f', -1, 64)
+	if c.before == 0 {
+		return newest
+	}
+	return newest + "," + strconv.FormatFloat(c.before, 'f', -1, 64) + "," + strconv.FormatFloat(c.stopAt, 'f', -1, 64)
+}
+
+// GetTransactionHistory 通过 history_list 从新到旧翻页，直到游标（上次获取的最新交易时间）或 since。
+// 同一时间的交易会被重复返回，由调用方按交易哈希去重。被 DeBank 标记为垃圾的交易会被跳过。
+// 达到页数上限时返回 HasMore 和记录翻页位置的游标，下次从最旧的已获取交易继续，不会跳过较早的交易
+func (d *DeBankProvider) GetTransactionHistory(ctx context.Context, address, chainID, cursor string, since time.Time) (*provider.TransactionHistory, error) {
+	state, err := parseHistoryCursor(cursor, since)
+	if err != nil {
+		return nil, err
+	}
+	stopAt := state.stopAt
+
+	type debankTransfer struct {
+		Amount   float64 `json:"amount"`
+		TokenID  string  `json:"token_id"`
+		FromAddr string  `json:"from_addr"`
+		ToAddr   string  `json:"to_addr"`
+	}
+
+	var (
+		transactions []provider.TransactionInfo
+		newest       = state.newest
+		startTime    = state.before
+		reached      bool
+	)
+	pages := 0
+	for ; pages < maxHistoryPages && !reached; pages++ {
+		params := map[string]string{
+			"id":         address,
+			"chain_id":   chainID,
+			"page_count": strconv.Itoa(historyPageCount),
+		}
+		if startTime > 0 {
+			params["start_time"] = strconv.FormatInt(int64(startTime), 10)
+		}
+
+		body, err := d.doRequest(ctx, "/v1/user/history_list", params)
+		if err != nil {
+			return nil, err
+		}
+
+		var response struct {
+			HistoryList []struct {
+				ID        string           `json:"id"`
+				Chain     string           `json:"chain"`
+				CateID    string           `json:"cate_id"`
+				ProjectID string           `json:"project_id"`
+				OtherAddr string           `json:"other_addr"`
+				TimeAt    float64          `json:"time_at"`
+				IsScam    bool             `json:"is_scam"`
+				Sends     []debankTransfer `json:"sends"`
+				Receives  []debankTransfer `json:"receives"`
+				Tx        *struct {
+					FromAddr  string  `json:"from_addr"`
+					Status    int     `json:"status"`
+					EthGasFee float64 `json:"eth_gas_fee"`
+					USDGasFee float64 `json:"usd_gas_fee"`
+				} `json:"tx"`
+			} `json:"history_list"`
+			TokenDict map[string]struct {
+				Symbol string `json:"symbol"`
+			} `json:"token_dict"`
+		}
+		if err := json.Unmarshal(body, &response); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+		}
+		if len(response.HistoryList) == 0 {
+			reached = true
+			break
+		}
+
+		convert := func(transfers []debankTransfer, outgoing bool) []provider.TokenTransfer {
+			result := make([]provider.TokenTransfer, 0, len(transfers))
+			for _, transfer := range transfers {
+				counterparty := transfer.FromAddr
+				if outgoing {
+					counterparty = transfer.ToAddr
+				}
+				result = append(result, provider.TokenTransfer{
+					TokenID:      transfer.TokenID,
+					Symbol:       response.TokenDict[transfer.TokenID].Symbol,
+					Amount:       transfer.Amount,
+					Counterparty: counterparty,
+				})
+			}
+			return result
+		}
+
+		for _, item := range response.HistoryList {
+			// 列表按时间倒序，早于游标的交易已在上次获取
+			if item.TimeAt < stopAt {
+				reached = true
+				break
+			}
+			if item.TimeAt > newest {
+				newest = item.TimeAt
+			}
+			if item.IsScam {
+				continue
+			}
+
+			tx := provider.TransactionInfo{
+				Hash:         item.ID,
+				ChainID:      item.Chain,
+				BlockTime:    time.Unix(int64(item.TimeAt), 0),
+				Counterparty: item.OtherAddr,
+				Category:     item.CateID,
+				ProtocolID:   item.ProjectID,
+				TransfersIn:  convert(item.Receives, false),
+				TransfersOut: convert(item.Sends, true),
+			}
+			if item.Tx != nil {
+				tx.Failed = item.Tx.Status == 0
+				if strings.EqualFold(item.Tx.FromAddr, address) {
+					tx.Fee = item.Tx.EthGasFee
+					tx.FeeUSD = item.Tx.USDGasFee
+				}
+			}
+			transactions = append(transactions, tx)
+		}
+
+		if len(response.HistoryList) < historyPageCount {
+			reached = true
+			break
+		}
+		startTime = response.HistoryList[len(response.HistoryList)-1].TimeAt
+	}
+
+	// 调用方按时间升序保存
+	for i, j := 0, len(transactions)-1; i < j; i, j = i+1, j-1 {
+		transactions[i], transactions[j] = transactions[j], transactions[i]
+	}
+
+	next := historyCursor{newest: newest, stopAt: stopAt}
+	if !reached {
+		// 达到页数上限，下次从本次获取的最旧交易时间继续翻页
+		next.before = startTime
+	}
+	return &provider.TransactionHistory{
+		Transactions: transactions,
+		NextCursor:   next.String(),
+		HasMore:      !reached,
+	}, nil
+}
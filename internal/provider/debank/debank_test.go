@@ -0,0 +1,201 @@
+package debank
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rotki-demo/internal/config"
+	"github.com/rotki-demo/internal/logger"
+)
+
+func TestMain(m *testing.M) {
+	if err := logger.InitLogger(&config.LogConfig{Level: "warn", Output: "stdout"}); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+func TestHistoryCursor(t *testing.T) {
+	since := time.Unix(1700000000, 0)
+	tests := []struct {
+		name    string
+		cursor  string
+		want    historyCursor
+		wantErr bool
+	}{
+		{name: "first sync", cursor: "", want: historyCursor{newest: 1700000000, stopAt: 1700000000}},
+		{name: "complete", cursor: "1700000500", want: historyCursor{newest: 1700000500, stopAt: 1700000500}},
+		{name: "fractional", cursor: "1700000500.25", want: historyCursor{newest: 1700000500.25, stopAt: 1700000500.25}},
+		{name: "paging", cursor: "1700000500,1700000300,1700000100", want: historyCursor{newest: 1700000500, before: 1700000300, stopAt: 1700000100}},
+		{name: "two parts", cursor: "1700000500,1700000300", wantErr: true},
+		{name: "not a number", cursor: "latest", wantErr: true},
+		{name: "bad part", cursor: "1700000500,x,1700000100", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseHistoryCursor(tt.cursor, since)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseHistoryCursor(%q) = %+v, want error", tt.cursor, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseHistoryCursor(%q): %v", tt.cursor, err)
+			}
+			if got != tt.want {
+				t.Fatalf("parseHistoryCursor(%q) = %+v, want %+v", tt.cursor, got, tt.want)
+			}
+			// 保存的游标可以原样解析回来
+			if tt.cursor != "" && got.String() != tt.cursor {
+				t.Fatalf("String() = %q, want %q", got.String(), tt.cursor)
+			}
+		})
+	}
+}
+
+// historyItem 是模拟的 history_list 中的一笔交易
+type historyItem struct {
+	ID     string  `json:"id"`
+	Chain  string  `json:"chain"`
+	TimeAt float64 `json:"time_at"`
+	IsScam bool    `json:"is_scam"`
+	Tx     struct {
+		FromAddr  string  `json:"from_addr"`
+		Status    int     `json:"status"`
+		EthGasFee float64 `json:"eth_gas_fee"`
+	} `json:"tx"`
+}
+
+// historyServer 模拟 DeBank 的 history_list：按时间倒序，start_time 返回更早的交易
+type historyServer struct {
+	mu       sync.Mutex
+	items    []historyItem // 按时间倒序
+	requests int
+}
+
+func (s *historyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+
+	if r.URL.Path != "/v1/user/history_list" {
+		http.NotFound(w, r)
+		return
+	}
+	pageCount, _ := strconv.Atoi(r.URL.Query().Get("page_count"))
+	startTime, _ := strconv.ParseFloat(r.URL.Query().Get("start_time"), 64)
+	page := []historyItem{}
+	for _, item := range s.items {
+		if startTime > 0 && item.TimeAt >= startTime {
+			continue
+		}
+		if len(page) == pageCount {
+			break
+		}
+		page = append(page, item)
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"history_list": page, "token_dict": map[string]interface{}{}})
+}
+
+// add 添加时间为 base+from 到 base+to-1 的交易，时间个位为 5 的标记为垃圾交易
+func (s *historyServer) add(base, from, to int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := from; i < to; i++ {
+		item := historyItem{ID: fmt.Sprintf("0x%d", i), Chain: "eth", TimeAt: float64(base + i), IsScam: i%10 == 5}
+		item.Tx.Status = 1
+		s.items = append([]historyItem{item}, s.items...)
+	}
+}
+
+func TestGetTransactionHistory(t *testing.T) {
+	const base = 1700000000
+	mock := &historyServer{}
+	mock.add(base, 0, 1100)
+	server := httptest.NewServer(mock)
+	defer server.Close()
+
+	d := NewDeBankProvider(&config.DeBankConfig{
+		APIKey:    "test",
+		BaseURL:   server.URL,
+		RateLimit: config.RateLimitConfig{RequestsPerSecond: 10000, Burst: 100},
+		Timeout:   5,
+	})
+	ctx := context.Background()
+
+	seen := make(map[string]bool)
+	fetch := func(cursor string) (string, bool) {
+		t.Helper()
+		history, err := d.GetTransactionHistory(ctx, "0xme", "eth", cursor, time.Unix(base+50, 0))
+		if err != nil {
+			t.Fatalf("GetTransactionHistory(%q): %v", cursor, err)
+		}
+		for i, tx := range history.Transactions {
+			if i > 0 && tx.BlockTime.Before(history.Transactions[i-1].BlockTime) {
+				t.Fatalf("transactions are not in ascending order at %d", i)
+			}
+			seen[tx.Hash] = true
+		}
+		return history.NextCursor, history.HasMore
+	}
+
+	// 首次同步只回溯到 since，超过页数上限时游标记录继续翻页的位置
+	cursor, more := fetch("")
+	if want := fmt.Sprintf("%d,%d,%d", base+1099, base+100, base+50); !more || cursor != want {
+		t.Fatalf("first fetch = %q, more %t, want %q, more true", cursor, more, want)
+	}
+	if mock.requests != maxHistoryPages {
+		t.Fatalf("first fetch made %d requests, want %d", mock.requests, maxHistoryPages)
+	}
+
+	// 继续翻页到原来的停止时间后，游标恢复为最新交易时间
+	cursor, more = fetch(cursor)
+	if want := strconv.Itoa(base + 1099); more || cursor != want {
+		t.Fatalf("second fetch = %q, more %t, want %q, more false", cursor, more, want)
+	}
+	for i := 0; i < 1100; i++ {
+		want := i >= 50 && i%10 != 5
+		if hash := fmt.Sprintf("0x%d", i); seen[hash] != want {
+			t.Fatalf("transaction %s seen = %t, want %t", hash, seen[hash], want)
+		}
+	}
+
+	// 增量获取只返回游标之后的交易，游标时间上的交易重复返回
+	mock.add(base, 1100, 1103)
+	mock.mu.Lock()
+	mock.items[0].Tx.FromAddr = "0xME"
+	mock.items[0].Tx.EthGasFee = 0.01
+	mock.items[1].Tx.Status = 0
+	mock.mu.Unlock()
+
+	history, err := d.GetTransactionHistory(ctx, "0xme", "eth", cursor, time.Unix(base+50, 0))
+	if err != nil {
+		t.Fatalf("GetTransactionHistory: %v", err)
+	}
+	var hashes []string
+	for _, tx := range history.Transactions {
+		hashes = append(hashes, tx.Hash)
+	}
+	if fmt.Sprint(hashes) != "[0x1099 0x1100 0x1101 0x1102]" {
+		t.Fatalf("incremental fetch returned %v, want [0x1099 0x1100 0x1101 0x1102]", hashes)
+	}
+	if want := strconv.Itoa(base + 1102); history.HasMore || history.NextCursor != want {
+		t.Fatalf("incremental fetch cursor = %q, more %t, want %q, more false", history.NextCursor, history.HasMore, want)
+	}
+	if tx := history.Transactions[3]; tx.Fee != 0.01 || tx.Failed {
+		t.Fatalf("own transaction fee %v failed %t, want fee 0.01 and not failed", tx.Fee, tx.Failed)
+	}
+	if tx := history.Transactions[2]; tx.Fee != 0 || !tx.Failed {
+		t.Fatalf("incoming transaction fee %v failed %t, want no fee and failed", tx.Fee, tx.Failed)
+	}
+}
@@ -2,9 +2,13 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
+// ErrChainNotSupported 表示提供者无法获取该链的数据（如没有配置 RPC 节点）
+var ErrChainNotSupported = errors.New("chain not supported by provider")
+
 // DataProvider 定义区块链数据提供者的接口
 // 此抽象允许在不同数据源之间切换（DeBank、自查询等）
 type DataProvider interface {
@@ -20,10 +24,60 @@ type DataProvider interface {
 	// GetProtocolList 返回地址的 DeFi 协议持仓
 	GetProtocolList(ctx context.Context, address string, chainIDs []string) ([]ProtocolInfo, error)
 
+	// GetChainList 返回提供者支持的所有链
+	GetChainList(ctx context.Context) ([]ChainInfo, error)
+
+	// GetTransactionHistory 增量获取地址在链上的交易，见 TransactionHistoryProvider
+	GetTransactionHistory(ctx context.Context, address, chainID, cursor string, since time.Time) (*TransactionHistory, error)
+
+	// Ping 检查提供者是否可达且凭证有效，应使用不消耗配额的轻量请求
+	Ping(ctx context.Context) error
+
 	// GetName 返回提供者名称（例如 "debank"、"self-query"）
 	GetName() string
 }
 
+// TransactionHistoryProvider 提供交易历史，DataProvider 和基于 RPC 日志的实现都满足此接口
+type TransactionHistoryProvider interface {
+	// GetTransactionHistory 获取地址在链上游标之后的交易。cursor 为空表示首次同步，只回溯到 since；
+	// 游标的格式由提供者决定，调用方原样保存返回的 NextCursor 即可。不支持的链返回 ErrChainNotSupported
+	GetTransactionHistory(ctx context.Context, address, chainID, cursor string, since time.Time) (*TransactionHistory, error)
+
+	// GetName 返回提供者名称，游标只对生成它的提供者有效
+	GetName() string
+}
+
+// TransactionHistory 是一次增量获取的结果
+type TransactionHistory struct {
+	Transactions []TransactionInfo `json:"transactions"`
+	NextCursor   string            `json:"next_cursor"` // 下次获取时传入的游标
+	HasMore      bool              `json:"has_more"`    // 达到单次获取的上限，可以立即用 NextCursor 继续获取
+}
+
+// TransactionInfo 表示地址参与的一笔交易
+type TransactionInfo struct {
+	Hash         string          `json:"hash"`
+	ChainID      string          `json:"chain_id"`
+	BlockNumber  uint64          `json:"block_number"` // 0 表示提供者未返回
+	BlockTime    time.Time       `json:"block_time"`
+	Counterparty string          `json:"counterparty"` // 交易对手：地址发起时为接收方合约或地址，否则为发起方
+	Category     string          `json:"category"`     // send、receive、approve 等，空表示一般合约调用
+	ProtocolID   string          `json:"protocol_id"`  // 交互的协议，未知时为空
+	TransfersIn  []TokenTransfer `json:"transfers_in"`
+	TransfersOut []TokenTransfer `json:"transfers_out"`
+	Fee          float64         `json:"fee"`     // 原生代币计的手续费，只在地址发起交易时记录
+	FeeUSD       float64         `json:"fee_usd"` // 手续费的 USD 价值，提供者未返回时为 0
+	Failed       bool            `json:"failed"`
+}
+
+// TokenTransfer 表示交易中的一笔代币转入或转出
+type TokenTransfer struct {
+	TokenID      string  `json:"token_id"` // 代币合约地址，原生代币为链的原生代币 ID
+	Symbol       string  `json:"symbol,omitempty"`
+	Amount       float64 `json:"amount"`
+	Counterparty string  `json:"counterparty,omitempty"` // 转入时为发送方，转出时为接收方
+}
+
 // TotalBalanceResponse 表示所有链的总余额
 type TotalBalanceResponse struct {
 	TotalUSDValue float64        `json:"total_usd_value"`
@@ -61,6 +115,7 @@ type ChainInfo struct {
 	Name          string    `json:"name"`
 	LogoURL       string    `json:"logo_url"`
 	NativeTokenID string    `json:"native_token_id"`
+	NetworkID     int64     `json:"network_id,omitempty"` // 数字链 ID（EIP-155）
 	BornAt        time.Time `json:"born_at,omitempty"`
 }
 
@@ -81,12 +136,15 @@ type ProtocolInfo struct {
 type PortfolioItem struct {
 	Name            string        `json:"name"`
 	PositionType    string        `json:"position_type"` // deposit、borrow、stake 等
+	DetailTypes     []string      `json:"detail_types"`  // 提供者返回的所有类型
+	PoolID          string        `json:"pool_id"`
 	NetUSDValue     float64       `json:"net_usd_value"`
 	AssetUSDValue   float64       `json:"asset_usd_value"`
 	DebtUSDValue    float64       `json:"debt_usd_value"`
 	AssetTokenList  []TokenDetail `json:"asset_token_list"`  // 所有代币（包含负值的debt）
 	SupplyTokenList []TokenDetail `json:"supply_token_list"` // 只包含供应的代币
 	BorrowTokenList []TokenDetail `json:"borrow_token_list"` // 只包含借出的代币
+	RewardTokenList []TokenDetail `json:"reward_token_list"` // 待领取的奖励代币
 	HealthRate      float64       `json:"health_rate"`       // 健康因子
 }
 
@@ -0,0 +1,97 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/rotki-demo/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer 是数据提供者 span 的埋点范围
+var tracer = tracing.Tracer("github.com/rotki-demo/internal/provider")
+
+// tracedProvider 为 DataProvider 的每个方法创建 span，记录提供者名称、地址和结果数量
+type tracedProvider struct {
+	next DataProvider
+}
+
+// NewTracedProvider 用链路追踪包装数据提供者
+func NewTracedProvider(next DataProvider) DataProvider {
+	return &tracedProvider{next: next}
+}
+
+// start 开始一个提供者方法的 span
+func (p *tracedProvider) start(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, attribute.String("provider.name", p.next.GetName()))
+	return tracer.Start(ctx, "DataProvider."+method, trace.WithAttributes(attrs...))
+}
+
+// GetTotalBalance 实现 DataProvider
+func (p *tracedProvider) GetTotalBalance(ctx context.Context, address string) (*TotalBalanceResponse, error) {
+	ctx, span := p.start(ctx, "GetTotalBalance", attribute.String("address", address))
+	result, err := p.next.GetTotalBalance(ctx, address)
+	tracing.End(span, err)
+	return result, err
+}
+
+// GetTokenList 实现 DataProvider
+func (p *tracedProvider) GetTokenList(ctx context.Context, address string, chainIDs []string) ([]TokenInfo, error) {
+	ctx, span := p.start(ctx, "GetTokenList", attribute.String("address", address), attribute.StringSlice("chain_ids", chainIDs))
+	tokens, err := p.next.GetTokenList(ctx, address, chainIDs)
+	span.SetAttributes(attribute.Int("result.count", len(tokens)))
+	tracing.End(span, err)
+	return tokens, err
+}
+
+// GetUsedChainList 实现 DataProvider
+func (p *tracedProvider) GetUsedChainList(ctx context.Context, address string) ([]ChainInfo, error) {
+	ctx, span := p.start(ctx, "GetUsedChainList", attribute.String("address", address))
+	chains, err := p.next.GetUsedChainList(ctx, address)
+	span.SetAttributes(attribute.Int("result.count", len(chains)))
+	tracing.End(span, err)
+	return chains, err
+}
+
+// GetProtocolList 实现 DataProvider
+func (p *tracedProvider) GetProtocolList(ctx context.Context, address string, chainIDs []string) ([]ProtocolInfo, error) {
+	ctx, span := p.start(ctx, "GetProtocolList", attribute.String("address", address), attribute.StringSlice("chain_ids", chainIDs))
+	protocols, err := p.next.GetProtocolList(ctx, address, chainIDs)
+	span.SetAttributes(attribute.Int("result.count", len(protocols)))
+	tracing.End(span, err)
+	return protocols, err
+}
+
+// GetChainList 实现 DataProvider
+func (p *tracedProvider) GetChainList(ctx context.Context) ([]ChainInfo, error) {
+	ctx, span := p.start(ctx, "GetChainList")
+	chains, err := p.next.GetChainList(ctx)
+	span.SetAttributes(attribute.Int("result.count", len(chains)))
+	tracing.End(span, err)
+	return chains, err
+}
+
+// GetTransactionHistory 实现 DataProvider
+func (p *tracedProvider) GetTransactionHistory(ctx context.Context, address, chainID, cursor string, since time.Time) (*TransactionHistory, error) {
+	ctx, span := p.start(ctx, "GetTransactionHistory", attribute.String("address", address), attribute.String("chain_id", chainID))
+	history, err := p.next.GetTransactionHistory(ctx, address, chainID, cursor, since)
+	if history != nil {
+		span.SetAttributes(attribute.Int("result.count", len(history.Transactions)))
+	}
+	tracing.End(span, err)
+	return history, err
+}
+
+// Ping 实现 DataProvider
+func (p *tracedProvider) Ping(ctx context.Context) error {
+	ctx, span := p.start(ctx, "Ping")
+	err := p.next.Ping(ctx)
+	tracing.End(span, err)
+	return err
+}
+
+// GetName 实现 DataProvider
+func (p *tracedProvider) GetName() string {
+	return p.next.GetName()
+}
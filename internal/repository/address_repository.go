@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"time"
 
 	"github.com/rotki-demo/internal/models"
@@ -17,6 +18,11 @@ func NewAddressRepository(db *gorm.DB) *AddressRepository {
 	return &AddressRepository{db: db}
 }
 
+// WithContext 返回使用指定上下文的仓库副本，查询会作为上下文中 span 的子 span 记录
+func (r *AddressRepository) WithContext(ctx context.Context) *AddressRepository {
+	return &AddressRepository{db: r.db.WithContext(ctx)}
+}
+
 // Create 创建一个新地址
 func (r *AddressRepository) Create(address *models.Address) error {
 	return r.db.Create(address).Error
@@ -32,10 +38,11 @@ func (r *AddressRepository) GetByID(id uint) (*models.Address, error) {
 	return &address, nil
 }
 
-// GetByAddress 根据地址字符串和链类型获取地址
-func (r *AddressRepository) GetByAddress(addr string, chainType string) (*models.Address, error) {
+// GetByWalletAndAddress 根据钱包、地址字符串和链类型获取地址
+// 同一地址可以出现在不同钱包中，只在钱包内唯一
+func (r *AddressRepository) GetByWalletAndAddress(walletID uint, addr string, chainType string) (*models.Address, error) {
 	var address models.Address
-	err := r.db.Where("address = ? AND chain_type = ?", addr, chainType).
+	err := r.db.Where("wallet_id = ? AND address = ? AND chain_type = ?", walletID, addr, chainType).
 		Preload("Wallet").
 		Preload("Tokens").
 		First(&address).Error
@@ -54,10 +61,10 @@ func (r *AddressRepository) GetByWalletID(walletID uint) ([]models.Address, erro
 	return addresses, err
 }
 
-// List 获取所有地址
-func (r *AddressRepository) List() ([]models.Address, error) {
+// List 获取范围内的所有地址
+func (r *AddressRepository) List(scope WalletScope) ([]models.Address, error) {
 	var addresses []models.Address
-	err := r.db.Preload("Wallet").Preload("Tokens").Find(&addresses).Error
+	err := scope.applyWallet(r.db, "wallet_id").Preload("Wallet").Preload("Tokens").Find(&addresses).Error
 	return addresses, err
 }
 
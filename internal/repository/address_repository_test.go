@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rotki-demo/internal/models"
+	"gorm.io/gorm"
+)
+
+func TestAddressRepositoryUniquePerWallet(t *testing.T) {
+	db := newTestDB(t)
+	alice, _ := createWallet(t, db, "alice")
+	bob, _ := createWallet(t, db, "bob")
+	repo := NewAddressRepository(db)
+
+	// 不同钱包可以跟踪同一地址
+	for _, walletID := range []uint{alice.ID, bob.ID} {
+		if err := repo.Create(&models.Address{WalletID: walletID, Address: "0xaaa", ChainType: "EVM"}); err != nil {
+			t.Fatalf("create address in wallet %d: %v", walletID, err)
+		}
+	}
+
+	// 同一钱包内重复时返回统一的 gorm.ErrDuplicatedKey，处理器据此返回 409
+	err := repo.Create(&models.Address{WalletID: bob.ID, Address: "0xaaa", ChainType: "EVM"})
+	if !errors.Is(err, gorm.ErrDuplicatedKey) {
+		t.Fatalf("duplicate create error = %v, want gorm.ErrDuplicatedKey", err)
+	}
+
+	address, err := repo.GetByWalletAndAddress(bob.ID, "0xaaa", "EVM")
+	if err != nil {
+		t.Fatalf("GetByWalletAndAddress: %v", err)
+	}
+	if address.WalletID != bob.ID {
+		t.Fatalf("wallet id = %d, want %d", address.WalletID, bob.ID)
+	}
+}
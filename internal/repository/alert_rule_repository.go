@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/rotki-demo/internal/models"
+	"gorm.io/gorm"
+)
+
+// AlertRuleRepository 处理余额变化告警规则和告警记录的数据操作
+type AlertRuleRepository struct {
+	db *gorm.DB
+}
+
+// NewAlertRuleRepository 创建一个新的告警规则仓库
+func NewAlertRuleRepository(db *gorm.DB) *AlertRuleRepository {
+	return &AlertRuleRepository{db: db}
+}
+
+// Create 创建规则
+func (r *AlertRuleRepository) Create(rule *models.AlertRule) error {
+	return r.db.Create(rule).Error
+}
+
+// GetByID 根据 ID 获取规则
+func (r *AlertRuleRepository) GetByID(id uint) (*models.AlertRule, error) {
+	var rule models.AlertRule
+	err := r.db.First(&rule, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// List 获取范围内的规则，作用于所有钱包或标签的规则总是包含在内
+func (r *AlertRuleRepository) List(scope WalletScope) ([]models.AlertRule, error) {
+	var rules []models.AlertRule
+	query := r.db.Order("id")
+	if scope.Restricted {
+		query = query.Where(
+			r.db.Where("scope_type IN ?", []string{models.AlertScopeAll, models.AlertScopeTag}).
+				Or(scope.applyWallet(r.db.Where("scope_type = ?", models.AlertScopeWallet), "scope_id")).
+				Or(scope.applyAddress(r.db, r.db.Where("scope_type = ?", models.AlertScopeAddress), "scope_id")),
+		)
+	}
+	err := query.Find(&rules).Error
+	return rules, err
+}
+
+// ListEnabled 获取所有启用的规则
+func (r *AlertRuleRepository) ListEnabled() ([]models.AlertRule, error) {
+	var rules []models.AlertRule
+	err := r.db.Where("enabled = ?", true).Order("id").Find(&rules).Error
+	return rules, err
+}
+
+// Update 更新规则
+func (r *AlertRuleRepository) Update(rule *models.AlertRule) error {
+	return r.db.Save(rule).Error
+}
+
+// Delete 删除规则及其告警记录
+func (r *AlertRuleRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("rule_id = ?", id).Delete(&models.AlertEvent{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.AlertRule{}, id).Error
+	})
+}
+
+// LastEventTime 获取规则对地址最近一次告警的时间，没有告警时返回 nil
+func (r *AlertRuleRepository) LastEventTime(ruleID, addressID uint) (*time.Time, error) {
+	var events []models.AlertEvent
+	err := r.db.Select("created_at").
+		Where("rule_id = ? AND address_id = ?", ruleID, addressID).
+		Order("created_at DESC").
+		Limit(1).
+		Find(&events).Error
+	if err != nil || len(events) == 0 {
+		return nil, err
+	}
+	return &events[0].CreatedAt, nil
+}
+
+// CreateEvent 写入告警记录
+func (r *AlertRuleRepository) CreateEvent(event *models.AlertEvent) error {
+	return r.db.Create(event).Error
+}
+
+// UpdateEventDelivery 更新告警的投递结果
+func (r *AlertRuleRepository) UpdateEventDelivery(id uint, delivered bool, deliveryError string) error {
+	return r.db.Model(&models.AlertEvent{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"delivered":      delivered,
+			"delivery_error": deliveryError,
+		}).Error
+}
+
+// AlertEventFilter 过滤告警记录，零值字段不参与过滤
+type AlertEventFilter struct {
+	RuleID    uint
+	WalletID  uint
+	AddressID uint
+	RuleType  string
+	Scope     WalletScope
+}
+
+// ListEvents 获取满足过滤条件的告警记录，按时间倒序
+func (r *AlertRuleRepository) ListEvents(filter AlertEventFilter, limit int) ([]models.AlertEvent, error) {
+	var events []models.AlertEvent
+	query := filter.Scope.applyWallet(r.db, "wallet_id")
+	if filter.RuleID != 0 {
+		query = query.Where("rule_id = ?", filter.RuleID)
+	}
+	if filter.WalletID != 0 {
+		query = query.Where("wallet_id = ?", filter.WalletID)
+	}
+	if filter.AddressID != 0 {
+		query = query.Where("address_id = ?", filter.AddressID)
+	}
+	if filter.RuleType != "" {
+		query = query.Where("rule_type = ?", filter.RuleType)
+	}
+	err := query.Order("created_at DESC, id DESC").Limit(limit).Find(&events).Error
+	return events, err
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/rotki-demo/internal/models"
+	"gorm.io/gorm"
+)
+
+// APIKeyRepository 处理 API key 的数据操作
+type APIKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyRepository 创建一个新的 API key 仓库
+func NewAPIKeyRepository(db *gorm.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+// Create 创建 API key
+func (r *APIKeyRepository) Create(key *models.APIKey) error {
+	return r.db.Create(key).Error
+}
+
+// GetByID 根据 ID 获取 API key
+func (r *APIKeyRepository) GetByID(id uint) (*models.APIKey, error) {
+	var key models.APIKey
+	err := r.db.First(&key, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// GetByHash 根据 key 哈希获取 API key
+func (r *APIKeyRepository) GetByHash(hash string) (*models.APIKey, error) {
+	var key models.APIKey
+	err := r.db.Where("key_hash = ?", hash).First(&key).Error
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// List 获取所有 API key
+func (r *APIKeyRepository) List() ([]models.APIKey, error) {
+	var keys []models.APIKey
+	err := r.db.Order("id").Find(&keys).Error
+	return keys, err
+}
+
+// Revoke 吊销 API key
+func (r *APIKeyRepository) Revoke(id uint, at time.Time) error {
+	return r.db.Model(&models.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at).Error
+}
+
+// UpdateLastUsed 更新最后使用时间
+func (r *APIKeyRepository) UpdateLastUsed(id uint, at time.Time) error {
+	return r.db.Model(&models.APIKey{}).Where("id = ?", id).Update("last_used_at", at).Error
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/rotki-demo/internal/models"
+	"gorm.io/gorm"
+)
+
+// AuditLogRepository 处理审计日志的数据操作
+type AuditLogRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditLogRepository 创建一个新的审计日志仓库
+func NewAuditLogRepository(db *gorm.DB) *AuditLogRepository {
+	return &AuditLogRepository{db: db}
+}
+
+// Create 写入审计记录
+func (r *AuditLogRepository) Create(entry *models.AuditLog) error {
+	return r.db.Create(entry).Error
+}
+
+// AuditLogFilter 过滤审计记录，零值字段不参与过滤
+type AuditLogFilter struct {
+	EntityType string
+	EntityID   string
+	Actor      string
+	Method     string
+	Since      *time.Time
+	Until      *time.Time
+	BeforeID   uint // 只返回 ID 小于该值的记录，用于翻页
+}
+
+// List 获取满足过滤条件的审计记录，按时间倒序
+func (r *AuditLogRepository) List(filter AuditLogFilter, limit int) ([]models.AuditLog, error) {
+	var entries []models.AuditLog
+	query := r.db
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.Actor != "" {
+		query = query.Where("actor = ?", filter.Actor)
+	}
+	if filter.Method != "" {
+		query = query.Where("method = ?", filter.Method)
+	}
+	if filter.Since != nil {
+		query = query.Where("created_at >= ?", *filter.Since)
+	}
+	if filter.Until != nil {
+		query = query.Where("created_at < ?", *filter.Until)
+	}
+	if filter.BeforeID != 0 {
+		query = query.Where("id < ?", filter.BeforeID)
+	}
+	err := query.Order("id DESC").Limit(limit).Find(&entries).Error
+	return entries, err
+}
+
+// DeleteOlderThan 删除早于指定时间的审计记录，返回删除的数量
+func (r *AuditLogRepository) DeleteOlderThan(before time.Time) (int64, error) {
+	result := r.db.Where("created_at < ?", before).Delete(&models.AuditLog{})
+	return result.RowsAffected, result.Error
+}
+
+// LoadEntity 按主键加载任意实体（不预加载关联），用于记录修改前后的状态
+func (r *AuditLogRepository) LoadEntity(dest interface{}, id string) error {
+	return r.db.Where("id = ?", id).First(dest).Error
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rotki-demo/internal/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// backupInsertBatch 是恢复时批量插入的行数
+const backupInsertBatch = 500
+
+// BackupRepository 为备份和恢复提供与表无关的读写操作
+type BackupRepository struct {
+	db *gorm.DB
+}
+
+// NewBackupRepository 创建一个新的备份仓库
+func NewBackupRepository(db *gorm.DB) *BackupRepository {
+	return &BackupRepository{db: db}
+}
+
+// WithContext 返回使用指定上下文的仓库副本
+func (r *BackupRepository) WithContext(ctx context.Context) *BackupRepository {
+	return &BackupRepository{db: r.db.WithContext(ctx)}
+}
+
+// Transaction 在事务中执行 fn，fn 返回错误时回滚
+func (r *BackupRepository) Transaction(fn func(tx *BackupRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&BackupRepository{db: tx})
+	})
+}
+
+// LoadAll 按主键顺序读取表中的所有行，dest 为模型切片的指针
+func (r *BackupRepository) LoadAll(dest interface{}) error {
+	return r.db.Order("id").Find(dest).Error
+}
+
+// Count 返回模型对应表的行数
+func (r *BackupRepository) Count(model interface{}) (int64, error) {
+	var count int64
+	err := r.db.Model(model).Count(&count).Error
+	return count, err
+}
+
+// FindID 返回 column 等于 value 的行的 ID，不存在时返回 gorm.ErrRecordNotFound
+func (r *BackupRepository) FindID(model interface{}, column string, value interface{}) (uint, error) {
+	var ids []uint
+	if err := r.db.Model(model).Where(column+" = ?", value).Limit(1).Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, gorm.ErrRecordNotFound
+	}
+	return ids[0], nil
+}
+
+// Create 插入一行，ID 由数据库分配并回填；关联字段不会被写入
+func (r *BackupRepository) Create(value interface{}) error {
+	return r.db.Omit(clause.Associations).Create(value).Error
+}
+
+// CreateInBatches 分批插入切片，用于快照等没有被其他表引用的大表
+func (r *BackupRepository) CreateInBatches(value interface{}) error {
+	return r.db.Omit(clause.Associations).CreateInBatches(value, backupInsertBatch).Error
+}
+
+// Upsert 插入切片，key 列冲突时更新 columns 列
+func (r *BackupRepository) Upsert(value interface{}, key string, columns []string) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: key}},
+		DoUpdates: clause.AssignmentColumns(columns),
+	}).Create(value).Error
+}
+
+// MigrationState 返回已应用的最新迁移版本和待执行的迁移数量
+func (r *BackupRepository) MigrationState() (version uint, pending int, err error) {
+	migrator, err := database.NewDefaultMigrator(r.db)
+	if err != nil {
+		return 0, 0, err
+	}
+	statuses, err := migrator.Status()
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, status := range statuses {
+		if !status.Applied {
+			pending++
+		} else if status.Version > version {
+			version = status.Version
+		}
+	}
+	return version, pending, nil
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"context"
+
 	"github.com/rotki-demo/internal/models"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -16,19 +18,42 @@ func NewChainRepository(db *gorm.DB) *ChainRepository {
 	return &ChainRepository{db: db}
 }
 
-// UpsertBatch 插入或更新多个链
-func (r *ChainRepository) UpsertBatch(chains []models.Chain) error {
+// WithContext 返回使用指定上下文的仓库副本，查询会作为上下文中 span 的子 span 记录
+func (r *ChainRepository) WithContext(ctx context.Context) *ChainRepository {
+	return &ChainRepository{db: r.db.WithContext(ctx)}
+}
+
+// DefaultChainUpdateColumns 是 UpsertBatch 冲突时默认更新的列
+// is_active 由管理员控制，任何 upsert 都不会修改它
+var DefaultChainUpdateColumns = []string{"name", "logo_url", "native_token_id"}
+
+// ChainMetadataColumns 是从 chains.json 重新同步元数据时更新的列
+var ChainMetadataColumns = []string{"name", "chain_type", "logo_url", "native_token_id", "network_id", "explorer_url"}
+
+// UpsertBatch 插入或更新多个链，updateColumns 为空时使用 DefaultChainUpdateColumns
+func (r *ChainRepository) UpsertBatch(chains []models.Chain, updateColumns ...string) error {
 	if len(chains) == 0 {
 		return nil
 	}
 
-	// 为 MySQL 使用 ON DUPLICATE KEY UPDATE
+	if len(updateColumns) == 0 {
+		updateColumns = DefaultChainUpdateColumns
+	}
+
+	chains = dedupeLast(chains, func(c models.Chain) string { return c.ID })
+
+	// GORM 按方言生成 ON DUPLICATE KEY UPDATE（MySQL）或 ON CONFLICT（PostgreSQL/SQLite）
 	return r.db.Clauses(clause.OnConflict{
 		Columns:   []clause.Column{{Name: "id"}},
-		DoUpdates: clause.AssignmentColumns([]string{"name", "logo_url", "native_token_id"}),
+		DoUpdates: clause.AssignmentColumns(updateColumns),
 	}).Create(&chains).Error
 }
 
+// Create 创建一个新链
+func (r *ChainRepository) Create(chain *models.Chain) error {
+	return r.db.Create(chain).Error
+}
+
 // GetByID 根据 ID 获取链
 func (r *ChainRepository) GetByID(chainID string) (*models.Chain, error) {
 	var chain models.Chain
@@ -39,27 +64,33 @@ func (r *ChainRepository) GetByID(chainID string) (*models.Chain, error) {
 	return &chain, nil
 }
 
-// List 获取所有链
+// List 获取所有已激活（支持）的链
 func (r *ChainRepository) List() ([]models.Chain, error) {
-	// 只返回我们支持的链
-	supportedChainIDs := []string{
-		"eth",    // Ethereum
-		"arb",    // Arbitrum
-		"op",     // Optimism
-		"base",   // Base
-		"linea",  // Linea
-		"uni",    // Unichain
-		"plasma", // Plasma
-		"scrl",   // Scroll
-		"plume",  // Plume
-		"matic",  // Polygon
-		"ink",    // Ink
-		"hyper",  // HyperEVM
-		"bsc",    // BSC
-		"bera",   // Berachain
-	}
+	var chains []models.Chain
+	err := r.db.Where("is_active = ?", true).Order("id").Find(&chains).Error
+	return chains, err
+}
 
+// ListAll 获取注册表中的所有链，包括未激活的链
+func (r *ChainRepository) ListAll() ([]models.Chain, error) {
 	var chains []models.Chain
-	err := r.db.Where("id IN ?", supportedChainIDs).Find(&chains).Error
+	err := r.db.Order("id").Find(&chains).Error
 	return chains, err
 }
+
+// Update 更新链
+func (r *ChainRepository) Update(chain *models.Chain) error {
+	return r.db.Save(chain).Error
+}
+
+// CountTokens 返回引用该链的代币数量
+func (r *ChainRepository) CountTokens(chainID string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Token{}).Where("chain_id = ?", chainID).Count(&count).Error
+	return count, err
+}
+
+// Delete 删除链
+func (r *ChainRepository) Delete(chainID string) error {
+	return r.db.Where("id = ?", chainID).Delete(&models.Chain{}).Error
+}
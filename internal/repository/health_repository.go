@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"github.com/rotki-demo/internal/models"
+	"gorm.io/gorm"
+)
+
+// HealthRepository 处理健康因子历史、告警阈值和告警记录的数据操作
+type HealthRepository struct {
+	db *gorm.DB
+}
+
+// NewHealthRepository 创建一个新的健康因子仓库
+func NewHealthRepository(db *gorm.DB) *HealthRepository {
+	return &HealthRepository{db: db}
+}
+
+// HealthHistoryFilter 过滤健康因子历史，零值字段不参与过滤
+type HealthHistoryFilter struct {
+	AddressID   uint
+	ProtocolID  string
+	ChainID     string
+	PositionKey string
+	Scope       WalletScope
+}
+
+// apply 将过滤条件添加到查询
+func (f HealthHistoryFilter) apply(db *gorm.DB) *gorm.DB {
+	query := f.Scope.applyAddress(db, db, "address_id")
+	if f.AddressID != 0 {
+		query = query.Where("address_id = ?", f.AddressID)
+	}
+	if f.ProtocolID != "" {
+		query = query.Where("protocol_id = ?", f.ProtocolID)
+	}
+	if f.ChainID != "" {
+		query = query.Where("chain_id = ?", f.ChainID)
+	}
+	if f.PositionKey != "" {
+		query = query.Where("position_key = ?", f.PositionKey)
+	}
+	return query
+}
+
+// RecordHealthRate 写入一条健康因子历史
+func (r *HealthRepository) RecordHealthRate(record *models.HealthRateRecord) error {
+	return r.db.Create(record).Error
+}
+
+// LatestHealthRate 获取持仓最近一次记录的健康因子
+func (r *HealthRepository) LatestHealthRate(addressID uint, protocolID, chainID, positionKey string) (*models.HealthRateRecord, error) {
+	var record models.HealthRateRecord
+	err := r.db.Where("address_id = ? AND protocol_id = ? AND chain_id = ? AND position_key = ?",
+		addressID, protocolID, chainID, positionKey).
+		Order("recorded_at DESC, id DESC").
+		First(&record).Error
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// ListHistory 获取满足过滤条件的健康因子历史，按时间倒序
+func (r *HealthRepository) ListHistory(filter HealthHistoryFilter, limit int) ([]models.HealthRateRecord, error) {
+	var records []models.HealthRateRecord
+	err := filter.apply(r.db).Order("recorded_at DESC, id DESC").Limit(limit).Find(&records).Error
+	return records, err
+}
+
+// ListThresholds 获取范围内的钱包阈值和全局阈值
+func (r *HealthRepository) ListThresholds(scope WalletScope) ([]models.HealthThreshold, error) {
+	var thresholds []models.HealthThreshold
+	err := scope.applyWalletOrGlobal(r.db, "wallet_id").Order("wallet_id, protocol_id, position_key").Find(&thresholds).Error
+	return thresholds, err
+}
+
+// GetThresholdByID 根据 ID 获取告警阈值
+func (r *HealthRepository) GetThresholdByID(id uint) (*models.HealthThreshold, error) {
+	var threshold models.HealthThreshold
+	err := r.db.First(&threshold, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &threshold, nil
+}
+
+// CreateThreshold 创建告警阈值
+func (r *HealthRepository) CreateThreshold(threshold *models.HealthThreshold) error {
+	return r.db.Create(threshold).Error
+}
+
+// UpdateThreshold 更新告警阈值
+func (r *HealthRepository) UpdateThreshold(threshold *models.HealthThreshold) error {
+	return r.db.Save(threshold).Error
+}
+
+// DeleteThreshold 删除告警阈值
+func (r *HealthRepository) DeleteThreshold(id uint) error {
+	return r.db.Delete(&models.HealthThreshold{}, id).Error
+}
+
+// FindThresholds 获取可能适用于持仓的告警阈值（包括全局和通配设置）
+func (r *HealthRepository) FindThresholds(walletID uint, protocolID, positionKey string) ([]models.HealthThreshold, error) {
+	var thresholds []models.HealthThreshold
+	err := r.db.Where("wallet_id IN ?", []uint{0, walletID}).
+		Where("protocol_id IN ?", []string{"", protocolID}).
+		Where("position_key IN ?", []string{"", positionKey}).
+		Find(&thresholds).Error
+	return thresholds, err
+}
+
+// HealthAlertFilter 过滤告警记录，零值字段不参与过滤
+type HealthAlertFilter struct {
+	WalletID  uint
+	AddressID uint
+	AlertType string
+	Scope     WalletScope
+}
+
+// CreateAlert 写入告警记录
+func (r *HealthRepository) CreateAlert(alert *models.HealthAlert) error {
+	return r.db.Create(alert).Error
+}
+
+// UpdateAlertDelivery 更新告警的投递结果
+func (r *HealthRepository) UpdateAlertDelivery(id uint, delivered bool, deliveryError string) error {
+	return r.db.Model(&models.HealthAlert{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"delivered":      delivered,
+			"delivery_error": deliveryError,
+		}).Error
+}
+
+// ListAlerts 获取满足过滤条件的告警记录，按时间倒序
+func (r *HealthRepository) ListAlerts(filter HealthAlertFilter, limit int) ([]models.HealthAlert, error) {
+	var alerts []models.HealthAlert
+	query := filter.Scope.applyWallet(r.db, "wallet_id")
+	if filter.WalletID != 0 {
+		query = query.Where("wallet_id = ?", filter.WalletID)
+	}
+	if filter.AddressID != 0 {
+		query = query.Where("address_id = ?", filter.AddressID)
+	}
+	if filter.AlertType != "" {
+		query = query.Where("alert_type = ?", filter.AlertType)
+	}
+	err := query.Order("created_at DESC, id DESC").Limit(limit).Find(&alerts).Error
+	return alerts, err
+}
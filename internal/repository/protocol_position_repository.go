@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rotki-demo/internal/models"
+	"gorm.io/gorm"
+)
+
+// ProtocolPositionRepository 处理协议持仓明细的数据库操作
+type ProtocolPositionRepository struct {
+	db *gorm.DB
+}
+
+// NewProtocolPositionRepository 创建一个新的协议持仓仓库
+func NewProtocolPositionRepository(db *gorm.DB) *ProtocolPositionRepository {
+	return &ProtocolPositionRepository{db: db}
+}
+
+// WithContext 返回使用指定上下文的仓库副本，查询会作为上下文中 span 的子 span 记录
+func (r *ProtocolPositionRepository) WithContext(ctx context.Context) *ProtocolPositionRepository {
+	return &ProtocolPositionRepository{db: r.db.WithContext(ctx)}
+}
+
+// PositionFilter 过滤协议持仓，零值字段不参与过滤
+type PositionFilter struct {
+	AddressID    uint
+	WalletID     uint
+	ProtocolID   string
+	ChainID      string
+	PositionType string
+	Scope        WalletScope
+}
+
+// ReplaceByAddressID 用新的持仓替换地址的所有持仓（包括持仓代币）
+func (r *ProtocolPositionRepository) ReplaceByAddressID(addressID uint, positions []models.ProtocolPosition) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// 显式删除子行，不依赖数据库的级联删除设置
+		if err := tx.Where("position_id IN (?)",
+			tx.Model(&models.ProtocolPosition{}).Select("id").Where("address_id = ?", addressID),
+		).Delete(&models.ProtocolPositionToken{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("address_id = ?", addressID).Delete(&models.ProtocolPosition{}).Error; err != nil {
+			return err
+		}
+		if len(positions) == 0 {
+			return nil
+		}
+		// Create 会同时插入 Tokens 关联
+		return tx.Create(&positions).Error
+	})
+}
+
+// GetByID 根据 ID 获取持仓及其代币
+func (r *ProtocolPositionRepository) GetByID(id uint) (*models.ProtocolPosition, error) {
+	var position models.ProtocolPosition
+	err := r.db.Preload("Tokens").First(&position, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &position, nil
+}
+
+// List 获取满足过滤条件的持仓及其代币，按净值降序
+func (r *ProtocolPositionRepository) List(filter PositionFilter) ([]models.ProtocolPosition, error) {
+	query := filter.Scope.applyAddress(r.db, r.db.Model(&models.ProtocolPosition{}), "address_id")
+	if filter.AddressID != 0 {
+		query = query.Where("address_id = ?", filter.AddressID)
+	}
+	if filter.WalletID != 0 {
+		query = query.Where("address_id IN (?)",
+			r.db.Model(&models.Address{}).Select("id").Where("wallet_id = ?", filter.WalletID),
+		)
+	}
+	if filter.ProtocolID != "" {
+		query = query.Where("protocol_id = ?", filter.ProtocolID)
+	}
+	if filter.ChainID != "" {
+		query = query.Where("chain_id = ?", filter.ChainID)
+	}
+	if filter.PositionType != "" {
+		query = query.Where("position_type = ?", filter.PositionType)
+	}
+
+	var positions []models.ProtocolPosition
+	err := query.Preload("Tokens").Order("net_usd_value DESC, id").Find(&positions).Error
+	return positions, err
+}
@@ -1,6 +1,9 @@
 package repository
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/rotki-demo/internal/models"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -16,6 +19,11 @@ func NewProtocolRepository(db *gorm.DB) *ProtocolRepository {
 	return &ProtocolRepository{db: db}
 }
 
+// WithContext 返回使用指定上下文的仓库副本，查询会作为上下文中 span 的子 span 记录
+func (r *ProtocolRepository) WithContext(ctx context.Context) *ProtocolRepository {
+	return &ProtocolRepository{db: r.db.WithContext(ctx)}
+}
+
 // GetByAddressID 根据地址 ID 获取所有协议
 func (r *ProtocolRepository) GetByAddressID(addressID uint) ([]models.Protocol, error) {
 	var protocols []models.Protocol
@@ -35,6 +43,10 @@ func (r *ProtocolRepository) UpsertBatch(protocols []models.Protocol) error {
 		return nil
 	}
 
+	protocols = dedupeLast(protocols, func(p models.Protocol) string {
+		return fmt.Sprintf("%d|%s", p.AddressID, p.ProtocolID)
+	})
+
 	// 使用 ON CONFLICT 更新或插入
 	return r.db.Clauses(clause.OnConflict{
 		Columns: []clause.Column{
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"os"
+	"testing"
+
+	"github.com/rotki-demo/internal/config"
+	"github.com/rotki-demo/internal/database"
+	"github.com/rotki-demo/internal/logger"
+	"github.com/rotki-demo/internal/models"
+	"gorm.io/gorm"
+)
+
+func TestMain(m *testing.M) {
+	// 迁移过程的 Info 日志在测试中没有意义
+	if err := logger.InitLogger(&config.LogConfig{Level: "warn", Output: "stdout"}); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+// newTestDB 打开一个独立的 SQLite 内存数据库并应用所有迁移，使测试覆盖真实的表结构和唯一索引
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := database.Open(&config.DatabaseConfig{Driver: config.DriverSQLite, Path: ":memory:"})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	migrator, err := database.NewDefaultMigrator(db)
+	if err != nil {
+		t.Fatalf("NewDefaultMigrator: %v", err)
+	}
+	if err := migrator.Up(0); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+// createWallet 创建一个钱包及其地址
+func createWallet(t *testing.T, db *gorm.DB, name string, addresses ...string) (*models.Wallet, []models.Address) {
+	t.Helper()
+	wallet := &models.Wallet{Name: name}
+	if err := db.Create(wallet).Error; err != nil {
+		t.Fatalf("create wallet: %v", err)
+	}
+
+	result := make([]models.Address, 0, len(addresses))
+	for _, addr := range addresses {
+		address := models.Address{WalletID: wallet.ID, Address: addr, ChainType: "EVM"}
+		if err := db.Create(&address).Error; err != nil {
+			t.Fatalf("create address: %v", err)
+		}
+		result = append(result, address)
+	}
+	return wallet, result
+}
+
+// ensureChain 确保代币引用的链存在，常见的链已由迁移写入
+func ensureChain(t *testing.T, db *gorm.DB, id string) {
+	t.Helper()
+	if err := db.Where("id = ?", id).Attrs(models.Chain{ID: id, Name: id}).FirstOrCreate(&models.Chain{}).Error; err != nil {
+		t.Fatalf("ensure chain: %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/rotki-demo/internal/models"
 	"gorm.io/gorm"
@@ -71,7 +72,7 @@ func (r *RPCNodeRepository) Update(ctx context.Context, node *models.RPCNode) er
 
 // UpdateConnectionStatus 更新 RPC 节点的连接状态
 func (r *RPCNodeRepository) UpdateConnectionStatus(ctx context.Context, id uint, isConnected bool) error {
-	now := gorm.Expr("NOW()")
+	now := time.Now()
 	return r.db.WithContext(ctx).Model(&models.RPCNode{}).
 		Where("id = ?", id).
 		Updates(map[string]interface{}{
@@ -80,6 +81,20 @@ func (r *RPCNodeRepository) UpdateConnectionStatus(ctx context.Context, id uint,
 		}).Error
 }
 
+// UpdateCapabilities 更新 RPC 节点的能力探测结果
+func (r *RPCNodeRepository) UpdateCapabilities(ctx context.Context, node *models.RPCNode) error {
+	return r.db.WithContext(ctx).Model(&models.RPCNode{}).
+		Where("id = ?", node.ID).
+		Updates(map[string]interface{}{
+			"is_archive":              node.IsArchive,
+			"supports_trace":          node.SupportsTrace,
+			"supports_debug":          node.SupportsDebug,
+			"supports_websocket":      node.SupportsWebSocket,
+			"max_logs_block_range":    node.MaxLogsBlockRange,
+			"capabilities_checked_at": node.CapabilitiesCheckedAt,
+		}).Error
+}
+
 // Delete 删除 RPC 节点
 func (r *RPCNodeRepository) Delete(ctx context.Context, id uint) error {
 	return r.db.WithContext(ctx).Delete(&models.RPCNode{}, id).Error
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"github.com/rotki-demo/internal/models"
+	"gorm.io/gorm"
+)
+
+// WalletScope 限定查询可以访问的钱包。零值不做限制（供后台任务等内部调用使用），
+// Restricted 为 true 时只能访问 WalletIDs 中的钱包，WalletIDs 为空表示没有可访问的钱包
+type WalletScope struct {
+	Restricted bool
+	WalletIDs  []uint
+}
+
+// Allows 检查范围是否包含钱包
+func (s WalletScope) Allows(walletID uint) bool {
+	if !s.Restricted {
+		return true
+	}
+	for _, id := range s.WalletIDs {
+		if id == walletID {
+			return true
+		}
+	}
+	return false
+}
+
+// applyWallet 按钱包 ID 列限制查询
+func (s WalletScope) applyWallet(query *gorm.DB, column string) *gorm.DB {
+	if !s.Restricted {
+		return query
+	}
+	if len(s.WalletIDs) == 0 {
+		return query.Where("1 = 0")
+	}
+	return query.Where(column+" IN ?", s.WalletIDs)
+}
+
+// applyAddress 按地址 ID 列所属的钱包限制查询
+func (s WalletScope) applyAddress(db, query *gorm.DB, column string) *gorm.DB {
+	if !s.Restricted {
+		return query
+	}
+	return query.Where(column+" IN (?)", s.applyWallet(db.Model(&models.Address{}).Select("id"), "wallet_id"))
+}
+
+// applyWalletOrGlobal 按钱包 ID 列限制查询，同时保留 wallet_id 为 0 的全局记录
+func (s WalletScope) applyWalletOrGlobal(query *gorm.DB, column string) *gorm.DB {
+	if !s.Restricted {
+		return query
+	}
+	return query.Where(column+" IN ?", append([]uint{0}, s.WalletIDs...))
+}
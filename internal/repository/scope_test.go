@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/rotki-demo/internal/models"
+)
+
+func TestWalletScopeAllows(t *testing.T) {
+	tests := []struct {
+		name     string
+		scope    WalletScope
+		walletID uint
+		want     bool
+	}{
+		{name: "unrestricted", scope: WalletScope{}, walletID: 7, want: true},
+		{name: "unrestricted ignores ids", scope: WalletScope{WalletIDs: []uint{1}}, walletID: 7, want: true},
+		{name: "in scope", scope: WalletScope{Restricted: true, WalletIDs: []uint{1, 7}}, walletID: 7, want: true},
+		{name: "out of scope", scope: WalletScope{Restricted: true, WalletIDs: []uint{1}}, walletID: 7, want: false},
+		{name: "empty restricted scope", scope: WalletScope{Restricted: true}, walletID: 7, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.scope.Allows(tt.walletID); got != tt.want {
+				t.Fatalf("Allows(%d) = %t, want %t", tt.walletID, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWalletScopeFilters(t *testing.T) {
+	db := newTestDB(t)
+	alice, aliceAddrs := createWallet(t, db, "alice", "0xa1")
+	bob, bobAddrs := createWallet(t, db, "bob", "0xb1")
+
+	for _, addr := range []models.Address{aliceAddrs[0], bobAddrs[0]} {
+		position := models.ProtocolPosition{AddressID: addr.ID, ProtocolID: "aave", ChainID: "eth", Name: addr.Address, PositionType: "lending"}
+		if err := db.Create(&position).Error; err != nil {
+			t.Fatalf("create position: %v", err)
+		}
+	}
+	for _, walletID := range []uint{0, alice.ID, bob.ID} {
+		override := models.TokenOverride{ChainID: "eth", TokenID: "0xtoken", WalletID: walletID, Action: "ignore"}
+		if err := db.Create(&override).Error; err != nil {
+			t.Fatalf("create override: %v", err)
+		}
+	}
+
+	walletRepo := NewWalletRepository(db)
+	addressRepo := NewAddressRepository(db)
+	positionRepo := NewProtocolPositionRepository(db)
+	overrideRepo := NewTokenOverrideRepository(db)
+
+	tests := []struct {
+		name          string
+		scope         WalletScope
+		wantWallets   []uint
+		wantOverrides []uint // 覆盖设置的 wallet_id，全局设置始终可见
+	}{
+		{name: "unrestricted", scope: WalletScope{}, wantWallets: []uint{alice.ID, bob.ID}, wantOverrides: []uint{0, alice.ID, bob.ID}},
+		{name: "single wallet", scope: WalletScope{Restricted: true, WalletIDs: []uint{alice.ID}}, wantWallets: []uint{alice.ID}, wantOverrides: []uint{0, alice.ID}},
+		{name: "no wallets", scope: WalletScope{Restricted: true}, wantWallets: []uint{}, wantOverrides: []uint{0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wallets, err := walletRepo.List(tt.scope)
+			if err != nil {
+				t.Fatalf("wallets: %v", err)
+			}
+			got := []uint{}
+			for _, wallet := range wallets {
+				got = append(got, wallet.ID)
+			}
+			assertIDs(t, "wallets", got, tt.wantWallets)
+
+			addresses, err := addressRepo.List(tt.scope)
+			if err != nil {
+				t.Fatalf("addresses: %v", err)
+			}
+			got = []uint{}
+			for _, address := range addresses {
+				got = append(got, address.WalletID)
+			}
+			assertIDs(t, "address wallets", got, tt.wantWallets)
+
+			// 持仓按地址所属的钱包过滤
+			positions, err := positionRepo.List(PositionFilter{Scope: tt.scope})
+			if err != nil {
+				t.Fatalf("positions: %v", err)
+			}
+			got = []uint{}
+			for _, position := range positions {
+				address, err := addressRepo.GetByID(position.AddressID)
+				if err != nil {
+					t.Fatalf("GetByID: %v", err)
+				}
+				got = append(got, address.WalletID)
+			}
+			assertIDs(t, "position wallets", got, tt.wantWallets)
+
+			overrides, err := overrideRepo.List(TokenOverrideFilter{Scope: tt.scope})
+			if err != nil {
+				t.Fatalf("overrides: %v", err)
+			}
+			got = []uint{}
+			for _, override := range overrides {
+				got = append(got, override.WalletID)
+			}
+			assertIDs(t, "override wallets", got, tt.wantOverrides)
+		})
+	}
+}
+
+// assertIDs 不考虑顺序比较两组 ID
+func assertIDs(t *testing.T, what string, got, want []uint) {
+	t.Helper()
+	got, want = slices.Clone(got), slices.Clone(want)
+	slices.Sort(got)
+	slices.Sort(want)
+	if !slices.Equal(got, want) {
+		t.Fatalf("%s = %v, want %v", what, got, want)
+	}
+}
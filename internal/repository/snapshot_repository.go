@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/rotki-demo/internal/models"
+	"gorm.io/gorm"
+)
+
+// SnapshotRepository 处理资产快照数据操作
+type SnapshotRepository struct {
+	db *gorm.DB
+}
+
+// NewSnapshotRepository 创建一个新的快照仓库
+func NewSnapshotRepository(db *gorm.DB) *SnapshotRepository {
+	return &SnapshotRepository{db: db}
+}
+
+// Create 创建快照
+func (r *SnapshotRepository) Create(snapshot *models.AssetSnapshot) error {
+	return r.db.Create(snapshot).Error
+}
+
+// GetLatestByAddressID 获取地址最近一次快照
+func (r *SnapshotRepository) GetLatestByAddressID(addressID uint) (*models.AssetSnapshot, error) {
+	var snapshot models.AssetSnapshot
+	err := r.db.Where("address_id = ?", addressID).
+		Order("snapshot_time DESC, id DESC").
+		First(&snapshot).Error
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// ListByAddressID 获取地址的快照，按时间倒序
+func (r *SnapshotRepository) ListByAddressID(addressID uint, limit int) ([]models.AssetSnapshot, error) {
+	var snapshots []models.AssetSnapshot
+	err := r.db.Where("address_id = ?", addressID).
+		Order("snapshot_time DESC, id DESC").
+		Limit(limit).
+		Find(&snapshots).Error
+	return snapshots, err
+}
+
+// ListAddressIDs 返回有快照的地址 ID
+func (r *SnapshotRepository) ListAddressIDs() ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&models.AssetSnapshot{}).Distinct("address_id").Order("address_id").Pluck("address_id", &ids).Error
+	return ids, err
+}
+
+// ListTimesBefore 返回地址在 before 之前的快照 ID 和时间（不加载快照内容），按时间倒序
+func (r *SnapshotRepository) ListTimesBefore(addressID uint, before time.Time) ([]models.AssetSnapshot, error) {
+	var snapshots []models.AssetSnapshot
+	err := r.db.Select("id", "snapshot_time").
+		Where("address_id = ? AND snapshot_time < ?", addressID, before).
+		Order("snapshot_time DESC, id DESC").
+		Find(&snapshots).Error
+	return snapshots, err
+}
+
+// DeleteByIDs 删除指定的快照，返回删除的行数
+func (r *SnapshotRepository) DeleteByIDs(ids []uint) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	result := r.db.Where("id IN ?", ids).Delete(&models.AssetSnapshot{})
+	return result.RowsAffected, result.Error
+}
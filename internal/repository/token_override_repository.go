@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"github.com/rotki-demo/internal/models"
+	"gorm.io/gorm"
+)
+
+// TokenOverrideRepository 处理代币忽略/白名单数据操作
+type TokenOverrideRepository struct {
+	db *gorm.DB
+}
+
+// NewTokenOverrideRepository 创建一个新的代币覆盖仓库
+func NewTokenOverrideRepository(db *gorm.DB) *TokenOverrideRepository {
+	return &TokenOverrideRepository{db: db}
+}
+
+// TokenOverrideFilter 过滤覆盖设置和审计记录，零值字段不参与过滤
+type TokenOverrideFilter struct {
+	ChainID  string
+	TokenID  string
+	WalletID *uint
+	Scope    WalletScope // 全局设置（wallet_id 为 0）始终可见
+}
+
+// apply 将过滤条件添加到查询
+func (f TokenOverrideFilter) apply(query *gorm.DB) *gorm.DB {
+	query = f.Scope.applyWalletOrGlobal(query, "wallet_id")
+	if f.ChainID != "" {
+		query = query.Where("chain_id = ?", f.ChainID)
+	}
+	if f.TokenID != "" {
+		query = query.Where("token_id = ?", f.TokenID)
+	}
+	if f.WalletID != nil {
+		query = query.Where("wallet_id = ?", *f.WalletID)
+	}
+	return query
+}
+
+// GetByID 根据 ID 获取覆盖设置
+func (r *TokenOverrideRepository) GetByID(id uint) (*models.TokenOverride, error) {
+	var override models.TokenOverride
+	err := r.db.First(&override, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &override, nil
+}
+
+// GetByKey 根据 (chain_id, token_id, wallet_id) 获取覆盖设置
+func (r *TokenOverrideRepository) GetByKey(chainID, tokenID string, walletID uint) (*models.TokenOverride, error) {
+	var override models.TokenOverride
+	err := r.db.Where("chain_id = ? AND token_id = ? AND wallet_id = ?", chainID, tokenID, walletID).
+		First(&override).Error
+	if err != nil {
+		return nil, err
+	}
+	return &override, nil
+}
+
+// List 获取满足过滤条件的覆盖设置
+func (r *TokenOverrideRepository) List(filter TokenOverrideFilter) ([]models.TokenOverride, error) {
+	var overrides []models.TokenOverride
+	err := filter.apply(r.db).Order("chain_id, token_id, wallet_id").Find(&overrides).Error
+	return overrides, err
+}
+
+// SaveWithAudit 在同一事务中保存覆盖设置并写入审计记录
+func (r *TokenOverrideRepository) SaveWithAudit(override *models.TokenOverride, audit *models.TokenOverrideAudit) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(override).Error; err != nil {
+			return err
+		}
+		audit.OverrideID = override.ID
+		return tx.Create(audit).Error
+	})
+}
+
+// DeleteWithAudit 在同一事务中删除覆盖设置并写入审计记录
+func (r *TokenOverrideRepository) DeleteWithAudit(override *models.TokenOverride, audit *models.TokenOverrideAudit) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.TokenOverride{}, override.ID).Error; err != nil {
+			return err
+		}
+		return tx.Create(audit).Error
+	})
+}
+
+// ListAudits 获取满足过滤条件的审计记录，按时间倒序
+func (r *TokenOverrideRepository) ListAudits(filter TokenOverrideFilter, limit int) ([]models.TokenOverrideAudit, error) {
+	var audits []models.TokenOverrideAudit
+	err := filter.apply(r.db).Order("created_at DESC, id DESC").Limit(limit).Find(&audits).Error
+	return audits, err
+}
@@ -1,6 +1,10 @@
 package repository
 
 import (
+	"context"
+	"fmt"
+	"strings"
+
 	"github.com/rotki-demo/internal/models"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -16,35 +20,63 @@ func NewTokenRepository(db *gorm.DB) *TokenRepository {
 	return &TokenRepository{db: db}
 }
 
+// WithContext 返回使用指定上下文的仓库副本，查询会作为上下文中 span 的子 span 记录
+func (r *TokenRepository) WithContext(ctx context.Context) *TokenRepository {
+	return &TokenRepository{db: r.db.WithContext(ctx)}
+}
+
 // UpsertBatch 批量插入或更新代币
 func (r *TokenRepository) UpsertBatch(tokens []models.Token) error {
 	if len(tokens) == 0 {
 		return nil
 	}
 
+	// 同一批次中的重复键在 PostgreSQL 会报错，MySQL 则以后者为准，统一为后者覆盖前者
+	tokens = dedupeLast(tokens, func(t models.Token) string {
+		return fmt.Sprintf("%d|%s|%s|%s", t.AddressID, t.ChainID, t.TokenID, t.ProtocolID)
+	})
+
+	// 冲突列必须与唯一索引 uk_address_chain_token_protocol 一致（SQLite/PostgreSQL 要求精确匹配）
 	return r.db.Clauses(clause.OnConflict{
 		Columns: []clause.Column{
 			{Name: "address_id"},
 			{Name: "chain_id"},
 			{Name: "token_id"},
+			{Name: "protocol_id"},
 		},
 		DoUpdates: clause.AssignmentColumns([]string{
 			"symbol", "name", "decimals", "logo_url",
-			"balance", "price", "usd_value", "last_updated",
+			"balance", "price", "usd_value", "is_verified", "is_core",
+			"classification", "last_updated",
 		}),
 	}).Create(&tokens).Error
 }
 
-// GetByAddressID 获取地址的所有代币
-func (r *TokenRepository) GetByAddressID(addressID uint) ([]models.Token, error) {
+// GetByAddressID 获取地址的代币，includeHidden 为 false 时只返回 normal 分类的代币
+func (r *TokenRepository) GetByAddressID(addressID uint, includeHidden bool) ([]models.Token, error) {
 	var tokens []models.Token
-	err := r.db.Where("address_id = ?", addressID).
+	query := r.db.Where("address_id = ?", addressID)
+	if !includeHidden {
+		query = query.Where("classification = ?", models.TokenClassificationNormal)
+	}
+	err := query.
 		Preload("Chain").
 		Order("usd_value DESC").
 		Find(&tokens).Error
 	return tokens, err
 }
 
+// GetChainIDsByAddressID 返回地址持有代币（包括协议代币）的链
+func (r *TokenRepository) GetChainIDsByAddressID(addressID uint) ([]string, error) {
+	var chainIDs []string
+	err := r.db.Model(&models.Token{}).
+		Where("address_id = ?", addressID).
+		Distinct("chain_id").
+		Order("chain_id").
+		Pluck("chain_id", &chainIDs).Error
+	return chainIDs, err
+}
+
 // DeleteByAddressID 删除地址的所有代币
 func (r *TokenRepository) DeleteByAddressID(addressID uint) error {
 	return r.db.Where("address_id = ?", addressID).Delete(&models.Token{}).Error
@@ -60,12 +92,67 @@ func (r *TokenRepository) DeleteProtocolTokensByAddressID(addressID uint) error
 	return r.db.Where("address_id = ? AND protocol_id IS NOT NULL AND protocol_id != ''", addressID).Delete(&models.Token{}).Error
 }
 
-// GetTotalValueByAddressID 计算地址的总 USD 价值
+// ListWalletTokens 获取所有地址的钱包代币（不包括协议代币），预加载地址以获取所属钱包
+func (r *TokenRepository) ListWalletTokens() ([]models.Token, error) {
+	var tokens []models.Token
+	err := r.db.Where("protocol_id IS NULL OR protocol_id = ''").
+		Preload("Address").
+		Find(&tokens).Error
+	return tokens, err
+}
+
+// ListByToken 获取所有地址中指定 (chain_id, token_id) 的钱包代币和协议代币
+func (r *TokenRepository) ListByToken(chainID, tokenID string) ([]models.Token, error) {
+	var tokens []models.Token
+	err := r.db.Where("chain_id = ? AND LOWER(token_id) = ?", chainID, strings.ToLower(tokenID)).
+		Preload("Address").
+		Find(&tokens).Error
+	return tokens, err
+}
+
+// UpdateClassification 更新代币分类
+func (r *TokenRepository) UpdateClassification(id uint, classification string) error {
+	return r.db.Model(&models.Token{}).Where("id = ?", id).
+		UpdateColumn("classification", classification).Error
+}
+
+// GetTotalValueByAddressID 计算地址的总 USD 价值（只计入 normal 分类的代币）
 func (r *TokenRepository) GetTotalValueByAddressID(addressID uint) (float64, error) {
 	var total float64
 	err := r.db.Model(&models.Token{}).
-		Where("address_id = ?", addressID).
+		Where("address_id = ? AND classification = ?", addressID, models.TokenClassificationNormal).
+		Select("COALESCE(SUM(usd_value), 0)").
+		Scan(&total).Error
+	return total, err
+}
+
+// GetTotalValueByWalletID 计算钱包所有地址的总 USD 价值（只计入 normal 分类的代币，协议债务为负数）
+func (r *TokenRepository) GetTotalValueByWalletID(walletID uint) (float64, error) {
+	var total float64
+	err := r.db.Model(&models.Token{}).
+		Where("address_id IN (?)", r.db.Model(&models.Address{}).Select("id").Where("wallet_id = ?", walletID)).
+		Where("classification = ?", models.TokenClassificationNormal).
 		Select("COALESCE(SUM(usd_value), 0)").
 		Scan(&total).Error
 	return total, err
 }
+
+// WalletValue 是钱包的总 USD 价值
+type WalletValue struct {
+	WalletID uint
+	Name     string
+	USDValue float64
+}
+
+// GetTotalValuesByWallet 计算所有钱包的总 USD 价值（只计入 normal 分类的代币），没有代币的钱包价值为 0
+func (r *TokenRepository) GetTotalValuesByWallet() ([]WalletValue, error) {
+	var values []WalletValue
+	err := r.db.Table("wallets").
+		Select("wallets.id AS wallet_id, wallets.name AS name, COALESCE(SUM(tokens.usd_value), 0) AS usd_value").
+		Joins("LEFT JOIN addresses ON addresses.wallet_id = wallets.id").
+		Joins("LEFT JOIN tokens ON tokens.address_id = addresses.id AND tokens.classification = ?", models.TokenClassificationNormal).
+		Group("wallets.id, wallets.name").
+		Order("wallets.id").
+		Scan(&values).Error
+	return values, err
+}
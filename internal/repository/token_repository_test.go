@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/rotki-demo/internal/models"
+)
+
+func TestTokenRepositoryUpsertBatch(t *testing.T) {
+	db := newTestDB(t)
+	ensureChain(t, db, "eth")
+	_, addresses := createWallet(t, db, "main", "0xaaa")
+	repo := NewTokenRepository(db)
+	addressID := addresses[0].ID
+
+	// 同一批次中的重复键以后者为准；协议中的同一代币是另一行
+	err := repo.UpsertBatch([]models.Token{
+		{AddressID: addressID, ChainID: "eth", TokenID: "usdc", Symbol: "USDC", Balance: "10", USDValue: 10},
+		{AddressID: addressID, ChainID: "eth", TokenID: "usdc", Symbol: "USDC", Balance: "20", USDValue: 20},
+		{AddressID: addressID, ChainID: "eth", TokenID: "usdc", Symbol: "USDC", Balance: "5", USDValue: 5, ProtocolID: "aave"},
+	})
+	if err != nil {
+		t.Fatalf("UpsertBatch: %v", err)
+	}
+
+	tokens, err := repo.GetByAddressID(addressID, true)
+	if err != nil {
+		t.Fatalf("GetByAddressID: %v", err)
+	}
+	if len(tokens) != 2 {
+		t.Fatalf("got %d tokens, want 2", len(tokens))
+	}
+	if tokens[0].ProtocolID != "" || tokens[0].USDValue != 20 {
+		t.Fatalf("wallet token = %+v, want usd_value 20 from the last duplicate", tokens[0])
+	}
+	walletTokenID := tokens[0].ID
+
+	// 再次同步时按唯一键更新已有行
+	err = repo.UpsertBatch([]models.Token{
+		{AddressID: addressID, ChainID: "eth", TokenID: "usdc", Symbol: "USDC", Balance: "30", USDValue: 30, Classification: models.TokenClassificationSpam},
+	})
+	if err != nil {
+		t.Fatalf("second UpsertBatch: %v", err)
+	}
+	tokens, err = repo.GetByAddressID(addressID, true)
+	if err != nil {
+		t.Fatalf("GetByAddressID: %v", err)
+	}
+	if len(tokens) != 2 {
+		t.Fatalf("got %d tokens after update, want 2", len(tokens))
+	}
+	updated := tokens[0]
+	if updated.ID != walletTokenID || updated.USDValue != 30 || updated.Classification != models.TokenClassificationSpam {
+		t.Fatalf("updated token = %+v, want id %d with usd_value 30 and spam classification", updated, walletTokenID)
+	}
+
+	// 隐藏分类不在默认结果中
+	visible, err := repo.GetByAddressID(addressID, false)
+	if err != nil {
+		t.Fatalf("GetByAddressID: %v", err)
+	}
+	if len(visible) != 1 || visible[0].ProtocolID != "aave" {
+		t.Fatalf("visible tokens = %+v, want only the protocol token", visible)
+	}
+}
+
+func TestTokenRepositoryTotals(t *testing.T) {
+	db := newTestDB(t)
+	ensureChain(t, db, "eth")
+	main, mainAddresses := createWallet(t, db, "main", "0xaaa", "0xbbb")
+	cold, coldAddresses := createWallet(t, db, "cold", "0xccc")
+	empty, _ := createWallet(t, db, "empty")
+	repo := NewTokenRepository(db)
+
+	err := repo.UpsertBatch([]models.Token{
+		{AddressID: mainAddresses[0].ID, ChainID: "eth", TokenID: "eth", Balance: "1", USDValue: 100},
+		{AddressID: mainAddresses[0].ID, ChainID: "eth", TokenID: "scam", Balance: "1", USDValue: 1000, Classification: models.TokenClassificationSpam},
+		{AddressID: mainAddresses[0].ID, ChainID: "eth", TokenID: "usdc", Balance: "-30", USDValue: -30, ProtocolID: "aave", IsDebt: true},
+		{AddressID: mainAddresses[1].ID, ChainID: "eth", TokenID: "eth", Balance: "0.5", USDValue: 50},
+		{AddressID: coldAddresses[0].ID, ChainID: "eth", TokenID: "dust", Balance: "1", USDValue: 7, Classification: models.TokenClassificationHidden},
+	})
+	if err != nil {
+		t.Fatalf("UpsertBatch: %v", err)
+	}
+
+	// 只计入 normal 分类，债务为负数
+	addressTotal, err := repo.GetTotalValueByAddressID(mainAddresses[0].ID)
+	if err != nil {
+		t.Fatalf("GetTotalValueByAddressID: %v", err)
+	}
+	if addressTotal != 70 {
+		t.Errorf("address total = %v, want 70", addressTotal)
+	}
+
+	walletTotal, err := repo.GetTotalValueByWalletID(main.ID)
+	if err != nil {
+		t.Fatalf("GetTotalValueByWalletID: %v", err)
+	}
+	if walletTotal != 120 {
+		t.Errorf("wallet total = %v, want 120", walletTotal)
+	}
+
+	// 没有代币或只有隐藏代币的钱包价值为 0
+	coldTotal, err := repo.GetTotalValueByWalletID(cold.ID)
+	if err != nil {
+		t.Fatalf("GetTotalValueByWalletID: %v", err)
+	}
+	if coldTotal != 0 {
+		t.Errorf("cold wallet total = %v, want 0", coldTotal)
+	}
+
+	values, err := repo.GetTotalValuesByWallet()
+	if err != nil {
+		t.Fatalf("GetTotalValuesByWallet: %v", err)
+	}
+	want := []WalletValue{
+		{WalletID: main.ID, Name: "main", USDValue: 120},
+		{WalletID: cold.ID, Name: "cold", USDValue: 0},
+		{WalletID: empty.ID, Name: "empty", USDValue: 0},
+	}
+	if len(values) != len(want) {
+		t.Fatalf("GetTotalValuesByWallet = %+v, want %+v", values, want)
+	}
+	for i := range want {
+		if values[i] != want[i] {
+			t.Errorf("wallet value %d = %+v, want %+v", i, values[i], want[i])
+		}
+	}
+}
+
+func TestTokenRepositoryListByToken(t *testing.T) {
+	db := newTestDB(t)
+	ensureChain(t, db, "eth")
+	ensureChain(t, db, "bsc")
+	_, addresses := createWallet(t, db, "main", "0xaaa", "0xbbb")
+	repo := NewTokenRepository(db)
+
+	err := repo.UpsertBatch([]models.Token{
+		{AddressID: addresses[0].ID, ChainID: "eth", TokenID: "0xAbC", Balance: "1"},
+		{AddressID: addresses[0].ID, ChainID: "eth", TokenID: "0xAbC", Balance: "1", ProtocolID: "aave"},
+		{AddressID: addresses[1].ID, ChainID: "eth", TokenID: "0xabc", Balance: "1"},
+		{AddressID: addresses[1].ID, ChainID: "bsc", TokenID: "0xabc", Balance: "1"},
+	})
+	if err != nil {
+		t.Fatalf("UpsertBatch: %v", err)
+	}
+
+	// 代币 ID 不区分大小写，包括协议代币，并预加载所属地址
+	tokens, err := repo.ListByToken("eth", "0xABC")
+	if err != nil {
+		t.Fatalf("ListByToken: %v", err)
+	}
+	if len(tokens) != 3 {
+		t.Fatalf("got %d tokens, want 3", len(tokens))
+	}
+	protocols := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		if token.Address == nil {
+			t.Fatalf("token %d has no preloaded address", token.ID)
+		}
+		protocols = append(protocols, token.ProtocolID)
+	}
+	sort.Strings(protocols)
+	if protocols[0] != "" || protocols[1] != "" || protocols[2] != "aave" {
+		t.Fatalf("protocol ids = %q, want two wallet tokens and one aave token", protocols)
+	}
+}
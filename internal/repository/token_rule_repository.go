@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"github.com/rotki-demo/internal/models"
+	"gorm.io/gorm"
+)
+
+// TokenRuleRepository 处理代币分类规则数据操作
+type TokenRuleRepository struct {
+	db *gorm.DB
+}
+
+// NewTokenRuleRepository 创建一个新的代币规则仓库
+func NewTokenRuleRepository(db *gorm.DB) *TokenRuleRepository {
+	return &TokenRuleRepository{db: db}
+}
+
+// Create 创建一条新规则
+func (r *TokenRuleRepository) Create(rule *models.TokenRule) error {
+	return r.db.Create(rule).Error
+}
+
+// GetByID 根据 ID 获取规则
+func (r *TokenRuleRepository) GetByID(id uint) (*models.TokenRule, error) {
+	var rule models.TokenRule
+	err := r.db.First(&rule, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// List 获取所有规则，按优先级排序
+func (r *TokenRuleRepository) List() ([]models.TokenRule, error) {
+	var rules []models.TokenRule
+	err := r.db.Order("priority, id").Find(&rules).Error
+	return rules, err
+}
+
+// ListEnabled 获取所有已启用的规则，按优先级排序
+func (r *TokenRuleRepository) ListEnabled() ([]models.TokenRule, error) {
+	var rules []models.TokenRule
+	err := r.db.Where("enabled = ?", true).Order("priority, id").Find(&rules).Error
+	return rules, err
+}
+
+// Update 更新规则
+func (r *TokenRuleRepository) Update(rule *models.TokenRule) error {
+	return r.db.Save(rule).Error
+}
+
+// Delete 删除规则
+func (r *TokenRuleRepository) Delete(id uint) error {
+	return r.db.Delete(&models.TokenRule{}, id).Error
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/rotki-demo/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TransactionRepository 处理交易历史和同步游标的数据操作
+type TransactionRepository struct {
+	db *gorm.DB
+}
+
+// NewTransactionRepository 创建一个新的交易仓库
+func NewTransactionRepository(db *gorm.DB) *TransactionRepository {
+	return &TransactionRepository{db: db}
+}
+
+// WithContext 返回使用指定上下文的仓库副本
+func (r *TransactionRepository) WithContext(ctx context.Context) *TransactionRepository {
+	return &TransactionRepository{db: r.db.WithContext(ctx)}
+}
+
+// TransactionFilter 是交易查询条件，零值字段表示不限
+type TransactionFilter struct {
+	AddressID uint
+	ChainID   string
+	Since     *time.Time // 包含
+	Until     *time.Time // 不包含
+}
+
+// apply 把过滤条件加到查询上
+func (f TransactionFilter) apply(query *gorm.DB) *gorm.DB {
+	query = query.Where("address_id = ?", f.AddressID)
+	if f.ChainID != "" {
+		query = query.Where("chain_id = ?", f.ChainID)
+	}
+	if f.Since != nil {
+		query = query.Where("block_time >= ?", *f.Since)
+	}
+	if f.Until != nil {
+		query = query.Where("block_time < ?", *f.Until)
+	}
+	return query
+}
+
+// List 按区块时间倒序分页查询交易，同时返回满足条件的总数
+func (r *TransactionRepository) List(filter TransactionFilter, offset, limit int) ([]models.Transaction, int64, error) {
+	var total int64
+	if err := filter.apply(r.db.Model(&models.Transaction{})).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var transactions []models.Transaction
+	err := filter.apply(r.db).
+		Order("block_time DESC, id DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&transactions).Error
+	return transactions, total, err
+}
+
+// UpsertBatch 插入交易，已存在的交易（同一地址、链和哈希）更新为新获取的内容
+func (r *TransactionRepository) UpsertBatch(transactions []models.Transaction) error {
+	transactions = dedupeLast(transactions, func(t models.Transaction) string {
+		return t.ChainID + "|" + t.TxHash
+	})
+	if len(transactions) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "address_id"}, {Name: "chain_id"}, {Name: "tx_hash"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"block_number", "block_time", "counterparty", "category", "protocol_id",
+			"transfers_in", "transfers_out", "fee", "fee_usd", "failed", "source",
+		}),
+	}).CreateInBatches(transactions, 500).Error
+}
+
+// GetCursors 返回地址在各条链上的同步游标
+func (r *TransactionRepository) GetCursors(addressID uint) ([]models.TransactionCursor, error) {
+	var cursors []models.TransactionCursor
+	err := r.db.Where("address_id = ?", addressID).Order("chain_id").Find(&cursors).Error
+	return cursors, err
+}
+
+// SaveCursor 保存地址在链上的同步游标
+func (r *TransactionRepository) SaveCursor(cursor *models.TransactionCursor) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "address_id"}, {Name: "chain_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"source", "next_cursor", "synced_at"}),
+	}).Create(cursor).Error
+}
+
+// UpsertBatchWithCursor 在一个事务中保存交易和游标，避免游标前进而交易未保存
+func (r *TransactionRepository) UpsertBatchWithCursor(transactions []models.Transaction, cursor *models.TransactionCursor) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		repo := &TransactionRepository{db: tx}
+		if err := repo.UpsertBatch(transactions); err != nil {
+			return err
+		}
+		return repo.SaveCursor(cursor)
+	})
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rotki-demo/internal/models"
+)
+
+func TestTransactionRepositoryUpsertBatchWithCursor(t *testing.T) {
+	db := newTestDB(t)
+	_, addresses := createWallet(t, db, "main", "0xaaa")
+	repo := NewTransactionRepository(db)
+	addressID := addresses[0].ID
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tx := func(hash string, offset time.Duration, category string) models.Transaction {
+		return models.Transaction{
+			AddressID: addressID,
+			ChainID:   "eth",
+			TxHash:    hash,
+			BlockTime: base.Add(offset),
+			Category:  category,
+			Source:    "debank",
+			TransfersIn: models.TokenTransferList{
+				{TokenID: "eth", Symbol: "ETH", Amount: 1},
+			},
+		}
+	}
+	cursor := func(value string) *models.TransactionCursor {
+		return &models.TransactionCursor{AddressID: addressID, ChainID: "eth", Source: "debank", Cursor: value, SyncedAt: time.Now()}
+	}
+
+	err := repo.UpsertBatchWithCursor([]models.Transaction{
+		tx("0x1", 0, "receive"),
+		tx("0x2", time.Hour, "send"),
+	}, cursor("100"))
+	if err != nil {
+		t.Fatalf("UpsertBatchWithCursor: %v", err)
+	}
+
+	// 再次获取的交易按哈希更新，批次内的重复以后者为准，游标只保留一行
+	err = repo.UpsertBatchWithCursor([]models.Transaction{
+		tx("0x2", time.Hour, "approve"),
+		tx("0x3", 2*time.Hour, "send"),
+		tx("0x3", 2*time.Hour, "swap"),
+	}, cursor("200"))
+	if err != nil {
+		t.Fatalf("second UpsertBatchWithCursor: %v", err)
+	}
+
+	transactions, total, err := repo.List(TransactionFilter{AddressID: addressID}, 0, 10)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if total != 3 || len(transactions) != 3 {
+		t.Fatalf("got %d of %d transactions, want 3", len(transactions), total)
+	}
+	// 按区块时间倒序
+	wantCategories := map[string]string{"0x3": "swap", "0x2": "approve", "0x1": "receive"}
+	for i, hash := range []string{"0x3", "0x2", "0x1"} {
+		got := transactions[i]
+		if got.TxHash != hash || got.Category != wantCategories[hash] {
+			t.Errorf("transaction %d = %s/%s, want %s/%s", i, got.TxHash, got.Category, hash, wantCategories[hash])
+		}
+	}
+	if len(transactions[0].TransfersIn) != 1 || transactions[0].TransfersIn[0].Symbol != "ETH" {
+		t.Errorf("transfers_in = %+v, want one ETH transfer", transactions[0].TransfersIn)
+	}
+
+	cursors, err := repo.GetCursors(addressID)
+	if err != nil {
+		t.Fatalf("GetCursors: %v", err)
+	}
+	if len(cursors) != 1 || cursors[0].Cursor != "200" {
+		t.Fatalf("cursors = %+v, want a single cursor at 200", cursors)
+	}
+
+	// 时间范围：包含 since，不包含 until
+	since, until := base.Add(time.Hour), base.Add(2*time.Hour)
+	transactions, total, err = repo.List(TransactionFilter{AddressID: addressID, Since: &since, Until: &until}, 0, 10)
+	if err != nil {
+		t.Fatalf("List with range: %v", err)
+	}
+	if total != 1 || len(transactions) != 1 || transactions[0].TxHash != "0x2" {
+		t.Fatalf("range returned %d of %d transactions, want only 0x2", len(transactions), total)
+	}
+}
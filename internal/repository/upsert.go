@@ -0,0 +1,18 @@
+package repository
+
+// dedupeLast 按键去重，重复键保留最后一次出现的记录，顺序按首次出现的位置
+// 批量 upsert 前调用，使 PostgreSQL 的 ON CONFLICT 与 MySQL 的 ON DUPLICATE KEY 行为一致
+func dedupeLast[T any](items []T, key func(T) string) []T {
+	index := make(map[string]int, len(items))
+	result := make([]T, 0, len(items))
+	for _, item := range items {
+		k := key(item)
+		if i, ok := index[k]; ok {
+			result[i] = item
+			continue
+		}
+		index[k] = len(result)
+		result = append(result, item)
+	}
+	return result
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/rotki-demo/internal/models"
+	"gorm.io/gorm"
+)
+
+// UserRepository 处理用户和登录会话的数据操作
+type UserRepository struct {
+	db *gorm.DB
+}
+
+// NewUserRepository 创建一个新的用户仓库
+func NewUserRepository(db *gorm.DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+// Create 创建用户
+func (r *UserRepository) Create(user *models.User) error {
+	return r.db.Create(user).Error
+}
+
+// GetByID 根据 ID 获取用户
+func (r *UserRepository) GetByID(id uint) (*models.User, error) {
+	var user models.User
+	err := r.db.First(&user, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetByUsername 根据用户名获取用户
+func (r *UserRepository) GetByUsername(username string) (*models.User, error) {
+	var user models.User
+	err := r.db.Where("username = ?", username).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// List 获取所有用户
+func (r *UserRepository) List() ([]models.User, error) {
+	var users []models.User
+	err := r.db.Order("id").Find(&users).Error
+	return users, err
+}
+
+// Update 更新用户
+func (r *UserRepository) Update(user *models.User) error {
+	return r.db.Save(user).Error
+}
+
+// Delete 删除用户及其会话和共享记录，拥有的钱包变为无所有者（只有 admin 可见）
+func (r *UserRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// 显式处理关联数据，不依赖数据库的级联设置
+		if err := tx.Where("user_id = ?", id).Delete(&models.UserSession{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", id).Delete(&models.WalletShare{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", id).Delete(&models.APIKey{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Wallet{}).Where("owner_id = ?", id).Update("owner_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.User{}, id).Error
+	})
+}
+
+// CreateSession 创建登录会话
+func (r *UserRepository) CreateSession(session *models.UserSession) error {
+	return r.db.Create(session).Error
+}
+
+// GetSessionByHash 根据 token 哈希获取会话及其用户
+func (r *UserRepository) GetSessionByHash(hash string) (*models.UserSession, error) {
+	var session models.UserSession
+	err := r.db.Preload("User").Where("token_hash = ?", hash).First(&session).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// UpdateSessionLastUsed 更新会话最后使用时间
+func (r *UserRepository) UpdateSessionLastUsed(id uint, at time.Time) error {
+	return r.db.Model(&models.UserSession{}).Where("id = ?", id).Update("last_used_at", at).Error
+}
+
+// DeleteSessionByHash 删除会话（登出）
+func (r *UserRepository) DeleteSessionByHash(hash string) error {
+	return r.db.Where("token_hash = ?", hash).Delete(&models.UserSession{}).Error
+}
+
+// DeleteSessionsByUserID 删除用户的所有会话
+func (r *UserRepository) DeleteSessionsByUserID(userID uint) error {
+	return r.db.Where("user_id = ?", userID).Delete(&models.UserSession{}).Error
+}
+
+// DeleteExpiredSessions 删除已过期的会话
+func (r *UserRepository) DeleteExpiredSessions(now time.Time) (int64, error) {
+	result := r.db.Where("expires_at < ?", now).Delete(&models.UserSession{})
+	return result.RowsAffected, result.Error
+}
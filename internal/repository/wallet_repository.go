@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"context"
+
 	"github.com/rotki-demo/internal/models"
 	"gorm.io/gorm"
 )
@@ -15,6 +17,11 @@ func NewWalletRepository(db *gorm.DB) *WalletRepository {
 	return &WalletRepository{db: db}
 }
 
+// WithContext 返回使用指定上下文的仓库副本，查询会作为上下文中 span 的子 span 记录
+func (r *WalletRepository) WithContext(ctx context.Context) *WalletRepository {
+	return &WalletRepository{db: r.db.WithContext(ctx)}
+}
+
 // Create 创建一个新钱包
 func (r *WalletRepository) Create(wallet *models.Wallet) error {
 	return r.db.Create(wallet).Error
@@ -40,19 +47,68 @@ func (r *WalletRepository) GetByName(name string) (*models.Wallet, error) {
 	return &wallet, nil
 }
 
-// List 获取所有钱包
-func (r *WalletRepository) List() ([]models.Wallet, error) {
+// List 获取范围内的所有钱包
+func (r *WalletRepository) List(scope WalletScope) ([]models.Wallet, error) {
 	var wallets []models.Wallet
-	err := r.db.Preload("Addresses").Find(&wallets).Error
+	err := scope.applyWallet(r.db, "id").Preload("Addresses").Find(&wallets).Error
 	return wallets, err
 }
 
+// ListAccessibleIDs 获取用户拥有或被共享的钱包 ID
+func (r *WalletRepository) ListAccessibleIDs(userID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&models.Wallet{}).
+		Where("owner_id = ?", userID).
+		Or("id IN (?)", r.db.Model(&models.WalletShare{}).Select("wallet_id").Where("user_id = ?", userID)).
+		Order("id").
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// GetShare 获取钱包对用户的共享记录
+func (r *WalletRepository) GetShare(walletID, userID uint) (*models.WalletShare, error) {
+	var share models.WalletShare
+	err := r.db.Where("wallet_id = ? AND user_id = ?", walletID, userID).First(&share).Error
+	if err != nil {
+		return nil, err
+	}
+	return &share, nil
+}
+
+// ListShares 获取钱包的所有共享记录及其用户
+func (r *WalletRepository) ListShares(walletID uint) ([]models.WalletShare, error) {
+	var shares []models.WalletShare
+	err := r.db.Preload("User").Where("wallet_id = ?", walletID).Order("id").Find(&shares).Error
+	return shares, err
+}
+
+// SaveShare 创建或更新共享记录
+func (r *WalletRepository) SaveShare(share *models.WalletShare) error {
+	existing, err := r.GetShare(share.WalletID, share.UserID)
+	if err == nil {
+		existing.Permission = share.Permission
+		*share = *existing
+		return r.db.Save(share).Error
+	}
+	return r.db.Create(share).Error
+}
+
+// DeleteShare 取消共享
+func (r *WalletRepository) DeleteShare(walletID, userID uint) error {
+	return r.db.Where("wallet_id = ? AND user_id = ?", walletID, userID).Delete(&models.WalletShare{}).Error
+}
+
 // Update 更新钱包
 func (r *WalletRepository) Update(wallet *models.Wallet) error {
 	return r.db.Save(wallet).Error
 }
 
-// Delete 删除钱包
+// Delete 删除钱包及其共享记录
 func (r *WalletRepository) Delete(id uint) error {
-	return r.db.Delete(&models.Wallet{}, id).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("wallet_id = ?", id).Delete(&models.WalletShare{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Wallet{}, id).Error
+	})
 }
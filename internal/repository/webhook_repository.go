@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/rotki-demo/internal/models"
+	"gorm.io/gorm"
+)
+
+// WebhookRepository 处理 webhook 端点和投递记录的数据操作
+type WebhookRepository struct {
+	db *gorm.DB
+}
+
+// NewWebhookRepository 创建一个新的 webhook 仓库
+func NewWebhookRepository(db *gorm.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+// CreateEndpoint 创建端点
+func (r *WebhookRepository) CreateEndpoint(endpoint *models.WebhookEndpoint) error {
+	return r.db.Create(endpoint).Error
+}
+
+// GetEndpointByID 根据 ID 获取端点
+func (r *WebhookRepository) GetEndpointByID(id uint) (*models.WebhookEndpoint, error) {
+	var endpoint models.WebhookEndpoint
+	err := r.db.First(&endpoint, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+// ListEndpoints 获取所有端点
+func (r *WebhookRepository) ListEndpoints() ([]models.WebhookEndpoint, error) {
+	var endpoints []models.WebhookEndpoint
+	err := r.db.Order("id").Find(&endpoints).Error
+	return endpoints, err
+}
+
+// ListEnabledEndpoints 获取所有启用的端点
+func (r *WebhookRepository) ListEnabledEndpoints() ([]models.WebhookEndpoint, error) {
+	var endpoints []models.WebhookEndpoint
+	err := r.db.Where("enabled = ?", true).Order("id").Find(&endpoints).Error
+	return endpoints, err
+}
+
+// UpdateEndpoint 更新端点
+func (r *WebhookRepository) UpdateEndpoint(endpoint *models.WebhookEndpoint) error {
+	return r.db.Save(endpoint).Error
+}
+
+// DeleteEndpoint 删除端点及其投递记录
+func (r *WebhookRepository) DeleteEndpoint(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("endpoint_id = ?", id).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.WebhookEndpoint{}, id).Error
+	})
+}
+
+// CreateDeliveries 批量创建投递记录
+func (r *WebhookRepository) CreateDeliveries(deliveries []models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return r.db.Create(&deliveries).Error
+}
+
+// GetDeliveryByID 根据 ID 获取投递记录
+func (r *WebhookRepository) GetDeliveryByID(id uint) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	err := r.db.First(&delivery, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// ListDueDeliveries 获取到期待投递的记录，按到期时间排序
+func (r *WebhookRepository) ListDueDeliveries(now time.Time, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := r.db.Where("status = ? AND next_attempt_at <= ?", models.WebhookDeliveryPending, now).
+		Order("next_attempt_at, id").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+// WebhookDeliveryFilter 过滤投递记录，零值字段不参与过滤
+type WebhookDeliveryFilter struct {
+	EndpointID uint
+	Status     string
+	EventType  string
+}
+
+// apply 将过滤条件添加到查询
+func (f WebhookDeliveryFilter) apply(query *gorm.DB) *gorm.DB {
+	if f.EndpointID != 0 {
+		query = query.Where("endpoint_id = ?", f.EndpointID)
+	}
+	if f.Status != "" {
+		query = query.Where("status = ?", f.Status)
+	}
+	if f.EventType != "" {
+		query = query.Where("event_type = ?", f.EventType)
+	}
+	return query
+}
+
+// ListDeliveries 获取满足过滤条件的投递记录，按时间倒序
+func (r *WebhookRepository) ListDeliveries(filter WebhookDeliveryFilter, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := filter.apply(r.db).Order("created_at DESC, id DESC").Limit(limit).Find(&deliveries).Error
+	return deliveries, err
+}
+
+// UpdateDelivery 保存投递结果；只更新已有记录，投递期间端点被删除（投递记录随之删除）时不会重新插入
+func (r *WebhookRepository) UpdateDelivery(delivery *models.WebhookDelivery) error {
+	return r.db.Select("*").Omit("id", "created_at").Updates(delivery).Error
+}
+
+// RequeueDeliveries 将满足过滤条件的投递记录重新放回队列，返回重新排队的数量
+func (r *WebhookRepository) RequeueDeliveries(filter WebhookDeliveryFilter, now time.Time) (int64, error) {
+	result := filter.apply(r.db.Model(&models.WebhookDelivery{})).
+		Updates(map[string]interface{}{
+			"status":          models.WebhookDeliveryPending,
+			"attempts":        0,
+			"next_attempt_at": now,
+			"last_error":      "",
+			"updated_at":      now,
+		})
+	return result.RowsAffected, result.Error
+}
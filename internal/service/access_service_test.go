@@ -0,0 +1,126 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rotki-demo/internal/models"
+	"github.com/rotki-demo/internal/repository"
+)
+
+func TestAccessService(t *testing.T) {
+	db := newTestDB(t)
+	createUser := func(name, role string) *models.User {
+		user := &models.User{Username: name, PasswordHash: "x", Role: role}
+		if err := db.Create(user).Error; err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+		return user
+	}
+	alice := createUser("alice", models.RoleOperator)
+	bob := createUser("bob", models.RoleOperator)
+	carol := createUser("carol", models.RoleOperator)
+	admin := createUser("admin", models.RoleAdmin)
+
+	createWallet := func(name string, owner *models.User) *models.Wallet {
+		wallet := &models.Wallet{Name: name}
+		if owner != nil {
+			wallet.OwnerID = &owner.ID
+		}
+		if err := db.Create(wallet).Error; err != nil {
+			t.Fatalf("create wallet: %v", err)
+		}
+		return wallet
+	}
+	aliceWallet := createWallet("alice", alice)
+	viewShared := createWallet("view-shared", alice)
+	editShared := createWallet("edit-shared", alice)
+	legacy := createWallet("legacy", nil)
+
+	walletRepo := repository.NewWalletRepository(db)
+	for _, share := range []models.WalletShare{
+		{WalletID: viewShared.ID, UserID: bob.ID, Permission: models.SharePermissionView},
+		{WalletID: editShared.ID, UserID: bob.ID, Permission: models.SharePermissionEdit},
+	} {
+		if err := walletRepo.SaveShare(&share); err != nil {
+			t.Fatalf("SaveShare: %v", err)
+		}
+	}
+
+	access := NewAccessService(walletRepo)
+	principal := func(user *models.User) *Principal {
+		return &Principal{UserID: user.ID, Name: user.Username, Role: user.Role}
+	}
+
+	t.Run("scope", func(t *testing.T) {
+		tests := []struct {
+			name       string
+			principal  *Principal
+			restricted bool
+			want       []uint
+		}{
+			{name: "auth disabled", principal: nil},
+			{name: "service key", principal: &Principal{Name: "ci", Role: models.RoleOperator}},
+			{name: "admin user", principal: principal(admin)},
+			{name: "owner", principal: principal(alice), restricted: true, want: []uint{aliceWallet.ID, viewShared.ID, editShared.ID}},
+			{name: "shared", principal: principal(bob), restricted: true, want: []uint{viewShared.ID, editShared.ID}},
+			{name: "no wallets", principal: principal(carol), restricted: true, want: nil},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				scope, err := access.Scope(tt.principal)
+				if err != nil {
+					t.Fatalf("Scope: %v", err)
+				}
+				if scope.Restricted != tt.restricted {
+					t.Fatalf("restricted = %t, want %t", scope.Restricted, tt.restricted)
+				}
+				if len(scope.WalletIDs) != len(tt.want) {
+					t.Fatalf("wallet ids = %v, want %v", scope.WalletIDs, tt.want)
+				}
+				for i := range tt.want {
+					if scope.WalletIDs[i] != tt.want[i] {
+						t.Fatalf("wallet ids = %v, want %v", scope.WalletIDs, tt.want)
+					}
+				}
+				// 升级前创建的无主钱包只有不受限的调用方可以访问
+				if scope.Allows(legacy.ID) == tt.restricted {
+					t.Fatalf("Allows(legacy) = %t, want %t", scope.Allows(legacy.ID), !tt.restricted)
+				}
+			})
+		}
+	})
+
+	t.Run("authorize", func(t *testing.T) {
+		tests := []struct {
+			name      string
+			principal *Principal
+			walletID  uint
+			required  string
+			wantErr   error
+		}{
+			{name: "owner edits", principal: principal(alice), walletID: aliceWallet.ID, required: AccessOwner},
+			{name: "admin owns everything", principal: principal(admin), walletID: legacy.ID, required: AccessOwner},
+			{name: "viewer views", principal: principal(bob), walletID: viewShared.ID, required: models.SharePermissionView},
+			{name: "viewer cannot edit", principal: principal(bob), walletID: viewShared.ID, required: models.SharePermissionEdit, wantErr: ErrAccessDenied},
+			{name: "editor edits", principal: principal(bob), walletID: editShared.ID, required: models.SharePermissionEdit},
+			{name: "editor cannot manage shares", principal: principal(bob), walletID: editShared.ID, required: AccessOwner, wantErr: ErrAccessDenied},
+			{name: "unshared wallet is hidden", principal: principal(bob), walletID: aliceWallet.ID, required: models.SharePermissionView, wantErr: ErrWalletNotFound},
+			{name: "legacy wallet is hidden", principal: principal(carol), walletID: legacy.ID, required: models.SharePermissionView, wantErr: ErrWalletNotFound},
+			{name: "missing wallet", principal: principal(admin), walletID: 9999, required: models.SharePermissionView, wantErr: ErrWalletNotFound},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				wallet, err := access.AuthorizeWallet(tt.principal, tt.walletID, tt.required)
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("AuthorizeWallet error = %v, want %v", err, tt.wantErr)
+				}
+				if err == nil && wallet.ID != tt.walletID {
+					t.Fatalf("wallet id = %d, want %d", wallet.ID, tt.walletID)
+				}
+			})
+		}
+	})
+}
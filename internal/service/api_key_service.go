@@ -0,0 +1,115 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rotki-demo/internal/logger"
+	"github.com/rotki-demo/internal/models"
+	"github.com/rotki-demo/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// apiKeyPrefix 是明文 API key 的固定前缀，便于在日志和密钥扫描中识别
+const apiKeyPrefix = "rk_"
+
+// lastUsedInterval 是更新 last_used_at 的最小间隔，避免每个请求都写数据库
+const lastUsedInterval = time.Minute
+
+// ErrInvalidAPIKey 表示 key 不存在、已吊销或已过期
+var ErrInvalidAPIKey = errors.New("invalid API key")
+
+// APIKeyService 处理 API key 的创建和校验
+type APIKeyService struct {
+	repo *repository.APIKeyRepository
+}
+
+// NewAPIKeyService 创建一个新的 API key 服务
+func NewAPIKeyService(repo *repository.APIKeyRepository) *APIKeyService {
+	return &APIKeyService{repo: repo}
+}
+
+// Create 生成一个新的 API key，明文只在此时返回一次。ttl 为 0 表示永不过期，userID 非空时 key 属于该用户
+func (s *APIKeyService) Create(name, role string, ttl time.Duration, userID *uint) (*models.APIKey, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", fmt.Errorf("name is required")
+	}
+	if !models.IsValidRole(role) {
+		return nil, "", fmt.Errorf("invalid role %q (expected %s, %s or %s)", role, models.RoleReadOnly, models.RoleOperator, models.RoleAdmin)
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, "", fmt.Errorf("failed to generate key: %w", err)
+	}
+	raw := apiKeyPrefix + hex.EncodeToString(b)
+
+	key := &models.APIKey{
+		Name:      name,
+		KeyPrefix: raw[:len(apiKeyPrefix)+8],
+		KeyHash:   HashAPIKey(raw),
+		Role:      role,
+		UserID:    userID,
+	}
+	if ttl > 0 {
+		expiresAt := time.Now().Add(ttl)
+		key.ExpiresAt = &expiresAt
+	}
+	if err := s.repo.Create(key); err != nil {
+		return nil, "", err
+	}
+	return key, raw, nil
+}
+
+// Authenticate 校验明文 key 并返回对应的 API key 记录
+func (s *APIKeyService) Authenticate(raw string) (*models.APIKey, error) {
+	if !strings.HasPrefix(raw, apiKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	key, err := s.repo.GetByHash(HashAPIKey(raw))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if !key.Active(now) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedInterval {
+		if err := s.repo.UpdateLastUsed(key.ID, now); err != nil {
+			logger.Warn("Failed to update API key last used time", zap.Uint("api_key_id", key.ID), zap.Error(err))
+		}
+	}
+	return key, nil
+}
+
+// List 获取所有 API key
+func (s *APIKeyService) List() ([]models.APIKey, error) {
+	return s.repo.List()
+}
+
+// Revoke 吊销 API key
+func (s *APIKeyService) Revoke(id uint) error {
+	if _, err := s.repo.GetByID(id); err != nil {
+		return err
+	}
+	return s.repo.Revoke(id, time.Now())
+}
+
+// HashAPIKey 返回 key 的 SHA-256 十六进制哈希。key 本身是高熵随机值，不需要加盐或慢哈希
+func HashAPIKey(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
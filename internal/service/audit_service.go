@@ -0,0 +1,200 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/rotki-demo/internal/config"
+	"github.com/rotki-demo/internal/logger"
+	"github.com/rotki-demo/internal/models"
+	"github.com/rotki-demo/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// auditEntity 描述一种可以记录修改前后状态的实体
+type auditEntity struct {
+	Type  string
+	Model func() interface{}
+	// URLFields 是可能在用户信息、路径或查询参数中包含 API key 的地址字段，记录前只保留协议和主机
+	URLFields []string
+}
+
+// auditEntities 将 API 路由中的资源路径映射到实体类型和模型
+var auditEntities = map[string]auditEntity{
+	"wallets":             {Type: "wallet", Model: func() interface{} { return &models.Wallet{} }},
+	"addresses":           {Type: "address", Model: func() interface{} { return &models.Address{} }},
+	"chains":              {Type: "chain", Model: func() interface{} { return &models.Chain{} }},
+	"rpc-nodes":           {Type: "rpc_node", Model: func() interface{} { return &models.RPCNode{} }, URLFields: []string{"url", "ws_url"}},
+	"token-rules":         {Type: "token_rule", Model: func() interface{} { return &models.TokenRule{} }},
+	"token-overrides":     {Type: "token_override", Model: func() interface{} { return &models.TokenOverride{} }},
+	"health-thresholds":   {Type: "health_threshold", Model: func() interface{} { return &models.HealthThreshold{} }},
+	"alert-rules":         {Type: "alert_rule", Model: func() interface{} { return &models.AlertRule{} }},
+	"webhooks":            {Type: "webhook", Model: func() interface{} { return &models.WebhookEndpoint{} }, URLFields: []string{"url"}},
+	"webhooks/deliveries": {Type: "webhook_delivery", Model: func() interface{} { return &models.WebhookDelivery{} }},
+	"users":               {Type: "user", Model: func() interface{} { return &models.User{} }},
+}
+
+// auditIgnoredFields 是计算变化时忽略的字段，每次保存都会变化，没有审计意义
+var auditIgnoredFields = map[string]bool{
+	"updated_at": true,
+}
+
+// AuditService 记录修改类 API 请求并按保留期清理
+type AuditService struct {
+	repo     *repository.AuditLogRepository
+	cfg      config.AuditConfig
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewAuditService 创建一个新的审计服务
+func NewAuditService(repo *repository.AuditLogRepository, cfg config.AuditConfig) *AuditService {
+	return &AuditService{
+		repo:     repo,
+		cfg:      cfg,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Enabled 判断是否记录审计日志
+func (s *AuditService) Enabled() bool {
+	return s.cfg.Enabled
+}
+
+// EntityType 返回资源路径对应的实体类型，未登记的资源使用路径本身
+func EntityType(resource string) string {
+	if entity, ok := auditEntities[resource]; ok {
+		return entity.Type
+	}
+	return resource
+}
+
+// IsAuditResource 判断资源路径是否登记了实体模型
+func IsAuditResource(resource string) bool {
+	_, ok := auditEntities[resource]
+	return ok
+}
+
+// Snapshot 加载实体的当前状态，资源未登记或实体不存在时返回 nil
+func (s *AuditService) Snapshot(resource, id string) models.JSONMap {
+	entity, ok := auditEntities[resource]
+	if !ok || id == "" {
+		return nil
+	}
+
+	model := entity.Model()
+	if err := s.repo.LoadEntity(model, id); err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Warn("Failed to load entity for audit", zap.String("entity_type", entity.Type), zap.String("entity_id", id), zap.Error(err))
+		}
+		return nil
+	}
+
+	// 通过 JSON 转换，json:"-" 的敏感字段（密码哈希、密钥等）不会进入审计日志
+	data, err := json.Marshal(model)
+	if err != nil {
+		return nil
+	}
+	var state models.JSONMap
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil
+	}
+	for _, field := range entity.URLFields {
+		if raw, ok := state[field].(string); ok {
+			state[field] = config.RedactURL(raw)
+		}
+	}
+	return state
+}
+
+// Record 计算修改前后的字段变化并写入审计记录，失败只记录日志，不影响请求
+func (s *AuditService) Record(entry *models.AuditLog) {
+	if entry.BeforeState != nil && entry.AfterState != nil {
+		entry.Changes = diffStates(entry.BeforeState, entry.AfterState)
+	}
+	if err := s.repo.Create(entry); err != nil {
+		logger.Error("Failed to write audit log",
+			zap.String("actor", entry.Actor),
+			zap.String("route", entry.Route),
+			zap.Error(err))
+	}
+}
+
+// List 查询审计记录
+func (s *AuditService) List(filter repository.AuditLogFilter, limit int) ([]models.AuditLog, error) {
+	return s.repo.List(filter, limit)
+}
+
+// Start 启动后台清理进程，保留期为 0 时不清理
+func (s *AuditService) Start() {
+	if s.cfg.GetRetention() <= 0 || s.cfg.GetCleanupInterval() <= 0 {
+		return
+	}
+	s.wg.Add(1)
+	go s.cleanupLoop()
+	logger.Info("Audit log cleanup started",
+		zap.Int("retention_days", s.cfg.RetentionDays),
+		zap.Duration("interval", s.cfg.GetCleanupInterval()))
+}
+
+// Stop 停止后台清理进程
+func (s *AuditService) Stop() {
+	close(s.stopChan)
+	s.wg.Wait()
+}
+
+// cleanupLoop 定期删除超过保留期的记录
+func (s *AuditService) cleanupLoop() {
+	defer s.wg.Done()
+
+	s.Purge()
+	ticker := time.NewTicker(s.cfg.GetCleanupInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.Purge()
+		case <-s.stopChan:
+			return
+		}
+	}
+}
+
+// Purge 删除超过保留期的记录
+func (s *AuditService) Purge() {
+	deleted, err := s.repo.DeleteOlderThan(time.Now().Add(-s.cfg.GetRetention()))
+	if err != nil {
+		logger.Error("Failed to purge audit log", zap.Error(err))
+		return
+	}
+	if deleted > 0 {
+		logger.Info("Purged expired audit log entries", zap.Int64("deleted", deleted))
+	}
+}
+
+// diffStates 返回修改前后发生变化的字段，没有变化时返回 nil
+func diffStates(before, after models.JSONMap) models.JSONMap {
+	changes := models.JSONMap{}
+	for key, old := range before {
+		if auditIgnoredFields[key] {
+			continue
+		}
+		if value, ok := after[key]; !ok || !reflect.DeepEqual(old, value) {
+			changes[key] = map[string]interface{}{"from": old, "to": after[key]}
+		}
+	}
+	for key, value := range after {
+		if _, ok := before[key]; !ok && !auditIgnoredFields[key] {
+			changes[key] = map[string]interface{}{"from": nil, "to": value}
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	return changes
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/rotki-demo/internal/config"
+	"github.com/rotki-demo/internal/models"
+	"github.com/rotki-demo/internal/repository"
+)
+
+func TestAuditSnapshotRedactsURLs(t *testing.T) {
+	db := newTestDB(t)
+	s := NewAuditService(repository.NewAuditLogRepository(db), config.AuditConfig{Enabled: true})
+
+	node := models.RPCNode{ChainID: "eth", Name: "alchemy", URL: "https://eth-mainnet.g.alchemy.com/v2/secret-key", WSURL: "wss://eth-mainnet.g.alchemy.com/v2/secret-key?x=1"}
+	if err := db.Create(&node).Error; err != nil {
+		t.Fatalf("create node: %v", err)
+	}
+	endpoint := models.WebhookEndpoint{Name: "slack", URL: "https://hooks.slack.com/services/T000/B000/secret", Secret: "signing-secret", Enabled: true}
+	if err := db.Create(&endpoint).Error; err != nil {
+		t.Fatalf("create endpoint: %v", err)
+	}
+
+	tests := []struct {
+		resource string
+		id       uint
+		want     map[string]interface{}
+		hidden   []string // 不能出现在快照中的字段
+	}{
+		{
+			resource: "rpc-nodes",
+			id:       node.ID,
+			want: map[string]interface{}{
+				"name":   "alchemy",
+				"url":    "https://eth-mainnet.g.alchemy.com/" + config.RedactedValue,
+				"ws_url": "wss://eth-mainnet.g.alchemy.com/" + config.RedactedValue,
+			},
+		},
+		{
+			resource: "webhooks",
+			id:       endpoint.ID,
+			want: map[string]interface{}{
+				"name": "slack",
+				"url":  "https://hooks.slack.com/" + config.RedactedValue,
+			},
+			hidden: []string{"secret"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.resource, func(t *testing.T) {
+			state := s.Snapshot(tt.resource, strconv.FormatUint(uint64(tt.id), 10))
+			if state == nil {
+				t.Fatal("Snapshot returned nil")
+			}
+			for key, want := range tt.want {
+				if state[key] != want {
+					t.Errorf("%s = %v, want %v", key, state[key], want)
+				}
+			}
+			for _, key := range tt.hidden {
+				if _, ok := state[key]; ok {
+					t.Errorf("%s present in snapshot", key)
+				}
+			}
+		})
+	}
+
+	if state := s.Snapshot("rpc-nodes", "999"); state != nil {
+		t.Fatalf("Snapshot of a missing node = %v, want nil", state)
+	}
+}
+
+func TestDiffStates(t *testing.T) {
+	before := models.JSONMap{"name": "a", "weight": float64(1), "updated_at": "t1", "removed": true}
+	after := models.JSONMap{"name": "b", "weight": float64(1), "updated_at": "t2", "added": "x"}
+
+	changes := diffStates(before, after)
+	want := map[string]map[string]interface{}{
+		"name":    {"from": "a", "to": "b"},
+		"removed": {"from": true, "to": nil},
+		"added":   {"from": nil, "to": "x"},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("changes = %v, want %v", changes, want)
+	}
+	for key, change := range want {
+		got, ok := changes[key].(map[string]interface{})
+		if !ok || got["from"] != change["from"] || got["to"] != change["to"] {
+			t.Errorf("changes[%s] = %v, want %v", key, changes[key], change)
+		}
+	}
+
+	if changes := diffStates(before, before); changes != nil {
+		t.Fatalf("diffStates of identical states = %v, want nil", changes)
+	}
+}
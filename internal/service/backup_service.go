@@ -0,0 +1,643 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"reflect"
+	"time"
+
+	"github.com/rotki-demo/internal/models"
+	"github.com/rotki-demo/internal/repository"
+	"gorm.io/gorm"
+)
+
+// BackupFormatVersion 是当前备份格式的版本，格式发生不兼容变化时递增
+const BackupFormatVersion = 1
+
+// 备份文件格式
+const (
+	BackupFormatJSON = "json" // 单个 JSON 文档
+	BackupFormatZIP  = "zip"  // manifest.json 加每个部分一个 JSON 文件
+)
+
+// backupManifestFile 是 ZIP 备份中的清单文件名
+const backupManifestFile = "manifest.json"
+
+// zipMagic 是 ZIP 文件的起始字节，用于识别备份格式
+var zipMagic = []byte("PK\x03\x04")
+
+// 恢复错误
+var (
+	ErrBackupInvalid        = errors.New("invalid backup")                           // 备份版本不受支持或内容不完整
+	ErrBackupTargetNotReady = errors.New("target database is not ready for restore") // 目标数据库有待执行的迁移或已有数据
+)
+
+// BackupManifest 描述备份的来源和内容
+type BackupManifest struct {
+	FormatVersion int            `json:"format_version"`
+	CreatedAt     time.Time      `json:"created_at"`
+	SchemaVersion uint           `json:"schema_version"` // 导出时数据库已应用的最新迁移版本
+	SourceDriver  string         `json:"source_driver"`
+	Counts        map[string]int `json:"counts"` // 每个部分的记录数
+}
+
+// BackupUser 是备份中的用户，包含密码哈希使恢复后可以继续登录
+type BackupUser struct {
+	models.User
+	PasswordHash string `json:"password_hash"`
+}
+
+// BackupAPIKey 是备份中的 API key，包含 key 哈希使恢复后原有 key 继续有效
+type BackupAPIKey struct {
+	models.APIKey
+	KeyHash string `json:"key_hash"`
+}
+
+// BackupWebhook 是备份中的 webhook 端点，包含签名密钥
+type BackupWebhook struct {
+	models.WebhookEndpoint
+	Secret string `json:"secret"`
+}
+
+// BackupArchive 是与 SQL 方言无关的完整备份。
+// 只包含无法重新获取的数据：代币余额、协议持仓、健康因子历史、告警和投递记录、审计日志和登录会话不在其中，恢复后重新同步即可
+type BackupArchive struct {
+	Manifest         BackupManifest           `json:"manifest"`
+	Users            []BackupUser             `json:"users"`
+	APIKeys          []BackupAPIKey           `json:"api_keys"`
+	Chains           []models.Chain           `json:"chains"`
+	RPCNodes         []models.RPCNode         `json:"rpc_nodes"`
+	Wallets          []models.Wallet          `json:"wallets"`
+	WalletShares     []models.WalletShare     `json:"wallet_shares"`
+	Addresses        []models.Address         `json:"addresses"`
+	AlertRules       []models.AlertRule       `json:"alert_rules"`
+	HealthThresholds []models.HealthThreshold `json:"health_thresholds"`
+	TokenRules       []models.TokenRule       `json:"token_rules"`
+	TokenOverrides   []models.TokenOverride   `json:"token_overrides"`
+	Webhooks         []BackupWebhook          `json:"webhooks"`
+	Snapshots        []models.AssetSnapshot   `json:"snapshots"`
+}
+
+// backupSection 是备份中的一个部分，data 为切片的指针
+type backupSection struct {
+	name string
+	data interface{}
+}
+
+// sections 返回备份的所有部分，按恢复时的依赖顺序排列
+func (a *BackupArchive) sections() []backupSection {
+	return []backupSection{
+		{"users", &a.Users},
+		{"api_keys", &a.APIKeys},
+		{"chains", &a.Chains},
+		{"rpc_nodes", &a.RPCNodes},
+		{"wallets", &a.Wallets},
+		{"wallet_shares", &a.WalletShares},
+		{"addresses", &a.Addresses},
+		{"alert_rules", &a.AlertRules},
+		{"health_thresholds", &a.HealthThresholds},
+		{"token_rules", &a.TokenRules},
+		{"token_overrides", &a.TokenOverrides},
+		{"webhooks", &a.Webhooks},
+		{"snapshots", &a.Snapshots},
+	}
+}
+
+// counts 返回每个部分的记录数
+func (a *BackupArchive) counts() map[string]int {
+	counts := make(map[string]int)
+	for _, section := range a.sections() {
+		counts[section.name] = reflect.ValueOf(section.data).Elem().Len()
+	}
+	return counts
+}
+
+// BackupExportOptions 是导出选项
+type BackupExportOptions struct {
+	Snapshots bool // 是否包含资产快照（通常是备份中最大的部分）
+}
+
+// BackupRestoreResult 是恢复的统计
+type BackupRestoreResult struct {
+	Restored       map[string]int `json:"restored"`         // 每个部分写入的记录数
+	MatchedUsers   int            `json:"matched_users"`    // 与目标数据库中同名用户合并的用户数，保留目标中的密码和角色
+	SkippedAPIKeys int            `json:"skipped_api_keys"` // 目标数据库中已存在的 API key
+}
+
+// backupFreshTables 是恢复前必须为空的表；用户、API key 和链可以与已有数据合并
+var backupFreshTables = []struct {
+	name  string
+	model interface{}
+}{
+	{"wallets", &models.Wallet{}},
+	{"addresses", &models.Address{}},
+	{"wallet_shares", &models.WalletShare{}},
+	{"rpc_nodes", &models.RPCNode{}},
+	{"alert_rules", &models.AlertRule{}},
+	{"health_thresholds", &models.HealthThreshold{}},
+	{"token_rules", &models.TokenRule{}},
+	{"token_overrides", &models.TokenOverride{}},
+	{"webhooks", &models.WebhookEndpoint{}},
+	{"snapshots", &models.AssetSnapshot{}},
+}
+
+// backupChainColumns 是恢复链时更新的列，目标数据库中预置的链以备份为准
+var backupChainColumns = append(append([]string{}, repository.ChainMetadataColumns...), "is_active")
+
+// BackupService 导出和恢复可移植的备份，用于在 MySQL、PostgreSQL 和 SQLite 之间迁移或迁移部署
+type BackupService struct {
+	repo   *repository.BackupRepository
+	driver string
+}
+
+// NewBackupService 创建备份服务，driver 为当前数据库驱动，记录在备份清单中
+func NewBackupService(repo *repository.BackupRepository, driver string) *BackupService {
+	return &BackupService{repo: repo, driver: driver}
+}
+
+// Export 在一个事务中读取所有部分，得到一致的备份
+func (s *BackupService) Export(ctx context.Context, opts BackupExportOptions) (*BackupArchive, error) {
+	repo := s.repo.WithContext(ctx)
+	version, _, err := repo.MigrationState()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schema version: %w", err)
+	}
+
+	archive := &BackupArchive{}
+	err = repo.Transaction(func(tx *repository.BackupRepository) error {
+		// 嵌入的模型在 JSON 中隐藏了密码哈希、key 哈希和签名密钥，先读取模型再复制到备份字段
+		var (
+			users    []models.User
+			apiKeys  []models.APIKey
+			webhooks []models.WebhookEndpoint
+		)
+		sections := []backupSection{
+			{"users", &users},
+			{"api_keys", &apiKeys},
+			{"chains", &archive.Chains},
+			{"rpc_nodes", &archive.RPCNodes},
+			{"wallets", &archive.Wallets},
+			{"wallet_shares", &archive.WalletShares},
+			{"addresses", &archive.Addresses},
+			{"alert_rules", &archive.AlertRules},
+			{"health_thresholds", &archive.HealthThresholds},
+			{"token_rules", &archive.TokenRules},
+			{"token_overrides", &archive.TokenOverrides},
+			{"webhooks", &webhooks},
+		}
+		if opts.Snapshots {
+			sections = append(sections, backupSection{"snapshots", &archive.Snapshots})
+		}
+		for _, section := range sections {
+			if err := tx.LoadAll(section.data); err != nil {
+				return fmt.Errorf("failed to export %s: %w", section.name, err)
+			}
+		}
+
+		for _, user := range users {
+			archive.Users = append(archive.Users, BackupUser{User: user, PasswordHash: user.PasswordHash})
+		}
+		for _, key := range apiKeys {
+			archive.APIKeys = append(archive.APIKeys, BackupAPIKey{APIKey: key, KeyHash: key.KeyHash})
+		}
+		for _, endpoint := range webhooks {
+			archive.Webhooks = append(archive.Webhooks, BackupWebhook{WebhookEndpoint: endpoint, Secret: endpoint.Secret})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	archive.Manifest = BackupManifest{
+		FormatVersion: BackupFormatVersion,
+		CreatedAt:     time.Now().UTC(),
+		SchemaVersion: version,
+		SourceDriver:  s.driver,
+		Counts:        archive.counts(),
+	}
+	return archive, nil
+}
+
+// Restore 在一个事务中把备份写入已迁移到最新版本且没有业务数据的数据库。
+// 所有记录由目标数据库重新分配 ID，引用（所有者、钱包、地址、告警范围等）按新 ID 重写；
+// 用户按用户名与已有用户合并，API key 按哈希去重，链按 ID 覆盖元数据
+func (s *BackupService) Restore(ctx context.Context, archive *BackupArchive) (*BackupRestoreResult, error) {
+	if v := archive.Manifest.FormatVersion; v < 1 || v > BackupFormatVersion {
+		return nil, fmt.Errorf("%w: unsupported format version %d (supported: 1-%d)", ErrBackupInvalid, v, BackupFormatVersion)
+	}
+
+	repo := s.repo.WithContext(ctx)
+	version, pending, err := repo.MigrationState()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schema version: %w", err)
+	}
+	if pending > 0 {
+		return nil, fmt.Errorf("%w: %d pending migration(s); run `migrate up` first", ErrBackupTargetNotReady, pending)
+	}
+	if archive.Manifest.SchemaVersion > version {
+		return nil, fmt.Errorf("%w: created with schema version %d, newer than the target database (%d); upgrade the application first",
+			ErrBackupInvalid, archive.Manifest.SchemaVersion, version)
+	}
+
+	for _, table := range backupFreshTables {
+		count, err := repo.Count(table.model)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check %s: %w", table.name, err)
+		}
+		if count > 0 {
+			return nil, fmt.Errorf("%w: %s already has %d row(s)", ErrBackupTargetNotReady, table.name, count)
+		}
+	}
+
+	result := &BackupRestoreResult{Restored: make(map[string]int)}
+	err = repo.Transaction(func(tx *repository.BackupRepository) error {
+		r := &backupRestorer{tx: tx, archive: archive, result: result}
+		return r.run()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// backupRestorer 执行一次恢复并记录旧 ID 到新 ID 的映射
+type backupRestorer struct {
+	tx      *repository.BackupRepository
+	archive *BackupArchive
+	result  *BackupRestoreResult
+
+	users     map[uint]uint
+	wallets   map[uint]uint
+	addresses map[uint]uint
+}
+
+// run 按依赖顺序恢复所有部分
+func (r *backupRestorer) run() error {
+	steps := []struct {
+		name string
+		fn   func() error
+	}{
+		{"users", r.restoreUsers},
+		{"api_keys", r.restoreAPIKeys},
+		{"chains", r.restoreChains},
+		{"rpc_nodes", r.restoreRPCNodes},
+		{"wallets", r.restoreWallets},
+		{"wallet_shares", r.restoreWalletShares},
+		{"addresses", r.restoreAddresses},
+		{"alert_rules", r.restoreAlertRules},
+		{"health_thresholds", r.restoreHealthThresholds},
+		{"token_rules", r.restoreTokenRules},
+		{"token_overrides", r.restoreTokenOverrides},
+		{"webhooks", r.restoreWebhooks},
+		{"snapshots", r.restoreSnapshots},
+	}
+	for _, step := range steps {
+		if err := step.fn(); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", step.name, err)
+		}
+	}
+	return nil
+}
+
+// create 插入一条记录并计数
+func (r *backupRestorer) create(section string, value interface{}) error {
+	if err := r.tx.Create(value); err != nil {
+		return err
+	}
+	r.result.Restored[section]++
+	return nil
+}
+
+// remap 返回旧 ID 对应的新 ID，0 表示不限（全局）时保持为 0
+func remap(ids map[uint]uint, kind string, old uint) (uint, error) {
+	if old == 0 {
+		return 0, nil
+	}
+	id, ok := ids[old]
+	if !ok {
+		return 0, fmt.Errorf("%w: reference to %s %d, which is not in the backup", ErrBackupInvalid, kind, old)
+	}
+	return id, nil
+}
+
+// remapPtr 重写可为空的 ID 引用
+func remapPtr(ids map[uint]uint, kind string, old *uint) (*uint, error) {
+	if old == nil {
+		return nil, nil
+	}
+	id, err := remap(ids, kind, *old)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+func (r *backupRestorer) restoreUsers() error {
+	r.users = make(map[uint]uint, len(r.archive.Users))
+	for _, record := range r.archive.Users {
+		existing, err := r.tx.FindID(&models.User{}, "username", record.Username)
+		if err == nil {
+			r.users[record.ID] = existing
+			r.result.MatchedUsers++
+			continue
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		user := record.User
+		user.ID = 0
+		user.PasswordHash = record.PasswordHash
+		if err := r.create("users", &user); err != nil {
+			return err
+		}
+		r.users[record.ID] = user.ID
+	}
+	return nil
+}
+
+func (r *backupRestorer) restoreAPIKeys() error {
+	for _, record := range r.archive.APIKeys {
+		if _, err := r.tx.FindID(&models.APIKey{}, "key_hash", record.KeyHash); err == nil {
+			r.result.SkippedAPIKeys++
+			continue
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		key := record.APIKey
+		key.ID = 0
+		key.KeyHash = record.KeyHash
+		userID, err := remapPtr(r.users, "user", key.UserID)
+		if err != nil {
+			return err
+		}
+		key.UserID = userID
+		if err := r.create("api_keys", &key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *backupRestorer) restoreChains() error {
+	if len(r.archive.Chains) == 0 {
+		return nil
+	}
+	if err := r.tx.Upsert(&r.archive.Chains, "id", backupChainColumns); err != nil {
+		return err
+	}
+	r.result.Restored["chains"] = len(r.archive.Chains)
+	return nil
+}
+
+func (r *backupRestorer) restoreRPCNodes() error {
+	for _, node := range r.archive.RPCNodes {
+		node.ID = 0
+		if err := r.create("rpc_nodes", &node); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *backupRestorer) restoreWallets() error {
+	r.wallets = make(map[uint]uint, len(r.archive.Wallets))
+	for _, wallet := range r.archive.Wallets {
+		oldID := wallet.ID
+		ownerID, err := remapPtr(r.users, "user", wallet.OwnerID)
+		if err != nil {
+			return err
+		}
+		wallet.ID = 0
+		wallet.OwnerID = ownerID
+		wallet.Addresses = nil
+		if err := r.create("wallets", &wallet); err != nil {
+			return err
+		}
+		r.wallets[oldID] = wallet.ID
+	}
+	return nil
+}
+
+func (r *backupRestorer) restoreWalletShares() error {
+	for _, share := range r.archive.WalletShares {
+		walletID, err := remap(r.wallets, "wallet", share.WalletID)
+		if err != nil {
+			return err
+		}
+		userID, err := remap(r.users, "user", share.UserID)
+		if err != nil {
+			return err
+		}
+		share.ID = 0
+		share.WalletID = walletID
+		share.UserID = userID
+		share.User = nil
+		if err := r.create("wallet_shares", &share); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *backupRestorer) restoreAddresses() error {
+	r.addresses = make(map[uint]uint, len(r.archive.Addresses))
+	for _, address := range r.archive.Addresses {
+		oldID := address.ID
+		walletID, err := remap(r.wallets, "wallet", address.WalletID)
+		if err != nil {
+			return err
+		}
+		// 余额和持仓不在备份中，清空同步时间使地址在下一次定时同步中立即更新
+		address.ID = 0
+		address.WalletID = walletID
+		address.LastSyncedAt = nil
+		address.Wallet = nil
+		address.Tokens = nil
+		address.Protocols = nil
+		address.AssetSnapshots = nil
+		if err := r.create("addresses", &address); err != nil {
+			return err
+		}
+		r.addresses[oldID] = address.ID
+	}
+	return nil
+}
+
+func (r *backupRestorer) restoreAlertRules() error {
+	for _, rule := range r.archive.AlertRules {
+		var err error
+		switch rule.ScopeType {
+		case models.AlertScopeWallet:
+			rule.ScopeID, err = remap(r.wallets, "wallet", rule.ScopeID)
+		case models.AlertScopeAddress:
+			rule.ScopeID, err = remap(r.addresses, "address", rule.ScopeID)
+		}
+		if err != nil {
+			return err
+		}
+		rule.ID = 0
+		if err := r.create("alert_rules", &rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *backupRestorer) restoreHealthThresholds() error {
+	for _, threshold := range r.archive.HealthThresholds {
+		walletID, err := remap(r.wallets, "wallet", threshold.WalletID)
+		if err != nil {
+			return err
+		}
+		threshold.ID = 0
+		threshold.WalletID = walletID
+		if err := r.create("health_thresholds", &threshold); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *backupRestorer) restoreTokenRules() error {
+	for _, rule := range r.archive.TokenRules {
+		rule.ID = 0
+		if err := r.create("token_rules", &rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *backupRestorer) restoreTokenOverrides() error {
+	for _, override := range r.archive.TokenOverrides {
+		walletID, err := remap(r.wallets, "wallet", override.WalletID)
+		if err != nil {
+			return err
+		}
+		override.ID = 0
+		override.WalletID = walletID
+		if err := r.create("token_overrides", &override); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *backupRestorer) restoreWebhooks() error {
+	for _, record := range r.archive.Webhooks {
+		endpoint := record.WebhookEndpoint
+		walletID, err := remap(r.wallets, "wallet", endpoint.WalletID)
+		if err != nil {
+			return err
+		}
+		endpoint.ID = 0
+		endpoint.WalletID = walletID
+		endpoint.Secret = record.Secret
+		if err := r.create("webhooks", &endpoint); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *backupRestorer) restoreSnapshots() error {
+	snapshots := r.archive.Snapshots
+	if len(snapshots) == 0 {
+		return nil
+	}
+	for i := range snapshots {
+		addressID, err := remap(r.addresses, "address", snapshots[i].AddressID)
+		if err != nil {
+			return err
+		}
+		snapshots[i].ID = 0
+		snapshots[i].AddressID = addressID
+	}
+	if err := r.tx.CreateInBatches(&snapshots); err != nil {
+		return err
+	}
+	r.result.Restored["snapshots"] = len(snapshots)
+	return nil
+}
+
+// WriteBackup 以指定格式写出备份
+func WriteBackup(w io.Writer, archive *BackupArchive, format string) error {
+	switch format {
+	case BackupFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(archive)
+
+	case BackupFormatZIP:
+		zw := zip.NewWriter(w)
+		files := append([]backupSection{{backupManifestFile, &archive.Manifest}}, archive.sections()...)
+		for _, file := range files {
+			name := file.name
+			if name != backupManifestFile {
+				name += ".json"
+			}
+			fw, err := zw.Create(name)
+			if err != nil {
+				return err
+			}
+			if err := json.NewEncoder(fw).Encode(file.data); err != nil {
+				return fmt.Errorf("failed to write %s: %w", name, err)
+			}
+		}
+		return zw.Close()
+
+	default:
+		return fmt.Errorf("unsupported backup format %q", format)
+	}
+}
+
+// ReadBackup 读取 JSON 或 ZIP 格式的备份，格式按内容自动识别
+func ReadBackup(r io.Reader) (*BackupArchive, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	archive := &BackupArchive{}
+	if !bytes.HasPrefix(data, zipMagic) {
+		if err := json.Unmarshal(data, archive); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrBackupInvalid, err)
+		}
+		return archive, nil
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBackupInvalid, err)
+	}
+	files := append([]backupSection{{backupManifestFile, &archive.Manifest}}, archive.sections()...)
+	for _, file := range files {
+		name := file.name
+		if name != backupManifestFile {
+			name += ".json"
+		}
+		// 缺少的部分视为空，便于手工裁剪备份
+		f, err := zr.Open(name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		err = json.NewDecoder(f).Decode(file.data)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrBackupInvalid, name, err)
+		}
+	}
+	return archive, nil
+}
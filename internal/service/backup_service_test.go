@@ -0,0 +1,245 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rotki-demo/internal/config"
+	"github.com/rotki-demo/internal/models"
+	"github.com/rotki-demo/internal/repository"
+)
+
+func TestRemap(t *testing.T) {
+	ids := map[uint]uint{10: 1, 11: 2}
+	tests := []struct {
+		name    string
+		old     uint
+		want    uint
+		wantErr bool
+	}{
+		{name: "global", old: 0, want: 0},
+		{name: "mapped", old: 11, want: 2},
+		{name: "not in backup", old: 12, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := remap(ids, "wallet", tt.old)
+			if tt.wantErr {
+				if !errors.Is(err, ErrBackupInvalid) {
+					t.Fatalf("remap(%d) error = %v, want ErrBackupInvalid", tt.old, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("remap(%d) = %d, %v, want %d", tt.old, got, err, tt.want)
+			}
+		})
+	}
+
+	if got, err := remapPtr(ids, "user", nil); got != nil || err != nil {
+		t.Fatalf("remapPtr(nil) = %v, %v, want nil", got, err)
+	}
+	old := uint(10)
+	if got, err := remapPtr(ids, "user", &old); err != nil || got == nil || *got != 1 {
+		t.Fatalf("remapPtr(10) = %v, %v, want 1", got, err)
+	}
+}
+
+// testBackupArchive 返回一个 ID 与空数据库分配的 ID 不同的备份，用于检查引用是否按新 ID 重写
+func testBackupArchive() *BackupArchive {
+	alice, bob := uint(10), uint(11)
+	return &BackupArchive{
+		Manifest: BackupManifest{FormatVersion: BackupFormatVersion},
+		Users: []BackupUser{
+			{User: models.User{ID: alice, Username: "alice", Role: models.RoleOperator}, PasswordHash: "alice-hash"},
+			{User: models.User{ID: bob, Username: "bob", Role: models.RoleOperator}, PasswordHash: "bob-hash"},
+		},
+		APIKeys: []BackupAPIKey{
+			{APIKey: models.APIKey{ID: 5, Name: "alice-key", KeyPrefix: "rk_a", Role: models.RoleOperator, UserID: &alice}, KeyHash: "alice-key-hash"},
+		},
+		Wallets: []models.Wallet{
+			{ID: 20, Name: "main", OwnerID: &alice},
+			{ID: 21, Name: "legacy"},
+		},
+		WalletShares: []models.WalletShare{
+			{ID: 1, WalletID: 20, UserID: bob, Permission: models.SharePermissionView},
+		},
+		Addresses: []models.Address{
+			{ID: 30, WalletID: 20, Address: "0xmain", ChainType: "EVM"},
+			{ID: 31, WalletID: 21, Address: "0xlegacy", ChainType: "EVM"},
+		},
+		AlertRules: []models.AlertRule{
+			{ID: 1, Name: "wallet", RuleType: models.AlertRuleNetWorthDrop, ScopeType: models.AlertScopeWallet, ScopeID: 21},
+			{ID: 2, Name: "address", RuleType: models.AlertRuleNetWorthDrop, ScopeType: models.AlertScopeAddress, ScopeID: 31},
+			{ID: 3, Name: "tag", RuleType: models.AlertRuleNetWorthDrop, ScopeType: models.AlertScopeTag, ScopeTag: "defi"},
+		},
+		HealthThresholds: []models.HealthThreshold{
+			{ID: 1, WalletID: 20, ProtocolID: "aave", PositionKey: "pool", Threshold: 1.2},
+		},
+		TokenOverrides: []models.TokenOverride{
+			{ID: 1, ChainID: "eth", TokenID: "0xglobal", Action: "ignore"},
+			{ID: 2, ChainID: "eth", TokenID: "0xwallet", WalletID: 21, Action: "ignore"},
+		},
+		Webhooks: []BackupWebhook{
+			{WebhookEndpoint: models.WebhookEndpoint{ID: 3, Name: "hook", URL: "https://example.com/hook", WalletID: 20}, Secret: "hook-secret"},
+		},
+		Snapshots: []models.AssetSnapshot{
+			{ID: 40, AddressID: 31, TotalUSDValue: 42},
+		},
+	}
+}
+
+func TestBackupRestoreRemapsIDs(t *testing.T) {
+	db := newTestDB(t)
+	// 目标数据库中已有的用户使新分配的 ID 与备份不同，同名用户按用户名合并
+	for _, user := range []models.User{
+		{Username: "admin", PasswordHash: "admin-hash", Role: models.RoleAdmin},
+		{Username: "bob", PasswordHash: "target-bob-hash", Role: models.RoleReadOnly},
+	} {
+		if err := db.Create(&user).Error; err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+	}
+
+	// 通过 ZIP 格式往返，覆盖实际的导入路径
+	var buf bytes.Buffer
+	if err := WriteBackup(&buf, testBackupArchive(), BackupFormatZIP); err != nil {
+		t.Fatalf("WriteBackup: %v", err)
+	}
+	archive, err := ReadBackup(&buf)
+	if err != nil {
+		t.Fatalf("ReadBackup: %v", err)
+	}
+
+	svc := NewBackupService(repository.NewBackupRepository(db), config.DriverSQLite)
+	result, err := svc.Restore(context.Background(), archive)
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if result.MatchedUsers != 1 || result.Restored["users"] != 1 {
+		t.Fatalf("users: matched %d, restored %d, want 1 and 1", result.MatchedUsers, result.Restored["users"])
+	}
+
+	userID := func(name string) uint {
+		var user models.User
+		if err := db.Where("username = ?", name).First(&user).Error; err != nil {
+			t.Fatalf("user %s: %v", name, err)
+		}
+		return user.ID
+	}
+	walletID := func(name string) uint {
+		var wallet models.Wallet
+		if err := db.Where("name = ?", name).First(&wallet).Error; err != nil {
+			t.Fatalf("wallet %s: %v", name, err)
+		}
+		return wallet.ID
+	}
+	addressID := func(address string) uint {
+		var record models.Address
+		if err := db.Where("address = ?", address).First(&record).Error; err != nil {
+			t.Fatalf("address %s: %v", address, err)
+		}
+		return record.ID
+	}
+	alice, bob := userID("alice"), userID("bob")
+	main, legacy := walletID("main"), walletID("legacy")
+	if alice == 10 || main == 20 {
+		t.Fatalf("restored ids %d/%d equal the backup ids, remapping is not exercised", alice, main)
+	}
+
+	var bobUser models.User
+	db.First(&bobUser, bob)
+	if bobUser.PasswordHash != "target-bob-hash" || bobUser.Role != models.RoleReadOnly {
+		t.Fatalf("merged user bob = %+v, want the target's password and role", bobUser)
+	}
+
+	var key models.APIKey
+	if err := db.Where("key_hash = ?", "alice-key-hash").First(&key).Error; err != nil {
+		t.Fatalf("api key: %v", err)
+	}
+	if key.UserID == nil || *key.UserID != alice {
+		t.Fatalf("api key user = %v, want %d", key.UserID, alice)
+	}
+
+	var wallet models.Wallet
+	db.First(&wallet, main)
+	if wallet.OwnerID == nil || *wallet.OwnerID != alice {
+		t.Fatalf("wallet owner = %v, want %d", wallet.OwnerID, alice)
+	}
+	var legacyWallet models.Wallet
+	db.First(&legacyWallet, legacy)
+	if legacyWallet.OwnerID != nil {
+		t.Fatalf("legacy wallet owner = %d, want nil", *legacyWallet.OwnerID)
+	}
+
+	var share models.WalletShare
+	if err := db.First(&share).Error; err != nil {
+		t.Fatalf("share: %v", err)
+	}
+	if share.WalletID != main || share.UserID != bob {
+		t.Fatalf("share = wallet %d user %d, want wallet %d user %d", share.WalletID, share.UserID, main, bob)
+	}
+
+	var address models.Address
+	db.First(&address, addressID("0xlegacy"))
+	if address.WalletID != legacy {
+		t.Fatalf("address wallet = %d, want %d", address.WalletID, legacy)
+	}
+
+	var rules []models.AlertRule
+	db.Order("name").Find(&rules)
+	wantScopes := map[string]uint{"address": addressID("0xlegacy"), "tag": 0, "wallet": legacy}
+	for _, rule := range rules {
+		if rule.ScopeID != wantScopes[rule.Name] {
+			t.Fatalf("alert rule %s scope id = %d, want %d", rule.Name, rule.ScopeID, wantScopes[rule.Name])
+		}
+	}
+	if len(rules) != len(wantScopes) {
+		t.Fatalf("restored %d alert rules, want %d", len(rules), len(wantScopes))
+	}
+
+	var threshold models.HealthThreshold
+	db.First(&threshold)
+	if threshold.WalletID != main {
+		t.Fatalf("threshold wallet = %d, want %d", threshold.WalletID, main)
+	}
+
+	var overrides []models.TokenOverride
+	db.Order("token_id").Find(&overrides)
+	if len(overrides) != 2 || overrides[0].WalletID != 0 || overrides[1].WalletID != legacy {
+		t.Fatalf("overrides = %+v, want a global override and one for wallet %d", overrides, legacy)
+	}
+
+	var endpoint models.WebhookEndpoint
+	db.First(&endpoint)
+	if endpoint.WalletID != main || endpoint.Secret != "hook-secret" {
+		t.Fatalf("webhook = wallet %d secret %q, want wallet %d and the backed up secret", endpoint.WalletID, endpoint.Secret, main)
+	}
+
+	var snapshot models.AssetSnapshot
+	db.First(&snapshot)
+	if snapshot.AddressID != addressID("0xlegacy") {
+		t.Fatalf("snapshot address = %d, want %d", snapshot.AddressID, addressID("0xlegacy"))
+	}
+}
+
+func TestBackupRestoreRejectsDanglingReference(t *testing.T) {
+	db := newTestDB(t)
+	archive := testBackupArchive()
+	archive.Addresses = append(archive.Addresses, models.Address{ID: 32, WalletID: 99, Address: "0xorphan", ChainType: "EVM"})
+
+	svc := NewBackupService(repository.NewBackupRepository(db), config.DriverSQLite)
+	if _, err := svc.Restore(context.Background(), archive); !errors.Is(err, ErrBackupInvalid) {
+		t.Fatalf("Restore error = %v, want ErrBackupInvalid", err)
+	}
+
+	// 恢复在一个事务中进行，失败时不留下部分数据
+	var count int64
+	db.Model(&models.Wallet{}).Count(&count)
+	if count != 0 {
+		t.Fatalf("%d wallet(s) left after a failed restore, want 0", count)
+	}
+}
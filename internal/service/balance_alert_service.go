@@ -0,0 +1,467 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rotki-demo/internal/config"
+	"github.com/rotki-demo/internal/logger"
+	"github.com/rotki-demo/internal/models"
+	"github.com/rotki-demo/internal/notifier"
+	"github.com/rotki-demo/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// snapshotDataSource 是同步后写入快照的数据来源
+const snapshotDataSource = "sync"
+
+// maxMessageItems 是告警消息中最多列出的代币数量，完整列表保存在 details 中
+const maxMessageItems = 5
+
+// SnapshotHolding 是快照中的一个代币持仓
+type SnapshotHolding struct {
+	ChainID    string  `json:"chain_id"`
+	TokenID    string  `json:"token_id"`
+	Symbol     string  `json:"symbol"`
+	ProtocolID string  `json:"protocol_id,omitempty"`
+	Amount     float64 `json:"amount"`
+	USDValue   float64 `json:"usd_value"`
+	IsDebt     bool    `json:"is_debt,omitempty"`
+}
+
+// key 返回持仓的比较键
+func (h SnapshotHolding) key() string {
+	return tokenKey(h.ChainID, h.TokenID, h.ProtocolID)
+}
+
+// isDebt 检查持仓是否为债务
+func (h SnapshotHolding) isDebt() bool {
+	return h.IsDebt || h.USDValue < 0
+}
+
+// addressSnapshot 是用于比较的快照内容
+type addressSnapshot struct {
+	Total    float64
+	Holdings map[string]SnapshotHolding
+}
+
+// HoldingChange 是 token_balance_change 告警中一个代币的变化
+type HoldingChange struct {
+	SnapshotHolding
+	PreviousAmount   float64 `json:"previous_amount"`
+	PreviousUSDValue float64 `json:"previous_usd_value"`
+	ChangePercent    float64 `json:"change_percent"`
+	ChangeUSD        float64 `json:"change_usd"`
+}
+
+// BalanceAlertService 在每次同步后写入资产快照，并根据告警规则与上一次快照比较
+type BalanceAlertService struct {
+	ruleRepo     *repository.AlertRuleRepository
+	snapshotRepo *repository.SnapshotRepository
+	tokenRepo    *repository.TokenRepository
+	notifier     notifier.Notifier
+	cfg          config.AlertsConfig
+}
+
+// NewBalanceAlertService 创建一个新的余额告警服务
+func NewBalanceAlertService(
+	ruleRepo *repository.AlertRuleRepository,
+	snapshotRepo *repository.SnapshotRepository,
+	tokenRepo *repository.TokenRepository,
+	n notifier.Notifier,
+	cfg config.AlertsConfig,
+) *BalanceAlertService {
+	return &BalanceAlertService{
+		ruleRepo:     ruleRepo,
+		snapshotRepo: snapshotRepo,
+		tokenRepo:    tokenRepo,
+		notifier:     n,
+		cfg:          cfg,
+	}
+}
+
+// DefaultCooldown 返回规则未设置冷却时间时使用的默认值（分钟）
+func (s *BalanceAlertService) DefaultCooldown() int {
+	return s.cfg.DefaultCooldown
+}
+
+// ValidateAlertRule 校验告警规则
+func ValidateAlertRule(rule *models.AlertRule) error {
+	switch rule.RuleType {
+	case models.AlertRuleNetWorthDrop, models.AlertRuleTokenBalanceChange:
+		if rule.ThresholdPercent == nil && rule.ThresholdUSD == nil {
+			return fmt.Errorf("%s requires threshold_percent or threshold_usd", rule.RuleType)
+		}
+	case models.AlertRuleTokenDisappeared, models.AlertRuleNewDebt:
+	default:
+		return fmt.Errorf("invalid rule_type: %s", rule.RuleType)
+	}
+
+	if rule.ThresholdPercent != nil && *rule.ThresholdPercent <= 0 {
+		return fmt.Errorf("threshold_percent must be positive")
+	}
+	if rule.ThresholdUSD != nil && *rule.ThresholdUSD < 0 {
+		return fmt.Errorf("threshold_usd must not be negative")
+	}
+	if rule.CooldownMinutes < 0 {
+		return fmt.Errorf("cooldown_minutes must not be negative")
+	}
+
+	switch rule.ScopeType {
+	case models.AlertScopeAll:
+	case models.AlertScopeWallet, models.AlertScopeAddress:
+		if rule.ScopeID == 0 {
+			return fmt.Errorf("scope_id is required for scope_type %s", rule.ScopeType)
+		}
+	case models.AlertScopeTag:
+		if rule.ScopeTag == "" {
+			return fmt.Errorf("scope_tag is required for scope_type tag")
+		}
+	default:
+		return fmt.Errorf("invalid scope_type: %s", rule.ScopeType)
+	}
+	return nil
+}
+
+// RecordAndEvaluate 写入地址的资产快照，并用上一次快照评估所有适用的告警规则
+// 失败只记录日志，不影响同步结果
+func (s *BalanceAlertService) RecordAndEvaluate(ctx context.Context, address *models.Address) {
+	if err := s.recordAndEvaluate(ctx, address); err != nil {
+		logger.Error("Failed to evaluate balance alerts",
+			zap.Uint("address_id", address.ID),
+			zap.Error(err),
+		)
+	}
+}
+
+// recordAndEvaluate 写入快照并评估规则
+func (s *BalanceAlertService) recordAndEvaluate(ctx context.Context, address *models.Address) error {
+	tokens, err := s.tokenRepo.GetByAddressID(address.ID, false)
+	if err != nil {
+		return fmt.Errorf("failed to get tokens: %w", err)
+	}
+	holdings, total := holdingsFromTokens(tokens)
+
+	var previous *addressSnapshot
+	last, err := s.snapshotRepo.GetLatestByAddressID(address.ID)
+	switch {
+	case err == nil:
+		previous, err = decodeSnapshot(last)
+		if err != nil {
+			logger.Warn("Failed to decode previous snapshot", zap.Uint("snapshot_id", last.ID), zap.Error(err))
+		}
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return fmt.Errorf("failed to get previous snapshot: %w", err)
+	}
+
+	if err := s.snapshotRepo.Create(&models.AssetSnapshot{
+		AddressID:     address.ID,
+		SnapshotTime:  time.Now(),
+		TotalUSDValue: total,
+		DataSource:    snapshotDataSource,
+		RawData:       models.JSONMap{"holdings": holdings},
+	}); err != nil {
+		return fmt.Errorf("failed to create snapshot: %w", err)
+	}
+
+	if previous == nil {
+		return nil
+	}
+	current := &addressSnapshot{Total: total, Holdings: indexHoldings(holdings)}
+
+	rules, err := s.ruleRepo.ListEnabled()
+	if err != nil {
+		return fmt.Errorf("failed to list alert rules: %w", err)
+	}
+
+	now := time.Now()
+	for i := range rules {
+		rule := &rules[i]
+		if !alertRuleApplies(rule, address) {
+			continue
+		}
+
+		message, details := evaluateAlertRule(rule, previous, current)
+		if message == "" {
+			continue
+		}
+
+		if s.inCooldown(rule, address.ID, now) {
+			logger.Debug("Balance alert suppressed by cooldown",
+				zap.Uint("rule_id", rule.ID),
+				zap.Uint("address_id", address.ID),
+			)
+			continue
+		}
+
+		event := &models.AlertEvent{
+			RuleID:    rule.ID,
+			WalletID:  address.WalletID,
+			AddressID: address.ID,
+			RuleType:  rule.RuleType,
+			Message:   truncate(fmt.Sprintf("%s (%s): %s", rule.Name, address.Address, message), 1000),
+			Details:   details,
+		}
+		if err := s.ruleRepo.CreateEvent(event); err != nil {
+			logger.Error("Failed to create alert event", zap.Uint("rule_id", rule.ID), zap.Error(err))
+			continue
+		}
+		s.deliver(ctx, event)
+	}
+	return nil
+}
+
+// inCooldown 检查规则对该地址是否仍在冷却期内
+func (s *BalanceAlertService) inCooldown(rule *models.AlertRule, addressID uint, now time.Time) bool {
+	if rule.CooldownMinutes <= 0 {
+		return false
+	}
+	last, err := s.ruleRepo.LastEventTime(rule.ID, addressID)
+	if err != nil {
+		logger.Warn("Failed to get last alert time", zap.Uint("rule_id", rule.ID), zap.Error(err))
+		return false
+	}
+	return last != nil && now.Sub(*last) < time.Duration(rule.CooldownMinutes)*time.Minute
+}
+
+// deliver 通过通知渠道发送告警并记录投递结果
+func (s *BalanceAlertService) deliver(ctx context.Context, event *models.AlertEvent) {
+	severity := notifier.SeverityWarning
+	if event.RuleType == models.AlertRuleNetWorthDrop {
+		severity = notifier.SeverityCritical
+	}
+
+	err := s.notifier.Notify(ctx, notifier.Notification{
+		Title:    "Balance alert: " + event.RuleType,
+		Message:  event.Message,
+		Severity: severity,
+		Fields: map[string]string{
+			"rule_id":    fmt.Sprint(event.RuleID),
+			"wallet_id":  fmt.Sprint(event.WalletID),
+			"address_id": fmt.Sprint(event.AddressID),
+		},
+		Time: event.CreatedAt,
+	})
+
+	deliveryError := ""
+	if err != nil {
+		deliveryError = truncate(err.Error(), 1000)
+		logger.Warn("Failed to deliver balance alert", zap.Uint("alert_id", event.ID), zap.Error(err))
+	}
+	if err := s.ruleRepo.UpdateEventDelivery(event.ID, err == nil, deliveryError); err != nil {
+		logger.Error("Failed to update alert delivery", zap.Uint("alert_id", event.ID), zap.Error(err))
+	}
+}
+
+// alertRuleApplies 检查规则的作用范围是否包含地址；tag 范围同时匹配地址和所属钱包的标签
+func alertRuleApplies(rule *models.AlertRule, address *models.Address) bool {
+	switch rule.ScopeType {
+	case models.AlertScopeAll:
+		return true
+	case models.AlertScopeWallet:
+		return address.WalletID == rule.ScopeID
+	case models.AlertScopeAddress:
+		return address.ID == rule.ScopeID
+	case models.AlertScopeTag:
+		if containsTag(address.Tags, rule.ScopeTag) {
+			return true
+		}
+		return address.Wallet != nil && containsTag(address.Wallet.Tags, rule.ScopeTag)
+	}
+	return false
+}
+
+// evaluateAlertRule 比较两次快照，返回告警消息和详情；消息为空表示未触发
+func evaluateAlertRule(rule *models.AlertRule, previous, current *addressSnapshot) (string, models.JSONMap) {
+	switch rule.RuleType {
+	case models.AlertRuleNetWorthDrop:
+		drop := previous.Total - current.Total
+		if drop <= 0 {
+			return "", nil
+		}
+		dropPercent := 0.0
+		if previous.Total > 0 {
+			dropPercent = drop / previous.Total * 100
+		}
+		if !exceeds(rule, dropPercent, drop) {
+			return "", nil
+		}
+		return fmt.Sprintf("net worth dropped %.2f%% ($%.2f) from $%.2f to $%.2f", dropPercent, drop, previous.Total, current.Total),
+			models.JSONMap{
+				"previous_usd_value": previous.Total,
+				"current_usd_value":  current.Total,
+				"drop_usd":           drop,
+				"drop_percent":       dropPercent,
+			}
+
+	case models.AlertRuleTokenBalanceChange:
+		var changes []HoldingChange
+		for key, cur := range current.Holdings {
+			prev, ok := previous.Holdings[key]
+			if !ok || !matchesHolding(rule, cur) {
+				continue
+			}
+			change := HoldingChange{
+				SnapshotHolding:  cur,
+				PreviousAmount:   prev.Amount,
+				PreviousUSDValue: prev.USDValue,
+				ChangeUSD:        cur.USDValue - prev.USDValue,
+			}
+			if prev.Amount != 0 {
+				change.ChangePercent = (cur.Amount - prev.Amount) / absFloat(prev.Amount) * 100
+			}
+			if exceeds(rule, absFloat(change.ChangePercent), absFloat(change.ChangeUSD)) {
+				changes = append(changes, change)
+			}
+		}
+		if len(changes) == 0 {
+			return "", nil
+		}
+		sort.Slice(changes, func(i, j int) bool { return absFloat(changes[i].ChangeUSD) > absFloat(changes[j].ChangeUSD) })
+		items := make([]string, 0, len(changes))
+		for _, c := range changes {
+			items = append(items, fmt.Sprintf("%s %+.2f%% ($%+.2f)", c.Symbol, c.ChangePercent, c.ChangeUSD))
+		}
+		return fmt.Sprintf("%d token balance(s) changed: %s", len(changes), summarize(items)),
+			models.JSONMap{"changes": changes}
+
+	case models.AlertRuleTokenDisappeared:
+		gone := diffHoldings(previous, current, func(h SnapshotHolding) bool {
+			return !h.isDebt() && matchesHolding(rule, h) && aboveMinimum(rule, h.USDValue)
+		})
+		if len(gone) == 0 {
+			return "", nil
+		}
+		items := make([]string, 0, len(gone))
+		for _, h := range gone {
+			items = append(items, fmt.Sprintf("%s ($%.2f)", h.Symbol, h.USDValue))
+		}
+		return fmt.Sprintf("%d token(s) disappeared: %s", len(gone), summarize(items)),
+			models.JSONMap{"tokens": gone}
+
+	case models.AlertRuleNewDebt:
+		debts := diffHoldings(current, previous, func(h SnapshotHolding) bool {
+			return h.isDebt() && matchesHolding(rule, h) && aboveMinimum(rule, h.USDValue)
+		})
+		if len(debts) == 0 {
+			return "", nil
+		}
+		items := make([]string, 0, len(debts))
+		for _, h := range debts {
+			items = append(items, fmt.Sprintf("%s on %s ($%.2f)", h.Symbol, h.ProtocolID, absFloat(h.USDValue)))
+		}
+		return fmt.Sprintf("%d new debt position(s): %s", len(debts), summarize(items)),
+			models.JSONMap{"tokens": debts}
+	}
+	return "", nil
+}
+
+// exceeds 检查变化是否超过规则的任一阈值
+func exceeds(rule *models.AlertRule, percent, usd float64) bool {
+	if rule.ThresholdPercent != nil && percent >= *rule.ThresholdPercent {
+		return true
+	}
+	return rule.ThresholdUSD != nil && usd >= *rule.ThresholdUSD
+}
+
+// aboveMinimum 检查金额是否达到规则的最小金额（threshold_usd），未设置时总是满足
+func aboveMinimum(rule *models.AlertRule, usdValue float64) bool {
+	return rule.ThresholdUSD == nil || absFloat(usdValue) >= *rule.ThresholdUSD
+}
+
+// matchesHolding 检查持仓是否满足规则的链和代币过滤
+func matchesHolding(rule *models.AlertRule, h SnapshotHolding) bool {
+	if rule.ChainID != "" && rule.ChainID != h.ChainID {
+		return false
+	}
+	return rule.TokenID == "" || strings.EqualFold(rule.TokenID, h.TokenID)
+}
+
+// diffHoldings 返回 from 中存在而 to 中不存在且满足条件的持仓，按金额从大到小排序
+func diffHoldings(from, to *addressSnapshot, keep func(SnapshotHolding) bool) []SnapshotHolding {
+	var result []SnapshotHolding
+	for key, h := range from.Holdings {
+		if _, ok := to.Holdings[key]; !ok && keep(h) {
+			result = append(result, h)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return absFloat(result[i].USDValue) > absFloat(result[j].USDValue) })
+	return result
+}
+
+// holdingsFromTokens 将代币转换为快照持仓并计算总价值
+func holdingsFromTokens(tokens []models.Token) ([]SnapshotHolding, float64) {
+	holdings := make([]SnapshotHolding, 0, len(tokens))
+	var total float64
+	for _, token := range tokens {
+		amount, _ := strconv.ParseFloat(token.Balance, 64)
+		holdings = append(holdings, SnapshotHolding{
+			ChainID:    token.ChainID,
+			TokenID:    token.TokenID,
+			Symbol:     token.Symbol,
+			ProtocolID: token.ProtocolID,
+			Amount:     amount,
+			USDValue:   token.USDValue,
+			IsDebt:     token.IsDebt,
+		})
+		total += token.USDValue
+	}
+	return holdings, total
+}
+
+// decodeSnapshot 从快照的 raw_data 中读取持仓
+func decodeSnapshot(snapshot *models.AssetSnapshot) (*addressSnapshot, error) {
+	var holdings []SnapshotHolding
+	if raw, ok := snapshot.RawData["holdings"]; ok {
+		data, err := json.Marshal(raw)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &holdings); err != nil {
+			return nil, err
+		}
+	}
+	return &addressSnapshot{Total: snapshot.TotalUSDValue, Holdings: indexHoldings(holdings)}, nil
+}
+
+// indexHoldings 按比较键索引持仓
+func indexHoldings(holdings []SnapshotHolding) map[string]SnapshotHolding {
+	index := make(map[string]SnapshotHolding, len(holdings))
+	for _, h := range holdings {
+		index[h.key()] = h
+	}
+	return index
+}
+
+// containsTag 检查标签列表是否包含指定标签（不区分大小写）
+func containsTag(tags models.StringSlice, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// summarize 拼接前几项，其余以数量表示
+func summarize(items []string) string {
+	if len(items) <= maxMessageItems {
+		return strings.Join(items, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(items[:maxMessageItems], ", "), len(items)-maxMessageItems)
+}
+
+// truncate 将字符串截断到最大长度
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max]
+}
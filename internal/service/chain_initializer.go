@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -8,6 +9,7 @@ import (
 
 	"github.com/rotki-demo/internal/logger"
 	"github.com/rotki-demo/internal/models"
+	"github.com/rotki-demo/internal/provider"
 	"github.com/rotki-demo/internal/repository"
 	"go.uber.org/zap"
 )
@@ -31,11 +33,13 @@ type DeBankChainInfo struct {
 	LogoURL       string  `json:"logo_url"`
 	TokenID       string  `json:"token_id"`
 	TokenSymbol   string  `json:"token_symbol"`
-	NetworkID     int     `json:"network_id"`
+	NetworkID     int64   `json:"network_id"`
+	ExplorerHost  string  `json:"explorer_host"`
 	BlockInterval float64 `json:"block_interval"`
 }
 
 // InitializeAllChains 从 chains.json 文件加载所有链并填充数据库
+// 新链以未激活状态插入，已有链只更新元数据，不修改 is_active
 func (ci *ChainInitializer) InitializeAllChains(chainsFilePath string) error {
 	logger.Info("Initializing chains from file", zap.String("path", chainsFilePath))
 
@@ -62,13 +66,14 @@ func (ci *ChainInitializer) InitializeAllChains(chainsFilePath string) error {
 			ChainType:     "EVM", // DeBank 中的所有链都是 EVM 兼容的
 			LogoURL:       dc.LogoURL,
 			NativeTokenID: dc.TokenID,
-			IsActive:      true,
+			NetworkID:     dc.NetworkID,
+			ExplorerURL:   dc.ExplorerHost,
 		}
 		chains = append(chains, chain)
 	}
 
 	// 批量更新或插入所有链
-	if err := ci.chainRepo.UpsertBatch(chains); err != nil {
+	if err := ci.chainRepo.UpsertBatch(chains, repository.ChainMetadataColumns...); err != nil {
 		return fmt.Errorf("failed to upsert chains: %w", err)
 	}
 
@@ -76,8 +81,39 @@ func (ci *ChainInitializer) InitializeAllChains(chainsFilePath string) error {
 	return nil
 }
 
-// InitializeAllChainsFromDefault 从默认位置加载链
-func (ci *ChainInitializer) InitializeAllChainsFromDefault() error {
+// SyncFromProvider 从数据提供者的链列表同步链元数据，返回同步的链数量
+// 与 InitializeAllChains 相同，新链以未激活状态插入
+func (ci *ChainInitializer) SyncFromProvider(ctx context.Context, dataProvider provider.DataProvider) (int, error) {
+	logger.Info("Syncing chains from provider", zap.String("provider", dataProvider.GetName()))
+
+	providerChains, err := dataProvider.GetChainList(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get chain list: %w", err)
+	}
+
+	chains := make([]models.Chain, 0, len(providerChains))
+	for _, pc := range providerChains {
+		chains = append(chains, models.Chain{
+			ID:            pc.ChainID,
+			Name:          pc.Name,
+			ChainType:     "EVM",
+			LogoURL:       pc.LogoURL,
+			NativeTokenID: pc.NativeTokenID,
+			NetworkID:     pc.NetworkID,
+		})
+	}
+
+	// 提供者不返回浏览器地址，避免覆盖从 chains.json 或手动设置的值
+	if err := ci.chainRepo.UpsertBatch(chains, "name", "chain_type", "logo_url", "native_token_id", "network_id"); err != nil {
+		return 0, fmt.Errorf("failed to upsert chains: %w", err)
+	}
+
+	logger.Info("Successfully synced chains from provider", zap.Int("count", len(chains)))
+	return len(chains), nil
+}
+
+// FindDefaultChainsFile 返回第一个存在的默认 chains.json 路径
+func FindDefaultChainsFile() (string, bool) {
 	// 尝试多个可能的位置
 	possiblePaths := []string{
 		"frontend/public/images/chains/chains.json",
@@ -89,11 +125,20 @@ func (ci *ChainInitializer) InitializeAllChainsFromDefault() error {
 	for _, path := range possiblePaths {
 		absPath, _ := filepath.Abs(path)
 		if _, err := os.Stat(absPath); err == nil {
-			logger.Info("Found chains file", zap.String("path", absPath))
-			return ci.InitializeAllChains(absPath)
+			return absPath, true
 		}
 	}
 
+	return "", false
+}
+
+// InitializeAllChainsFromDefault 从默认位置加载链
+func (ci *ChainInitializer) InitializeAllChainsFromDefault() error {
+	if path, ok := FindDefaultChainsFile(); ok {
+		logger.Info("Found chains file", zap.String("path", path))
+		return ci.InitializeAllChains(path)
+	}
+
 	logger.Warn("Could not find chains.json file in any default location, chains will be populated dynamically")
 	return nil
 }
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rotki-demo/internal/config"
+	"github.com/rotki-demo/internal/logger"
+	"go.uber.org/zap"
+)
+
+// ReloadableConfigKeys 是修改配置文件后无需重启即可生效的配置键，其他键的修改在重启后生效
+var ReloadableConfigKeys = []string{
+	"sync.interval",
+	"sync.batch_size",
+	"debank.rate_limit.requests_per_second",
+	"debank.rate_limit.burst",
+	"log.level",
+	"token_rules.use_defaults",
+	"token_rules.rules",
+}
+
+// RateLimitSetter 是可以在运行时修改速率限制的数据提供者
+type RateLimitSetter interface {
+	SetRateLimit(cfg config.RateLimitConfig)
+}
+
+// ConfigStatus 是当前生效的配置及热加载状态，敏感值已隐藏
+type ConfigStatus struct {
+	ConfigFile     string                 `json:"config_file"`
+	Config         map[string]interface{} `json:"config"`
+	Reloadable     []string               `json:"reloadable"`
+	PendingRestart []string               `json:"pending_restart"` // 配置文件中已修改但需要重启才能生效的键
+	LoadedAt       time.Time              `json:"loaded_at"`
+	ReloadedAt     *time.Time             `json:"reloaded_at,omitempty"`
+	LastError      string                 `json:"last_error,omitempty"` // 最近一次重新加载失败的原因，成功加载后清空
+	LastErrorAt    *time.Time             `json:"last_error_at,omitempty"`
+}
+
+// ConfigService 保存当前生效的配置，监听配置文件并把可热加载的修改应用到各组件
+type ConfigService struct {
+	syncService *SyncService
+	rateLimiter RateLimitSetter
+	classifier  *TokenClassifier
+
+	mu             sync.RWMutex
+	active         *config.Config
+	pendingRestart []string
+	loadedAt       time.Time
+	reloadedAt     *time.Time
+	lastError      string
+	lastErrorAt    *time.Time
+}
+
+// NewConfigService 创建配置服务，cfg 为启动时加载的配置
+func NewConfigService(cfg *config.Config, syncService *SyncService, rateLimiter RateLimitSetter, classifier *TokenClassifier) *ConfigService {
+	return &ConfigService{
+		syncService: syncService,
+		rateLimiter: rateLimiter,
+		classifier:  classifier,
+		active:      cfg,
+		loadedAt:    time.Now(),
+	}
+}
+
+// Watch 开始监听配置文件变化
+func (s *ConfigService) Watch() {
+	config.Watch(s.apply, s.reject)
+	logger.Info("Watching config file for changes", zap.String("file", config.ConfigFile()))
+}
+
+// Status 返回当前生效的配置（敏感值已隐藏）和热加载状态
+func (s *ConfigService) Status() *ConfigStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return &ConfigStatus{
+		ConfigFile:     config.ConfigFile(),
+		Config:         s.active.Redacted(),
+		Reloadable:     ReloadableConfigKeys,
+		PendingRestart: append([]string{}, s.pendingRestart...),
+		LoadedAt:       s.loadedAt,
+		ReloadedAt:     s.reloadedAt,
+		LastError:      s.lastError,
+		LastErrorAt:    s.lastErrorAt,
+	}
+}
+
+// apply 应用重新加载的配置：可热加载的键立即生效，其他修改记录为待重启
+func (s *ConfigService) apply(next *config.Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old := s.active
+	changed := config.Diff(old, next)
+
+	// 新的生效配置只包含可热加载的修改
+	active := *old
+	active.Sync.Interval = next.Sync.Interval
+	active.Sync.BatchSize = next.Sync.BatchSize
+	active.DeBank.RateLimit = next.DeBank.RateLimit
+	active.Log.Level = next.Log.Level
+	active.TokenRules = next.TokenRules
+
+	var applied []string
+	for _, key := range changed {
+		if slices.Contains(ReloadableConfigKeys, key) {
+			applied = append(applied, key)
+		}
+	}
+
+	if containsAny(applied, "sync.interval", "sync.batch_size") {
+		s.syncService.SetSchedule(active.Sync.GetSyncInterval(), active.Sync.BatchSize)
+	}
+	if containsAny(applied, "debank.rate_limit.requests_per_second", "debank.rate_limit.burst") {
+		s.rateLimiter.SetRateLimit(active.DeBank.RateLimit)
+	}
+	if containsAny(applied, "log.level") {
+		logger.SetLevel(active.Log.Level)
+	}
+	var applyErr error
+	if containsAny(applied, "token_rules.use_defaults", "token_rules.rules") {
+		if applyErr = s.classifier.SetConfig(active.TokenRules); applyErr != nil {
+			// 保留原来的规则，下次修改配置文件时重试
+			logger.Error("Failed to reload token rules, keeping current rules", zap.Error(applyErr))
+			active.TokenRules = old.TokenRules
+			if err := s.classifier.SetConfig(old.TokenRules); err != nil {
+				logger.Error("Failed to restore token rules", zap.Error(err))
+			}
+			applied = slices.DeleteFunc(applied, func(key string) bool {
+				return strings.HasPrefix(key, "token_rules.")
+			})
+		}
+	}
+
+	now := time.Now()
+	s.active = &active
+	s.pendingRestart = slices.DeleteFunc(config.Diff(&active, next), func(key string) bool {
+		return slices.Contains(ReloadableConfigKeys, key)
+	})
+	s.reloadedAt = &now
+	s.lastError = ""
+	s.lastErrorAt = nil
+	if applyErr != nil {
+		s.lastError = "token_rules: " + applyErr.Error()
+		s.lastErrorAt = &now
+	}
+
+	if len(applied) > 0 {
+		logger.Info("Config reloaded", zap.Strings("applied", applied))
+	}
+	if len(s.pendingRestart) > 0 {
+		logger.Warn("Config changes require a restart to take effect", zap.Strings("keys", s.pendingRestart))
+	}
+}
+
+// reject 记录无效的配置文件，继续使用当前配置
+func (s *ConfigService) reject(err error) {
+	now := time.Now()
+	s.mu.Lock()
+	s.lastError = err.Error()
+	s.lastErrorAt = &now
+	s.mu.Unlock()
+
+	logger.Error("Config reload rejected, keeping current config", zap.Error(err))
+}
+
+// containsAny 判断 keys 中是否有任意一个在 list 中
+func containsAny(list []string, keys ...string) bool {
+	for _, key := range keys {
+		if slices.Contains(list, key) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rotki-demo/internal/config"
+	"github.com/rotki-demo/internal/repository"
+)
+
+func TestConfigServiceKeepsTokenRulesWhenReloadFails(t *testing.T) {
+	db := newTestDB(t)
+	oldRules := config.TokenRulesConfig{UseDefaults: true}
+	classifier, err := NewTokenClassifier(nil, repository.NewTokenOverrideRepository(db), nil, oldRules)
+	if err != nil {
+		t.Fatalf("NewTokenClassifier: %v", err)
+	}
+	active := &config.Config{TokenRules: oldRules}
+	s := NewConfigService(active, nil, nil, classifier)
+
+	// 数据库不可用时重新加载规则失败
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("DB: %v", err)
+	}
+	sqlDB.Close()
+
+	next := *active
+	next.TokenRules = config.TokenRulesConfig{Rules: []config.TokenRuleConfig{{Classification: "spam", SymbolPattern: "x"}}}
+	s.apply(&next)
+
+	status := s.Status()
+	if !strings.HasPrefix(status.LastError, "token_rules:") || status.LastErrorAt == nil {
+		t.Fatalf("last error = %q at %v, want the token_rules failure", status.LastError, status.LastErrorAt)
+	}
+	if len(status.PendingRestart) != 0 {
+		t.Fatalf("pending restart = %q, want none", status.PendingRestart)
+	}
+	if got := s.active.TokenRules; !got.UseDefaults || len(got.Rules) != 0 {
+		t.Fatalf("active token rules = %+v, want the previous rules", got)
+	}
+	if got := classifier.cfg; !got.UseDefaults || len(got.Rules) != 0 {
+		t.Fatalf("classifier rules = %+v, want the previous rules", got)
+	}
+}
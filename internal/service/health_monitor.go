@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rotki-demo/internal/config"
+	"github.com/rotki-demo/internal/logger"
+	"github.com/rotki-demo/internal/models"
+	"github.com/rotki-demo/internal/notifier"
+	"github.com/rotki-demo/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// HealthMonitor 在每次同步后检查借贷持仓的健康因子并发送清算风险告警
+type HealthMonitor struct {
+	healthRepo *repository.HealthRepository
+	notifier   notifier.Notifier
+	cfg        config.AlertsConfig
+}
+
+// NewHealthMonitor 创建一个新的健康因子监控器
+func NewHealthMonitor(healthRepo *repository.HealthRepository, n notifier.Notifier, cfg config.AlertsConfig) *HealthMonitor {
+	return &HealthMonitor{
+		healthRepo: healthRepo,
+		notifier:   n,
+		cfg:        cfg,
+	}
+}
+
+// ResolveThreshold 返回适用于持仓的告警阈值
+// 钱包 > 协议 > 持仓的顺序决定具体程度，最具体的设置优先；没有设置时使用配置中的默认值
+func (m *HealthMonitor) ResolveThreshold(walletID uint, protocolID, positionKey string) (models.HealthThreshold, error) {
+	resolved := models.HealthThreshold{
+		Threshold:   m.cfg.HealthThreshold,
+		DropPercent: m.cfg.HealthDropPercent,
+	}
+
+	thresholds, err := m.healthRepo.FindThresholds(walletID, protocolID, positionKey)
+	if err != nil {
+		return resolved, err
+	}
+
+	best := -1
+	for _, t := range thresholds {
+		score := 0
+		if t.WalletID != 0 {
+			score += 4
+		}
+		if t.ProtocolID != "" {
+			score += 2
+		}
+		if t.PositionKey != "" {
+			score++
+		}
+		if score > best {
+			best = score
+			resolved = t
+		}
+	}
+	return resolved, nil
+}
+
+// Check 记录地址所有持仓的健康因子，并在跌破阈值或急剧下降时发送告警
+// 检查失败只记录日志，不影响同步结果
+func (m *HealthMonitor) Check(ctx context.Context, address *models.Address, positions []models.ProtocolPosition) {
+	if !m.cfg.HealthEnabled {
+		return
+	}
+
+	now := time.Now()
+	for i := range positions {
+		position := &positions[i]
+		if position.HealthRate == nil {
+			continue
+		}
+		if err := m.checkPosition(ctx, address, position, now); err != nil {
+			logger.Error("Failed to check position health",
+				zap.Uint("address_id", address.ID),
+				zap.String("protocol_id", position.ProtocolID),
+				zap.String("position_key", position.PositionKey()),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// checkPosition 检查单个持仓
+func (m *HealthMonitor) checkPosition(ctx context.Context, address *models.Address, position *models.ProtocolPosition, now time.Time) error {
+	key := position.PositionKey()
+	current := *position.HealthRate
+
+	var previous *float64
+	last, err := m.healthRepo.LatestHealthRate(address.ID, position.ProtocolID, position.ChainID, key)
+	switch {
+	case err == nil:
+		previous = &last.HealthRate
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return fmt.Errorf("failed to get previous health rate: %w", err)
+	}
+
+	if err := m.healthRepo.RecordHealthRate(&models.HealthRateRecord{
+		AddressID:   address.ID,
+		ProtocolID:  position.ProtocolID,
+		ChainID:     position.ChainID,
+		PositionKey: key,
+		HealthRate:  current,
+		RecordedAt:  now,
+	}); err != nil {
+		return fmt.Errorf("failed to record health rate: %w", err)
+	}
+
+	threshold, err := m.ResolveThreshold(address.WalletID, position.ProtocolID, key)
+	if err != nil {
+		return fmt.Errorf("failed to resolve threshold: %w", err)
+	}
+
+	alertType, message := evaluateHealth(current, previous, threshold)
+	if alertType == "" {
+		return nil
+	}
+
+	alert := &models.HealthAlert{
+		WalletID:           address.WalletID,
+		AddressID:          address.ID,
+		ProtocolID:         position.ProtocolID,
+		ChainID:            position.ChainID,
+		PositionKey:        key,
+		AlertType:          alertType,
+		HealthRate:         current,
+		PreviousHealthRate: previous,
+		Threshold:          threshold.Threshold,
+		Message:            fmt.Sprintf("%s %s on %s: %s", address.Address, position.Name, position.ProtocolID, message),
+	}
+	if err := m.healthRepo.CreateAlert(alert); err != nil {
+		return fmt.Errorf("failed to create health alert: %w", err)
+	}
+
+	m.deliver(ctx, alert, position)
+	return nil
+}
+
+// evaluateHealth 判断是否需要告警
+// 跌破阈值只在穿越时告警（上一次不低于阈值或没有记录），避免每次同步重复告警
+func evaluateHealth(current float64, previous *float64, threshold models.HealthThreshold) (string, string) {
+	if current < threshold.Threshold && (previous == nil || *previous >= threshold.Threshold) {
+		return models.HealthAlertBelowThreshold,
+			fmt.Sprintf("health rate %.4f fell below threshold %.4f", current, threshold.Threshold)
+	}
+
+	if previous != nil && *previous > 0 && threshold.DropPercent > 0 {
+		drop := (*previous - current) / *previous * 100
+		if drop >= threshold.DropPercent {
+			return models.HealthAlertSharpDrop,
+				fmt.Sprintf("health rate dropped %.2f%% from %.4f to %.4f", drop, *previous, current)
+		}
+	}
+
+	return "", ""
+}
+
+// deliver 通过通知渠道发送告警并记录投递结果
+func (m *HealthMonitor) deliver(ctx context.Context, alert *models.HealthAlert, position *models.ProtocolPosition) {
+	severity := notifier.SeverityWarning
+	if alert.AlertType == models.HealthAlertBelowThreshold {
+		severity = notifier.SeverityCritical
+	}
+
+	fields := map[string]string{
+		"wallet_id":    fmt.Sprint(alert.WalletID),
+		"address_id":   fmt.Sprint(alert.AddressID),
+		"protocol_id":  alert.ProtocolID,
+		"chain_id":     alert.ChainID,
+		"position_key": alert.PositionKey,
+		"health_rate":  fmt.Sprintf("%.4f", alert.HealthRate),
+		"threshold":    fmt.Sprintf("%.4f", alert.Threshold),
+		"debt_usd":     fmt.Sprintf("%.2f", position.DebtUSDValue),
+	}
+	if alert.PreviousHealthRate != nil {
+		fields["previous_health_rate"] = fmt.Sprintf("%.4f", *alert.PreviousHealthRate)
+	}
+
+	err := m.notifier.Notify(ctx, notifier.Notification{
+		Title:    "Liquidation risk: " + alert.AlertType,
+		Message:  alert.Message,
+		Severity: severity,
+		Fields:   fields,
+		Time:     alert.CreatedAt,
+	})
+
+	deliveryError := ""
+	if err != nil {
+		deliveryError = truncate(err.Error(), 1000)
+		logger.Warn("Failed to deliver health alert", zap.Uint("alert_id", alert.ID), zap.Error(err))
+	}
+	if err := m.healthRepo.UpdateAlertDelivery(alert.ID, err == nil, deliveryError); err != nil {
+		logger.Error("Failed to update alert delivery", zap.Uint("alert_id", alert.ID), zap.Error(err))
+	}
+}
+
+// SendTestNotification 通过所有已配置的渠道发送一条测试通知
+func (m *HealthMonitor) SendTestNotification(ctx context.Context, message string) error {
+	if message == "" {
+		message = "This is a test notification"
+	}
+	return m.notifier.Notify(ctx, notifier.Notification{
+		Title:    "Test notification",
+		Message:  message,
+		Severity: notifier.SeverityInfo,
+		Time:     time.Now(),
+	})
+}
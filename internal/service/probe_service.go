@@ -0,0 +1,291 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rotki-demo/internal/config"
+	"github.com/rotki-demo/internal/database"
+	"github.com/rotki-demo/internal/provider"
+	"github.com/rotki-demo/internal/repository"
+	"gorm.io/gorm"
+)
+
+// 探针组件名称
+const (
+	ProbeComponentDatabase   = "database"
+	ProbeComponentMigrations = "migrations"
+	ProbeComponentProvider   = "provider"
+	ProbeComponentSync       = "sync"
+	ProbeComponentRPCNodes   = "rpc_nodes"
+)
+
+// 组件检查状态
+const (
+	ProbeStatusOK       = "ok"
+	ProbeStatusFail     = "fail"
+	ProbeStatusDisabled = "disabled" // 组件未启用，不影响整体状态
+)
+
+// 整体探测状态，degraded 表示只有非必需组件失败
+const (
+	ProbeOverallOK       = "ok"
+	ProbeOverallDegraded = "degraded"
+	ProbeOverallFail     = "fail"
+)
+
+// ProbeCheck 是单个组件的检查结果
+type ProbeCheck struct {
+	Status    string      `json:"status"`
+	Required  bool        `json:"required"`          // 失败时探针是否返回 503
+	Message   string      `json:"message,omitempty"` // 失败原因或补充说明
+	LatencyMS int64       `json:"latency_ms"`
+	CheckedAt time.Time   `json:"checked_at"` // 提供者检查结果可能来自缓存
+	Details   interface{} `json:"details,omitempty"`
+}
+
+// ProbeReport 是 /livez 和 /readyz 的响应
+type ProbeReport struct {
+	Status    string                 `json:"status"`
+	Checks    map[string]*ProbeCheck `json:"checks"`
+	CheckedAt time.Time              `json:"checked_at"`
+}
+
+// Healthy 报告探针是否通过（没有必需组件失败）
+func (r *ProbeReport) Healthy() bool {
+	return r.Status != ProbeOverallFail
+}
+
+// ChainRPCStatus 是 rpc_nodes 检查中单条链的节点可用情况
+type ChainRPCStatus struct {
+	ChainID        string `json:"chain_id"`
+	EnabledNodes   int    `json:"enabled_nodes"`
+	ConnectedNodes int    `json:"connected_nodes"`
+}
+
+// ProbeService 执行存活和就绪检查
+// 存活检查只判断进程内部是否卡住（同步循环心跳），就绪检查还包括数据库、迁移、数据提供者和 RPC 节点
+type ProbeService struct {
+	db           *gorm.DB
+	dataProvider provider.DataProvider
+	syncService  *SyncService
+	syncEnabled  bool
+	chainRepo    *repository.ChainRepository
+	rpcNodeRepo  *repository.RPCNodeRepository
+	cfg          config.ProbesConfig
+
+	// 提供者检查结果缓存，避免每次探测都请求外部 API
+	providerMu     sync.Mutex
+	providerResult *ProbeCheck
+}
+
+// NewProbeService 创建探针服务
+func NewProbeService(
+	db *gorm.DB,
+	dataProvider provider.DataProvider,
+	syncService *SyncService,
+	syncCfg config.SyncConfig,
+	chainRepo *repository.ChainRepository,
+	rpcNodeRepo *repository.RPCNodeRepository,
+	cfg config.ProbesConfig,
+) *ProbeService {
+	return &ProbeService{
+		db:           db,
+		dataProvider: dataProvider,
+		syncService:  syncService,
+		syncEnabled:  syncCfg.Enabled,
+		chainRepo:    chainRepo,
+		rpcNodeRepo:  rpcNodeRepo,
+		cfg:          cfg,
+	}
+}
+
+// Liveness 执行存活检查
+func (s *ProbeService) Liveness(ctx context.Context) *ProbeReport {
+	return s.report(map[string]*ProbeCheck{
+		ProbeComponentSync: s.checkSync(),
+	}, true)
+}
+
+// Readiness 并发执行所有就绪检查
+func (s *ProbeService) Readiness(ctx context.Context) *ProbeReport {
+	checks := map[string]func(context.Context) *ProbeCheck{
+		ProbeComponentDatabase:   s.checkDatabase,
+		ProbeComponentMigrations: s.checkMigrations,
+		ProbeComponentProvider:   s.checkProvider,
+		ProbeComponentSync:       func(context.Context) *ProbeCheck { return s.checkSync() },
+		ProbeComponentRPCNodes:   s.checkRPCNodes,
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make(map[string]*ProbeCheck, len(checks))
+	)
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check func(context.Context) *ProbeCheck) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, s.cfg.GetTimeout())
+			defer cancel()
+			result := check(checkCtx)
+			mu.Lock()
+			results[name] = result
+			mu.Unlock()
+		}(name, check)
+	}
+	wg.Wait()
+
+	return s.report(results, false)
+}
+
+// report 汇总组件结果，livenessOnly 时所有启用的组件都视为必需
+func (s *ProbeService) report(checks map[string]*ProbeCheck, livenessOnly bool) *ProbeReport {
+	status := ProbeOverallOK
+	for name, check := range checks {
+		check.Required = livenessOnly || slices.Contains(s.cfg.Required, name)
+		if check.Status != ProbeStatusFail {
+			continue
+		}
+		if check.Required {
+			status = ProbeOverallFail
+		} else if status == ProbeOverallOK {
+			status = ProbeOverallDegraded
+		}
+	}
+	return &ProbeReport{Status: status, Checks: checks, CheckedAt: time.Now()}
+}
+
+// checkDatabase 检查数据库连接
+func (s *ProbeService) checkDatabase(ctx context.Context) *ProbeCheck {
+	start := time.Now()
+	sqlDB, err := s.db.DB()
+	if err == nil {
+		err = sqlDB.PingContext(ctx)
+	}
+	return newProbeCheck(start, err, nil)
+}
+
+// checkMigrations 检查是否有未应用的迁移
+func (s *ProbeService) checkMigrations(ctx context.Context) *ProbeCheck {
+	start := time.Now()
+	migrator, err := database.NewDefaultMigrator(s.db.WithContext(ctx))
+	if err != nil {
+		return newProbeCheck(start, err, nil)
+	}
+	pending, err := migrator.Pending()
+	if err == nil && pending > 0 {
+		err = fmt.Errorf("%d pending migration(s); run `migrate up`", pending)
+	}
+	return newProbeCheck(start, err, map[string]int{"pending": pending})
+}
+
+// checkProvider 检查数据提供者是否可达且凭证有效，结果缓存 provider_check_interval
+func (s *ProbeService) checkProvider(ctx context.Context) *ProbeCheck {
+	s.providerMu.Lock()
+	defer s.providerMu.Unlock()
+
+	if cached := s.providerResult; cached != nil && time.Since(cached.CheckedAt) < s.cfg.GetProviderCheckInterval() {
+		result := *cached
+		return &result
+	}
+
+	start := time.Now()
+	err := s.dataProvider.Ping(ctx)
+	result := newProbeCheck(start, err, map[string]string{"name": s.dataProvider.GetName()})
+	s.providerResult = result
+
+	copied := *result
+	return &copied
+}
+
+// checkSync 检查同步循环心跳是否新鲜
+// 过期时间至少为两个同步间隔，避免 sync_stale_after 小于同步间隔时误判
+func (s *ProbeService) checkSync() *ProbeCheck {
+	now := time.Now()
+	if !s.syncEnabled {
+		return &ProbeCheck{Status: ProbeStatusDisabled, CheckedAt: now}
+	}
+
+	staleAfter := max(s.cfg.GetSyncStaleAfter(), 2*s.syncService.Interval())
+	details := map[string]interface{}{"stale_after_seconds": int64(staleAfter.Seconds())}
+
+	last, ok := s.syncService.LastHeartbeat()
+	if !ok {
+		return &ProbeCheck{Status: ProbeStatusFail, Message: "sync loop has not started", CheckedAt: now, Details: details}
+	}
+
+	details["last_heartbeat"] = last
+	check := &ProbeCheck{Status: ProbeStatusOK, CheckedAt: now, Details: details}
+	if age := now.Sub(last); age > staleAfter {
+		check.Status = ProbeStatusFail
+		check.Message = fmt.Sprintf("no sync loop heartbeat for %s", age.Round(time.Second))
+	}
+	return check
+}
+
+// checkRPCNodes 检查每条已激活且配置了节点的链是否至少有一个已连接的启用节点
+// 使用节点最近一次连接检查的结果，不在探测时发起 RPC 请求
+func (s *ProbeService) checkRPCNodes(ctx context.Context) *ProbeCheck {
+	start := time.Now()
+	chains, err := s.chainRepo.WithContext(ctx).List()
+	if err != nil {
+		return newProbeCheck(start, err, nil)
+	}
+	nodes, err := s.rpcNodeRepo.GetAll(ctx)
+	if err != nil {
+		return newProbeCheck(start, err, nil)
+	}
+
+	byChain := make(map[string]*ChainRPCStatus)
+	for _, node := range nodes {
+		if !node.IsEnabled {
+			continue
+		}
+		status, ok := byChain[node.ChainID]
+		if !ok {
+			status = &ChainRPCStatus{ChainID: node.ChainID}
+			byChain[node.ChainID] = status
+		}
+		status.EnabledNodes++
+		if node.IsConnected {
+			status.ConnectedNodes++
+		}
+	}
+
+	statuses := make([]ChainRPCStatus, 0, len(byChain))
+	var unavailable []string
+	for _, chain := range chains {
+		status, ok := byChain[chain.ID]
+		if !ok {
+			continue
+		}
+		statuses = append(statuses, *status)
+		if status.ConnectedNodes == 0 {
+			unavailable = append(unavailable, chain.ID)
+		}
+	}
+
+	if len(unavailable) > 0 {
+		err = fmt.Errorf("no connected RPC node for chain(s): %v", unavailable)
+	}
+	return newProbeCheck(start, err, statuses)
+}
+
+// newProbeCheck 根据检查耗时和错误创建结果
+func newProbeCheck(start time.Time, err error, details interface{}) *ProbeCheck {
+	check := &ProbeCheck{
+		Status:    ProbeStatusOK,
+		LatencyMS: time.Since(start).Milliseconds(),
+		CheckedAt: time.Now(),
+		Details:   details,
+	}
+	if err != nil {
+		check.Status = ProbeStatusFail
+		check.Message = err.Error()
+	}
+	return check
+}
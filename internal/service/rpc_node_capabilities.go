@@ -0,0 +1,369 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rotki-demo/internal/models"
+	"github.com/rotki-demo/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const (
+	// archiveProbeBlock 用于探测归档状态的旧区块，非归档节点早已裁剪该区块的状态
+	archiveProbeBlock = 1
+
+	// zeroAddress 和 zeroHash 用于构造结果为空的探测请求
+	zeroAddress = "0x0000000000000000000000000000000000000000"
+	zeroHash    = "0x0000000000000000000000000000000000000000000000000000000000000000"
+
+	// capabilitiesMaxAge 是能力探测结果的有效期，例行连接检查只在从未探测或结果过期时重新探测
+	capabilitiesMaxAge = 24 * time.Hour
+)
+
+// errCapabilityProbeIncomplete 表示部分探测因网络错误没有结论，这些能力保留原值
+var errCapabilityProbeIncomplete = errors.New("some capability probes failed at the network level")
+
+// logsRangeCandidates 是探测 eth_getLogs 区块范围上限时依次尝试的范围（从大到小）
+var logsRangeCandidates = []uint64{100000, 50000, 10000, 5000, 2000, 1000, 500, 100}
+
+// rpcError 表示 JSON-RPC 响应中的错误对象
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("RPC error %d: %s", e.Code, e.Message)
+}
+
+// methodUnavailable 判断错误是否表示节点不提供该方法
+func (e *rpcError) methodUnavailable() bool {
+	if e.Code == -32601 {
+		return true
+	}
+	msg := strings.ToLower(e.Message)
+	for _, pattern := range []string{
+		"method not found", "does not exist", "not available", "not supported",
+		"unsupported", "not allowed", "not whitelisted", "disabled",
+	} {
+		if strings.Contains(msg, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// callRPC 向节点发起一次 JSON-RPC 调用并返回原始结果
+// RPC 层面的错误以 *rpcError 返回，便于调用方区分网络错误和方法错误
+func callRPC(ctx context.Context, client *http.Client, endpoint, method string, params ...interface{}) (_ json.RawMessage, err error) {
+	// 只记录节点主机名，URL 路径中可能包含 API key
+	attrs := []attribute.KeyValue{
+		attribute.String("rpc.system", "jsonrpc"),
+		attribute.String("rpc.method", method),
+	}
+	if parsed, parseErr := url.Parse(endpoint); parseErr == nil {
+		attrs = append(attrs, attribute.String("server.address", parsed.Hostname()))
+	}
+	ctx, span := tracer.Start(ctx, "JSON-RPC "+method, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+	defer func() { tracing.End(span, err) }()
+
+	if params == nil {
+		params = []interface{}{}
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  method,
+		"params":  params,
+		"id":      1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Result json.RawMessage `json:"result"`
+		Error  *rpcError       `json:"error"`
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		// 部分节点对超出限制的请求（如 eth_getLogs 区块范围过大）返回 400/413 和 JSON-RPC 错误体，
+		// 这是对请求的明确拒绝而不是网络错误；限流和超时仍视为网络错误
+		if clientRejected(resp.StatusCode) && json.NewDecoder(resp.Body).Decode(&result) == nil && result.Error != nil {
+			return nil, result.Error
+		}
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	if result.Result == nil {
+		return nil, fmt.Errorf("no result in response")
+	}
+
+	return result.Result, nil
+}
+
+// clientRejected 判断状态码是否表示节点拒绝了请求本身（4xx，限流和请求超时除外）
+func clientRejected(statusCode int) bool {
+	return statusCode >= 400 && statusCode < 500 &&
+		statusCode != http.StatusTooManyRequests && statusCode != http.StatusRequestTimeout
+}
+
+// DetectCapabilities 探测节点支持的能力并写入 node 的能力字段
+// 节点明确拒绝的能力标记为不支持；超时、连接失败等网络错误没有结论，对应能力保留原值，
+// 此时返回 errCapabilityProbeIncomplete 且不更新探测时间，以便下次连接检查重新探测
+func (s *RPCNodeService) DetectCapabilities(ctx context.Context, node *models.RPCNode) error {
+	client := &http.Client{
+		Timeout: time.Duration(node.Timeout) * time.Second,
+	}
+
+	// 获取最新区块号，后续探测都以它为基准
+	raw, err := callRPC(ctx, client, node.URL, "eth_blockNumber")
+	if err != nil {
+		return fmt.Errorf("failed to get latest block: %w", err)
+	}
+	latest, err := parseHexQuantity(raw)
+	if err != nil {
+		return fmt.Errorf("failed to parse latest block: %w", err)
+	}
+
+	// 归档：查询旧区块的状态，裁剪过的节点会返回 missing trie node 等错误
+	incomplete := false
+	_, err = callRPC(ctx, client, node.URL, "eth_getBalance", zeroAddress, toHexQuantity(archiveProbeBlock))
+	if archive, definitive := probeOutcome(err); definitive {
+		node.IsArchive = archive
+	} else {
+		incomplete = true
+	}
+
+	// eth_getLogs 区块范围上限：从大到小尝试，第一个成功的范围即为上限
+	maxLogsBlockRange, rangeKnown := 0, true
+	for _, blockRange := range logsRangeCandidates {
+		if blockRange > latest {
+			continue
+		}
+		filter := map[string]interface{}{
+			"fromBlock": toHexQuantity(latest - blockRange + 1),
+			"toBlock":   toHexQuantity(latest),
+			"address":   zeroAddress,
+		}
+		_, err := callRPC(ctx, client, node.URL, "eth_getLogs", filter)
+		if err == nil {
+			maxLogsBlockRange = int(blockRange)
+			break
+		}
+		var rpcErr *rpcError
+		if !errors.As(err, &rpcErr) {
+			// 网络错误时不再继续缩小范围
+			rangeKnown = false
+			break
+		}
+	}
+	if rangeKnown {
+		node.MaxLogsBlockRange = maxLogsBlockRange
+	} else {
+		incomplete = true
+	}
+
+	// trace_/debug_ 命名空间：使用不存在的交易哈希，支持的节点会返回空结果或"未找到交易"
+	if available, definitive := methodAvailable(ctx, client, node.URL, "trace_transaction", zeroHash); definitive {
+		node.SupportsTrace = available
+	} else {
+		incomplete = true
+	}
+	if available, definitive := methodAvailable(ctx, client, node.URL, "debug_traceTransaction", zeroHash, map[string]interface{}{}); definitive {
+		node.SupportsDebug = available
+	} else {
+		incomplete = true
+	}
+
+	// WebSocket：尝试完成握手
+	if supported, definitive := probeWebSocket(ctx, webSocketURL(node), client.Timeout); definitive {
+		node.SupportsWebSocket = supported
+	} else {
+		incomplete = true
+	}
+
+	if incomplete {
+		return errCapabilityProbeIncomplete
+	}
+	now := time.Now()
+	node.CapabilitiesCheckedAt = &now
+
+	s.logger.Debug("RPC node capabilities detected",
+		zap.String("url", node.URL),
+		zap.Bool("archive", node.IsArchive),
+		zap.Bool("trace", node.SupportsTrace),
+		zap.Bool("debug", node.SupportsDebug),
+		zap.Bool("websocket", node.SupportsWebSocket),
+		zap.Int("max_logs_block_range", node.MaxLogsBlockRange))
+
+	return nil
+}
+
+// CheckCapabilities 探测特定节点的能力并保存结果
+func (s *RPCNodeService) CheckCapabilities(ctx context.Context, id uint) (*models.RPCNode, error) {
+	node, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get node: %w", err)
+	}
+
+	if err := s.refreshCapabilities(ctx, node); err != nil {
+		return nil, err
+	}
+
+	return node, nil
+}
+
+// refreshCapabilities 探测节点能力并保存；部分探测没有结论时仍保存其余结果，并返回错误
+func (s *RPCNodeService) refreshCapabilities(ctx context.Context, node *models.RPCNode) error {
+	detectErr := s.DetectCapabilities(ctx, node)
+	if detectErr != nil && !errors.Is(detectErr, errCapabilityProbeIncomplete) {
+		return detectErr
+	}
+	if err := s.repo.UpdateCapabilities(ctx, node); err != nil {
+		return fmt.Errorf("failed to update capabilities: %w", err)
+	}
+	return detectErr
+}
+
+// capabilitiesStale 判断节点的能力探测结果是否缺失或过期
+func capabilitiesStale(node *models.RPCNode) bool {
+	return node.CapabilitiesCheckedAt == nil || time.Since(*node.CapabilitiesCheckedAt) > capabilitiesMaxAge
+}
+
+// probeOutcome 把探测调用的结果转换为能力：成功表示支持，RPC 错误表示不支持，网络错误没有结论（definitive 为 false）
+func probeOutcome(err error) (supported, definitive bool) {
+	if err == nil {
+		return true, true
+	}
+	var rpcErr *rpcError
+	return false, errors.As(err, &rpcErr)
+}
+
+// methodAvailable 判断节点是否提供某个方法
+// 除"方法不存在"类错误外的 RPC 错误（如参数错误、交易未找到）都说明方法可用；网络错误没有结论
+func methodAvailable(ctx context.Context, client *http.Client, endpoint, method string, params ...interface{}) (available, definitive bool) {
+	_, err := callRPC(ctx, client, endpoint, method, params...)
+	if err == nil {
+		return true, true
+	}
+	var rpcErr *rpcError
+	if errors.As(err, &rpcErr) {
+		return !rpcErr.methodUnavailable(), true
+	}
+	return false, false
+}
+
+// webSocketURL 返回节点的 WebSocket 端点，未配置时从 HTTP URL 推导
+func webSocketURL(node *models.RPCNode) string {
+	if node.WSURL != "" {
+		return node.WSURL
+	}
+	switch {
+	case strings.HasPrefix(node.URL, "https://"):
+		return "wss://" + strings.TrimPrefix(node.URL, "https://")
+	case strings.HasPrefix(node.URL, "http://"):
+		return "ws://" + strings.TrimPrefix(node.URL, "http://")
+	default:
+		return node.URL
+	}
+}
+
+// probeWebSocket 尝试与端点完成 WebSocket 握手；超时或请求被取消时没有结论（definitive 为 false）
+func probeWebSocket(ctx context.Context, wsURL string, timeout time.Duration) (supported, definitive bool) {
+	u, err := url.Parse(wsURL)
+	if err != nil {
+		return false, true
+	}
+	switch u.Scheme {
+	case "ws":
+		u.Scheme = "http"
+	case "wss":
+		u.Scheme = "https"
+	default:
+		return false, true
+	}
+
+	keyBytes := make([]byte, 16)
+	if _, err := rand.Read(keyBytes); err != nil {
+		return false, false
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return false, true
+	}
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", base64.StdEncoding.EncodeToString(keyBytes))
+
+	// WebSocket 升级需要 HTTP/1.1，禁用 HTTP/2 协商
+	client := &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy:        http.ProxyFromEnvironment,
+			TLSNextProto: map[string]func(string, *tls.Conn) http.RoundTripper{},
+		},
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		// 连接被拒绝等说明端点不提供 WebSocket；超时可能只是网络抖动
+		var netErr net.Error
+		timedOut := errors.As(err, &netErr) && netErr.Timeout()
+		return false, !timedOut && ctx.Err() == nil
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode == http.StatusSwitchingProtocols, true
+}
+
+// parseHexQuantity 解析 JSON-RPC 返回的十六进制数量
+func parseHexQuantity(raw json.RawMessage) (uint64, error) {
+	var hex string
+	if err := json.Unmarshal(raw, &hex); err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimPrefix(hex, "0x"), 16, 64)
+}
+
+// toHexQuantity 将数字编码为 JSON-RPC 十六进制数量
+func toHexQuantity(n uint64) string {
+	return "0x" + strconv.FormatUint(n, 16)
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/rotki-demo/internal/models"
+	"go.uber.org/zap"
+)
+
+// rpcReply 是模拟节点对一次调用的响应
+type rpcReply struct {
+	status int // 为 0 时使用 200
+	result interface{}
+	err    *rpcError
+}
+
+// newMockRPCNode 启动一个按方法名响应的 JSON-RPC 节点；没有处理函数的方法返回"方法不存在"
+func newMockRPCNode(t *testing.T, handlers map[string]func(params []json.RawMessage) rpcReply) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// WebSocket 握手只在设置了 ws 处理函数时成功
+		if r.Header.Get("Upgrade") == "websocket" {
+			if _, ok := handlers["websocket"]; !ok {
+				http.Error(w, "not a websocket endpoint", http.StatusBadRequest)
+				return
+			}
+			conn, buf, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			buf.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+			buf.Flush()
+			return
+		}
+
+		var req struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		reply := rpcReply{err: &rpcError{Code: -32601, Message: "the method " + req.Method + " does not exist/is not available"}}
+		if handler, ok := handlers[req.Method]; ok {
+			reply = handler(req.Params)
+		}
+
+		body := map[string]interface{}{"jsonrpc": "2.0", "id": 1}
+		if reply.err != nil {
+			body["error"] = reply.err
+		} else {
+			body["result"] = reply.result
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if reply.status != 0 {
+			w.WriteHeader(reply.status)
+		}
+		json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// logsRange 返回 eth_getLogs 过滤条件的区块数
+func logsRange(t *testing.T, params []json.RawMessage) uint64 {
+	var filter struct {
+		FromBlock string `json:"fromBlock"`
+		ToBlock   string `json:"toBlock"`
+	}
+	if err := json.Unmarshal(params[0], &filter); err != nil {
+		t.Errorf("decode getLogs filter: %v", err)
+		return 0
+	}
+	from, _ := strconv.ParseUint(strings.TrimPrefix(filter.FromBlock, "0x"), 16, 64)
+	to, _ := strconv.ParseUint(strings.TrimPrefix(filter.ToBlock, "0x"), 16, 64)
+	return to - from + 1
+}
+
+func TestDetectCapabilities(t *testing.T) {
+	blockNumber := func([]json.RawMessage) rpcReply { return rpcReply{result: "0x1000000"} }
+	emptyResult := func([]json.RawMessage) rpcReply { return rpcReply{result: []interface{}{}} }
+	missingTrieNode := func([]json.RawMessage) rpcReply {
+		return rpcReply{err: &rpcError{Code: -32000, Message: "missing trie node"}}
+	}
+	txNotFound := func([]json.RawMessage) rpcReply {
+		return rpcReply{err: &rpcError{Code: -32000, Message: "transaction not found"}}
+	}
+	// limitedLogs 拒绝超过 limit 的区块范围
+	limitedLogs := func(limit uint64, status int) func([]json.RawMessage) rpcReply {
+		return func(params []json.RawMessage) rpcReply {
+			if blocks := logsRange(t, params); blocks > limit {
+				return rpcReply{status: status, err: &rpcError{Code: -32005, Message: fmt.Sprintf("block range too large: %d", blocks)}}
+			}
+			return rpcReply{result: []interface{}{}}
+		}
+	}
+
+	tests := []struct {
+		name         string
+		handlers     map[string]func([]json.RawMessage) rpcReply
+		previous     models.RPCNode // 探测前已有的能力
+		want         models.RPCNode
+		wantComplete bool
+	}{
+		{
+			name: "full node",
+			handlers: map[string]func([]json.RawMessage) rpcReply{
+				"eth_blockNumber":        blockNumber,
+				"eth_getBalance":         func([]json.RawMessage) rpcReply { return rpcReply{result: "0x0"} },
+				"eth_getLogs":            emptyResult,
+				"trace_transaction":      func([]json.RawMessage) rpcReply { return rpcReply{result: nil} },
+				"debug_traceTransaction": txNotFound,
+				"websocket":              nil,
+			},
+			want:         models.RPCNode{IsArchive: true, SupportsTrace: true, SupportsDebug: true, SupportsWebSocket: true, MaxLogsBlockRange: 100000},
+			wantComplete: true,
+		},
+		{
+			name: "pruned node with range limit in the rpc error",
+			handlers: map[string]func([]json.RawMessage) rpcReply{
+				"eth_blockNumber": blockNumber,
+				"eth_getBalance":  missingTrieNode,
+				"eth_getLogs":     limitedLogs(2000, 0),
+			},
+			want:         models.RPCNode{MaxLogsBlockRange: 2000},
+			wantComplete: true,
+		},
+		{
+			name: "range limit rejected with http 413",
+			handlers: map[string]func([]json.RawMessage) rpcReply{
+				"eth_blockNumber": blockNumber,
+				"eth_getBalance":  missingTrieNode,
+				"eth_getLogs":     limitedLogs(1000, http.StatusRequestEntityTooLarge),
+			},
+			want:         models.RPCNode{MaxLogsBlockRange: 1000},
+			wantComplete: true,
+		},
+		{
+			name: "range limit rejected with http 400",
+			handlers: map[string]func([]json.RawMessage) rpcReply{
+				"eth_blockNumber": blockNumber,
+				"eth_getBalance":  missingTrieNode,
+				"eth_getLogs":     limitedLogs(500, http.StatusBadRequest),
+			},
+			want:         models.RPCNode{MaxLogsBlockRange: 500},
+			wantComplete: true,
+		},
+		{
+			name: "rate limited probes keep previous results",
+			handlers: map[string]func([]json.RawMessage) rpcReply{
+				"eth_blockNumber": blockNumber,
+				"eth_getBalance": func([]json.RawMessage) rpcReply {
+					return rpcReply{status: http.StatusTooManyRequests, err: &rpcError{Code: -32005, Message: "rate limited"}}
+				},
+				"eth_getLogs": limitedLogs(0, http.StatusTooManyRequests),
+			},
+			previous: models.RPCNode{IsArchive: true, MaxLogsBlockRange: 5000},
+			want:     models.RPCNode{IsArchive: true, MaxLogsBlockRange: 5000},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newMockRPCNode(t, tt.handlers)
+			node := tt.previous
+			node.URL = server.URL
+			node.Timeout = 5
+
+			s := &RPCNodeService{logger: zap.NewNop()}
+			err := s.DetectCapabilities(context.Background(), &node)
+			if tt.wantComplete {
+				if err != nil {
+					t.Fatalf("DetectCapabilities: %v", err)
+				}
+				if node.CapabilitiesCheckedAt == nil {
+					t.Fatal("capabilities_checked_at not set after a complete probe")
+				}
+			} else {
+				if !errors.Is(err, errCapabilityProbeIncomplete) {
+					t.Fatalf("DetectCapabilities = %v, want errCapabilityProbeIncomplete", err)
+				}
+				if node.CapabilitiesCheckedAt != nil {
+					t.Fatal("capabilities_checked_at set after an incomplete probe")
+				}
+			}
+
+			got := models.RPCNode{
+				IsArchive:         node.IsArchive,
+				SupportsTrace:     node.SupportsTrace,
+				SupportsDebug:     node.SupportsDebug,
+				SupportsWebSocket: node.SupportsWebSocket,
+				MaxLogsBlockRange: node.MaxLogsBlockRange,
+			}
+			if got != tt.want {
+				t.Fatalf("capabilities = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,12 +2,13 @@ package service
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"math/rand"
 	"net/http"
-	"strings"
 	"time"
 
+	"github.com/rotki-demo/internal/events"
+	"github.com/rotki-demo/internal/metrics"
 	"github.com/rotki-demo/internal/models"
 	"github.com/rotki-demo/internal/repository"
 	"go.uber.org/zap"
@@ -15,15 +16,27 @@ import (
 
 // RPCNodeService 处理 RPC 节点业务逻辑
 type RPCNodeService struct {
-	repo   *repository.RPCNodeRepository
-	logger *zap.Logger
+	repo      *repository.RPCNodeRepository
+	publisher events.Publisher
+	logger    *zap.Logger
+}
+
+// RPCNodeStatus 是 rpc_node.status_changed 事件的数据（不包含可能带有 API key 的 URL）
+type RPCNodeStatus struct {
+	NodeID       uint   `json:"node_id"`
+	ChainID      string `json:"chain_id"`
+	Name         string `json:"name"`
+	IsConnected  bool   `json:"is_connected"`
+	WasConnected bool   `json:"was_connected"`
+	Error        string `json:"error,omitempty"`
 }
 
 // NewRPCNodeService 创建一个新的 RPC 节点服务
-func NewRPCNodeService(repo *repository.RPCNodeRepository, logger *zap.Logger) *RPCNodeService {
+func NewRPCNodeService(repo *repository.RPCNodeRepository, publisher events.Publisher, logger *zap.Logger) *RPCNodeService {
 	return &RPCNodeService{
-		repo:   repo,
-		logger: logger,
+		repo:      repo,
+		publisher: publisher,
+		logger:    logger,
 	}
 }
 
@@ -34,51 +47,9 @@ func (s *RPCNodeService) TestConnection(ctx context.Context, url string, timeout
 		Timeout: time.Duration(timeout) * time.Second,
 	}
 
-	// 准备 JSON-RPC 请求（eth_blockNumber 是一个简单的测试）
-	payload := map[string]interface{}{
-		"jsonrpc": "2.0",
-		"method":  "eth_blockNumber",
-		"params":  []interface{}{},
-		"id":      1,
-	}
-
-	jsonData, err := json.Marshal(payload)
-	if err != nil {
-		return false, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	// 发起请求
-	req, err := http.NewRequestWithContext(ctx, "POST", url, strings.NewReader(string(jsonData)))
-	if err != nil {
-		return false, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return false, fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// 检查响应是否成功
-	if resp.StatusCode != http.StatusOK {
-		return false, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
-
-	// 解析响应
-	var result map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return false, fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	// 检查响应是否包含结果或错误
-	if _, hasError := result["error"]; hasError {
-		return false, fmt.Errorf("RPC error: %v", result["error"])
-	}
-
-	if _, hasResult := result["result"]; !hasResult {
-		return false, fmt.Errorf("no result in response")
+	// eth_blockNumber 是一个简单的测试
+	if _, err := callRPC(ctx, client, url, "eth_blockNumber"); err != nil {
+		return false, err
 	}
 
 	return true, nil
@@ -87,7 +58,9 @@ func (s *RPCNodeService) TestConnection(ctx context.Context, url string, timeout
 // Create 创建一个新的 RPC 节点并测试其连接
 func (s *RPCNodeService) Create(ctx context.Context, node *models.RPCNode) error {
 	// 在创建前测试连接
+	start := time.Now()
 	isConnected, err := s.TestConnection(ctx, node.URL, node.Timeout)
+	latency := time.Since(start)
 	if err != nil {
 		s.logger.Warn("RPC node connection test failed",
 			zap.String("url", node.URL),
@@ -98,7 +71,20 @@ func (s *RPCNodeService) Create(ctx context.Context, node *models.RPCNode) error
 	now := time.Now()
 	node.LastChecked = &now
 
-	return s.repo.Create(ctx, node)
+	// 连接成功时一并探测节点能力，没有结论的能力保持默认值（不支持）
+	if isConnected {
+		if err := s.DetectCapabilities(ctx, node); err != nil {
+			s.logger.Warn("RPC node capability detection failed",
+				zap.String("url", node.URL),
+				zap.Error(err))
+		}
+	}
+
+	if err := s.repo.Create(ctx, node); err != nil {
+		return err
+	}
+	metrics.SetRPCNodeStatus(node.ID, node.ChainID, node.Name, isConnected, latency)
+	return nil
 }
 
 // GetByID 根据 ID 获取 RPC 节点
@@ -128,7 +114,11 @@ func (s *RPCNodeService) Update(ctx context.Context, node *models.RPCNode) error
 
 // Delete 删除 RPC 节点
 func (s *RPCNodeService) Delete(ctx context.Context, id uint) error {
-	return s.repo.Delete(ctx, id)
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	metrics.ForgetRPCNode(id)
+	return nil
 }
 
 // CheckConnection 测试特定节点的连接并更新其状态
@@ -138,12 +128,14 @@ func (s *RPCNodeService) CheckConnection(ctx context.Context, id uint) (bool, er
 		return false, fmt.Errorf("failed to get node: %w", err)
 	}
 
-	isConnected, err := s.TestConnection(ctx, node.URL, node.Timeout)
-	if err != nil {
+	start := time.Now()
+	isConnected, checkErr := s.TestConnection(ctx, node.URL, node.Timeout)
+	metrics.SetRPCNodeStatus(node.ID, node.ChainID, node.Name, isConnected, time.Since(start))
+	if checkErr != nil {
 		s.logger.Warn("Connection check failed",
 			zap.Uint("node_id", id),
 			zap.String("url", node.URL),
-			zap.Error(err))
+			zap.Error(checkErr))
 	}
 
 	// 更新连接状态
@@ -154,6 +146,30 @@ func (s *RPCNodeService) CheckConnection(ctx context.Context, id uint) (bool, er
 		return isConnected, fmt.Errorf("failed to update status: %w", err)
 	}
 
+	if isConnected != node.IsConnected {
+		status := RPCNodeStatus{
+			NodeID:       node.ID,
+			ChainID:      node.ChainID,
+			Name:         node.Name,
+			IsConnected:  isConnected,
+			WasConnected: node.IsConnected,
+		}
+		if checkErr != nil {
+			status.Error = checkErr.Error()
+		}
+		s.publisher.Publish(events.New(events.RPCNodeStatusChanged, 0, 0, status))
+	}
+
+	// 能力探测需要十余次调用，例行检查只在从未探测或结果过期时刷新
+	if isConnected && capabilitiesStale(node) {
+		if err := s.refreshCapabilities(ctx, node); err != nil {
+			s.logger.Warn("Capability detection failed",
+				zap.Uint("node_id", id),
+				zap.String("url", node.URL),
+				zap.Error(err))
+		}
+	}
+
 	return isConnected, nil
 }
 
@@ -181,8 +197,91 @@ func (s *RPCNodeService) CheckAllConnections(ctx context.Context) error {
 	return nil
 }
 
-// GetEnabledNodesByChain 返回链的已启用和已连接节点
+// GetEnabledNodesByChain 返回链的已启用节点，可要求节点具备指定能力
 // 数据提供者可以使用此方法根据权重选择 RPC 节点
-func (s *RPCNodeService) GetEnabledNodesByChain(ctx context.Context, chainID string) ([]models.RPCNode, error) {
-	return s.repo.GetEnabledByChainID(ctx, chainID)
+func (s *RPCNodeService) GetEnabledNodesByChain(ctx context.Context, chainID string, capabilities ...string) ([]models.RPCNode, error) {
+	nodes, err := s.repo.GetEnabledByChainID(ctx, chainID)
+	if err != nil {
+		return nil, err
+	}
+	return filterByCapabilities(nodes, capabilities), nil
+}
+
+// GetByChainIDWithCapabilities 获取特定链上具备指定能力的所有 RPC 节点
+func (s *RPCNodeService) GetByChainIDWithCapabilities(ctx context.Context, chainID string, capabilities ...string) ([]models.RPCNode, error) {
+	var nodes []models.RPCNode
+	var err error
+	if chainID != "" {
+		nodes, err = s.repo.GetByChainID(ctx, chainID)
+	} else {
+		nodes, err = s.repo.GetAll(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return filterByCapabilities(nodes, capabilities), nil
+}
+
+// SelectNode 为链选择一个已连接且具备指定能力的节点
+// 只在最高优先级的节点中按权重随机选择
+func (s *RPCNodeService) SelectNode(ctx context.Context, chainID string, capabilities ...string) (*models.RPCNode, error) {
+	nodes, err := s.GetEnabledNodesByChain(ctx, chainID, capabilities...)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []models.RPCNode
+	for _, node := range nodes {
+		if !node.IsConnected {
+			continue
+		}
+		// 节点已按优先级降序排列
+		if len(candidates) > 0 && node.Priority < candidates[0].Priority {
+			break
+		}
+		candidates = append(candidates, node)
+	}
+
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no connected RPC node for chain %s with capabilities %v", chainID, capabilities)
+	}
+
+	totalWeight := 0
+	for _, node := range candidates {
+		totalWeight += node.Weight
+	}
+	if totalWeight <= 0 {
+		return &candidates[0], nil
+	}
+
+	pick := rand.Intn(totalWeight)
+	for i := range candidates {
+		pick -= candidates[i].Weight
+		if pick < 0 {
+			return &candidates[i], nil
+		}
+	}
+	return &candidates[0], nil
+}
+
+// filterByCapabilities 只保留具备所有指定能力的节点
+func filterByCapabilities(nodes []models.RPCNode, capabilities []string) []models.RPCNode {
+	if len(capabilities) == 0 {
+		return nodes
+	}
+
+	filtered := make([]models.RPCNode, 0, len(nodes))
+	for _, node := range nodes {
+		matched := true
+		for _, capability := range capabilities {
+			if !node.HasCapability(capability) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			filtered = append(filtered, node)
+		}
+	}
+	return filtered
 }
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rotki-demo/internal/models"
+	"github.com/rotki-demo/internal/repository"
+	"go.uber.org/zap"
+)
+
+func TestSelectNode(t *testing.T) {
+	// node 描述测试中创建的节点
+	type node struct {
+		name      string
+		priority  int
+		weight    int
+		connected bool
+		archive   bool
+	}
+
+	tests := []struct {
+		name         string
+		nodes        []node
+		capabilities []string
+		want         map[string]float64 // 节点名 -> 期望的选中比例，为空表示应返回错误
+	}{
+		{
+			name: "highest connected priority wins",
+			nodes: []node{
+				{name: "backup", priority: 1, weight: 100, connected: true},
+				{name: "primary", priority: 10, weight: 100, connected: true},
+				{name: "offline", priority: 20, weight: 100},
+			},
+			want: map[string]float64{"primary": 1},
+		},
+		{
+			name: "weighted within the same priority",
+			nodes: []node{
+				{name: "light", priority: 10, weight: 25, connected: true},
+				{name: "heavy", priority: 10, weight: 75, connected: true},
+				{name: "backup", priority: 1, weight: 100, connected: true},
+			},
+			want: map[string]float64{"light": 0.25, "heavy": 0.75},
+		},
+		{
+			name: "zero weights fall back to the first node",
+			nodes: []node{
+				{name: "a", priority: 10, weight: 0, connected: true},
+			},
+			want: map[string]float64{"a": 1},
+		},
+		{
+			name: "capability filter applies before priority",
+			nodes: []node{
+				{name: "full", priority: 10, weight: 100, connected: true},
+				{name: "archive", priority: 1, weight: 100, connected: true, archive: true},
+			},
+			capabilities: []string{models.RPCCapabilityArchive},
+			want:         map[string]float64{"archive": 1},
+		},
+		{
+			name: "no connected node",
+			nodes: []node{
+				{name: "offline", priority: 10, weight: 100},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			for _, n := range tt.nodes {
+				record := models.RPCNode{ChainID: "eth", Name: n.name, URL: "http://" + n.name, Priority: n.priority, Weight: n.weight, IsConnected: n.connected, IsArchive: n.archive}
+				if err := db.Create(&record).Error; err != nil {
+					t.Fatalf("create node: %v", err)
+				}
+				// weight 为 0 时 Create 使用列默认值，需要单独更新
+				if err := db.Model(&record).Update("weight", n.weight).Error; err != nil {
+					t.Fatalf("update weight: %v", err)
+				}
+			}
+			s := NewRPCNodeService(repository.NewRPCNodeRepository(db), nil, zap.NewNop())
+
+			const picks = 1000
+			counts := make(map[string]int)
+			for i := 0; i < picks; i++ {
+				selected, err := s.SelectNode(context.Background(), "eth", tt.capabilities...)
+				if len(tt.want) == 0 {
+					if err == nil {
+						t.Fatalf("SelectNode = %s, want error", selected.Name)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("SelectNode: %v", err)
+				}
+				counts[selected.Name]++
+			}
+
+			for name, count := range counts {
+				if _, ok := tt.want[name]; !ok {
+					t.Fatalf("selected %s %d times, want only %v", name, count, tt.want)
+				}
+			}
+			for name, ratio := range tt.want {
+				got := float64(counts[name]) / picks
+				if got < ratio-0.06 || got > ratio+0.06 {
+					t.Errorf("%s selected %.2f of the time, want about %.2f", name, got, ratio)
+				}
+			}
+		})
+	}
+}
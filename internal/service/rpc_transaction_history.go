@@ -0,0 +1,405 @@
+package service
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rotki-demo/internal/config"
+	"github.com/rotki-demo/internal/provider"
+)
+
+const (
+	// erc20TransferTopic 是 Transfer(address,address,uint256) 事件的签名哈希
+	erc20TransferTopic = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+
+	// defaultLogsBlockRange 是节点未探测出 eth_getLogs 范围上限时每次查询的区块数
+	defaultLogsBlockRange = 1000
+
+	// maxLogRequests 是一次获取最多查询的区块范围数，剩余区块通过 HasMore 继续获取
+	maxLogRequests = 20
+
+	// ERC-20 decimals() 和 symbol() 的函数选择器
+	decimalsSelector = "0x313ce567"
+	symbolSelector   = "0x95d89b41"
+
+	// defaultTokenDecimals 是无法查询 decimals() 时使用的精度
+	defaultTokenDecimals = 18
+)
+
+// rpcLog 是 eth_getLogs 返回的一条日志
+type rpcLog struct {
+	Address         string   `json:"address"`
+	Topics          []string `json:"topics"`
+	Data            string   `json:"data"`
+	BlockNumber     string   `json:"blockNumber"`
+	TransactionHash string   `json:"transactionHash"`
+	LogIndex        string   `json:"logIndex"`
+	Removed         bool     `json:"removed"`
+}
+
+// rpcTokenMetadata 是从合约查询到的代币符号和精度
+type rpcTokenMetadata struct {
+	symbol   string
+	decimals int
+}
+
+// RPCHistoryProvider 通过已配置的 RPC 节点扫描 ERC-20 Transfer 日志获取交易历史，不消耗第三方 API 额度。
+// 只能发现包含 ERC-20 转账的交易：原生代币转账、授权和不产生转账的合约调用不会出现；手续费只在地址发起交易时记录
+type RPCHistoryProvider struct {
+	nodes *RPCNodeService
+
+	mu     sync.Mutex
+	tokens map[string]rpcTokenMetadata // 键为 链 ID/合约地址
+}
+
+// NewRPCHistoryProvider 创建一个基于 RPC 日志的交易历史提供者
+func NewRPCHistoryProvider(nodes *RPCNodeService) *RPCHistoryProvider {
+	return &RPCHistoryProvider{
+		nodes:  nodes,
+		tokens: make(map[string]rpcTokenMetadata),
+	}
+}
+
+// GetName 返回提供者名称
+func (p *RPCHistoryProvider) GetName() string {
+	return config.TransactionSourceRPC
+}
+
+// GetTransactionHistory 从游标（已扫描的最后一个区块）之后向前扫描 Transfer 日志；首次同步从 since 时的区块开始。
+// 每次最多查询 maxLogRequests 个区块范围，未扫描到最新区块时 HasMore 为 true
+func (p *RPCHistoryProvider) GetTransactionHistory(ctx context.Context, address, chainID, cursor string, since time.Time) (*provider.TransactionHistory, error) {
+	nodes, err := p.nodes.GetEnabledNodesByChain(ctx, chainID)
+	if err != nil {
+		return nil, err
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("%w: no RPC node for chain %s", provider.ErrChainNotSupported, chainID)
+	}
+	node, err := p.nodes.SelectNode(ctx, chainID)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Timeout: time.Duration(node.Timeout) * time.Second}
+
+	raw, err := callRPC(ctx, client, node.URL, "eth_blockNumber")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest block: %w", err)
+	}
+	latest, err := parseHexQuantity(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse latest block: %w", err)
+	}
+
+	var from uint64
+	if cursor != "" {
+		last, err := strconv.ParseUint(cursor, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor %q: %w", cursor, err)
+		}
+		from = last + 1
+	} else if from, err = p.findBlockAt(ctx, client, node.URL, since, latest); err != nil {
+		return nil, fmt.Errorf("failed to find start block: %w", err)
+	}
+	if from > latest {
+		return &provider.TransactionHistory{NextCursor: strconv.FormatUint(from-1, 10)}, nil
+	}
+
+	blockRange := uint64(node.MaxLogsBlockRange)
+	if blockRange == 0 {
+		blockRange = defaultLogsBlockRange
+	}
+	addressTopic := "0x000000000000000000000000" + strings.ToLower(strings.TrimPrefix(address, "0x"))
+
+	// 分别查询转出（from 为地址）和转入（to 为地址）的日志
+	var logs []rpcLog
+	next := from
+	for i := 0; i < maxLogRequests && next <= latest; i++ {
+		start := next
+		end := min(start+blockRange-1, latest)
+		for _, topics := range [][]interface{}{
+			{erc20TransferTopic, addressTopic},
+			{erc20TransferTopic, nil, addressTopic},
+		} {
+			raw, err := callRPC(ctx, client, node.URL, "eth_getLogs", map[string]interface{}{
+				"fromBlock": toHexQuantity(start),
+				"toBlock":   toHexQuantity(end),
+				"topics":    topics,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to get logs for blocks %d-%d: %w", start, end, err)
+			}
+			var batch []rpcLog
+			if err := json.Unmarshal(raw, &batch); err != nil {
+				return nil, fmt.Errorf("failed to decode logs: %w", err)
+			}
+			logs = append(logs, batch...)
+		}
+		next = end + 1
+	}
+
+	transactions, err := p.buildTransactions(ctx, client, node.URL, address, chainID, logs)
+	if err != nil {
+		return nil, err
+	}
+	return &provider.TransactionHistory{
+		Transactions: transactions,
+		NextCursor:   strconv.FormatUint(next-1, 10),
+		HasMore:      next <= latest,
+	}, nil
+}
+
+// buildTransactions 按交易哈希合并日志，查询收据和区块时间，按区块顺序返回
+func (p *RPCHistoryProvider) buildTransactions(ctx context.Context, client *http.Client, endpoint, address, chainID string, logs []rpcLog) ([]provider.TransactionInfo, error) {
+	address = strings.ToLower(address)
+
+	// 自转账会同时出现在两次查询中，按日志位置去重；ERC-721 的 Transfer 有 4 个 topic，跳过
+	type txLogs struct {
+		block uint64
+		logs  []rpcLog
+	}
+	byHash := make(map[string]*txLogs)
+	seen := make(map[string]bool)
+	for _, log := range logs {
+		key := log.TransactionHash + "/" + log.LogIndex
+		if log.Removed || len(log.Topics) != 3 || seen[key] {
+			continue
+		}
+		seen[key] = true
+		entry, ok := byHash[log.TransactionHash]
+		if !ok {
+			block, err := hexToUint64(log.BlockNumber)
+			if err != nil {
+				return nil, fmt.Errorf("invalid block number %q: %w", log.BlockNumber, err)
+			}
+			entry = &txLogs{block: block}
+			byHash[log.TransactionHash] = entry
+		}
+		entry.logs = append(entry.logs, log)
+	}
+
+	hashes := make([]string, 0, len(byHash))
+	for hash := range byHash {
+		hashes = append(hashes, hash)
+	}
+	sort.Slice(hashes, func(i, j int) bool {
+		if byHash[hashes[i]].block != byHash[hashes[j]].block {
+			return byHash[hashes[i]].block < byHash[hashes[j]].block
+		}
+		return hashes[i] < hashes[j]
+	})
+
+	blockTimes := make(map[uint64]time.Time)
+	transactions := make([]provider.TransactionInfo, 0, len(hashes))
+	for _, hash := range hashes {
+		entry := byHash[hash]
+
+		blockTime, ok := blockTimes[entry.block]
+		if !ok {
+			var err error
+			if blockTime, err = blockTimestamp(ctx, client, endpoint, entry.block); err != nil {
+				return nil, err
+			}
+			blockTimes[entry.block] = blockTime
+		}
+
+		raw, err := callRPC(ctx, client, endpoint, "eth_getTransactionReceipt", hash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get receipt %s: %w", hash, err)
+		}
+		var receipt struct {
+			From              string `json:"from"`
+			To                string `json:"to"`
+			Status            string `json:"status"`
+			GasUsed           string `json:"gasUsed"`
+			EffectiveGasPrice string `json:"effectiveGasPrice"`
+		}
+		if err := json.Unmarshal(raw, &receipt); err != nil {
+			return nil, fmt.Errorf("failed to decode receipt %s: %w", hash, err)
+		}
+		sentByAddress := strings.EqualFold(receipt.From, address)
+
+		tx := provider.TransactionInfo{
+			Hash:         hash,
+			ChainID:      chainID,
+			BlockNumber:  entry.block,
+			BlockTime:    blockTime,
+			Counterparty: receipt.From,
+			Failed:       receipt.Status == "0x0",
+		}
+		if sentByAddress {
+			tx.Counterparty = receipt.To
+			tx.Fee = weiToNative(receipt.GasUsed, receipt.EffectiveGasPrice)
+		}
+
+		for _, log := range entry.logs {
+			from := topicAddress(log.Topics[1])
+			to := topicAddress(log.Topics[2])
+			metadata := p.tokenMetadata(ctx, client, endpoint, chainID, log.Address)
+			transfer := provider.TokenTransfer{
+				TokenID: strings.ToLower(log.Address),
+				Symbol:  metadata.symbol,
+				Amount:  scaleAmount(log.Data, metadata.decimals),
+			}
+			if from == address {
+				out := transfer
+				out.Counterparty = to
+				tx.TransfersOut = append(tx.TransfersOut, out)
+			}
+			if to == address {
+				in := transfer
+				in.Counterparty = from
+				tx.TransfersIn = append(tx.TransfersIn, in)
+			}
+		}
+
+		switch {
+		case sentByAddress && len(tx.TransfersIn) == 0:
+			tx.Category = "send"
+		case !sentByAddress && len(tx.TransfersOut) == 0:
+			tx.Category = "receive"
+		}
+		transactions = append(transactions, tx)
+	}
+	return transactions, nil
+}
+
+// findBlockAt 二分查找时间不早于 t 的第一个区块
+func (p *RPCHistoryProvider) findBlockAt(ctx context.Context, client *http.Client, endpoint string, t time.Time, latest uint64) (uint64, error) {
+	lo, hi := uint64(0), latest
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		blockTime, err := blockTimestamp(ctx, client, endpoint, mid)
+		if err != nil {
+			return 0, err
+		}
+		if blockTime.Before(t) {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	return lo, nil
+}
+
+// tokenMetadata 返回代币的符号和精度，结果按链和合约缓存；查询失败时符号为空，精度为 defaultTokenDecimals
+func (p *RPCHistoryProvider) tokenMetadata(ctx context.Context, client *http.Client, endpoint, chainID, contract string) rpcTokenMetadata {
+	key := chainID + "/" + strings.ToLower(contract)
+	p.mu.Lock()
+	metadata, ok := p.tokens[key]
+	p.mu.Unlock()
+	if ok {
+		return metadata
+	}
+
+	metadata = rpcTokenMetadata{decimals: defaultTokenDecimals}
+	call := func(selector string) string {
+		raw, err := callRPC(ctx, client, endpoint, "eth_call", map[string]string{"to": contract, "data": selector}, "latest")
+		if err != nil {
+			return ""
+		}
+		var result string
+		_ = json.Unmarshal(raw, &result)
+		return result
+	}
+	if decimals, err := hexToUint64(call(decimalsSelector)); err == nil && decimals <= 36 {
+		metadata.decimals = int(decimals)
+	}
+	metadata.symbol = decodeABIString(call(symbolSelector))
+
+	// 请求被取消时不缓存默认值
+	if ctx.Err() == nil {
+		p.mu.Lock()
+		p.tokens[key] = metadata
+		p.mu.Unlock()
+	}
+	return metadata
+}
+
+// blockTimestamp 返回区块的时间
+func blockTimestamp(ctx context.Context, client *http.Client, endpoint string, number uint64) (time.Time, error) {
+	raw, err := callRPC(ctx, client, endpoint, "eth_getBlockByNumber", toHexQuantity(number), false)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get block %d: %w", number, err)
+	}
+	var block struct {
+		Timestamp string `json:"timestamp"`
+	}
+	if err := json.Unmarshal(raw, &block); err != nil {
+		return time.Time{}, fmt.Errorf("failed to decode block %d: %w", number, err)
+	}
+	seconds, err := hexToUint64(block.Timestamp)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp of block %d: %w", number, err)
+	}
+	return time.Unix(int64(seconds), 0), nil
+}
+
+// hexToUint64 解析十六进制数量字符串
+func hexToUint64(value string) (uint64, error) {
+	return strconv.ParseUint(strings.TrimPrefix(value, "0x"), 16, 64)
+}
+
+// hexToBig 解析十六进制数，无效时返回 0
+func hexToBig(value string) *big.Int {
+	n, ok := new(big.Int).SetString(strings.TrimPrefix(value, "0x"), 16)
+	if !ok {
+		return new(big.Int)
+	}
+	return n
+}
+
+// scaleAmount 把日志中的 uint256 原始数量按精度转换为代币数量
+func scaleAmount(data string, decimals int) float64 {
+	amount := new(big.Float).SetInt(hexToBig(data))
+	scale := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+	result, _ := new(big.Float).Quo(amount, scale).Float64()
+	return result
+}
+
+// weiToNative 计算 gasUsed × gasPrice 并转换为原生代币数量（18 位精度）
+func weiToNative(gasUsed, gasPrice string) float64 {
+	fee := new(big.Int).Mul(hexToBig(gasUsed), hexToBig(gasPrice))
+	return scaleAmount("0x"+fee.Text(16), 18)
+}
+
+// topicAddress 从 32 字节的 indexed 参数中取出小写地址
+func topicAddress(topic string) string {
+	topic = strings.TrimPrefix(topic, "0x")
+	if len(topic) < 40 {
+		return ""
+	}
+	return "0x" + strings.ToLower(topic[len(topic)-40:])
+}
+
+// decodeABIString 解码 eth_call 返回的 string；部分旧代币返回 bytes32
+func decodeABIString(result string) string {
+	data, err := hex.DecodeString(strings.TrimPrefix(result, "0x"))
+	if err != nil || len(data) == 0 {
+		return ""
+	}
+	if len(data) == 32 {
+		return strings.TrimRight(string(data), "\x00")
+	}
+	if len(data) < 64 {
+		return ""
+	}
+	offset := new(big.Int).SetBytes(data[:32])
+	if !offset.IsUint64() || offset.Uint64()+32 > uint64(len(data)) {
+		return ""
+	}
+	start := offset.Uint64()
+	length := new(big.Int).SetBytes(data[start : start+32])
+	if !length.IsUint64() || start+32+length.Uint64() > uint64(len(data)) {
+		return ""
+	}
+	return string(data[start+32 : start+32+length.Uint64()])
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rotki-demo/internal/repository"
+)
+
+// snapshotDeleteBatch 是每条 DELETE 语句删除的快照数，避免 IN 列表过长
+const snapshotDeleteBatch = 500
+
+// SnapshotCompactOptions 是快照压缩的保留策略
+type SnapshotCompactOptions struct {
+	KeepAll    time.Duration // 该时间内的快照全部保留
+	KeepHourly time.Duration // 该时间内（KeepAll 之前）每小时保留一个，更早的每天保留一个
+	DryRun     bool          // 只统计不删除
+}
+
+// SnapshotCompactResult 是快照压缩的统计
+type SnapshotCompactResult struct {
+	Addresses int   `json:"addresses"`
+	Scanned   int   `json:"scanned"` // 早于 KeepAll 的快照数
+	Deleted   int64 `json:"deleted"` // DryRun 时为将要删除的数量
+}
+
+// SnapshotService 维护资产快照。每次同步都会写入快照，长期运行后按小时和按天降采样，
+// 保留每个时间段内最新的快照，余额告警使用的最新快照不受影响
+type SnapshotService struct {
+	snapshotRepo *repository.SnapshotRepository
+}
+
+// NewSnapshotService 创建快照服务
+func NewSnapshotService(snapshotRepo *repository.SnapshotRepository) *SnapshotService {
+	return &SnapshotService{snapshotRepo: snapshotRepo}
+}
+
+// Compact 按保留策略降采样所有地址的快照，now 为计算保留期的基准时间
+func (s *SnapshotService) Compact(now time.Time, opts SnapshotCompactOptions) (*SnapshotCompactResult, error) {
+	if opts.KeepAll < 0 || opts.KeepHourly < opts.KeepAll {
+		return nil, fmt.Errorf("invalid retention: keep-all %s must not be negative or longer than keep-hourly %s", opts.KeepAll, opts.KeepHourly)
+	}
+
+	addressIDs, err := s.snapshotRepo.ListAddressIDs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshot addresses: %w", err)
+	}
+
+	allCutoff := now.Add(-opts.KeepAll)
+	hourlyCutoff := now.Add(-opts.KeepHourly)
+	result := &SnapshotCompactResult{Addresses: len(addressIDs)}
+
+	for _, addressID := range addressIDs {
+		snapshots, err := s.snapshotRepo.ListTimesBefore(addressID, allCutoff)
+		if err != nil {
+			return result, fmt.Errorf("failed to list snapshots of address %d: %w", addressID, err)
+		}
+		result.Scanned += len(snapshots)
+
+		// 快照按时间倒序，每个时间段第一个出现的是最新的，保留它
+		var (
+			remove []uint
+			kept   = make(map[time.Time]bool)
+		)
+		for _, snapshot := range snapshots {
+			bucket := snapshot.SnapshotTime.UTC().Truncate(24 * time.Hour)
+			if snapshot.SnapshotTime.After(hourlyCutoff) {
+				bucket = snapshot.SnapshotTime.UTC().Truncate(time.Hour)
+			}
+			if kept[bucket] {
+				remove = append(remove, snapshot.ID)
+				continue
+			}
+			kept[bucket] = true
+		}
+
+		if opts.DryRun {
+			result.Deleted += int64(len(remove))
+			continue
+		}
+		for start := 0; start < len(remove); start += snapshotDeleteBatch {
+			end := min(start+snapshotDeleteBatch, len(remove))
+			deleted, err := s.snapshotRepo.DeleteByIDs(remove[start:end])
+			result.Deleted += deleted
+			if err != nil {
+				return result, fmt.Errorf("failed to delete snapshots of address %d: %w", addressID, err)
+			}
+		}
+	}
+
+	return result, nil
+}
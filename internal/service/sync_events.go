@@ -0,0 +1,193 @@
+package service
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/rotki-demo/internal/events"
+	"github.com/rotki-demo/internal/metrics"
+	"github.com/rotki-demo/internal/models"
+)
+
+// SyncResult 是 sync.completed 和 sync.failed 事件的数据
+type SyncResult struct {
+	AddressID       uint     `json:"address_id"`
+	WalletID        uint     `json:"wallet_id"`
+	Address         string   `json:"address"`
+	AddressUSDValue *float64 `json:"address_usd_value,omitempty"` // 同步后的地址总价值，仅 sync.completed
+	WalletUSDValue  *float64 `json:"wallet_usd_value,omitempty"`  // 同步后的钱包总价值，仅 sync.completed
+	Error           string   `json:"error,omitempty"`
+}
+
+// 同步任务的触发方式
+const (
+	SyncTriggerScheduled = "scheduled"
+	SyncTriggerWallet    = "wallet"
+	SyncTriggerManual    = "manual" // 管理命令触发的全量同步
+)
+
+// 同步任务状态
+const (
+	SyncJobStarted  = "started"
+	SyncJobRunning  = "running"
+	SyncJobFinished = "finished"
+)
+
+// SyncProgress 是 sync.progress 事件的数据
+type SyncProgress struct {
+	JobID     string `json:"job_id"`
+	Trigger   string `json:"trigger"`
+	Status    string `json:"status"`
+	WalletID  uint   `json:"wallet_id,omitempty"`
+	Total     int    `json:"total"`
+	Completed int    `json:"completed"` // 已处理的地址数，包括失败的地址
+	Failed    int    `json:"failed"`
+	AddressID uint   `json:"address_id,omitempty"` // 刚处理完的地址
+	Address   string `json:"address,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// syncJob 跟踪一次批量同步的进度，每处理完一个地址发布一次 sync.progress 事件
+type syncJob struct {
+	publisher events.Publisher
+	mu        sync.Mutex
+	progress  SyncProgress
+}
+
+// startSyncJob 创建同步任务并发布 started 进度
+func (s *SyncService) startSyncJob(trigger string, walletID uint, total int) *syncJob {
+	event := events.New(events.SyncProgress, walletID, 0, nil)
+	job := &syncJob{
+		publisher: s.publisher,
+		progress: SyncProgress{
+			JobID:    event.ID,
+			Trigger:  trigger,
+			Status:   SyncJobStarted,
+			WalletID: walletID,
+			Total:    total,
+		},
+	}
+	event.Data = job.progress
+	s.publisher.Publish(event)
+	metrics.AddSyncQueue(total)
+	return job
+}
+
+// addressDone 记录一个地址的同步结果并发布进度，可并发调用
+func (j *syncJob) addressDone(address *models.Address, err error) {
+	j.mu.Lock()
+	j.progress.Status = SyncJobRunning
+	j.progress.Completed++
+	j.progress.AddressID = address.ID
+	j.progress.Address = address.Address
+	j.progress.Error = ""
+	if err != nil {
+		j.progress.Failed++
+		j.progress.Error = err.Error()
+	}
+	progress := j.progress
+	j.mu.Unlock()
+
+	metrics.AddSyncQueue(-1)
+	j.publisher.Publish(events.New(events.SyncProgress, progress.WalletID, 0, progress))
+}
+
+// finish 发布 finished 进度
+func (j *syncJob) finish() {
+	j.mu.Lock()
+	j.progress.Status = SyncJobFinished
+	j.progress.AddressID = 0
+	j.progress.Address = ""
+	j.progress.Error = ""
+	progress := j.progress
+	j.mu.Unlock()
+
+	// 任务提前结束时移除队列中剩余的地址
+	if remaining := progress.Total - progress.Completed; remaining > 0 {
+		metrics.AddSyncQueue(-remaining)
+	}
+
+	j.publisher.Publish(events.New(events.SyncProgress, progress.WalletID, 0, progress))
+}
+
+// WalletValueChange 是 wallet.value_changed 事件的数据
+type WalletValueChange struct {
+	WalletID         uint    `json:"wallet_id"`
+	PreviousUSDValue float64 `json:"previous_usd_value"`
+	CurrentUSDValue  float64 `json:"current_usd_value"`
+	ChangeUSD        float64 `json:"change_usd"`
+	ChangePercent    float64 `json:"change_percent"` // 之前价值为 0 时为 100
+}
+
+// publishValueChange 在同步后钱包总价值发生变化时发布事件，是否超过阈值由订阅方判断
+func (s *SyncService) publishValueChange(walletID uint, previous, current float64) {
+	if current == previous {
+		return
+	}
+
+	change := WalletValueChange{
+		WalletID:         walletID,
+		PreviousUSDValue: previous,
+		CurrentUSDValue:  current,
+		ChangeUSD:        current - previous,
+		ChangePercent:    100,
+	}
+	if previous != 0 {
+		change.ChangePercent = (current - previous) / absFloat(previous) * 100
+	}
+	s.publisher.Publish(events.New(events.WalletValueChanged, walletID, 0, change))
+}
+
+// publishNewTokens 为之前不存在的 normal 钱包代币发布 token.appeared 事件
+func (s *SyncService) publishNewTokens(address *models.Address, previous, current []models.Token) {
+	seen := make(map[string]bool, len(previous))
+	for _, token := range previous {
+		seen[tokenKey(token.ChainID, token.TokenID, token.ProtocolID)] = true
+	}
+
+	for _, token := range current {
+		if token.Classification != models.TokenClassificationNormal {
+			continue
+		}
+		if seen[tokenKey(token.ChainID, token.TokenID, token.ProtocolID)] {
+			continue
+		}
+		s.publisher.Publish(events.New(events.TokenAppeared, address.WalletID, address.ID, token))
+	}
+}
+
+// publishPositionChanges 比较同步前后的持仓，发布 position.opened / position.closed 事件
+// 首次同步时不发布开仓事件，避免把已有持仓当作新开仓
+func (s *SyncService) publishPositionChanges(address *models.Address, previous, current []models.ProtocolPosition, firstSync bool) {
+	before := make(map[string]models.ProtocolPosition, len(previous))
+	for _, position := range previous {
+		before[positionEventKey(&position)] = position
+	}
+	after := make(map[string]bool, len(current))
+	for _, position := range current {
+		after[positionEventKey(&position)] = true
+	}
+
+	if !firstSync {
+		for _, position := range current {
+			if _, ok := before[positionEventKey(&position)]; !ok {
+				s.publisher.Publish(events.New(events.PositionOpened, address.WalletID, address.ID, position))
+			}
+		}
+	}
+	for key, position := range before {
+		if !after[key] {
+			s.publisher.Publish(events.New(events.PositionClosed, address.WalletID, address.ID, position))
+		}
+	}
+}
+
+// tokenKey 返回代币的比较键
+func tokenKey(chainID, tokenID, protocolID string) string {
+	return chainID + "|" + strings.ToLower(tokenID) + "|" + protocolID
+}
+
+// positionEventKey 返回持仓的比较键
+func positionEventKey(position *models.ProtocolPosition) string {
+	return position.ProtocolID + "|" + position.ChainID + "|" + position.PositionKey()
+}
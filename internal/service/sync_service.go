@@ -5,15 +5,24 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/rotki-demo/internal/events"
 	"github.com/rotki-demo/internal/logger"
+	"github.com/rotki-demo/internal/metrics"
 	"github.com/rotki-demo/internal/models"
 	"github.com/rotki-demo/internal/provider"
 	"github.com/rotki-demo/internal/repository"
+	"github.com/rotki-demo/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
+// tracer 是服务层 span 的埋点范围
+var tracer = tracing.Tracer("github.com/rotki-demo/internal/service")
+
 // SyncService 处理数据同步
 type SyncService struct {
 	dataProvider provider.DataProvider
@@ -21,11 +30,29 @@ type SyncService struct {
 	addressRepo  *repository.AddressRepository
 	tokenRepo    *repository.TokenRepository
 	protocolRepo *repository.ProtocolRepository
+	positionRepo *repository.ProtocolPositionRepository
 	chainRepo    *repository.ChainRepository
-	syncInterval time.Duration
-	batchSize    int
+	classifier   *TokenClassifier
+	monitor      *HealthMonitor
+	alerts       *BalanceAlertService
+	publisher    events.Publisher
 	stopChan     chan struct{}
 	wg           sync.WaitGroup
+	// stopMu 保护 stopped，使 SyncAddressInBackground 的 wg.Add 不会与 Stop 中的 wg.Wait 并发
+	stopMu  sync.Mutex
+	stopped bool
+	// workCtx 是后台同步实际使用的上下文，只在 Stop 等待超时后取消，
+	// 使关闭信号到来时进行中的同步可以先正常完成
+	workCtx    context.Context
+	cancelWork context.CancelFunc
+	// 同步间隔和批次大小可在配置热加载时修改，修改间隔后通过 reschedule 重置定时器
+	scheduleMu   sync.RWMutex
+	syncInterval time.Duration
+	batchSize    int
+	reschedule   chan struct{}
+
+	// heartbeat 是同步循环最近一次活动的 Unix 纳秒时间，每次定时触发和每个批次完成时更新，供 /livez 判断循环是否卡住
+	heartbeat atomic.Int64
 }
 
 // NewSyncService 创建一个新的同步服务
@@ -35,51 +62,149 @@ func NewSyncService(
 	addressRepo *repository.AddressRepository,
 	tokenRepo *repository.TokenRepository,
 	protocolRepo *repository.ProtocolRepository,
+	positionRepo *repository.ProtocolPositionRepository,
 	chainRepo *repository.ChainRepository,
+	classifier *TokenClassifier,
+	monitor *HealthMonitor,
+	alerts *BalanceAlertService,
+	publisher events.Publisher,
 	syncInterval time.Duration,
 	batchSize int,
 ) *SyncService {
+	workCtx, cancelWork := context.WithCancel(context.Background())
 	return &SyncService{
 		dataProvider: dataProvider,
 		walletRepo:   walletRepo,
 		addressRepo:  addressRepo,
 		tokenRepo:    tokenRepo,
 		protocolRepo: protocolRepo,
+		positionRepo: positionRepo,
 		chainRepo:    chainRepo,
+		classifier:   classifier,
+		monitor:      monitor,
+		alerts:       alerts,
+		publisher:    publisher,
 		syncInterval: syncInterval,
 		batchSize:    batchSize,
 		stopChan:     make(chan struct{}),
+		reschedule:   make(chan struct{}, 1),
+		workCtx:      workCtx,
+		cancelWork:   cancelWork,
 	}
 }
 
-// Start 启动后台同步进程
-func (s *SyncService) Start() {
+// Start 启动后台同步进程，ctx 取消（收到关闭信号）后不再开始新的同步批次
+func (s *SyncService) Start(ctx context.Context) {
 	s.wg.Add(1)
-	go s.syncLoop()
-	logger.Info("Sync service started", zap.Duration("interval", s.syncInterval))
+	go s.syncLoop(ctx)
+	logger.Info("Sync service started", zap.Duration("interval", s.Interval()))
 }
 
-// Stop 停止后台同步进程
-func (s *SyncService) Stop() {
+// Stop 停止后台同步进程，最多等待 drainTimeout 让进行中的同步完成，
+// 超时后取消它们（中断进行中的 DeBank 请求）并等待退出
+func (s *SyncService) Stop(drainTimeout time.Duration) {
+	// 持锁标记停止后，不会再有新的后台同步加入 wg
+	s.stopMu.Lock()
+	s.stopped = true
 	close(s.stopChan)
-	s.wg.Wait()
+	s.stopMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(drainTimeout):
+		logger.Warn("Running syncs did not finish in time, cancelling", zap.Duration("drain_timeout", drainTimeout))
+		s.cancelWork()
+		<-done
+	}
+	s.cancelWork()
 	logger.Info("Sync service stopped")
 }
 
+// SyncAddressInBackground 在后台同步地址，不受请求上下文取消影响，关闭时由 Stop 等待
+func (s *SyncService) SyncAddressInBackground(addressID uint) {
+	s.stopMu.Lock()
+	defer s.stopMu.Unlock()
+	if s.stopped {
+		return
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		_ = s.SyncAddress(s.workCtx, addressID)
+	}()
+}
+
+// Interval 返回当前同步间隔
+func (s *SyncService) Interval() time.Duration {
+	s.scheduleMu.RLock()
+	defer s.scheduleMu.RUnlock()
+	return s.syncInterval
+}
+
+// BatchSize 返回当前每批并发同步的地址数
+func (s *SyncService) BatchSize() int {
+	s.scheduleMu.RLock()
+	defer s.scheduleMu.RUnlock()
+	return s.batchSize
+}
+
+// SetSchedule 修改同步间隔和批次大小，间隔变化时从现在起按新间隔计时，进行中的同步不受影响
+func (s *SyncService) SetSchedule(interval time.Duration, batchSize int) {
+	s.scheduleMu.Lock()
+	changed := interval != s.syncInterval
+	s.syncInterval = interval
+	s.batchSize = batchSize
+	s.scheduleMu.Unlock()
+
+	if changed {
+		select {
+		case s.reschedule <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// LastHeartbeat 返回同步循环最近一次心跳时间，循环未启动时 ok 为 false
+func (s *SyncService) LastHeartbeat() (t time.Time, ok bool) {
+	nanos := s.heartbeat.Load()
+	if nanos == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(0, nanos), true
+}
+
+// beat 记录同步循环心跳
+func (s *SyncService) beat() {
+	s.heartbeat.Store(time.Now().UnixNano())
+}
+
 // syncLoop 运行周期性同步
-func (s *SyncService) syncLoop() {
+func (s *SyncService) syncLoop(ctx context.Context) {
 	defer s.wg.Done()
 
-	ticker := time.NewTicker(s.syncInterval)
+	ticker := time.NewTicker(s.Interval())
 	defer ticker.Stop()
 
 	// 运行初始同步
-	s.syncAll()
+	s.beat()
+	s.syncAll(ctx)
 
 	for {
 		select {
 		case <-ticker.C:
-			s.syncAll()
+			s.beat()
+			s.syncAll(ctx)
+		case <-s.reschedule:
+			ticker.Reset(s.Interval())
+		case <-ctx.Done():
+			return
 		case <-s.stopChan:
 			return
 		}
@@ -87,73 +212,171 @@ func (s *SyncService) syncLoop() {
 }
 
 // syncAll 同步所有需要更新的地址
-func (s *SyncService) syncAll() {
-	ctx := context.Background()
+// stopCtx 只用于判断是否停止开始新的批次，同步本身使用 workCtx，关闭时进行中的批次可以完成
+func (s *SyncService) syncAll(stopCtx context.Context) {
+	ctx, span := tracer.Start(s.workCtx, "SyncService.syncAll")
+	var err error
+	defer func() { tracing.End(span, err) }()
 
 	// 获取需要同步的地址
-	addresses, err := s.addressRepo.GetAllNeedingSync(s.syncInterval)
+	addresses, err := s.addressRepo.WithContext(ctx).GetAllNeedingSync(s.Interval())
 	if err != nil {
-		logger.Error("Failed to get addresses for sync", zap.Error(err))
+		logger.FromContext(ctx).Error("Failed to get addresses for sync", zap.Error(err))
 		return
 	}
+	span.SetAttributes(attribute.Int("address_count", len(addresses)))
 
 	if len(addresses) == 0 {
-		logger.Debug("No addresses need syncing")
+		logger.FromContext(ctx).Debug("No addresses need syncing")
 		return
 	}
 
-	logger.Info("Starting sync", zap.Int("address_count", len(addresses)))
+	s.syncAddresses(stopCtx, ctx, SyncTriggerScheduled, addresses)
+}
+
+// SyncAll 立即同步所有地址，不考虑上次同步时间；ctx 取消后不再开始新的批次
+func (s *SyncService) SyncAll(ctx context.Context) (err error) {
+	ctx, span := tracer.Start(ctx, "SyncService.SyncAll")
+	defer func() { tracing.End(span, err) }()
 
-	// 分批处理
-	for i := 0; i < len(addresses); i += s.batchSize {
-		end := i + s.batchSize
+	addresses, err := s.addressRepo.WithContext(ctx).GetAllNeedingSync(0)
+	if err != nil {
+		return fmt.Errorf("failed to get addresses: %w", err)
+	}
+	span.SetAttributes(attribute.Int("address_count", len(addresses)))
+
+	s.syncAddresses(ctx, ctx, SyncTriggerManual, addresses)
+	return nil
+}
+
+// syncAddresses 创建同步任务并分批同步地址，stopCtx 取消后跳过剩余批次
+func (s *SyncService) syncAddresses(stopCtx, ctx context.Context, trigger string, addresses []models.Address) {
+	job := s.startSyncJob(trigger, 0, len(addresses))
+	ctx = logger.WithFields(ctx, zap.String("sync_job_id", job.progress.JobID))
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("sync_job_id", job.progress.JobID))
+	logger.FromContext(ctx).Info("Starting sync", zap.String("trigger", trigger), zap.Int("address_count", len(addresses)))
+
+	// 分批处理，批次大小在任务开始时确定
+	batchSize := s.BatchSize()
+	for i := 0; i < len(addresses); i += batchSize {
+		if stopCtx.Err() != nil {
+			logger.FromContext(ctx).Info("Shutting down, skipping remaining addresses",
+				zap.Int("remaining", len(addresses)-i))
+			break
+		}
+
+		end := i + batchSize
 		if end > len(addresses) {
 			end = len(addresses)
 		}
 
 		batch := addresses[i:end]
-		s.syncBatch(ctx, batch)
+		s.syncBatch(ctx, job, batch)
+		s.beat()
 	}
 
-	logger.Info("Sync completed", zap.Int("address_count", len(addresses)))
+	job.finish()
+	logger.FromContext(ctx).Info("Sync completed", zap.Int("address_count", len(addresses)))
 }
 
 // syncBatch 并发同步一批地址
-func (s *SyncService) syncBatch(ctx context.Context, addresses []models.Address) {
+func (s *SyncService) syncBatch(ctx context.Context, job *syncJob, addresses []models.Address) {
 	var wg sync.WaitGroup
 
 	for _, addr := range addresses {
 		wg.Add(1)
 		go func(address models.Address) {
 			defer wg.Done()
-			if err := s.SyncAddress(ctx, address.ID); err != nil {
-				logger.Error("Failed to sync address",
+			err := s.SyncAddress(ctx, address.ID)
+			if err != nil {
+				logger.FromContext(ctx).Error("Failed to sync address",
 					zap.Uint("address_id", address.ID),
 					zap.String("address", address.Address),
 					zap.Error(err),
 				)
 			}
+			job.addressDone(&address, err)
 		}(addr)
 	}
 
 	wg.Wait()
 }
 
-// SyncAddress 同步特定地址的数据
-func (s *SyncService) SyncAddress(ctx context.Context, addressID uint) error {
+// SyncAddress 同步特定地址的数据，并发布 sync.completed / sync.failed 和钱包价值变化事件
+func (s *SyncService) SyncAddress(ctx context.Context, addressID uint) (err error) {
+	ctx, span := tracer.Start(ctx, "SyncService.SyncAddress", trace.WithAttributes(attribute.Int64("address.id", int64(addressID))))
+	defer func() { tracing.End(span, err) }()
+
+	addressRepo := s.addressRepo.WithContext(ctx)
+	tokenRepo := s.tokenRepo.WithContext(ctx)
+
 	// 获取地址详情
-	address, err := s.addressRepo.GetByID(addressID)
+	address, err := addressRepo.GetByID(addressID)
 	if err != nil {
 		return fmt.Errorf("failed to get address: %w", err)
 	}
+	span.SetAttributes(attribute.Int64("wallet.id", int64(address.WalletID)))
+
+	previousValue, valueErr := tokenRepo.GetTotalValueByWalletID(address.WalletID)
+
+	start := time.Now()
+	err = s.syncAddress(ctx, address)
+	metrics.ObserveSync(address.ID, time.Since(start), err)
+	if err != nil {
+		s.publisher.Publish(events.New(events.SyncFailed, address.WalletID, address.ID, SyncResult{
+			AddressID: address.ID,
+			WalletID:  address.WalletID,
+			Address:   address.Address,
+			Error:     err.Error(),
+		}))
+		return err
+	}
+
+	// 写入资产快照并评估余额变化告警规则
+	s.alerts.RecordAndEvaluate(ctx, address)
+
+	result := SyncResult{
+		AddressID: address.ID,
+		WalletID:  address.WalletID,
+		Address:   address.Address,
+	}
+	if total, err := tokenRepo.GetTotalValueByAddressID(address.ID); err == nil {
+		result.AddressUSDValue = &total
+	}
+	walletValue, err := tokenRepo.GetTotalValueByWalletID(address.WalletID)
+	if err == nil {
+		result.WalletUSDValue = &walletValue
+	} else {
+		logger.FromContext(ctx).Warn("Failed to get wallet value", zap.Uint("wallet_id", address.WalletID), zap.Error(err))
+	}
+	s.publisher.Publish(events.New(events.SyncCompleted, address.WalletID, address.ID, result))
+
+	if valueErr == nil && err == nil {
+		s.publishValueChange(address.WalletID, previousValue, walletValue)
+	}
+	return nil
+}
+
+// syncAddress 从数据提供者拉取地址的链、代币和协议持仓并保存
+func (s *SyncService) syncAddress(ctx context.Context, address *models.Address) error {
+	addressID := address.ID
+	firstSync := address.LastSyncedAt == nil
+
+	// 绑定上下文，数据库查询作为当前 span 的子 span 记录
+	walletRepo := s.walletRepo.WithContext(ctx)
+	addressRepo := s.addressRepo.WithContext(ctx)
+	tokenRepo := s.tokenRepo.WithContext(ctx)
+	protocolRepo := s.protocolRepo.WithContext(ctx)
+	positionRepo := s.positionRepo.WithContext(ctx)
+	chainRepo := s.chainRepo.WithContext(ctx)
 
 	// 获取钱包以检查启用的链
-	wallet, err := s.walletRepo.GetByID(address.WalletID)
+	wallet, err := walletRepo.GetByID(address.WalletID)
 	if err != nil {
 		return fmt.Errorf("failed to get wallet: %w", err)
 	}
 
-	logger.Debug("Syncing address",
+	logger.FromContext(ctx).Debug("Syncing address",
 		zap.Uint("address_id", addressID),
 		zap.String("address", address.Address),
 		zap.Any("enabled_chains", wallet.EnabledChains),
@@ -199,7 +422,7 @@ func (s *SyncService) SyncAddress(ctx context.Context, addressID uint) error {
 	}
 
 	// 首先更新插入链
-	if err := s.chainRepo.UpsertBatch(dbChains); err != nil {
+	if err := chainRepo.UpsertBatch(dbChains); err != nil {
 		return fmt.Errorf("failed to upsert chains: %w", err)
 	}
 
@@ -209,40 +432,50 @@ func (s *SyncService) SyncAddress(ctx context.Context, addressID uint) error {
 		return fmt.Errorf("failed to get token list: %w", err)
 	}
 
-	// 过滤掉垃圾/欺诈代币
-	filteredTokens := filterSpamTokens(tokens)
+	// 转换为数据库模型并分类；垃圾代币和协议凭证代币同样保存，只是默认不返回
+	dbTokens := make([]models.Token, 0, len(tokens))
+	for _, token := range tokens {
+		dbToken := models.Token{
+			AddressID:  addressID,
+			ChainID:    token.ChainID,
+			TokenID:    token.TokenID,
+			Symbol:     token.Symbol,
+			Name:       token.Name,
+			Decimals:   token.Decimals,
+			LogoURL:    token.LogoURL,
+			Balance:    token.Balance,
+			Price:      token.Price,
+			USDValue:   token.USDValue,
+			IsVerified: token.IsVerified,
+			IsCore:     token.IsCore,
+		}
+		dbToken.Classification = s.classifier.Classify(&dbToken, wallet.ID)
+		dbTokens = append(dbTokens, dbToken)
+	}
 
-	// 转换为数据库模型
-	dbTokens := make([]models.Token, 0, len(filteredTokens))
-	for _, token := range filteredTokens {
-		dbTokens = append(dbTokens, models.Token{
-			AddressID: addressID,
-			ChainID:   token.ChainID,
-			TokenID:   token.TokenID,
-			Symbol:    token.Symbol,
-			Name:      token.Name,
-			Decimals:  token.Decimals,
-			LogoURL:   token.LogoURL,
-			Balance:   token.Balance,
-			Price:     token.Price,
-			USDValue:  token.USDValue,
-		})
+	// 记录同步前的代币，用于发现新出现的代币
+	previousTokens, err := tokenRepo.GetByAddressID(addressID, true)
+	if err != nil {
+		return fmt.Errorf("failed to get previous tokens: %w", err)
 	}
 
 	// 先删除旧的钱包代币（保留协议代币）
-	if err := s.tokenRepo.DeleteWalletTokensByAddressID(addressID); err != nil {
+	if err := tokenRepo.DeleteWalletTokensByAddressID(addressID); err != nil {
 		return fmt.Errorf("failed to delete old wallet tokens: %w", err)
 	}
 
 	// 插入新的钱包代币
-	if err := s.tokenRepo.UpsertBatch(dbTokens); err != nil {
+	if err := tokenRepo.UpsertBatch(dbTokens); err != nil {
 		return fmt.Errorf("failed to upsert tokens: %w", err)
 	}
+	if !firstSync {
+		s.publishNewTokens(address, previousTokens, dbTokens)
+	}
 
 	// 获取并同步协议持仓
 	protocols, err := s.dataProvider.GetProtocolList(ctx, address.Address, chainIDsToQuery)
 	if err != nil {
-		logger.Warn("Failed to get protocol list (non-fatal)",
+		logger.FromContext(ctx).Warn("Failed to get protocol list (non-fatal)",
 			zap.Uint("address_id", addressID),
 			zap.Error(err),
 		)
@@ -251,13 +484,12 @@ func (s *SyncService) SyncAddress(ctx context.Context, addressID uint) error {
 		// 转换为数据库模型
 		dbProtocols := make([]models.Protocol, 0, len(protocols))
 		protocolTokens := make([]models.Token, 0) // 收集所有协议代币
+		positions := make([]models.ProtocolPosition, 0)
 
 		for _, proto := range protocols {
-			// 确定主要的仓位类型
-			positionType := "unknown"
-			if len(proto.PortfolioItems) > 0 {
-				positionType = proto.PortfolioItems[0].PositionType
-			}
+			// 每个 portfolio item 单独保存为一个持仓
+			protoPositions := toProtocolPositions(addressID, proto)
+			positions = append(positions, protoPositions...)
 
 			// 计算所有 portfolio items 的总净值、资产值和债务值
 			var totalNetUSD, totalAssetUSD, totalDebtUSD float64
@@ -281,7 +513,7 @@ func (s *SyncService) SyncAddress(ctx context.Context, addressID uint) error {
 				NetUSDValue:   totalNetUSD,
 				AssetUSDValue: totalAssetUSD,
 				DebtUSDValue:  totalDebtUSD,
-				PositionType:  positionType,
+				PositionType:  summarizePositionType(protoPositions),
 				RawData:       rawData,
 			})
 
@@ -298,7 +530,7 @@ func (s *SyncService) SyncAddress(ctx context.Context, addressID uint) error {
 						}
 					}
 
-					protocolTokens = append(protocolTokens, models.Token{
+					protocolToken := models.Token{
 						AddressID:  addressID,
 						ChainID:    tokenDetail.ChainID,
 						TokenID:    tokenDetail.TokenID,
@@ -311,44 +543,60 @@ func (s *SyncService) SyncAddress(ctx context.Context, addressID uint) error {
 						USDValue:   tokenDetail.USDValue, // 可以是负数
 						ProtocolID: proto.ProtocolID,
 						IsDebt:     tokenDetail.IsDebt,
-					})
+					}
+					// 用户的忽略/白名单同样作用于协议代币，使总值和快照与代币列表一致
+					protocolToken.Classification = s.classifier.ClassifyProtocolToken(&protocolToken, wallet.ID)
+					protocolTokens = append(protocolTokens, protocolToken)
 				}
 			}
 		}
 
 		// 更新插入协议
-		if err := s.protocolRepo.UpsertBatch(dbProtocols); err != nil {
+		if err := protocolRepo.UpsertBatch(dbProtocols); err != nil {
 			return fmt.Errorf("failed to upsert protocols: %w", err)
 		}
 
+		// 替换持仓明细，并与之前的持仓比较以发布开仓/平仓事件
+		previousPositions, err := positionRepo.List(repository.PositionFilter{AddressID: addressID})
+		if err != nil {
+			return fmt.Errorf("failed to get previous positions: %w", err)
+		}
+		if err := positionRepo.ReplaceByAddressID(addressID, positions); err != nil {
+			return fmt.Errorf("failed to replace protocol positions: %w", err)
+		}
+		s.publishPositionChanges(address, previousPositions, positions, firstSync)
+
+		// 检查借贷持仓健康因子，必要时发送清算风险告警
+		s.monitor.Check(ctx, address, positions)
+
 		// 删除旧的协议代币
-		if err := s.tokenRepo.DeleteProtocolTokensByAddressID(addressID); err != nil {
+		if err := tokenRepo.DeleteProtocolTokensByAddressID(addressID); err != nil {
 			return fmt.Errorf("failed to delete old protocol tokens: %w", err)
 		}
 
 		// 将协议代币插入到 tokens 表中
 		if len(protocolTokens) > 0 {
-			if err := s.tokenRepo.UpsertBatch(protocolTokens); err != nil {
+			if err := tokenRepo.UpsertBatch(protocolTokens); err != nil {
 				return fmt.Errorf("failed to upsert protocol tokens: %w", err)
 			}
-			logger.Debug("Protocol tokens synced",
+			logger.FromContext(ctx).Debug("Protocol tokens synced",
 				zap.Uint("address_id", addressID),
 				zap.Int("token_count", len(protocolTokens)),
 			)
 		}
 
-		logger.Debug("Protocols synced",
+		logger.FromContext(ctx).Debug("Protocols synced",
 			zap.Uint("address_id", addressID),
 			zap.Int("protocol_count", len(protocols)),
 		)
 	}
 
 	// 更新最后同步时间戳
-	if err := s.addressRepo.UpdateLastSynced(addressID); err != nil {
+	if err := addressRepo.UpdateLastSynced(addressID); err != nil {
 		return fmt.Errorf("failed to update last synced: %w", err)
 	}
 
-	logger.Debug("Address synced successfully",
+	logger.FromContext(ctx).Debug("Address synced successfully",
 		zap.Uint("address_id", addressID),
 		zap.Int("token_count", len(tokens)),
 		zap.Int("protocol_count", len(protocols)),
@@ -358,87 +606,117 @@ func (s *SyncService) SyncAddress(ctx context.Context, addressID uint) error {
 }
 
 // SyncWallet 同步钱包中的所有地址
-func (s *SyncService) SyncWallet(ctx context.Context, walletID uint) error {
-	addresses, err := s.addressRepo.GetByWalletID(walletID)
+func (s *SyncService) SyncWallet(ctx context.Context, walletID uint) (err error) {
+	ctx, span := tracer.Start(ctx, "SyncService.SyncWallet", trace.WithAttributes(attribute.Int64("wallet.id", int64(walletID))))
+	defer func() { tracing.End(span, err) }()
+
+	addresses, err := s.addressRepo.WithContext(ctx).GetByWalletID(walletID)
 	if err != nil {
 		return fmt.Errorf("failed to get wallet addresses: %w", err)
 	}
 
+	job := s.startSyncJob(SyncTriggerWallet, walletID, len(addresses))
+	ctx = logger.WithFields(ctx, zap.String("sync_job_id", job.progress.JobID))
+	span.SetAttributes(attribute.String("sync_job_id", job.progress.JobID))
 	for _, address := range addresses {
-		if err := s.SyncAddress(ctx, address.ID); err != nil {
-			logger.Error("Failed to sync address in wallet",
+		err := s.SyncAddress(ctx, address.ID)
+		if err != nil {
+			logger.FromContext(ctx).Error("Failed to sync address in wallet",
 				zap.Uint("wallet_id", walletID),
 				zap.Uint("address_id", address.ID),
 				zap.Error(err),
 			)
 		}
+		job.addressDone(&address, err)
 	}
+	job.finish()
 
 	return nil
 }
 
-// filterSpamTokens 根据常见模式过滤掉垃圾/欺诈代币和协议凭证代币
-func filterSpamTokens(tokens []provider.TokenInfo) []provider.TokenInfo {
-	spamKeywords := []string{
-		"t.me/", "t.ly/", "fli.so/", "wr.do/", "www.",
-		"claim", "swap", "redeem", "visit", "airdrop",
-		"reward", "voucher", "distribution",
-	}
-
-	// 协议凭证代币前缀（aToken, cToken 等）
-	// 这些代币会在协议的 asset_token_list 中展开为实际资产，所以要过滤掉
-	protocolTokenPrefixes := []string{
-		"aEth",         // Aave Ethereum (aEthUSDC, aEthWETH, etc.)
-		"aBas",         // Aave Base
-		"aArb",         // Aave Arbitrum
-		"aOpt",         // Aave Optimism
-		"aPol",         // Aave Polygon
-		"aAva",         // Aave Avalanche
-		"cToken",       // Compound
-		"variableDebt", // Aave variable debt tokens
-		"stableDebt",   // Aave stable debt tokens
-	}
-
-	filtered := make([]provider.TokenInfo, 0, len(tokens))
-	for _, token := range tokens {
-		isSpam := false
-		isProtocolToken := false
-
-		// 检查符号或名称是否包含垃圾关键词
-		symbolLower := strings.ToLower(token.Symbol)
-		nameLower := strings.ToLower(token.Name)
-
-		for _, keyword := range spamKeywords {
-			if strings.Contains(symbolLower, keyword) || strings.Contains(nameLower, keyword) {
-				isSpam = true
-				break
-			}
+// maxHealthRate 是有意义的健康因子上限，超过该值视为没有清算风险
+const maxHealthRate = 1e9
+
+// toProtocolPositions 将协议的 portfolio items 转换为持仓明细
+func toProtocolPositions(addressID uint, proto provider.ProtocolInfo) []models.ProtocolPosition {
+	positions := make([]models.ProtocolPosition, 0, len(proto.PortfolioItems))
+	for _, item := range proto.PortfolioItems {
+		position := models.ProtocolPosition{
+			AddressID:     addressID,
+			ProtocolID:    proto.ProtocolID,
+			ChainID:       proto.ChainID,
+			Name:          item.Name,
+			PositionType:  item.PositionType,
+			DetailTypes:   models.StringSlice(item.DetailTypes),
+			PoolID:        item.PoolID,
+			NetUSDValue:   item.NetUSDValue,
+			AssetUSDValue: item.AssetUSDValue,
+			DebtUSDValue:  item.DebtUSDValue,
+		}
+		if position.PositionType == "" {
+			position.PositionType = "unknown"
+		}
+		// 没有债务时 DeBank 返回一个极大值（约 1e59），视为不适用
+		if item.HealthRate > 0 && item.HealthRate < maxHealthRate {
+			healthRate := item.HealthRate
+			position.HealthRate = &healthRate
 		}
 
-		// 检查是否是协议凭证代币
-		for _, prefix := range protocolTokenPrefixes {
-			if strings.HasPrefix(token.Symbol, prefix) {
-				isProtocolToken = true
-				break
+		supply, borrow := item.SupplyTokenList, item.BorrowTokenList
+		if len(supply) == 0 && len(borrow) == 0 {
+			// 没有明细列表的持仓（如简单存款）按 asset_token_list 的符号拆分
+			for _, token := range item.AssetTokenList {
+				if token.IsDebt || token.Amount < 0 {
+					borrow = append(borrow, token)
+				} else {
+					supply = append(supply, token)
+				}
 			}
 		}
 
-		// 额外检查：价值为 $0 且具有可疑模式的代币
-		if token.Price == 0 && token.USDValue == 0 {
-			// 检查是否看起来像垃圾代币（包含特殊字符、表情符号等）
-			if strings.Contains(token.Symbol, "✅") || strings.Contains(token.Name, "✅") {
-				isSpam = true
-			}
-			if strings.Contains(token.Symbol, "$") && !strings.HasPrefix(token.Symbol, "$") {
-				isSpam = true
+		for _, group := range []struct {
+			role   string
+			tokens []provider.TokenDetail
+		}{
+			{models.PositionTokenSupply, supply},
+			{models.PositionTokenBorrow, borrow},
+			{models.PositionTokenReward, item.RewardTokenList},
+		} {
+			for _, token := range group.tokens {
+				chainID := token.ChainID
+				if chainID == "" {
+					chainID = proto.ChainID
+				}
+				position.Tokens = append(position.Tokens, models.ProtocolPositionToken{
+					Role:     group.role,
+					ChainID:  chainID,
+					TokenID:  token.TokenID,
+					Symbol:   token.Symbol,
+					Name:     token.Name,
+					Decimals: token.Decimals,
+					LogoURL:  token.LogoURL,
+					Amount:   token.Amount,
+					Price:    token.Price,
+					USDValue: token.USDValue,
+				})
 			}
 		}
 
-		// 只保留非垃圾且非协议凭证的代币
-		if !isSpam && !isProtocolToken {
-			filtered = append(filtered, token)
-		}
+		positions = append(positions, position)
 	}
+	return positions
+}
 
-	return filtered
+// summarizePositionType 返回协议级别的仓位类型：所有持仓类型相同时为该类型，否则为 mixed
+func summarizePositionType(positions []models.ProtocolPosition) string {
+	if len(positions) == 0 {
+		return "unknown"
+	}
+	positionType := positions[0].PositionType
+	for _, position := range positions[1:] {
+		if position.PositionType != positionType {
+			return "mixed"
+		}
+	}
+	return positionType
 }
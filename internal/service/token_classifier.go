@@ -0,0 +1,408 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/rotki-demo/internal/config"
+	"github.com/rotki-demo/internal/logger"
+	"github.com/rotki-demo/internal/models"
+	"github.com/rotki-demo/internal/repository"
+	"go.uber.org/zap"
+)
+
+// 规则来源，用于展示生效规则
+const (
+	TokenRuleSourceDatabase = "database"
+	TokenRuleSourceConfig   = "config"
+	TokenRuleSourceDefault  = "default"
+)
+
+// defaultRulePriority 内置规则的优先级，数据库和配置中的规则默认排在它们之前
+const defaultRulePriority = 1000
+
+// DefaultTokenRules 返回内置的垃圾/凭证代币规则
+func DefaultTokenRules() []models.TokenRule {
+	zero := 0.0
+	return []models.TokenRule{
+		{
+			Name:           "spam-keywords",
+			Classification: models.TokenClassificationSpam,
+			Priority:       defaultRulePriority,
+			TextPattern:    `(?i)(t\.me/|t\.ly/|fli\.so/|wr\.do/|www\.|claim|swap|redeem|visit|airdrop|reward|voucher|distribution)`,
+		},
+		{
+			// 协议凭证代币会在协议的 asset_token_list 中展开为实际资产
+			Name:           "receipt-token-prefixes",
+			Classification: models.TokenClassificationReceipt,
+			Priority:       defaultRulePriority,
+			SymbolPattern:  `^(aEth|aBas|aArb|aOpt|aPol|aAva|cToken|variableDebt|stableDebt)`,
+		},
+		{
+			Name:           "zero-value-check-mark",
+			Classification: models.TokenClassificationSpam,
+			Priority:       defaultRulePriority,
+			TextPattern:    `✅`,
+			MaxPrice:       &zero,
+			MaxUSDValue:    &zero,
+		},
+		{
+			Name:           "zero-value-embedded-dollar",
+			Classification: models.TokenClassificationSpam,
+			Priority:       defaultRulePriority,
+			SymbolPattern:  `^[^$].*\$`,
+			MaxPrice:       &zero,
+			MaxUSDValue:    &zero,
+		},
+	}
+}
+
+// EffectiveTokenRule 表示一条生效中的规则及其来源
+type EffectiveTokenRule struct {
+	models.TokenRule
+	Source string `json:"source"`
+}
+
+// compiledTokenRule 是预编译正则后的规则
+type compiledTokenRule struct {
+	rule     EffectiveTokenRule
+	symbol   *regexp.Regexp
+	name     *regexp.Regexp
+	text     *regexp.Regexp
+	tokenIDs map[string]bool
+}
+
+// TokenClassifier 根据规则对代币分类
+type TokenClassifier struct {
+	ruleRepo     *repository.TokenRuleRepository
+	overrideRepo *repository.TokenOverrideRepository
+	tokenRepo    *repository.TokenRepository
+
+	mu        sync.RWMutex
+	cfg       config.TokenRulesConfig
+	rules     []compiledTokenRule
+	overrides map[overrideKey]string // 用户忽略/白名单，值为 ignore 或 show
+}
+
+// overrideKey 标识一个钱包（0 表示全局）中的代币
+type overrideKey struct {
+	walletID uint
+	chainID  string
+	tokenID  string
+}
+
+// NewTokenClassifier 创建一个新的代币分类器并加载规则
+func NewTokenClassifier(
+	ruleRepo *repository.TokenRuleRepository,
+	overrideRepo *repository.TokenOverrideRepository,
+	tokenRepo *repository.TokenRepository,
+	cfg config.TokenRulesConfig,
+) (*TokenClassifier, error) {
+	classifier := &TokenClassifier{
+		ruleRepo:     ruleRepo,
+		overrideRepo: overrideRepo,
+		tokenRepo:    tokenRepo,
+		cfg:          cfg,
+	}
+	if err := classifier.Reload(); err != nil {
+		return nil, err
+	}
+	return classifier, nil
+}
+
+// SetConfig 替换配置中的规则并重新加载
+func (tc *TokenClassifier) SetConfig(cfg config.TokenRulesConfig) error {
+	tc.mu.Lock()
+	tc.cfg = cfg
+	tc.mu.Unlock()
+	return tc.Reload()
+}
+
+// Reload 从数据库和配置重新加载规则和用户覆盖设置
+func (tc *TokenClassifier) Reload() error {
+	if err := tc.ReloadOverrides(); err != nil {
+		return err
+	}
+
+	tc.mu.RLock()
+	cfg := tc.cfg
+	tc.mu.RUnlock()
+
+	var effective []EffectiveTokenRule
+
+	if tc.ruleRepo != nil {
+		dbRules, err := tc.ruleRepo.ListEnabled()
+		if err != nil {
+			return fmt.Errorf("failed to load token rules: %w", err)
+		}
+		for _, rule := range dbRules {
+			effective = append(effective, EffectiveTokenRule{TokenRule: rule, Source: TokenRuleSourceDatabase})
+		}
+	}
+
+	for _, rc := range cfg.Rules {
+		effective = append(effective, EffectiveTokenRule{TokenRule: tokenRuleFromConfig(rc), Source: TokenRuleSourceConfig})
+	}
+
+	if cfg.UseDefaults {
+		for _, rule := range DefaultTokenRules() {
+			rule.Enabled = true
+			effective = append(effective, EffectiveTokenRule{TokenRule: rule, Source: TokenRuleSourceDefault})
+		}
+	}
+
+	// 稳定排序：同优先级时数据库规则优先于配置规则，配置规则优先于内置规则
+	sort.SliceStable(effective, func(i, j int) bool {
+		return effective[i].Priority < effective[j].Priority
+	})
+
+	compiled := make([]compiledTokenRule, 0, len(effective))
+	for _, rule := range effective {
+		c, err := compileTokenRule(rule)
+		if err != nil {
+			// 单条规则无效不影响其他规则
+			logger.Warn("Skipping invalid token rule",
+				zap.String("name", rule.Name),
+				zap.String("source", rule.Source),
+				zap.Error(err),
+			)
+			continue
+		}
+		compiled = append(compiled, c)
+	}
+
+	tc.mu.Lock()
+	tc.rules = compiled
+	tc.mu.Unlock()
+
+	logger.Info("Token classification rules loaded", zap.Int("count", len(compiled)))
+	return nil
+}
+
+// ReloadOverrides 从数据库重新加载用户忽略/白名单
+func (tc *TokenClassifier) ReloadOverrides() error {
+	overrides := make(map[overrideKey]string)
+	if tc.overrideRepo != nil {
+		list, err := tc.overrideRepo.List(repository.TokenOverrideFilter{})
+		if err != nil {
+			return fmt.Errorf("failed to load token overrides: %w", err)
+		}
+		for _, o := range list {
+			overrides[overrideKey{walletID: o.WalletID, chainID: o.ChainID, tokenID: strings.ToLower(o.TokenID)}] = o.Action
+		}
+	}
+
+	tc.mu.Lock()
+	tc.overrides = overrides
+	tc.mu.Unlock()
+	return nil
+}
+
+// Rules 返回当前生效的规则（按匹配顺序）
+func (tc *TokenClassifier) Rules() []EffectiveTokenRule {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+
+	result := make([]EffectiveTokenRule, len(tc.rules))
+	for i, c := range tc.rules {
+		result[i] = c.rule
+	}
+	return result
+}
+
+// Classify 返回代币在指定钱包中的分类
+// 用户覆盖设置优先（钱包级优先于全局），其次按规则匹配，没有规则匹配时为 normal
+func (tc *TokenClassifier) Classify(token *models.Token, walletID uint) string {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+
+	if classification, ok := tc.overrideClassification(token, walletID); ok {
+		return classification
+	}
+
+	for i := range tc.rules {
+		if tc.rules[i].matches(token) {
+			return tc.rules[i].rule.Classification
+		}
+	}
+	return models.TokenClassificationNormal
+}
+
+// ClassifyProtocolToken 返回协议持仓代币在指定钱包中的分类
+// 协议代币只应用用户覆盖设置：分类规则针对钱包中的垃圾代币和协议凭证代币，套用到持仓代币会把持仓价值排除在总值之外
+func (tc *TokenClassifier) ClassifyProtocolToken(token *models.Token, walletID uint) string {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+
+	if classification, ok := tc.overrideClassification(token, walletID); ok {
+		return classification
+	}
+	return models.TokenClassificationNormal
+}
+
+// overrideClassification 返回用户覆盖设置对应的分类（钱包级优先于全局），没有设置时 ok 为 false；调用方需持有读锁
+func (tc *TokenClassifier) overrideClassification(token *models.Token, walletID uint) (string, bool) {
+	tokenID := strings.ToLower(token.TokenID)
+	for _, scope := range []uint{walletID, 0} {
+		switch tc.overrides[overrideKey{walletID: scope, chainID: token.ChainID, tokenID: tokenID}] {
+		case models.TokenOverrideIgnore:
+			return models.TokenClassificationHidden, true
+		case models.TokenOverrideShow:
+			return models.TokenClassificationNormal, true
+		}
+	}
+	return "", false
+}
+
+// ReclassifyStoredTokens 使用当前规则重新分类已存储的钱包代币，返回分类发生变化的代币数量
+func (tc *TokenClassifier) ReclassifyStoredTokens() (int, error) {
+	tokens, err := tc.tokenRepo.ListWalletTokens()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list tokens: %w", err)
+	}
+
+	changed, err := tc.reclassify(tokens)
+	if err != nil {
+		return changed, err
+	}
+
+	logger.Info("Tokens reclassified", zap.Int("total", len(tokens)), zap.Int("changed", changed))
+	return changed, nil
+}
+
+// ReclassifyToken 重新分类所有地址中指定 (chain_id, token_id) 的已存储代币，包括协议持仓代币
+func (tc *TokenClassifier) ReclassifyToken(chainID, tokenID string) (int, error) {
+	tokens, err := tc.tokenRepo.ListByToken(chainID, tokenID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list tokens: %w", err)
+	}
+	return tc.reclassify(tokens)
+}
+
+// reclassify 更新分类发生变化的代币，返回变化数量
+func (tc *TokenClassifier) reclassify(tokens []models.Token) (int, error) {
+	changed := 0
+	for i := range tokens {
+		var walletID uint
+		if tokens[i].Address != nil {
+			walletID = tokens[i].Address.WalletID
+		}
+		classification := tc.Classify(&tokens[i], walletID)
+		if tokens[i].ProtocolID != "" {
+			classification = tc.ClassifyProtocolToken(&tokens[i], walletID)
+		}
+		if classification == tokens[i].Classification {
+			continue
+		}
+		if err := tc.tokenRepo.UpdateClassification(tokens[i].ID, classification); err != nil {
+			return changed, fmt.Errorf("failed to update token %d: %w", tokens[i].ID, err)
+		}
+		changed++
+	}
+	return changed, nil
+}
+
+// ValidateTokenRule 检查规则的分类、正则和条件是否有效
+func ValidateTokenRule(rule *models.TokenRule) error {
+	if !models.IsValidTokenClassification(rule.Classification) {
+		return fmt.Errorf("invalid classification: %s", rule.Classification)
+	}
+	if rule.ChainID == "" && rule.SymbolPattern == "" && rule.NamePattern == "" && rule.TextPattern == "" &&
+		len(rule.TokenIDs) == 0 && rule.MaxPrice == nil && rule.MaxUSDValue == nil &&
+		rule.IsVerified == nil && rule.IsCore == nil {
+		return fmt.Errorf("rule must have at least one condition")
+	}
+	_, err := compileTokenRule(EffectiveTokenRule{TokenRule: *rule})
+	return err
+}
+
+// tokenRuleFromConfig 将配置中的规则转换为模型
+func tokenRuleFromConfig(rc config.TokenRuleConfig) models.TokenRule {
+	return models.TokenRule{
+		Name:           rc.Name,
+		Classification: rc.Classification,
+		Priority:       rc.Priority,
+		ChainID:        rc.ChainID,
+		SymbolPattern:  rc.SymbolPattern,
+		NamePattern:    rc.NamePattern,
+		TextPattern:    rc.TextPattern,
+		TokenIDs:       models.StringSlice(rc.TokenIDs),
+		MaxPrice:       rc.MaxPrice,
+		MaxUSDValue:    rc.MaxUSDValue,
+		IsVerified:     rc.IsVerified,
+		IsCore:         rc.IsCore,
+		Enabled:        true,
+	}
+}
+
+// compileTokenRule 预编译规则中的正则表达式
+func compileTokenRule(rule EffectiveTokenRule) (compiledTokenRule, error) {
+	if !models.IsValidTokenClassification(rule.Classification) {
+		return compiledTokenRule{}, fmt.Errorf("invalid classification: %s", rule.Classification)
+	}
+
+	c := compiledTokenRule{rule: rule}
+	var err error
+	if c.symbol, err = compilePattern(rule.SymbolPattern); err != nil {
+		return c, fmt.Errorf("invalid symbol_pattern: %w", err)
+	}
+	if c.name, err = compilePattern(rule.NamePattern); err != nil {
+		return c, fmt.Errorf("invalid name_pattern: %w", err)
+	}
+	if c.text, err = compilePattern(rule.TextPattern); err != nil {
+		return c, fmt.Errorf("invalid text_pattern: %w", err)
+	}
+
+	if len(rule.TokenIDs) > 0 {
+		c.tokenIDs = make(map[string]bool, len(rule.TokenIDs))
+		for _, id := range rule.TokenIDs {
+			c.tokenIDs[strings.ToLower(id)] = true
+		}
+	}
+
+	return c, nil
+}
+
+// compilePattern 编译非空正则
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	return regexp.Compile(pattern)
+}
+
+// matches 检查代币是否满足规则的所有条件
+func (c *compiledTokenRule) matches(token *models.Token) bool {
+	rule := &c.rule
+	if rule.ChainID != "" && rule.ChainID != token.ChainID {
+		return false
+	}
+	if c.tokenIDs != nil && !c.tokenIDs[strings.ToLower(token.TokenID)] {
+		return false
+	}
+	if c.symbol != nil && !c.symbol.MatchString(token.Symbol) {
+		return false
+	}
+	if c.name != nil && !c.name.MatchString(token.Name) {
+		return false
+	}
+	if c.text != nil && !c.text.MatchString(token.Symbol) && !c.text.MatchString(token.Name) {
+		return false
+	}
+	if rule.MaxPrice != nil && token.Price > *rule.MaxPrice {
+		return false
+	}
+	if rule.MaxUSDValue != nil && token.USDValue > *rule.MaxUSDValue {
+		return false
+	}
+	if rule.IsVerified != nil && token.IsVerified != *rule.IsVerified {
+		return false
+	}
+	if rule.IsCore != nil && token.IsCore != *rule.IsCore {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rotki-demo/internal/config"
+	"github.com/rotki-demo/internal/models"
+	"github.com/rotki-demo/internal/repository"
+)
+
+func TestTokenClassifierDefaultRules(t *testing.T) {
+	classifier, err := NewTokenClassifier(nil, nil, nil, config.TokenRulesConfig{UseDefaults: true})
+	if err != nil {
+		t.Fatalf("NewTokenClassifier: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		token models.Token
+		want  string
+	}{
+		{name: "plain token", token: models.Token{Symbol: "USDC", Name: "USD Coin", Price: 1, USDValue: 10}, want: models.TokenClassificationNormal},
+		{name: "link in name", token: models.Token{Symbol: "GIFT", Name: "Visit t.me/claimnow"}, want: models.TokenClassificationSpam},
+		{name: "keyword is case insensitive", token: models.Token{Symbol: "AIRDROP", Name: "x"}, want: models.TokenClassificationSpam},
+		{name: "aave receipt", token: models.Token{Symbol: "aEthUSDC", Name: "Aave Ethereum USDC", Price: 1, USDValue: 5}, want: models.TokenClassificationReceipt},
+		{name: "zero value check mark", token: models.Token{Symbol: "✅ USDT", Name: "Tether"}, want: models.TokenClassificationSpam},
+		{name: "check mark with value", token: models.Token{Symbol: "✅ USDT", Name: "Tether", Price: 1, USDValue: 1}, want: models.TokenClassificationNormal},
+		{name: "zero value embedded dollar", token: models.Token{Symbol: "USDC$", Name: "x"}, want: models.TokenClassificationSpam},
+		{name: "leading dollar is allowed", token: models.Token{Symbol: "$MEME", Name: "x"}, want: models.TokenClassificationNormal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifier.Classify(&tt.token, 0); got != tt.want {
+				t.Fatalf("Classify(%s/%s) = %s, want %s", tt.token.Symbol, tt.token.Name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenClassifierRuleConditions(t *testing.T) {
+	yes, no := true, false
+	price := 0.01
+
+	tests := []struct {
+		name  string
+		rule  config.TokenRuleConfig
+		token models.Token
+		match bool
+	}{
+		{name: "chain matches", rule: config.TokenRuleConfig{ChainID: "bsc"}, token: models.Token{ChainID: "bsc"}, match: true},
+		{name: "chain differs", rule: config.TokenRuleConfig{ChainID: "bsc"}, token: models.Token{ChainID: "eth"}},
+		{name: "token id is case insensitive", rule: config.TokenRuleConfig{TokenIDs: []string{"0xABC"}}, token: models.Token{TokenID: "0xabc"}, match: true},
+		{name: "token id not listed", rule: config.TokenRuleConfig{TokenIDs: []string{"0xabc"}}, token: models.Token{TokenID: "0xdef"}},
+		{name: "name pattern", rule: config.TokenRuleConfig{NamePattern: "^Fake"}, token: models.Token{Name: "Fake USDT"}, match: true},
+		{name: "text pattern matches symbol", rule: config.TokenRuleConfig{TextPattern: "SCAM"}, token: models.Token{Symbol: "SCAM", Name: "x"}, match: true},
+		{name: "max price", rule: config.TokenRuleConfig{MaxPrice: &price}, token: models.Token{Price: 0.02}},
+		{name: "unverified", rule: config.TokenRuleConfig{IsVerified: &no}, token: models.Token{IsVerified: false}, match: true},
+		{name: "all conditions must match", rule: config.TokenRuleConfig{ChainID: "eth", IsCore: &yes}, token: models.Token{ChainID: "eth", IsCore: false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.rule.Name = tt.name
+			tt.rule.Classification = models.TokenClassificationHidden
+			classifier, err := NewTokenClassifier(nil, nil, nil, config.TokenRulesConfig{Rules: []config.TokenRuleConfig{tt.rule}})
+			if err != nil {
+				t.Fatalf("NewTokenClassifier: %v", err)
+			}
+
+			want := models.TokenClassificationNormal
+			if tt.match {
+				want = models.TokenClassificationHidden
+			}
+			if got := classifier.Classify(&tt.token, 0); got != want {
+				t.Fatalf("Classify() = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestTokenClassifierPrecedence(t *testing.T) {
+	db := newTestDB(t)
+	wallet := &models.Wallet{Name: "main"}
+	if err := db.Create(wallet).Error; err != nil {
+		t.Fatalf("create wallet: %v", err)
+	}
+
+	// 同优先级时数据库规则优先于配置规则；优先级数字更小的规则先匹配
+	ruleRepo := repository.NewTokenRuleRepository(db)
+	for _, rule := range []models.TokenRule{
+		{Name: "db-hidden", Classification: models.TokenClassificationHidden, Priority: 10, SymbolPattern: "^X", Enabled: true},
+		{Name: "db-disabled", Classification: models.TokenClassificationReceipt, Priority: 1, SymbolPattern: "^X", Enabled: false},
+	} {
+		if err := ruleRepo.Create(&rule); err != nil {
+			t.Fatalf("create rule: %v", err)
+		}
+	}
+	cfg := config.TokenRulesConfig{
+		UseDefaults: true,
+		Rules: []config.TokenRuleConfig{
+			{Name: "config-spam", Classification: models.TokenClassificationSpam, Priority: 10, SymbolPattern: "^X"},
+			{Name: "config-first", Classification: models.TokenClassificationSpam, Priority: 5, SymbolPattern: "^XY"},
+		},
+	}
+
+	// 全局忽略 0xaaa，但在 main 钱包中显示；全局显示一个命中内置规则的代币
+	for _, override := range []models.TokenOverride{
+		{ChainID: "eth", TokenID: "0xaaa", WalletID: 0, Action: models.TokenOverrideIgnore},
+		{ChainID: "eth", TokenID: "0xaaa", WalletID: wallet.ID, Action: models.TokenOverrideShow},
+		{ChainID: "eth", TokenID: "0xbbb", WalletID: 0, Action: models.TokenOverrideShow},
+	} {
+		if err := db.Create(&override).Error; err != nil {
+			t.Fatalf("create override: %v", err)
+		}
+	}
+
+	classifier, err := NewTokenClassifier(ruleRepo, repository.NewTokenOverrideRepository(db), nil, cfg)
+	if err != nil {
+		t.Fatalf("NewTokenClassifier: %v", err)
+	}
+
+	rules := classifier.Rules()
+	var names []string
+	for _, rule := range rules {
+		names = append(names, rule.Name)
+	}
+	if got := strings.Join(names[:3], ","); got != "config-first,db-hidden,config-spam" {
+		t.Fatalf("rule order = %s, want config-first,db-hidden,config-spam before the defaults", got)
+	}
+	if rules[1].Source != TokenRuleSourceDatabase || rules[len(rules)-1].Source != TokenRuleSourceDefault {
+		t.Fatalf("rule sources = %s ... %s, want database rules and defaults last", rules[1].Source, rules[len(rules)-1].Source)
+	}
+
+	tests := []struct {
+		name     string
+		token    models.Token
+		walletID uint
+		protocol bool
+		want     string
+	}{
+		{name: "lower priority number wins", token: models.Token{ChainID: "eth", TokenID: "0x1", Symbol: "XYZ"}, want: models.TokenClassificationSpam},
+		{name: "database rule before config rule", token: models.Token{ChainID: "eth", TokenID: "0x1", Symbol: "XA"}, want: models.TokenClassificationHidden},
+		{name: "global ignore", token: models.Token{ChainID: "eth", TokenID: "0xAAA", Symbol: "USDC"}, walletID: 99, want: models.TokenClassificationHidden},
+		{name: "wallet show beats global ignore", token: models.Token{ChainID: "eth", TokenID: "0xaaa", Symbol: "USDC"}, walletID: wallet.ID, want: models.TokenClassificationNormal},
+		{name: "show skips rules", token: models.Token{ChainID: "eth", TokenID: "0xbbb", Symbol: "XA", Name: "claim"}, want: models.TokenClassificationNormal},
+		{name: "protocol token ignores rules", token: models.Token{ChainID: "eth", TokenID: "0x1", Symbol: "aEthUSDC"}, protocol: true, want: models.TokenClassificationNormal},
+		{name: "protocol token honours overrides", token: models.Token{ChainID: "eth", TokenID: "0xaaa"}, walletID: 99, protocol: true, want: models.TokenClassificationHidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := classifier.Classify(&tt.token, tt.walletID)
+			if tt.protocol {
+				got = classifier.ClassifyProtocolToken(&tt.token, tt.walletID)
+			}
+			if got != tt.want {
+				t.Fatalf("classification = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateTokenRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    models.TokenRule
+		wantErr string
+	}{
+		{name: "valid", rule: models.TokenRule{Classification: models.TokenClassificationSpam, SymbolPattern: "^X"}},
+		{name: "unknown classification", rule: models.TokenRule{Classification: "junk", SymbolPattern: "^X"}, wantErr: "invalid classification"},
+		{name: "no condition", rule: models.TokenRule{Classification: models.TokenClassificationSpam}, wantErr: "at least one condition"},
+		{name: "invalid regex", rule: models.TokenRule{Classification: models.TokenClassificationSpam, TextPattern: "(["}, wantErr: "invalid text_pattern"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTokenRule(&tt.rule)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ValidateTokenRule() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ValidateTokenRule() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
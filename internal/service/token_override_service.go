@@ -0,0 +1,119 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rotki-demo/internal/logger"
+	"github.com/rotki-demo/internal/models"
+	"github.com/rotki-demo/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// TokenOverrideService 管理用户的代币忽略/白名单并记录审计
+type TokenOverrideService struct {
+	overrideRepo *repository.TokenOverrideRepository
+	classifier   *TokenClassifier
+}
+
+// NewTokenOverrideService 创建一个新的代币覆盖服务
+func NewTokenOverrideService(overrideRepo *repository.TokenOverrideRepository, classifier *TokenClassifier) *TokenOverrideService {
+	return &TokenOverrideService{
+		overrideRepo: overrideRepo,
+		classifier:   classifier,
+	}
+}
+
+// Set 创建或更新 (chain_id, token_id, wallet_id) 的覆盖设置
+// 变更会立即应用到已存储的代币，因此总值无需等待下一次同步
+func (s *TokenOverrideService) Set(chainID, tokenID string, walletID uint, action, reason, actor string) (*models.TokenOverride, error) {
+	if action != models.TokenOverrideIgnore && action != models.TokenOverrideShow {
+		return nil, fmt.Errorf("invalid action: %s", action)
+	}
+	tokenID = strings.ToLower(tokenID)
+
+	audit := &models.TokenOverrideAudit{
+		ChainID:   chainID,
+		TokenID:   tokenID,
+		WalletID:  walletID,
+		NewAction: action,
+		Reason:    reason,
+		Actor:     actor,
+	}
+
+	override, err := s.overrideRepo.GetByKey(chainID, tokenID, walletID)
+	switch {
+	case err == nil:
+		audit.Operation = "update"
+		audit.OldAction = override.Action
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		audit.Operation = "create"
+		override = &models.TokenOverride{ChainID: chainID, TokenID: tokenID, WalletID: walletID}
+	default:
+		return nil, fmt.Errorf("failed to get token override: %w", err)
+	}
+
+	override.Action = action
+	override.Reason = reason
+	override.UpdatedBy = actor
+
+	if err := s.overrideRepo.SaveWithAudit(override, audit); err != nil {
+		return nil, fmt.Errorf("failed to save token override: %w", err)
+	}
+
+	s.apply(chainID, tokenID)
+	return override, nil
+}
+
+// Remove 删除覆盖设置，代币恢复按规则分类
+func (s *TokenOverrideService) Remove(id uint, reason, actor string) error {
+	override, err := s.overrideRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	audit := &models.TokenOverrideAudit{
+		OverrideID: override.ID,
+		ChainID:    override.ChainID,
+		TokenID:    override.TokenID,
+		WalletID:   override.WalletID,
+		Operation:  "delete",
+		OldAction:  override.Action,
+		Reason:     reason,
+		Actor:      actor,
+	}
+
+	if err := s.overrideRepo.DeleteWithAudit(override, audit); err != nil {
+		return fmt.Errorf("failed to delete token override: %w", err)
+	}
+
+	s.apply(override.ChainID, override.TokenID)
+	return nil
+}
+
+// apply 重新加载覆盖设置并重新分类受影响的代币
+// 失败只记录日志：覆盖设置已保存，下一次同步时仍会生效
+func (s *TokenOverrideService) apply(chainID, tokenID string) {
+	if err := s.classifier.ReloadOverrides(); err != nil {
+		logger.Error("Failed to reload token overrides", zap.Error(err))
+		return
+	}
+
+	changed, err := s.classifier.ReclassifyToken(chainID, tokenID)
+	if err != nil {
+		logger.Error("Failed to reclassify tokens",
+			zap.String("chain_id", chainID),
+			zap.String("token_id", tokenID),
+			zap.Error(err),
+		)
+		return
+	}
+
+	logger.Debug("Token override applied",
+		zap.String("chain_id", chainID),
+		zap.String("token_id", tokenID),
+		zap.Int("changed", changed),
+	)
+}
@@ -0,0 +1,288 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rotki-demo/internal/config"
+	"github.com/rotki-demo/internal/logger"
+	"github.com/rotki-demo/internal/models"
+	"github.com/rotki-demo/internal/provider"
+	"github.com/rotki-demo/internal/repository"
+	"github.com/rotki-demo/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// maxHistoryRounds 是每条链一次同步最多连续获取的次数，提供者返回 HasMore 时继续获取，剩余部分留到下次同步
+const maxHistoryRounds = 10
+
+// TransactionSyncResult 是一个地址的交易同步结果
+type TransactionSyncResult struct {
+	AddressID uint           `json:"address_id"`
+	Fetched   map[string]int `json:"fetched"`           // 每条链获取到的交易数（包括已保存过的交易）
+	Skipped   []string       `json:"skipped,omitempty"` // 提供者不支持的链
+}
+
+// TransactionService 按地址和链增量同步交易历史
+type TransactionService struct {
+	source      provider.TransactionHistoryProvider
+	txRepo      *repository.TransactionRepository
+	addressRepo *repository.AddressRepository
+	walletRepo  *repository.WalletRepository
+	tokenRepo   *repository.TokenRepository
+	cfg         config.TransactionsConfig
+	stopChan    chan struct{}
+	stopOnce    sync.Once
+	wg          sync.WaitGroup
+}
+
+// NewTransactionService 创建一个新的交易同步服务
+func NewTransactionService(
+	source provider.TransactionHistoryProvider,
+	txRepo *repository.TransactionRepository,
+	addressRepo *repository.AddressRepository,
+	walletRepo *repository.WalletRepository,
+	tokenRepo *repository.TokenRepository,
+	cfg config.TransactionsConfig,
+) *TransactionService {
+	return &TransactionService{
+		source:      source,
+		txRepo:      txRepo,
+		addressRepo: addressRepo,
+		walletRepo:  walletRepo,
+		tokenRepo:   tokenRepo,
+		cfg:         cfg,
+		stopChan:    make(chan struct{}),
+	}
+}
+
+// Start 启动后台交易同步进程
+func (s *TransactionService) Start() {
+	s.wg.Add(1)
+	go s.syncLoop()
+	logger.Info("Transaction sync started",
+		zap.String("source", s.source.GetName()),
+		zap.Duration("interval", s.cfg.GetInterval()))
+}
+
+// Stop 停止后台交易同步进程，进行中的请求被取消，已获取的部分已随游标保存
+// 可以重复调用，只有第一次关闭 stopChan
+func (s *TransactionService) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stopChan)
+	})
+	s.wg.Wait()
+}
+
+// syncLoop 定期同步所有地址
+func (s *TransactionService) syncLoop() {
+	defer s.wg.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-s.stopChan
+		cancel()
+	}()
+
+	ticker := time.NewTicker(s.cfg.GetInterval())
+	defer ticker.Stop()
+
+	for {
+		if err := s.SyncAll(ctx); err != nil && ctx.Err() == nil {
+			logger.Error("Transaction sync failed", zap.Error(err))
+		}
+		select {
+		case <-ticker.C:
+		case <-s.stopChan:
+			return
+		}
+	}
+}
+
+// SyncAll 依次同步所有地址的交易，单个地址失败不影响其他地址；ctx 取消后停止
+func (s *TransactionService) SyncAll(ctx context.Context) (err error) {
+	ctx, span := tracer.Start(ctx, "TransactionService.SyncAll")
+	defer func() { tracing.End(span, err) }()
+
+	addresses, err := s.addressRepo.WithContext(ctx).GetAllNeedingSync(0)
+	if err != nil {
+		return fmt.Errorf("failed to get addresses: %w", err)
+	}
+	span.SetAttributes(attribute.Int("address_count", len(addresses)))
+
+	for _, address := range addresses {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if _, err := s.SyncAddress(ctx, address.ID); err != nil {
+			logger.FromContext(ctx).Error("Failed to sync transactions",
+				zap.Uint("address_id", address.ID),
+				zap.String("address", address.Address),
+				zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// SyncAddress 同步地址在每条链上游标之后的交易。链为地址持有代币的链和已有游标的链，受钱包启用的链限制；
+// 切换数据源后旧游标失效，从 backfill_days 前重新获取
+func (s *TransactionService) SyncAddress(ctx context.Context, addressID uint) (result *TransactionSyncResult, err error) {
+	ctx, span := tracer.Start(ctx, "TransactionService.SyncAddress", trace.WithAttributes(attribute.Int64("address.id", int64(addressID))))
+	defer func() { tracing.End(span, err) }()
+
+	txRepo := s.txRepo.WithContext(ctx)
+	address, err := s.addressRepo.WithContext(ctx).GetByID(addressID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get address: %w", err)
+	}
+	wallet, err := s.walletRepo.WithContext(ctx).GetByID(address.WalletID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallet: %w", err)
+	}
+
+	cursors, err := txRepo.GetCursors(addressID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cursors: %w", err)
+	}
+	chainIDs, err := s.tokenRepo.WithContext(ctx).GetChainIDsByAddressID(addressID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chains: %w", err)
+	}
+
+	sourceName := s.source.GetName()
+	cursorByChain := make(map[string]string, len(cursors))
+	for _, cursor := range cursors {
+		if cursor.Source == sourceName {
+			cursorByChain[cursor.ChainID] = cursor.Cursor
+		}
+		chainIDs = append(chainIDs, cursor.ChainID)
+	}
+	chainIDs = filterEnabledChains(dedupeStrings(chainIDs), wallet.EnabledChains)
+
+	result = &TransactionSyncResult{AddressID: addressID, Fetched: make(map[string]int)}
+	// 单条链失败不影响其他链，已获取的部分已随游标保存
+	since := time.Now().Add(-s.cfg.GetBackfill())
+	var errs []error
+	for _, chainID := range chainIDs {
+		fetched, err := s.syncChain(ctx, txRepo, address, chainID, cursorByChain[chainID], since)
+		if errors.Is(err, provider.ErrChainNotSupported) {
+			result.Skipped = append(result.Skipped, chainID)
+			continue
+		}
+		result.Fetched[chainID] = fetched
+		if err != nil {
+			errs = append(errs, fmt.Errorf("chain %s: %w", chainID, err))
+		}
+	}
+	if len(errs) > 0 {
+		return result, errors.Join(errs...)
+	}
+
+	logger.FromContext(ctx).Debug("Transactions synced",
+		zap.Uint("address_id", addressID),
+		zap.Any("fetched", result.Fetched),
+		zap.Strings("skipped", result.Skipped))
+	return result, nil
+}
+
+// syncChain 从游标开始获取一条链的交易，每次获取后在同一事务中保存交易和新游标
+func (s *TransactionService) syncChain(ctx context.Context, txRepo *repository.TransactionRepository, address *models.Address, chainID, cursor string, since time.Time) (int, error) {
+	fetched := 0
+	for round := 0; round < maxHistoryRounds; round++ {
+		history, err := s.source.GetTransactionHistory(ctx, address.Address, chainID, cursor, since)
+		if err != nil {
+			return fetched, err
+		}
+
+		transactions := make([]models.Transaction, 0, len(history.Transactions))
+		for _, tx := range history.Transactions {
+			transactions = append(transactions, models.Transaction{
+				AddressID:    address.ID,
+				ChainID:      chainID,
+				TxHash:       tx.Hash,
+				BlockNumber:  tx.BlockNumber,
+				BlockTime:    tx.BlockTime,
+				Counterparty: tx.Counterparty,
+				Category:     tx.Category,
+				ProtocolID:   tx.ProtocolID,
+				TransfersIn:  toModelTransfers(tx.TransfersIn),
+				TransfersOut: toModelTransfers(tx.TransfersOut),
+				Fee:          tx.Fee,
+				FeeUSD:       tx.FeeUSD,
+				Failed:       tx.Failed,
+				Source:       s.source.GetName(),
+			})
+		}
+
+		err = txRepo.UpsertBatchWithCursor(transactions, &models.TransactionCursor{
+			AddressID: address.ID,
+			ChainID:   chainID,
+			Source:    s.source.GetName(),
+			Cursor:    history.NextCursor,
+			SyncedAt:  time.Now(),
+		})
+		if err != nil {
+			return fetched, fmt.Errorf("failed to save transactions: %w", err)
+		}
+		fetched += len(transactions)
+		cursor = history.NextCursor
+
+		if !history.HasMore {
+			break
+		}
+	}
+	return fetched, nil
+}
+
+// toModelTransfers 转换提供者返回的代币转账
+func toModelTransfers(transfers []provider.TokenTransfer) models.TokenTransferList {
+	result := make(models.TokenTransferList, 0, len(transfers))
+	for _, transfer := range transfers {
+		result = append(result, models.TokenTransfer{
+			TokenID:      transfer.TokenID,
+			Symbol:       transfer.Symbol,
+			Amount:       transfer.Amount,
+			Counterparty: transfer.Counterparty,
+		})
+	}
+	return result
+}
+
+// dedupeStrings 去重并排序
+func dedupeStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// filterEnabledChains 只保留钱包启用的链，enabled 为空表示所有链
+func filterEnabledChains(chainIDs, enabled []string) []string {
+	if len(enabled) == 0 {
+		return chainIDs
+	}
+	allowed := make(map[string]bool, len(enabled))
+	for _, chainID := range enabled {
+		allowed[chainID] = true
+	}
+	result := make([]string, 0, len(chainIDs))
+	for _, chainID := range chainIDs {
+		if allowed[chainID] {
+			result = append(result, chainID)
+		}
+	}
+	return result
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rotki-demo/internal/logger"
+	"github.com/rotki-demo/internal/models"
+	"github.com/rotki-demo/internal/repository"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// sessionTokenPrefix 是登录会话 token 的固定前缀，用于和 API key 区分
+const sessionTokenPrefix = "rs_"
+
+// minPasswordLength 是密码的最小长度
+const minPasswordLength = 8
+
+var (
+	// ErrInvalidCredentials 表示用户名或密码错误，或用户已禁用
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	// ErrInvalidSession 表示会话不存在、已过期或用户已禁用
+	ErrInvalidSession = errors.New("invalid session")
+)
+
+// IsSessionToken 判断 token 是否为登录会话 token
+func IsSessionToken(token string) bool {
+	return strings.HasPrefix(token, sessionTokenPrefix)
+}
+
+// UserService 处理用户账号、密码和登录会话
+type UserService struct {
+	userRepo   *repository.UserRepository
+	sessionTTL time.Duration
+	dummyHash  []byte // 用户不存在时也计算一次哈希，避免通过响应时间判断用户是否存在
+}
+
+// NewUserService 创建一个新的用户服务
+func NewUserService(userRepo *repository.UserRepository, sessionTTL time.Duration) *UserService {
+	dummyHash, _ := bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+	return &UserService{
+		userRepo:   userRepo,
+		sessionTTL: sessionTTL,
+		dummyHash:  dummyHash,
+	}
+}
+
+// ValidateUser 校验用户名和角色
+func ValidateUser(username, role string) error {
+	if strings.TrimSpace(username) == "" {
+		return fmt.Errorf("username is required")
+	}
+	if !models.IsValidRole(role) {
+		return fmt.Errorf("invalid role %q (expected %s, %s or %s)", role, models.RoleReadOnly, models.RoleOperator, models.RoleAdmin)
+	}
+	return nil
+}
+
+// ValidatePassword 校验密码强度
+func ValidatePassword(password string) error {
+	if len(password) < minPasswordLength {
+		return fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	}
+	return nil
+}
+
+// CreateUser 创建用户并保存密码的 bcrypt 哈希
+func (s *UserService) CreateUser(username, password, role string) (*models.User, error) {
+	username = strings.TrimSpace(username)
+	if err := ValidateUser(username, role); err != nil {
+		return nil, err
+	}
+	hash, err := hashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{
+		Username:     username,
+		PasswordHash: hash,
+		Role:         role,
+	}
+	if err := s.userRepo.Create(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// SetPassword 修改用户密码并注销其所有会话
+func (s *UserService) SetPassword(user *models.User, password string) error {
+	hash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+	user.PasswordHash = hash
+	if err := s.userRepo.Update(user); err != nil {
+		return err
+	}
+	return s.userRepo.DeleteSessionsByUserID(user.ID)
+}
+
+// CheckPassword 校验用户密码
+func (s *UserService) CheckPassword(user *models.User, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) == nil
+}
+
+// Login 校验用户名和密码并创建会话，token 明文只在此时返回
+func (s *UserService) Login(username, password string) (*models.User, *models.UserSession, string, error) {
+	user, err := s.userRepo.GetByUsername(strings.TrimSpace(username))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			_ = bcrypt.CompareHashAndPassword(s.dummyHash, []byte(password))
+			return nil, nil, "", ErrInvalidCredentials
+		}
+		return nil, nil, "", err
+	}
+	if user.Disabled || !s.CheckPassword(user, password) {
+		return nil, nil, "", ErrInvalidCredentials
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, nil, "", fmt.Errorf("failed to generate session token: %w", err)
+	}
+	token := sessionTokenPrefix + hex.EncodeToString(b)
+
+	session := &models.UserSession{
+		UserID:    user.ID,
+		TokenHash: HashAPIKey(token),
+		ExpiresAt: time.Now().Add(s.sessionTTL),
+	}
+	if err := s.userRepo.CreateSession(session); err != nil {
+		return nil, nil, "", err
+	}
+	// 登录时顺便清理过期会话，避免会话表无限增长
+	if _, err := s.PurgeExpiredSessions(); err != nil {
+		logger.Warn("Failed to purge expired sessions", zap.Error(err))
+	}
+	return user, session, token, nil
+}
+
+// ValidateSession 校验会话 token 并返回对应的用户
+func (s *UserService) ValidateSession(token string) (*models.User, error) {
+	session, err := s.userRepo.GetSessionByHash(HashAPIKey(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidSession
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if now.After(session.ExpiresAt) || session.User == nil || session.User.Disabled {
+		return nil, ErrInvalidSession
+	}
+
+	if session.LastUsedAt == nil || now.Sub(*session.LastUsedAt) >= lastUsedInterval {
+		if err := s.userRepo.UpdateSessionLastUsed(session.ID, now); err != nil {
+			logger.Warn("Failed to update session last used time", zap.Uint("session_id", session.ID), zap.Error(err))
+		}
+	}
+	return session.User, nil
+}
+
+// Logout 删除会话
+func (s *UserService) Logout(token string) error {
+	return s.userRepo.DeleteSessionByHash(HashAPIKey(token))
+}
+
+// PurgeExpiredSessions 删除已过期的会话
+func (s *UserService) PurgeExpiredSessions() (int64, error) {
+	return s.userRepo.DeleteExpiredSessions(time.Now())
+}
+
+// hashPassword 校验密码长度并返回 bcrypt 哈希
+func hashPassword(password string) (string, error) {
+	if err := ValidatePassword(password); err != nil {
+		return "", err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hash), nil
+}
@@ -0,0 +1,386 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rotki-demo/internal/config"
+	"github.com/rotki-demo/internal/events"
+	"github.com/rotki-demo/internal/logger"
+	"github.com/rotki-demo/internal/models"
+	"github.com/rotki-demo/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// webhook 请求头
+const (
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookIDHeader        = "X-Webhook-ID"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// maxRetryDelay 是两次重试之间的最长等待时间
+const maxRetryDelay = time.Hour
+
+// webhookBatchSize 是每次处理的最大投递数量
+const webhookBatchSize = 50
+
+// WebhookService 将事件写入投递队列，并在后台异步投递、重试
+// 投递记录保存在数据库中，重启后未完成的投递会继续进行
+type WebhookService struct {
+	webhookRepo *repository.WebhookRepository
+	cfg         config.WebhooksConfig
+	client      *http.Client
+	wakeChan    chan struct{}
+	stopChan    chan struct{}
+	wg          sync.WaitGroup
+}
+
+// NewWebhookService 创建一个新的 webhook 服务
+func NewWebhookService(webhookRepo *repository.WebhookRepository, cfg config.WebhooksConfig) *WebhookService {
+	return &WebhookService{
+		webhookRepo: webhookRepo,
+		cfg:         cfg,
+		client:      &http.Client{Timeout: cfg.GetTimeout()},
+		wakeChan:    make(chan struct{}, 1),
+		stopChan:    make(chan struct{}),
+	}
+}
+
+// Start 启动后台投递进程
+func (s *WebhookService) Start() {
+	s.wg.Add(1)
+	go s.deliveryLoop()
+	logger.Info("Webhook service started", zap.Duration("poll_interval", s.cfg.GetPollInterval()))
+}
+
+// Stop 停止后台投递进程
+func (s *WebhookService) Stop() {
+	close(s.stopChan)
+	s.wg.Wait()
+	logger.Info("Webhook service stopped")
+}
+
+// Publish 将事件排队投递到所有匹配的端点，实现 events.Publisher 接口
+// 只写入队列，不会阻塞调用方等待投递
+func (s *WebhookService) Publish(event events.Event) {
+	if !events.IsValidType(event.Type) {
+		// 只推送到实时事件流的事件（如同步进度）不投递
+		return
+	}
+
+	endpoints, err := s.webhookRepo.ListEnabledEndpoints()
+	if err != nil {
+		logger.Error("Failed to list webhook endpoints", zap.String("event_type", event.Type), zap.Error(err))
+		return
+	}
+
+	matched := make([]models.WebhookEndpoint, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		if s.matches(&endpoint, event) {
+			matched = append(matched, endpoint)
+		}
+	}
+	if err := s.enqueue(event, matched); err != nil {
+		logger.Error("Failed to enqueue webhook event", zap.String("event_type", event.Type), zap.Error(err))
+	}
+}
+
+// SendTest 向指定端点排队一条测试事件
+func (s *WebhookService) SendTest(endpoint *models.WebhookEndpoint) (events.Event, error) {
+	event := events.New(events.WebhookTest, endpoint.WalletID, 0, map[string]interface{}{
+		"endpoint_id": endpoint.ID,
+		"message":     "This is a test event",
+	})
+	return event, s.enqueue(event, []models.WebhookEndpoint{*endpoint})
+}
+
+// Replay 将单条投递记录重新放回队列（包括已投递和已进入死信列表的记录）
+func (s *WebhookService) Replay(delivery *models.WebhookDelivery) error {
+	delivery.Status = models.WebhookDeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+	delivery.LastError = ""
+	if err := s.webhookRepo.UpdateDelivery(delivery); err != nil {
+		return err
+	}
+	s.wake()
+	return nil
+}
+
+// ReplayDeadLetters 将死信列表中的记录重新放回队列，endpointID 为 0 表示所有端点
+func (s *WebhookService) ReplayDeadLetters(endpointID uint) (int64, error) {
+	count, err := s.webhookRepo.RequeueDeliveries(repository.WebhookDeliveryFilter{
+		EndpointID: endpointID,
+		Status:     models.WebhookDeliveryDead,
+	}, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	if count > 0 {
+		s.wake()
+	}
+	return count, nil
+}
+
+// matches 检查端点是否应该接收事件
+func (s *WebhookService) matches(endpoint *models.WebhookEndpoint, event events.Event) bool {
+	if !endpoint.Subscribes(event.Type) {
+		return false
+	}
+	if endpoint.WalletID != 0 && endpoint.WalletID != event.WalletID {
+		return false
+	}
+	if event.Type == events.WalletValueChanged {
+		threshold := endpoint.ValueChangePercent
+		if threshold <= 0 {
+			threshold = s.cfg.ValueChangePercent
+		}
+		if data, ok := event.Data.(WalletValueChange); ok && absFloat(data.ChangePercent) < threshold {
+			return false
+		}
+	}
+	return true
+}
+
+// enqueue 为每个端点写入一条投递记录
+func (s *WebhookService) enqueue(event events.Event, endpoints []models.WebhookEndpoint) error {
+	if len(endpoints) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	now := time.Now()
+	deliveries := make([]models.WebhookDelivery, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		deliveries = append(deliveries, models.WebhookDelivery{
+			EndpointID:    endpoint.ID,
+			EventID:       event.ID,
+			EventType:     event.Type,
+			Payload:       models.RawJSON(payload),
+			Status:        models.WebhookDeliveryPending,
+			NextAttemptAt: now,
+		})
+	}
+	if err := s.webhookRepo.CreateDeliveries(deliveries); err != nil {
+		return fmt.Errorf("failed to create deliveries: %w", err)
+	}
+
+	s.wake()
+	return nil
+}
+
+// wake 通知投递进程立即检查队列
+func (s *WebhookService) wake() {
+	select {
+	case s.wakeChan <- struct{}{}:
+	default:
+	}
+}
+
+// deliveryLoop 周期性地投递到期的记录
+func (s *WebhookService) deliveryLoop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.cfg.GetPollInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.processDue()
+		case <-s.wakeChan:
+			s.processDue()
+		case <-s.stopChan:
+			return
+		}
+	}
+}
+
+// processDue 投递所有到期的记录
+func (s *WebhookService) processDue() {
+	for {
+		deliveries, err := s.webhookRepo.ListDueDeliveries(time.Now(), webhookBatchSize)
+		if err != nil {
+			logger.Error("Failed to list due webhook deliveries", zap.Error(err))
+			return
+		}
+
+		for i := range deliveries {
+			select {
+			case <-s.stopChan:
+				return
+			default:
+			}
+			s.attempt(&deliveries[i])
+		}
+
+		if len(deliveries) < webhookBatchSize {
+			return
+		}
+	}
+}
+
+// attempt 尝试投递一次并记录结果，失败时按指数退避安排重试，超过最大次数后移入死信列表
+func (s *WebhookService) attempt(delivery *models.WebhookDelivery) {
+	endpoint, err := s.webhookRepo.GetEndpointByID(delivery.EndpointID)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		// 端点已删除，投递永远无法完成
+		s.abandon(delivery, "endpoint not found")
+		return
+	case err != nil:
+		// 数据库暂时不可用等错误，不计入投递次数，稍后重试
+		delivery.LastError = truncate("failed to load endpoint: "+err.Error(), 1000)
+		delivery.NextAttemptAt = time.Now().Add(s.retryDelay(delivery.Attempts + 1))
+		logger.Warn("Failed to load webhook endpoint, will retry",
+			zap.Uint("delivery_id", delivery.ID),
+			zap.Uint("endpoint_id", delivery.EndpointID),
+			zap.Error(err),
+		)
+		s.saveDelivery(delivery)
+		return
+	case !endpoint.Enabled:
+		// 停用端点的投递进入死信列表，重新启用后可以重放
+		s.abandon(delivery, "endpoint is disabled")
+		return
+	}
+
+	delivery.Attempts++
+	status, err := s.send(endpoint, delivery)
+	delivery.ResponseStatus = status
+
+	now := time.Now()
+	if err == nil {
+		delivery.Status = models.WebhookDeliveryDelivered
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+	} else {
+		delivery.LastError = truncate(err.Error(), 1000)
+		if delivery.Attempts >= s.cfg.MaxAttempts {
+			delivery.Status = models.WebhookDeliveryDead
+			logger.Warn("Webhook delivery moved to dead-letter list",
+				zap.Uint("delivery_id", delivery.ID),
+				zap.Uint("endpoint_id", delivery.EndpointID),
+				zap.Int("attempts", delivery.Attempts),
+				zap.Error(err),
+			)
+		} else {
+			delivery.NextAttemptAt = now.Add(s.retryDelay(delivery.Attempts))
+			logger.Debug("Webhook delivery failed, will retry",
+				zap.Uint("delivery_id", delivery.ID),
+				zap.Int("attempts", delivery.Attempts),
+				zap.Time("next_attempt_at", delivery.NextAttemptAt),
+				zap.Error(err),
+			)
+		}
+	}
+
+	s.saveDelivery(delivery)
+}
+
+// abandon 不发送请求，直接将投递移入死信列表
+func (s *WebhookService) abandon(delivery *models.WebhookDelivery, reason string) {
+	delivery.Status = models.WebhookDeliveryDead
+	delivery.LastError = reason
+	logger.Warn("Webhook delivery moved to dead-letter list",
+		zap.Uint("delivery_id", delivery.ID),
+		zap.Uint("endpoint_id", delivery.EndpointID),
+		zap.String("reason", reason),
+	)
+	s.saveDelivery(delivery)
+}
+
+// saveDelivery 保存投递结果
+func (s *WebhookService) saveDelivery(delivery *models.WebhookDelivery) {
+	if err := s.webhookRepo.UpdateDelivery(delivery); err != nil {
+		logger.Error("Failed to update webhook delivery", zap.Uint("delivery_id", delivery.ID), zap.Error(err))
+	}
+}
+
+// retryDelay 返回第 attempts 次失败后的等待时间
+func (s *WebhookService) retryDelay(attempts int) time.Duration {
+	delay := s.cfg.GetRetryBaseDelay()
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay
+}
+
+// send 发送签名后的请求，非 2xx 响应视为失败
+func (s *WebhookService) send(endpoint *models.WebhookEndpoint, delivery *models.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.GetTimeout())
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, delivery.EventType)
+	req.Header.Set(WebhookIDHeader, delivery.EventID)
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, "sha256="+SignWebhookPayload(endpoint.Secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return resp.StatusCode, fmt.Errorf("endpoint returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+	return resp.StatusCode, nil
+}
+
+// SignWebhookPayload 计算 webhook 签名：HMAC-SHA256(secret, timestamp + "." + body) 的十六进制编码
+func SignWebhookPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// absFloat 返回绝对值
+func absFloat(v float64) float64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// GenerateWebhookSecret 生成随机的签名密钥
+func GenerateWebhookSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rotki-demo/internal/config"
+	"github.com/rotki-demo/internal/models"
+	"github.com/rotki-demo/internal/repository"
+)
+
+func TestSignWebhookPayload(t *testing.T) {
+	// 与接收方按文档计算的结果一致：hex(HMAC-SHA256(secret, timestamp + "." + body))
+	got := SignWebhookPayload("secret", "1700000000", []byte(`{"id":"evt"}`))
+	want := "7c757099788fba43a4fe1e0c3b767303fdd971ab6183bc900d3de418c62b08b0"
+	if got != want {
+		t.Fatalf("SignWebhookPayload() = %s, want %s", got, want)
+	}
+	if SignWebhookPayload("other", "1700000000", []byte(`{"id":"evt"}`)) == want {
+		t.Fatal("signature does not depend on the secret")
+	}
+}
+
+func TestWebhookRetryDelay(t *testing.T) {
+	tests := []struct {
+		baseDelay int
+		attempts  int
+		want      time.Duration
+	}{
+		{baseDelay: 30, attempts: 1, want: 30 * time.Second},
+		{baseDelay: 30, attempts: 2, want: time.Minute},
+		{baseDelay: 30, attempts: 4, want: 4 * time.Minute},
+		{baseDelay: 30, attempts: 8, want: maxRetryDelay},
+		{baseDelay: 30, attempts: 1000, want: maxRetryDelay},
+		{baseDelay: 7200, attempts: 1, want: maxRetryDelay},
+		{baseDelay: 0, attempts: 5, want: 0},
+	}
+
+	for _, tt := range tests {
+		s := &WebhookService{cfg: config.WebhooksConfig{RetryBaseDelay: tt.baseDelay}}
+		if got := s.retryDelay(tt.attempts); got != tt.want {
+			t.Errorf("retryDelay(%d) with base %ds = %v, want %v", tt.attempts, tt.baseDelay, got, tt.want)
+		}
+	}
+}
+
+func TestWebhookAttempt(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int  // 接收方返回的状态码
+		disabled     bool // 端点已停用
+		deleted      bool // 端点已删除
+		attempts     int  // 之前的投递次数
+		wantStatus   string
+		wantAttempts int
+		wantRequests int32
+		wantError    string
+	}{
+		{name: "delivered", status: http.StatusNoContent, wantStatus: models.WebhookDeliveryDelivered, wantAttempts: 1, wantRequests: 1},
+		{name: "retry after failure", status: http.StatusInternalServerError, wantStatus: models.WebhookDeliveryPending, wantAttempts: 1, wantRequests: 1, wantError: "status 500"},
+		{name: "dead after max attempts", status: http.StatusInternalServerError, attempts: 2, wantStatus: models.WebhookDeliveryDead, wantAttempts: 3, wantRequests: 1, wantError: "status 500"},
+		{name: "disabled endpoint", status: http.StatusOK, disabled: true, wantStatus: models.WebhookDeliveryDead, wantError: "endpoint is disabled"},
+		{name: "deleted endpoint", status: http.StatusOK, deleted: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				body, _ := io.ReadAll(r.Body)
+				want := "sha256=" + SignWebhookPayload("secret", r.Header.Get(WebhookTimestampHeader), body)
+				if r.Header.Get(WebhookSignatureHeader) != want || r.Header.Get(WebhookIDHeader) != "evt-1" {
+					t.Errorf("request headers = %v, want a valid signature for event evt-1", r.Header)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			db := newTestDB(t)
+			repo := repository.NewWebhookRepository(db)
+			endpoint := &models.WebhookEndpoint{Name: "test", URL: server.URL, Secret: "secret", Enabled: !tt.disabled}
+			if err := repo.CreateEndpoint(endpoint); err != nil {
+				t.Fatalf("CreateEndpoint: %v", err)
+			}
+			if err := repo.CreateDeliveries([]models.WebhookDelivery{{
+				EndpointID:    endpoint.ID,
+				EventID:       "evt-1",
+				EventType:     "address.added",
+				Payload:       `{"id":"evt-1"}`,
+				Status:        models.WebhookDeliveryPending,
+				Attempts:      tt.attempts,
+				NextAttemptAt: time.Now(),
+			}}); err != nil {
+				t.Fatalf("CreateDeliveries: %v", err)
+			}
+			if tt.deleted {
+				// 模拟端点在投递进程读取记录后被删除：投递记录随端点级联删除
+				deliveries, err := repo.ListDueDeliveries(time.Now(), 10)
+				if err != nil || len(deliveries) != 1 {
+					t.Fatalf("ListDueDeliveries = %d deliveries, %v", len(deliveries), err)
+				}
+				if err := repo.DeleteEndpoint(endpoint.ID); err != nil {
+					t.Fatalf("DeleteEndpoint: %v", err)
+				}
+				s := NewWebhookService(repo, config.WebhooksConfig{MaxAttempts: 3, RetryBaseDelay: 30, Timeout: 5})
+				s.attempt(&deliveries[0])
+				if deliveries[0].Status != models.WebhookDeliveryDead || deliveries[0].LastError != "endpoint not found" {
+					t.Fatalf("delivery = %s (%q), want dead because the endpoint is gone", deliveries[0].Status, deliveries[0].LastError)
+				}
+				// 已删除的投递记录不会被重新插入
+				remaining, err := repo.ListDeliveries(repository.WebhookDeliveryFilter{}, 10)
+				if err != nil || len(remaining) != 0 || requests.Load() != 0 {
+					t.Fatalf("after attempt: %d deliveries, %d requests, err %v; want none", len(remaining), requests.Load(), err)
+				}
+				return
+			}
+
+			s := NewWebhookService(repo, config.WebhooksConfig{MaxAttempts: 3, RetryBaseDelay: 30, Timeout: 5})
+			s.processDue()
+
+			deliveries, err := repo.ListDeliveries(repository.WebhookDeliveryFilter{}, 10)
+			if err != nil {
+				t.Fatalf("ListDeliveries: %v", err)
+			}
+			if len(deliveries) != 1 {
+				t.Fatalf("got %d deliveries, want 1", len(deliveries))
+			}
+			got := deliveries[0]
+			if got.Status != tt.wantStatus || got.Attempts != tt.wantAttempts || requests.Load() != tt.wantRequests {
+				t.Fatalf("delivery = %s after %d attempts with %d requests, want %s after %d with %d",
+					got.Status, got.Attempts, requests.Load(), tt.wantStatus, tt.wantAttempts, tt.wantRequests)
+			}
+			if !strings.Contains(got.LastError, tt.wantError) || (tt.wantError == "" && got.LastError != "") {
+				t.Fatalf("last error = %q, want %q", got.LastError, tt.wantError)
+			}
+			if got.Status == models.WebhookDeliveryPending && !got.NextAttemptAt.After(time.Now().Add(20*time.Second)) {
+				t.Fatalf("next attempt at %v, want about 30s from now", got.NextAttemptAt)
+			}
+
+			// 重放时清零的字段同样写回数据库
+			if got.Status == models.WebhookDeliveryDead {
+				if err := s.Replay(&got); err != nil {
+					t.Fatalf("Replay: %v", err)
+				}
+				replayed, err := repo.GetDeliveryByID(got.ID)
+				if err != nil {
+					t.Fatalf("GetDeliveryByID: %v", err)
+				}
+				if replayed.Status != models.WebhookDeliveryPending || replayed.Attempts != 0 || replayed.LastError != "" {
+					t.Fatalf("replayed delivery = %s after %d attempts (%q), want pending with no attempts", replayed.Status, replayed.Attempts, replayed.LastError)
+				}
+			}
+		})
+	}
+}
+
+func TestWebhookAttemptEndpointLookupFails(t *testing.T) {
+	db := newTestDB(t)
+	repo := repository.NewWebhookRepository(db)
+	s := NewWebhookService(repo, config.WebhooksConfig{MaxAttempts: 3, RetryBaseDelay: 30, Timeout: 5})
+
+	// 数据库不可用时不计入投递次数，推迟下一次尝试而不是每次轮询都重试
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("DB: %v", err)
+	}
+	sqlDB.Close()
+
+	delivery := &models.WebhookDelivery{ID: 1, EndpointID: 1, Status: models.WebhookDeliveryPending, Attempts: 1, NextAttemptAt: time.Now()}
+	s.attempt(delivery)
+
+	if delivery.Status != models.WebhookDeliveryPending || delivery.Attempts != 1 {
+		t.Fatalf("delivery = %s after %d attempts, want still pending after 1", delivery.Status, delivery.Attempts)
+	}
+	if !strings.HasPrefix(delivery.LastError, "failed to load endpoint") {
+		t.Fatalf("last error = %q, want the lookup failure", delivery.LastError)
+	}
+	if !delivery.NextAttemptAt.After(time.Now().Add(50 * time.Second)) {
+		t.Fatalf("next attempt at %v, want the second retry delay (60s)", delivery.NextAttemptAt)
+	}
+}
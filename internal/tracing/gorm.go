@@ -0,0 +1,98 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// gormTracer 是 GORM 查询 span 的埋点范围
+var gormTracer = Tracer("github.com/rotki-demo/internal/tracing/gorm")
+
+// gormSpanKey 和 gormParentKey 是保存在语句实例上的当前 span 和调用方上下文
+const (
+	gormSpanKey   = "tracing:span"
+	gormParentKey = "tracing:parent"
+)
+
+// gormPlugin 为 GORM 的每条 SQL 创建 span
+// 只有上下文中已经有 span（通过 WithContext 传入）时才记录，避免后台查询产生大量孤立的 trace
+type gormPlugin struct{}
+
+// NewGormPlugin 创建 GORM 链路追踪插件
+func NewGormPlugin() gorm.Plugin {
+	return &gormPlugin{}
+}
+
+// Name 实现 gorm.Plugin
+func (p *gormPlugin) Name() string {
+	return "tracing"
+}
+
+// Initialize 实现 gorm.Plugin，在各类操作前后注册回调
+func (p *gormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	for _, err := range []error{
+		cb.Create().Before("gorm:create").Register("tracing:before_create", p.before("gorm.create")),
+		cb.Create().After("gorm:create").Register("tracing:after_create", p.after),
+		cb.Query().Before("gorm:query").Register("tracing:before_query", p.before("gorm.query")),
+		cb.Query().After("gorm:query").Register("tracing:after_query", p.after),
+		cb.Update().Before("gorm:update").Register("tracing:before_update", p.before("gorm.update")),
+		cb.Update().After("gorm:update").Register("tracing:after_update", p.after),
+		cb.Delete().Before("gorm:delete").Register("tracing:before_delete", p.before("gorm.delete")),
+		cb.Delete().After("gorm:delete").Register("tracing:after_delete", p.after),
+		cb.Row().Before("gorm:row").Register("tracing:before_row", p.before("gorm.row")),
+		cb.Row().After("gorm:row").Register("tracing:after_row", p.after),
+		cb.Raw().Before("gorm:raw").Register("tracing:before_raw", p.before("gorm.raw")),
+		cb.Raw().After("gorm:raw").Register("tracing:after_raw", p.after),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// before 在执行 SQL 前开始 span
+func (p *gormPlugin) before(spanName string) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		parent := tx.Statement.Context
+		if parent == nil || !trace.SpanContextFromContext(parent).IsValid() {
+			return
+		}
+
+		ctx, span := gormTracer.Start(parent, spanName, trace.WithSpanKind(trace.SpanKindClient))
+		tx.Statement.Context = ctx
+		tx.InstanceSet(gormSpanKey, span)
+		tx.InstanceSet(gormParentKey, parent)
+	}
+}
+
+// after 在执行 SQL 后记录语句和结果并结束 span，未找到记录不视为错误
+func (p *gormPlugin) after(tx *gorm.DB) {
+	value, ok := tx.InstanceGet(gormSpanKey)
+	if !ok {
+		return
+	}
+	span := value.(trace.Span)
+	if parent, ok := tx.InstanceGet(gormParentKey); ok {
+		tx.Statement.Context = parent.(context.Context)
+	}
+
+	// 只记录带占位符的 SQL，不记录参数值
+	span.SetAttributes(
+		attribute.String("db.system", tx.Dialector.Name()),
+		attribute.String("db.statement", tx.Statement.SQL.String()),
+		attribute.String("db.sql.table", tx.Statement.Table),
+		attribute.Int64("db.rows_affected", tx.Statement.RowsAffected),
+	)
+
+	err := tx.Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	End(span, err)
+}
@@ -0,0 +1,10 @@
+// Package migrations 嵌入按数据库方言组织的 SQL 迁移文件
+package migrations
+
+import "embed"
+
+// FS 包含所有方言的迁移文件，每个方言一个子目录（如 mysql/）
+// 文件命名为 NNN_description.up.sql 和 NNN_description.down.sql
+//
+//go:embed mysql/*.sql postgres/*.sql sqlite/*.sql
+var FS embed.FS
@@ -111,18 +111,3 @@ CREATE TABLE sync_jobs (
     INDEX idx_address_id (address_id),
     INDEX idx_wallet_id (wallet_id)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
-
--- RPC nodes 表：存储每条链的 RPC 端点
-CREATE TABLE rpc_nodes (
-    id BIGINT PRIMARY KEY AUTO_INCREMENT,
-    chain_id VARCHAR(50) NOT NULL,
-    name VARCHAR(255) NOT NULL,
-    endpoint VARCHAR(512) NOT NULL,
-    weight INT DEFAULT 100 COMMENT '负载均衡权重 (0-100)',
-    is_enabled BOOLEAN DEFAULT TRUE,
-    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
-    FOREIGN KEY (chain_id) REFERENCES chains(id) ON DELETE CASCADE,
-    INDEX idx_chain_id (chain_id),
-    INDEX idx_enabled (is_enabled)
-) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
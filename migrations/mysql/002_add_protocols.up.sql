@@ -1,6 +1,6 @@
 -- 添加 protocols 表
 
-CREATE TABLE IF NOT EXISTS `protocols` (
+CREATE TABLE `protocols` (
   `id` bigint NOT NULL AUTO_INCREMENT,
   `address_id` bigint NOT NULL,
   `protocol_id` varchar(255) NOT NULL,
@@ -1,5 +1,5 @@
 -- 添加 RPC 节点表
-CREATE TABLE IF NOT EXISTS `rpc_nodes` (
+CREATE TABLE `rpc_nodes` (
   `id` bigint unsigned NOT NULL AUTO_INCREMENT,
   `chain_id` varchar(50) NOT NULL,
   `name` varchar(255) NOT NULL,
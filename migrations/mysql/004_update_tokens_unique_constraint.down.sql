@@ -0,0 +1,3 @@
+-- 恢复不含 protocol_id 的唯一约束
+ALTER TABLE tokens DROP INDEX uk_address_chain_token_protocol;
+ALTER TABLE tokens ADD UNIQUE KEY uk_address_chain_token (address_id, chain_id, token_id);
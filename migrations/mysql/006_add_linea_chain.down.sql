@@ -0,0 +1 @@
+-- Linea 可能已被 tokens/rpc_nodes 引用，回滚时保留该链数据
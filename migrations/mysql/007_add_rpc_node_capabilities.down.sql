@@ -0,0 +1,7 @@
+ALTER TABLE rpc_nodes DROP COLUMN capabilities_checked_at;
+ALTER TABLE rpc_nodes DROP COLUMN max_logs_block_range;
+ALTER TABLE rpc_nodes DROP COLUMN supports_websocket;
+ALTER TABLE rpc_nodes DROP COLUMN supports_debug;
+ALTER TABLE rpc_nodes DROP COLUMN supports_trace;
+ALTER TABLE rpc_nodes DROP COLUMN is_archive;
+ALTER TABLE rpc_nodes DROP COLUMN ws_url;
@@ -0,0 +1,8 @@
+-- 为 rpc_nodes 表添加能力探测字段
+ALTER TABLE rpc_nodes ADD COLUMN ws_url VARCHAR(500) DEFAULT NULL COMMENT 'WebSocket 端点 URL，空表示从 url 推导';
+ALTER TABLE rpc_nodes ADD COLUMN is_archive TINYINT(1) NOT NULL DEFAULT 0 COMMENT '是否可以查询历史区块状态';
+ALTER TABLE rpc_nodes ADD COLUMN supports_trace TINYINT(1) NOT NULL DEFAULT 0 COMMENT '是否支持 trace_ 命名空间';
+ALTER TABLE rpc_nodes ADD COLUMN supports_debug TINYINT(1) NOT NULL DEFAULT 0 COMMENT '是否支持 debug_ 命名空间';
+ALTER TABLE rpc_nodes ADD COLUMN supports_websocket TINYINT(1) NOT NULL DEFAULT 0 COMMENT 'WebSocket 端点是否可用';
+ALTER TABLE rpc_nodes ADD COLUMN max_logs_block_range INT NOT NULL DEFAULT 0 COMMENT 'eth_getLogs 允许的最大区块范围，0 表示未知';
+ALTER TABLE rpc_nodes ADD COLUMN capabilities_checked_at DATETIME(3) DEFAULT NULL COMMENT '最后能力探测时间';
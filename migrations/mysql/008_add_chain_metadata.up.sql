@@ -0,0 +1,28 @@
+-- 链注册表：数字链 ID、浏览器地址，支持的链改由 is_active 决定
+ALTER TABLE chains ADD COLUMN network_id BIGINT NOT NULL DEFAULT 0 COMMENT '数字链 ID（EIP-155），0 表示未知';
+ALTER TABLE chains ADD COLUMN explorer_url VARCHAR(512) DEFAULT NULL COMMENT '区块浏览器地址';
+ALTER TABLE chains ALTER COLUMN is_active SET DEFAULT FALSE;
+
+-- 之前硬编码在 ChainRepository.List 中的支持链保持激活，其余链停用
+UPDATE chains SET is_active = (id IN ('eth', 'arb', 'op', 'base', 'linea', 'uni', 'plasma', 'scrl', 'plume', 'matic', 'ink', 'hyper', 'bsc', 'bera'));
+
+INSERT INTO chains (id, name, chain_type, network_id, explorer_url, is_active)
+VALUES
+    ('eth', 'Ethereum', 'EVM', 1, 'https://etherscan.io', TRUE),
+    ('arb', 'Arbitrum', 'EVM', 42161, 'https://arbiscan.io', TRUE),
+    ('op', 'OP', 'EVM', 10, 'https://optimistic.etherscan.io', TRUE),
+    ('base', 'Base', 'EVM', 8453, 'https://basescan.org', TRUE),
+    ('linea', 'Linea', 'EVM', 59144, 'https://lineascan.build', TRUE),
+    ('uni', 'Unichain', 'EVM', 130, 'https://uniscan.xyz', TRUE),
+    ('plasma', 'Plasma', 'EVM', 9745, 'https://plasmascan.to', TRUE),
+    ('scrl', 'Scroll', 'EVM', 534352, 'https://scrollscan.com', TRUE),
+    ('plume', 'Plume', 'EVM', 98866, 'https://explorer.plume.org', TRUE),
+    ('matic', 'Polygon', 'EVM', 137, 'https://polygonscan.com', TRUE),
+    ('ink', 'Ink', 'EVM', 57073, 'https://explorer.inkonchain.com', TRUE),
+    ('hyper', 'HyperEVM', 'EVM', 999, 'https://hyperevmscan.io', TRUE),
+    ('bsc', 'BNB Chain', 'EVM', 56, 'https://bscscan.com', TRUE),
+    ('bera', 'Berachain', 'EVM', 80094, 'https://berascan.com', TRUE)
+ON DUPLICATE KEY UPDATE
+    network_id = VALUES(network_id),
+    explorer_url = VALUES(explorer_url),
+    is_active = VALUES(is_active);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS `token_rules`;
+DROP INDEX `idx_tokens_classification` ON `tokens`;
+ALTER TABLE tokens DROP COLUMN is_core;
+ALTER TABLE tokens DROP COLUMN is_verified;
+ALTER TABLE tokens DROP COLUMN classification;
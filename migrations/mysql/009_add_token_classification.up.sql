@@ -0,0 +1,27 @@
+-- 代币分类：垃圾代币不再丢弃，而是记录分类并默认隐藏
+ALTER TABLE tokens ADD COLUMN classification VARCHAR(20) NOT NULL DEFAULT 'normal' COMMENT '分类：normal、spam、receipt、hidden';
+ALTER TABLE tokens ADD COLUMN is_verified TINYINT(1) NOT NULL DEFAULT 0 COMMENT '提供者是否验证过该代币';
+ALTER TABLE tokens ADD COLUMN is_core TINYINT(1) NOT NULL DEFAULT 0 COMMENT '是否为提供者认定的核心资产';
+CREATE INDEX `idx_tokens_classification` ON `tokens` (`classification`);
+
+-- 代币分类规则表
+CREATE TABLE `token_rules` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `name` varchar(100) NOT NULL,
+  `classification` varchar(20) NOT NULL COMMENT '匹配后的分类',
+  `priority` int NOT NULL DEFAULT 0 COMMENT '越小越先匹配',
+  `chain_id` varchar(50) DEFAULT NULL COMMENT '空表示所有链',
+  `symbol_pattern` varchar(255) DEFAULT NULL COMMENT '符号正则',
+  `name_pattern` varchar(255) DEFAULT NULL COMMENT '名称正则',
+  `text_pattern` varchar(255) DEFAULT NULL COMMENT '符号或名称任一匹配的正则',
+  `token_ids` JSON DEFAULT NULL COMMENT '合约地址列表（允许/拒绝列表）',
+  `max_price` decimal(30,6) DEFAULT NULL COMMENT '价格不高于该值时匹配',
+  `max_usd_value` decimal(30,6) DEFAULT NULL COMMENT 'USD 价值不高于该值时匹配',
+  `is_verified` tinyint(1) DEFAULT NULL COMMENT '匹配提供者 is_verified 标志',
+  `is_core` tinyint(1) DEFAULT NULL COMMENT '匹配提供者 is_core 标志',
+  `enabled` tinyint(1) NOT NULL DEFAULT 1,
+  `created_at` datetime(3) NOT NULL,
+  `updated_at` datetime(3) NOT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_token_rules_enabled` (`enabled`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
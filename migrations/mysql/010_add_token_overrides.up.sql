@@ -0,0 +1,33 @@
+-- 用户管理的代币忽略/白名单，wallet_id 为 0 表示全局
+CREATE TABLE `token_overrides` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `chain_id` varchar(50) NOT NULL,
+  `token_id` varchar(255) NOT NULL COMMENT '小写的代币 ID/合约地址',
+  `wallet_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '0 表示对所有钱包生效',
+  `action` varchar(20) NOT NULL COMMENT 'ignore 或 show',
+  `reason` varchar(500) DEFAULT NULL,
+  `updated_by` varchar(255) DEFAULT NULL,
+  `created_at` datetime(3) NOT NULL,
+  `updated_at` datetime(3) NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_token_override` (`chain_id`, `token_id`, `wallet_id`),
+  KEY `idx_token_overrides_wallet_id` (`wallet_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 忽略/白名单变更审计
+CREATE TABLE `token_override_audits` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `override_id` bigint unsigned NOT NULL,
+  `chain_id` varchar(50) NOT NULL,
+  `token_id` varchar(255) NOT NULL,
+  `wallet_id` bigint unsigned NOT NULL DEFAULT 0,
+  `operation` varchar(20) NOT NULL COMMENT 'create、update、delete',
+  `old_action` varchar(20) DEFAULT NULL,
+  `new_action` varchar(20) DEFAULT NULL,
+  `reason` varchar(500) DEFAULT NULL,
+  `actor` varchar(255) NOT NULL COMMENT '执行变更的用户或客户端',
+  `created_at` datetime(3) NOT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_token_override_audits_token` (`chain_id`, `token_id`),
+  KEY `idx_token_override_audits_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
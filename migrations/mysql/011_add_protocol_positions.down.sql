@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `protocol_position_tokens`;
+DROP TABLE IF EXISTS `protocol_positions`;
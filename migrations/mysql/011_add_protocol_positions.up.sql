@@ -0,0 +1,42 @@
+-- 协议持仓明细：每个 portfolio item 一行
+CREATE TABLE `protocol_positions` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `address_id` bigint NOT NULL,
+  `protocol_id` varchar(255) NOT NULL,
+  `chain_id` varchar(50) NOT NULL,
+  `name` varchar(255) DEFAULT NULL COMMENT '持仓名称，如 Lending、Staked',
+  `position_type` varchar(50) NOT NULL DEFAULT 'unknown' COMMENT '主要类型：lending、staking、liquidity 等',
+  `detail_types` json DEFAULT NULL COMMENT '提供者返回的所有类型',
+  `pool_id` varchar(255) DEFAULT NULL COMMENT '提供者的池子 ID',
+  `net_usd_value` decimal(30,6) NOT NULL DEFAULT 0,
+  `asset_usd_value` decimal(30,6) NOT NULL DEFAULT 0,
+  `debt_usd_value` decimal(30,6) NOT NULL DEFAULT 0,
+  `health_rate` decimal(30,6) DEFAULT NULL COMMENT '健康因子，NULL 表示不适用',
+  `last_updated` datetime(3) DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_protocol_positions_address_id` (`address_id`),
+  KEY `idx_protocol_positions_protocol_id` (`protocol_id`),
+  KEY `idx_protocol_positions_chain_id` (`chain_id`),
+  KEY `idx_protocol_positions_position_type` (`position_type`),
+  CONSTRAINT `fk_protocol_positions_address` FOREIGN KEY (`address_id`) REFERENCES `addresses` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 持仓中的代币：supply、borrow、reward
+CREATE TABLE `protocol_position_tokens` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `position_id` bigint NOT NULL,
+  `role` varchar(20) NOT NULL COMMENT 'supply、borrow 或 reward',
+  `chain_id` varchar(50) NOT NULL,
+  `token_id` varchar(255) NOT NULL,
+  `symbol` varchar(100) DEFAULT NULL,
+  `name` varchar(255) DEFAULT NULL,
+  `decimals` int NOT NULL DEFAULT 0,
+  `logo_url` varchar(500) DEFAULT NULL,
+  `amount` decimal(40,18) NOT NULL DEFAULT 0,
+  `price` decimal(30,6) NOT NULL DEFAULT 0,
+  `usd_value` decimal(30,6) NOT NULL DEFAULT 0,
+  PRIMARY KEY (`id`),
+  KEY `idx_protocol_position_tokens_position_id` (`position_id`),
+  KEY `idx_protocol_position_tokens_token` (`chain_id`, `token_id`),
+  CONSTRAINT `fk_protocol_position_tokens_position` FOREIGN KEY (`position_id`) REFERENCES `protocol_positions` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
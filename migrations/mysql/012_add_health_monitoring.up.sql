@@ -0,0 +1,50 @@
+-- 健康因子历史：每次同步记录借贷持仓的健康因子
+CREATE TABLE `health_rate_history` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `address_id` bigint NOT NULL,
+  `protocol_id` varchar(255) NOT NULL,
+  `chain_id` varchar(50) NOT NULL,
+  `position_key` varchar(255) NOT NULL COMMENT '池子 ID，缺省为持仓名称',
+  `health_rate` decimal(30,6) NOT NULL,
+  `recorded_at` datetime(3) NOT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_health_rate_history_position` (`address_id`, `protocol_id`, `chain_id`, `position_key`, `recorded_at`),
+  CONSTRAINT `fk_health_rate_history_address` FOREIGN KEY (`address_id`) REFERENCES `addresses` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 健康因子告警阈值，wallet_id 为 0、protocol_id/position_key 为空表示不限
+CREATE TABLE `health_thresholds` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `wallet_id` bigint NOT NULL DEFAULT 0,
+  `protocol_id` varchar(255) NOT NULL DEFAULT '',
+  `position_key` varchar(255) NOT NULL DEFAULT '',
+  `threshold` decimal(30,6) NOT NULL COMMENT '健康因子跌破该值时告警',
+  `drop_percent` decimal(10,4) NOT NULL DEFAULT 0 COMMENT '两次同步间下降超过该百分比时告警，0 表示不检查',
+  `created_at` datetime(3) NOT NULL,
+  `updated_at` datetime(3) NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_health_threshold` (`wallet_id`, `protocol_id`, `position_key`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 已触发的健康因子告警
+CREATE TABLE `health_alerts` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `wallet_id` bigint NOT NULL,
+  `address_id` bigint NOT NULL,
+  `protocol_id` varchar(255) NOT NULL,
+  `chain_id` varchar(50) NOT NULL,
+  `position_key` varchar(255) NOT NULL,
+  `alert_type` varchar(50) NOT NULL COMMENT 'below_threshold 或 sharp_drop',
+  `health_rate` decimal(30,6) NOT NULL,
+  `previous_health_rate` decimal(30,6) DEFAULT NULL,
+  `threshold` decimal(30,6) NOT NULL,
+  `message` varchar(1000) NOT NULL,
+  `delivered` tinyint(1) NOT NULL DEFAULT 0,
+  `delivery_error` varchar(1000) DEFAULT NULL,
+  `created_at` datetime(3) NOT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_health_alerts_wallet_id` (`wallet_id`),
+  KEY `idx_health_alerts_address_id` (`address_id`),
+  KEY `idx_health_alerts_created_at` (`created_at`),
+  CONSTRAINT `fk_health_alerts_address` FOREIGN KEY (`address_id`) REFERENCES `addresses` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
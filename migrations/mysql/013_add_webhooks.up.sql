@@ -0,0 +1,35 @@
+-- 出站 webhook 端点
+CREATE TABLE `webhook_endpoints` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `name` varchar(255) NOT NULL,
+  `url` varchar(1000) NOT NULL,
+  `secret` varchar(255) NOT NULL COMMENT '用于 HMAC-SHA256 签名',
+  `events` JSON DEFAULT NULL COMMENT '订阅的事件类型，为空表示全部',
+  `wallet_id` bigint NOT NULL DEFAULT 0 COMMENT '只接收该钱包的事件，0 表示全部',
+  `value_change_percent` decimal(10,4) NOT NULL DEFAULT 0 COMMENT 'wallet.value_changed 的阈值，0 表示使用配置默认值',
+  `enabled` tinyint(1) NOT NULL DEFAULT 1,
+  `created_at` datetime(3) NOT NULL,
+  `updated_at` datetime(3) NOT NULL,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- webhook 投递记录，同时作为投递队列和死信列表
+CREATE TABLE `webhook_deliveries` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `endpoint_id` bigint NOT NULL,
+  `event_id` varchar(64) NOT NULL,
+  `event_type` varchar(50) NOT NULL,
+  `payload` mediumtext NOT NULL COMMENT '签名时使用的原始 JSON',
+  `status` varchar(20) NOT NULL DEFAULT 'pending' COMMENT 'pending、delivered、dead',
+  `attempts` int NOT NULL DEFAULT 0,
+  `next_attempt_at` datetime(3) NOT NULL,
+  `last_error` varchar(1000) DEFAULT NULL,
+  `response_status` int NOT NULL DEFAULT 0,
+  `delivered_at` datetime(3) DEFAULT NULL,
+  `created_at` datetime(3) NOT NULL,
+  `updated_at` datetime(3) NOT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_webhook_deliveries_endpoint_id` (`endpoint_id`),
+  KEY `idx_webhook_deliveries_due` (`status`, `next_attempt_at`),
+  CONSTRAINT `fk_webhook_deliveries_endpoint` FOREIGN KEY (`endpoint_id`) REFERENCES `webhook_endpoints` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `alert_events`;
+DROP TABLE IF EXISTS `alert_rules`;
@@ -0,0 +1,39 @@
+-- 余额变化告警规则
+CREATE TABLE `alert_rules` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `name` varchar(255) NOT NULL,
+  `rule_type` varchar(50) NOT NULL COMMENT 'net_worth_drop、token_balance_change、token_disappeared、new_debt',
+  `scope_type` varchar(20) NOT NULL DEFAULT 'all' COMMENT 'all、wallet、address、tag',
+  `scope_id` bigint NOT NULL DEFAULT 0 COMMENT 'scope_type 为 wallet/address 时的 ID',
+  `scope_tag` varchar(255) NOT NULL DEFAULT '' COMMENT 'scope_type 为 tag 时的标签',
+  `chain_id` varchar(50) NOT NULL DEFAULT '' COMMENT '只检查该链的代币，空表示全部',
+  `token_id` varchar(255) NOT NULL DEFAULT '' COMMENT '只检查该代币，空表示全部',
+  `threshold_percent` decimal(10,4) DEFAULT NULL,
+  `threshold_usd` decimal(30,6) DEFAULT NULL,
+  `cooldown_minutes` int NOT NULL DEFAULT 60 COMMENT '同一地址两次告警的最小间隔',
+  `enabled` tinyint(1) NOT NULL DEFAULT 1,
+  `created_at` datetime(3) NOT NULL,
+  `updated_at` datetime(3) NOT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_alert_rules_enabled` (`enabled`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 已触发的余额变化告警
+CREATE TABLE `alert_events` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `rule_id` bigint NOT NULL,
+  `wallet_id` bigint NOT NULL,
+  `address_id` bigint NOT NULL,
+  `rule_type` varchar(50) NOT NULL,
+  `message` varchar(1000) NOT NULL,
+  `details` JSON DEFAULT NULL,
+  `delivered` tinyint(1) NOT NULL DEFAULT 0,
+  `delivery_error` varchar(1000) DEFAULT NULL,
+  `created_at` datetime(3) NOT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_alert_events_rule_address` (`rule_id`, `address_id`, `created_at`),
+  KEY `idx_alert_events_wallet_id` (`wallet_id`),
+  KEY `idx_alert_events_created_at` (`created_at`),
+  CONSTRAINT `fk_alert_events_rule` FOREIGN KEY (`rule_id`) REFERENCES `alert_rules` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_alert_events_address` FOREIGN KEY (`address_id`) REFERENCES `addresses` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,14 @@
+-- API key，只保存 SHA-256 哈希
+CREATE TABLE `api_keys` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `name` varchar(255) NOT NULL,
+  `key_prefix` varchar(16) NOT NULL COMMENT '明文 key 的前缀，用于识别',
+  `key_hash` varchar(64) NOT NULL COMMENT 'SHA-256(key) 的十六进制',
+  `role` varchar(20) NOT NULL COMMENT 'read_only、operator、admin',
+  `expires_at` datetime(3) DEFAULT NULL,
+  `revoked_at` datetime(3) DEFAULT NULL,
+  `last_used_at` datetime(3) DEFAULT NULL,
+  `created_at` datetime(3) NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_api_keys_key_hash` (`key_hash`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
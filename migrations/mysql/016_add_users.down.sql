@@ -0,0 +1,11 @@
+ALTER TABLE `api_keys` DROP FOREIGN KEY `fk_api_keys_user`;
+ALTER TABLE `api_keys` DROP COLUMN `user_id`;
+
+ALTER TABLE `wallets` DROP FOREIGN KEY `fk_wallets_owner`;
+ALTER TABLE `wallets` DROP INDEX `uk_wallets_owner_name`;
+ALTER TABLE `wallets` DROP COLUMN `owner_id`;
+ALTER TABLE `wallets` ADD UNIQUE KEY `name` (`name`);
+
+DROP TABLE IF EXISTS `wallet_shares`;
+DROP TABLE IF EXISTS `user_sessions`;
+DROP TABLE IF EXISTS `users`;
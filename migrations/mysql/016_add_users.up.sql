@@ -0,0 +1,50 @@
+-- 本地用户账号
+CREATE TABLE `users` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `username` varchar(100) NOT NULL,
+  `password_hash` varchar(255) NOT NULL COMMENT 'bcrypt 哈希',
+  `role` varchar(20) NOT NULL DEFAULT 'operator' COMMENT 'read_only、operator、admin',
+  `disabled` tinyint(1) NOT NULL DEFAULT 0,
+  `created_at` datetime(3) NOT NULL,
+  `updated_at` datetime(3) NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_users_username` (`username`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 登录会话，只保存 token 的 SHA-256 哈希
+CREATE TABLE `user_sessions` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `user_id` bigint NOT NULL,
+  `token_hash` varchar(64) NOT NULL,
+  `expires_at` datetime(3) NOT NULL,
+  `last_used_at` datetime(3) DEFAULT NULL,
+  `created_at` datetime(3) NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_user_sessions_token_hash` (`token_hash`),
+  KEY `idx_user_sessions_user_id` (`user_id`),
+  CONSTRAINT `fk_user_sessions_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 钱包共享
+CREATE TABLE `wallet_shares` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `wallet_id` bigint NOT NULL,
+  `user_id` bigint NOT NULL,
+  `permission` varchar(10) NOT NULL COMMENT 'view、edit',
+  `created_at` datetime(3) NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_wallet_shares_wallet_user` (`wallet_id`, `user_id`),
+  KEY `idx_wallet_shares_user_id` (`user_id`),
+  CONSTRAINT `fk_wallet_shares_wallet` FOREIGN KEY (`wallet_id`) REFERENCES `wallets` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_wallet_shares_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 钱包所有者，钱包名称改为在同一所有者内唯一
+ALTER TABLE `wallets` ADD COLUMN `owner_id` bigint DEFAULT NULL COMMENT '所有者，NULL 表示升级前创建的钱包，只有 admin 可见';
+ALTER TABLE `wallets` ADD CONSTRAINT `fk_wallets_owner` FOREIGN KEY (`owner_id`) REFERENCES `users` (`id`) ON DELETE SET NULL;
+ALTER TABLE `wallets` DROP INDEX `name`;
+ALTER TABLE `wallets` ADD UNIQUE KEY `uk_wallets_owner_name` (`owner_id`, `name`);
+
+-- API key 可以属于某个用户，NULL 表示不限钱包的服务 key
+ALTER TABLE `api_keys` ADD COLUMN `user_id` bigint DEFAULT NULL COMMENT '所属用户，NULL 表示不限钱包的服务 key';
+ALTER TABLE `api_keys` ADD CONSTRAINT `fk_api_keys_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE;
@@ -0,0 +1,20 @@
+-- 修改类 API 请求的审计日志
+CREATE TABLE `audit_log` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `actor` varchar(255) NOT NULL COMMENT 'user:<用户名>、apikey:<名称>、X-Actor 请求头或客户端 IP',
+  `method` varchar(10) NOT NULL,
+  `route` varchar(255) NOT NULL COMMENT '路由模板，如 /api/v1/wallets/:id',
+  `path` varchar(500) NOT NULL,
+  `entity_type` varchar(50) NOT NULL DEFAULT '',
+  `entity_id` varchar(100) NOT NULL DEFAULT '',
+  `status_code` int NOT NULL,
+  `client_ip` varchar(64) NOT NULL DEFAULT '',
+  `before_state` JSON DEFAULT NULL COMMENT '修改前的实体',
+  `after_state` JSON DEFAULT NULL COMMENT '修改后的实体',
+  `changes` JSON DEFAULT NULL COMMENT '发生变化的字段：{"字段": {"from": 旧值, "to": 新值}}',
+  `created_at` datetime(3) NOT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_audit_log_entity` (`entity_type`, `entity_id`),
+  KEY `idx_audit_log_actor` (`actor`),
+  KEY `idx_audit_log_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
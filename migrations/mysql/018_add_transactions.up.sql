@@ -0,0 +1,36 @@
+-- 地址的交易历史
+CREATE TABLE `transactions` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `address_id` bigint NOT NULL,
+  `chain_id` varchar(50) NOT NULL,
+  `tx_hash` varchar(100) NOT NULL,
+  `block_number` bigint unsigned NOT NULL DEFAULT 0 COMMENT '0 表示提供者未返回',
+  `block_time` datetime(3) NOT NULL,
+  `counterparty` varchar(255) DEFAULT NULL,
+  `category` varchar(50) DEFAULT NULL COMMENT 'send、receive、approve 等，空表示一般合约调用',
+  `protocol_id` varchar(255) DEFAULT NULL,
+  `transfers_in` JSON DEFAULT NULL COMMENT '转入的代币：[{"token_id","symbol","amount","counterparty"}]',
+  `transfers_out` JSON DEFAULT NULL COMMENT '转出的代币',
+  `fee` decimal(40,18) DEFAULT NULL COMMENT '原生代币计的手续费，只在地址发起交易时记录',
+  `fee_usd` decimal(30,6) DEFAULT NULL,
+  `failed` tinyint(1) NOT NULL DEFAULT 0,
+  `source` varchar(20) NOT NULL COMMENT 'debank 或 rpc',
+  `created_at` datetime(3) NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_transactions_address_chain_hash` (`address_id`, `chain_id`, `tx_hash`),
+  KEY `idx_transactions_address_time` (`address_id`, `block_time`),
+  CONSTRAINT `fk_transactions_address` FOREIGN KEY (`address_id`) REFERENCES `addresses` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 每个地址和链的交易同步游标
+CREATE TABLE `transaction_cursors` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `address_id` bigint NOT NULL,
+  `chain_id` varchar(50) NOT NULL,
+  `source` varchar(20) NOT NULL COMMENT '生成游标的提供者，切换提供者后重新回溯',
+  `next_cursor` varchar(100) NOT NULL COMMENT '提供者定义的游标，如最新交易时间或已扫描的区块',
+  `synced_at` datetime(3) NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_transaction_cursors_address_chain` (`address_id`, `chain_id`),
+  CONSTRAINT `fk_transaction_cursors_address` FOREIGN KEY (`address_id`) REFERENCES `addresses` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
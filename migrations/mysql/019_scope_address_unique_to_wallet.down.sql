@@ -0,0 +1,4 @@
+-- 恢复全局唯一；如果多个钱包已包含同一地址，回滚会因唯一键冲突失败，需要先手动清理
+ALTER TABLE `addresses`
+  DROP INDEX `uk_addresses_wallet_address_chain`,
+  ADD UNIQUE KEY `uk_address_chain` (`address`, `chain_type`);
@@ -0,0 +1,4 @@
+-- 地址改为在同一钱包内唯一，不同用户可以各自跟踪同一地址
+ALTER TABLE `addresses`
+  DROP INDEX `uk_address_chain`,
+  ADD UNIQUE KEY `uk_addresses_wallet_address_chain` (`wallet_id`, `address`, `chain_type`);
@@ -0,0 +1,8 @@
+-- 回滚初始模式（按外键依赖逆序删除）
+DROP TABLE IF EXISTS sync_jobs;
+DROP TABLE IF EXISTS api_rate_limits;
+DROP TABLE IF EXISTS tokens;
+DROP TABLE IF EXISTS chains;
+DROP TABLE IF EXISTS asset_snapshots;
+DROP TABLE IF EXISTS addresses;
+DROP TABLE IF EXISTS wallets;
@@ -0,0 +1,19 @@
+-- 添加 protocols 表
+CREATE TABLE protocols (
+    id BIGSERIAL PRIMARY KEY,
+    address_id BIGINT NOT NULL REFERENCES addresses(id) ON DELETE CASCADE,
+    protocol_id VARCHAR(255) NOT NULL,
+    name VARCHAR(255) DEFAULT NULL,
+    site_url VARCHAR(500) DEFAULT NULL,
+    logo_url VARCHAR(500) DEFAULT NULL,
+    chain_id VARCHAR(50) NOT NULL,
+    net_usd_value NUMERIC(30, 6) DEFAULT NULL,
+    asset_usd_value NUMERIC(30, 6) DEFAULT NULL,
+    debt_usd_value NUMERIC(30, 6) DEFAULT NULL,
+    position_type VARCHAR(50) DEFAULT NULL,
+    raw_data JSONB DEFAULT NULL,
+    last_updated TIMESTAMPTZ DEFAULT NULL,
+    CONSTRAINT uk_address_protocol UNIQUE (address_id, protocol_id)
+);
+CREATE INDEX idx_protocols_address_id ON protocols (address_id);
+CREATE INDEX idx_protocols_chain_id ON protocols (chain_id);
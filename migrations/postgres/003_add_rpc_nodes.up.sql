@@ -0,0 +1,18 @@
+-- 添加 RPC 节点表
+CREATE TABLE rpc_nodes (
+    id BIGSERIAL PRIMARY KEY,
+    chain_id VARCHAR(50) NOT NULL REFERENCES chains(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    url VARCHAR(500) NOT NULL,
+    weight INT NOT NULL DEFAULT 100,
+    is_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    is_connected BOOLEAN NOT NULL DEFAULT FALSE,
+    last_checked TIMESTAMPTZ DEFAULT NULL,
+    priority INT NOT NULL DEFAULT 0,
+    timeout INT NOT NULL DEFAULT 30,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX idx_rpc_nodes_chain_id ON rpc_nodes (chain_id);
+CREATE INDEX idx_rpc_nodes_enabled ON rpc_nodes (is_enabled);
+CREATE INDEX idx_rpc_nodes_chain_enabled ON rpc_nodes (chain_id, is_enabled);
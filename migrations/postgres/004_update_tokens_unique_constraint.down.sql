@@ -0,0 +1,2 @@
+ALTER TABLE tokens DROP CONSTRAINT uk_address_chain_token_protocol;
+ALTER TABLE tokens ADD CONSTRAINT uk_address_chain_token UNIQUE (address_id, chain_id, token_id);
@@ -0,0 +1,3 @@
+-- 更新 tokens 表的唯一约束以包含 protocol_id
+ALTER TABLE tokens DROP CONSTRAINT uk_address_chain_token;
+ALTER TABLE tokens ADD CONSTRAINT uk_address_chain_token_protocol UNIQUE (address_id, chain_id, token_id, protocol_id);
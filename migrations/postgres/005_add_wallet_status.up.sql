@@ -0,0 +1,2 @@
+-- Add status column to wallets table
+ALTER TABLE wallets ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'Enabled';
@@ -0,0 +1,9 @@
+-- Add Linea chain to the chains table
+INSERT INTO chains (id, name, chain_type, logo_url, is_active)
+VALUES ('linea', 'Linea', 'EVM', '/images/chains/linea.png', TRUE)
+ON CONFLICT (id) DO UPDATE SET
+    name = excluded.name,
+    chain_type = excluded.chain_type,
+    logo_url = excluded.logo_url,
+    is_active = excluded.is_active,
+    updated_at = CURRENT_TIMESTAMP;
@@ -0,0 +1,9 @@
+-- 为 rpc_nodes 表添加能力探测字段
+ALTER TABLE rpc_nodes
+    ADD COLUMN ws_url VARCHAR(500) DEFAULT NULL,
+    ADD COLUMN is_archive BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN supports_trace BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN supports_debug BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN supports_websocket BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN max_logs_block_range INT NOT NULL DEFAULT 0,
+    ADD COLUMN capabilities_checked_at TIMESTAMPTZ DEFAULT NULL;
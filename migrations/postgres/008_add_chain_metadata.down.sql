@@ -0,0 +1,4 @@
+UPDATE chains SET is_active = TRUE;
+ALTER TABLE chains ALTER COLUMN is_active SET DEFAULT TRUE;
+ALTER TABLE chains DROP COLUMN explorer_url;
+ALTER TABLE chains DROP COLUMN network_id;
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS token_rules;
+DROP INDEX IF EXISTS idx_tokens_classification;
+ALTER TABLE tokens
+    DROP COLUMN is_core,
+    DROP COLUMN is_verified,
+    DROP COLUMN classification;
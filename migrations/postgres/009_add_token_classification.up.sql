@@ -0,0 +1,30 @@
+-- 代币分类：垃圾代币不再丢弃，而是记录分类并默认隐藏
+ALTER TABLE tokens
+    ADD COLUMN classification VARCHAR(20) NOT NULL DEFAULT 'normal',
+    ADD COLUMN is_verified BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN is_core BOOLEAN NOT NULL DEFAULT FALSE;
+COMMENT ON COLUMN tokens.classification IS '分类：normal、spam、receipt、hidden';
+CREATE INDEX idx_tokens_classification ON tokens (classification);
+
+-- 代币分类规则表
+CREATE TABLE token_rules (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    classification VARCHAR(20) NOT NULL,
+    priority INT NOT NULL DEFAULT 0,
+    chain_id VARCHAR(50) DEFAULT NULL,
+    symbol_pattern VARCHAR(255) DEFAULT NULL,
+    name_pattern VARCHAR(255) DEFAULT NULL,
+    text_pattern VARCHAR(255) DEFAULT NULL,
+    token_ids JSONB DEFAULT NULL,
+    max_price DECIMAL(30,6) DEFAULT NULL,
+    max_usd_value DECIMAL(30,6) DEFAULT NULL,
+    is_verified BOOLEAN DEFAULT NULL,
+    is_core BOOLEAN DEFAULT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+COMMENT ON COLUMN token_rules.priority IS '越小越先匹配';
+COMMENT ON COLUMN token_rules.text_pattern IS '符号或名称任一匹配的正则';
+CREATE INDEX idx_token_rules_enabled ON token_rules (enabled);
@@ -0,0 +1,34 @@
+-- 用户管理的代币忽略/白名单，wallet_id 为 0 表示全局
+CREATE TABLE token_overrides (
+    id BIGSERIAL PRIMARY KEY,
+    chain_id VARCHAR(50) NOT NULL,
+    token_id VARCHAR(255) NOT NULL,
+    wallet_id BIGINT NOT NULL DEFAULT 0,
+    action VARCHAR(20) NOT NULL,
+    reason VARCHAR(500) DEFAULT NULL,
+    updated_by VARCHAR(255) DEFAULT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    CONSTRAINT uk_token_override UNIQUE (chain_id, token_id, wallet_id)
+);
+COMMENT ON COLUMN token_overrides.wallet_id IS '0 表示对所有钱包生效';
+COMMENT ON COLUMN token_overrides.action IS 'ignore 或 show';
+CREATE INDEX idx_token_overrides_wallet_id ON token_overrides (wallet_id);
+
+-- 忽略/白名单变更审计
+CREATE TABLE token_override_audits (
+    id BIGSERIAL PRIMARY KEY,
+    override_id BIGINT NOT NULL,
+    chain_id VARCHAR(50) NOT NULL,
+    token_id VARCHAR(255) NOT NULL,
+    wallet_id BIGINT NOT NULL DEFAULT 0,
+    operation VARCHAR(20) NOT NULL,
+    old_action VARCHAR(20) DEFAULT NULL,
+    new_action VARCHAR(20) DEFAULT NULL,
+    reason VARCHAR(500) DEFAULT NULL,
+    actor VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+COMMENT ON COLUMN token_override_audits.operation IS 'create、update、delete';
+CREATE INDEX idx_token_override_audits_token ON token_override_audits (chain_id, token_id);
+CREATE INDEX idx_token_override_audits_created_at ON token_override_audits (created_at);
@@ -0,0 +1,41 @@
+-- 协议持仓明细：每个 portfolio item 一行
+CREATE TABLE protocol_positions (
+    id BIGSERIAL PRIMARY KEY,
+    address_id BIGINT NOT NULL REFERENCES addresses(id) ON DELETE CASCADE,
+    protocol_id VARCHAR(255) NOT NULL,
+    chain_id VARCHAR(50) NOT NULL,
+    name VARCHAR(255) DEFAULT NULL,
+    position_type VARCHAR(50) NOT NULL DEFAULT 'unknown',
+    detail_types JSONB DEFAULT NULL,
+    pool_id VARCHAR(255) DEFAULT NULL,
+    net_usd_value NUMERIC(30, 6) NOT NULL DEFAULT 0,
+    asset_usd_value NUMERIC(30, 6) NOT NULL DEFAULT 0,
+    debt_usd_value NUMERIC(30, 6) NOT NULL DEFAULT 0,
+    health_rate NUMERIC(30, 6) DEFAULT NULL,
+    last_updated TIMESTAMPTZ DEFAULT NULL
+);
+COMMENT ON COLUMN protocol_positions.position_type IS '主要类型：lending、staking、liquidity 等';
+COMMENT ON COLUMN protocol_positions.health_rate IS '健康因子，NULL 表示不适用';
+CREATE INDEX idx_protocol_positions_address_id ON protocol_positions (address_id);
+CREATE INDEX idx_protocol_positions_protocol_id ON protocol_positions (protocol_id);
+CREATE INDEX idx_protocol_positions_chain_id ON protocol_positions (chain_id);
+CREATE INDEX idx_protocol_positions_position_type ON protocol_positions (position_type);
+
+-- 持仓中的代币：supply、borrow、reward
+CREATE TABLE protocol_position_tokens (
+    id BIGSERIAL PRIMARY KEY,
+    position_id BIGINT NOT NULL REFERENCES protocol_positions(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL,
+    chain_id VARCHAR(50) NOT NULL,
+    token_id VARCHAR(255) NOT NULL,
+    symbol VARCHAR(100) DEFAULT NULL,
+    name VARCHAR(255) DEFAULT NULL,
+    decimals INT NOT NULL DEFAULT 0,
+    logo_url VARCHAR(500) DEFAULT NULL,
+    amount NUMERIC(40, 18) NOT NULL DEFAULT 0,
+    price NUMERIC(30, 6) NOT NULL DEFAULT 0,
+    usd_value NUMERIC(30, 6) NOT NULL DEFAULT 0
+);
+COMMENT ON COLUMN protocol_position_tokens.role IS 'supply、borrow 或 reward';
+CREATE INDEX idx_protocol_position_tokens_position_id ON protocol_position_tokens (position_id);
+CREATE INDEX idx_protocol_position_tokens_token ON protocol_position_tokens (chain_id, token_id);
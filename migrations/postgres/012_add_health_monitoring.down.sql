@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS health_alerts;
+DROP TABLE IF EXISTS health_thresholds;
+DROP TABLE IF EXISTS health_rate_history;
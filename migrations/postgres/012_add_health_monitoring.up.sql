@@ -0,0 +1,49 @@
+-- 健康因子历史：每次同步记录借贷持仓的健康因子
+CREATE TABLE health_rate_history (
+    id BIGSERIAL PRIMARY KEY,
+    address_id BIGINT NOT NULL REFERENCES addresses(id) ON DELETE CASCADE,
+    protocol_id VARCHAR(255) NOT NULL,
+    chain_id VARCHAR(50) NOT NULL,
+    position_key VARCHAR(255) NOT NULL,
+    health_rate NUMERIC(30, 6) NOT NULL,
+    recorded_at TIMESTAMPTZ NOT NULL
+);
+COMMENT ON COLUMN health_rate_history.position_key IS '池子 ID，缺省为持仓名称';
+CREATE INDEX idx_health_rate_history_position ON health_rate_history (address_id, protocol_id, chain_id, position_key, recorded_at);
+
+-- 健康因子告警阈值，wallet_id 为 0、protocol_id/position_key 为空表示不限
+CREATE TABLE health_thresholds (
+    id BIGSERIAL PRIMARY KEY,
+    wallet_id BIGINT NOT NULL DEFAULT 0,
+    protocol_id VARCHAR(255) NOT NULL DEFAULT '',
+    position_key VARCHAR(255) NOT NULL DEFAULT '',
+    threshold NUMERIC(30, 6) NOT NULL,
+    drop_percent NUMERIC(10, 4) NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    CONSTRAINT uk_health_threshold UNIQUE (wallet_id, protocol_id, position_key)
+);
+COMMENT ON COLUMN health_thresholds.threshold IS '健康因子跌破该值时告警';
+COMMENT ON COLUMN health_thresholds.drop_percent IS '两次同步间下降超过该百分比时告警，0 表示不检查';
+
+-- 已触发的健康因子告警
+CREATE TABLE health_alerts (
+    id BIGSERIAL PRIMARY KEY,
+    wallet_id BIGINT NOT NULL,
+    address_id BIGINT NOT NULL REFERENCES addresses(id) ON DELETE CASCADE,
+    protocol_id VARCHAR(255) NOT NULL,
+    chain_id VARCHAR(50) NOT NULL,
+    position_key VARCHAR(255) NOT NULL,
+    alert_type VARCHAR(50) NOT NULL,
+    health_rate NUMERIC(30, 6) NOT NULL,
+    previous_health_rate NUMERIC(30, 6) DEFAULT NULL,
+    threshold NUMERIC(30, 6) NOT NULL,
+    message VARCHAR(1000) NOT NULL,
+    delivered BOOLEAN NOT NULL DEFAULT FALSE,
+    delivery_error VARCHAR(1000) DEFAULT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+COMMENT ON COLUMN health_alerts.alert_type IS 'below_threshold 或 sharp_drop';
+CREATE INDEX idx_health_alerts_wallet_id ON health_alerts (wallet_id);
+CREATE INDEX idx_health_alerts_address_id ON health_alerts (address_id);
+CREATE INDEX idx_health_alerts_created_at ON health_alerts (created_at);
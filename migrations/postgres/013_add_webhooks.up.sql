@@ -0,0 +1,38 @@
+-- 出站 webhook 端点
+CREATE TABLE webhook_endpoints (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    url VARCHAR(1000) NOT NULL,
+    secret VARCHAR(255) NOT NULL,
+    events JSONB DEFAULT NULL,
+    wallet_id BIGINT NOT NULL DEFAULT 0,
+    value_change_percent NUMERIC(10, 4) NOT NULL DEFAULT 0,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+COMMENT ON COLUMN webhook_endpoints.secret IS '用于 HMAC-SHA256 签名';
+COMMENT ON COLUMN webhook_endpoints.events IS '订阅的事件类型，为空表示全部';
+COMMENT ON COLUMN webhook_endpoints.wallet_id IS '只接收该钱包的事件，0 表示全部';
+COMMENT ON COLUMN webhook_endpoints.value_change_percent IS 'wallet.value_changed 的阈值，0 表示使用配置默认值';
+
+-- webhook 投递记录，同时作为投递队列和死信列表
+CREATE TABLE webhook_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    endpoint_id BIGINT NOT NULL REFERENCES webhook_endpoints(id) ON DELETE CASCADE,
+    event_id VARCHAR(64) NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    payload TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL,
+    last_error VARCHAR(1000) DEFAULT NULL,
+    response_status INTEGER NOT NULL DEFAULT 0,
+    delivered_at TIMESTAMPTZ DEFAULT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+COMMENT ON COLUMN webhook_deliveries.payload IS '签名时使用的原始 JSON';
+COMMENT ON COLUMN webhook_deliveries.status IS 'pending、delivered、dead';
+CREATE INDEX idx_webhook_deliveries_endpoint_id ON webhook_deliveries (endpoint_id);
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries (status, next_attempt_at);
@@ -0,0 +1,40 @@
+-- 余额变化告警规则
+CREATE TABLE alert_rules (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    rule_type VARCHAR(50) NOT NULL,
+    scope_type VARCHAR(20) NOT NULL DEFAULT 'all',
+    scope_id BIGINT NOT NULL DEFAULT 0,
+    scope_tag VARCHAR(255) NOT NULL DEFAULT '',
+    chain_id VARCHAR(50) NOT NULL DEFAULT '',
+    token_id VARCHAR(255) NOT NULL DEFAULT '',
+    threshold_percent NUMERIC(10, 4) DEFAULT NULL,
+    threshold_usd NUMERIC(30, 6) DEFAULT NULL,
+    cooldown_minutes INTEGER NOT NULL DEFAULT 60,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+COMMENT ON COLUMN alert_rules.rule_type IS 'net_worth_drop、token_balance_change、token_disappeared、new_debt';
+COMMENT ON COLUMN alert_rules.scope_type IS 'all、wallet、address、tag';
+COMMENT ON COLUMN alert_rules.scope_id IS 'scope_type 为 wallet/address 时的 ID';
+COMMENT ON COLUMN alert_rules.scope_tag IS 'scope_type 为 tag 时的标签';
+COMMENT ON COLUMN alert_rules.cooldown_minutes IS '同一地址两次告警的最小间隔';
+CREATE INDEX idx_alert_rules_enabled ON alert_rules (enabled);
+
+-- 已触发的余额变化告警
+CREATE TABLE alert_events (
+    id BIGSERIAL PRIMARY KEY,
+    rule_id BIGINT NOT NULL REFERENCES alert_rules(id) ON DELETE CASCADE,
+    wallet_id BIGINT NOT NULL,
+    address_id BIGINT NOT NULL REFERENCES addresses(id) ON DELETE CASCADE,
+    rule_type VARCHAR(50) NOT NULL,
+    message VARCHAR(1000) NOT NULL,
+    details JSONB DEFAULT NULL,
+    delivered BOOLEAN NOT NULL DEFAULT FALSE,
+    delivery_error VARCHAR(1000) DEFAULT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX idx_alert_events_rule_address ON alert_events (rule_id, address_id, created_at);
+CREATE INDEX idx_alert_events_wallet_id ON alert_events (wallet_id);
+CREATE INDEX idx_alert_events_created_at ON alert_events (created_at);
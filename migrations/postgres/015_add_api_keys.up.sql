@@ -0,0 +1,17 @@
+-- API key，只保存 SHA-256 哈希
+CREATE TABLE api_keys (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    key_prefix VARCHAR(16) NOT NULL,
+    key_hash VARCHAR(64) NOT NULL,
+    role VARCHAR(20) NOT NULL,
+    expires_at TIMESTAMPTZ DEFAULT NULL,
+    revoked_at TIMESTAMPTZ DEFAULT NULL,
+    last_used_at TIMESTAMPTZ DEFAULT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+COMMENT ON COLUMN api_keys.key_prefix IS '明文 key 的前缀，用于识别';
+COMMENT ON COLUMN api_keys.key_hash IS 'SHA-256(key) 的十六进制';
+COMMENT ON COLUMN api_keys.role IS 'read_only、operator、admin';
+
+CREATE UNIQUE INDEX uk_api_keys_key_hash ON api_keys (key_hash);
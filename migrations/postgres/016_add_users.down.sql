@@ -0,0 +1,9 @@
+ALTER TABLE api_keys DROP COLUMN IF EXISTS user_id;
+
+DROP INDEX IF EXISTS uk_wallets_owner_name;
+ALTER TABLE wallets DROP COLUMN IF EXISTS owner_id;
+ALTER TABLE wallets ADD CONSTRAINT wallets_name_key UNIQUE (name);
+
+DROP TABLE IF EXISTS wallet_shares;
+DROP TABLE IF EXISTS user_sessions;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,50 @@
+-- 本地用户账号
+CREATE TABLE users (
+    id BIGSERIAL PRIMARY KEY,
+    username VARCHAR(100) NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    role VARCHAR(20) NOT NULL DEFAULT 'operator',
+    disabled BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+COMMENT ON COLUMN users.password_hash IS 'bcrypt 哈希';
+COMMENT ON COLUMN users.role IS 'read_only、operator、admin';
+
+CREATE UNIQUE INDEX uk_users_username ON users (username);
+
+-- 登录会话，只保存 token 的 SHA-256 哈希
+CREATE TABLE user_sessions (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    last_used_at TIMESTAMPTZ DEFAULT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE UNIQUE INDEX uk_user_sessions_token_hash ON user_sessions (token_hash);
+CREATE INDEX idx_user_sessions_user_id ON user_sessions (user_id);
+
+-- 钱包共享
+CREATE TABLE wallet_shares (
+    id BIGSERIAL PRIMARY KEY,
+    wallet_id BIGINT NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    permission VARCHAR(10) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+COMMENT ON COLUMN wallet_shares.permission IS 'view、edit';
+
+CREATE UNIQUE INDEX uk_wallet_shares_wallet_user ON wallet_shares (wallet_id, user_id);
+CREATE INDEX idx_wallet_shares_user_id ON wallet_shares (user_id);
+
+-- 钱包所有者，钱包名称改为在同一所有者内唯一
+ALTER TABLE wallets ADD COLUMN owner_id BIGINT DEFAULT NULL REFERENCES users(id) ON DELETE SET NULL;
+COMMENT ON COLUMN wallets.owner_id IS '所有者，NULL 表示升级前创建的钱包，只有 admin 可见';
+ALTER TABLE wallets DROP CONSTRAINT wallets_name_key;
+CREATE UNIQUE INDEX uk_wallets_owner_name ON wallets (owner_id, name);
+
+-- API key 可以属于某个用户，NULL 表示不限钱包的服务 key
+ALTER TABLE api_keys ADD COLUMN user_id BIGINT DEFAULT NULL REFERENCES users(id) ON DELETE CASCADE;
+COMMENT ON COLUMN api_keys.user_id IS '所属用户，NULL 表示不限钱包的服务 key';
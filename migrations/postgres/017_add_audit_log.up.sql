@@ -0,0 +1,23 @@
+-- 修改类 API 请求的审计日志
+CREATE TABLE audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    actor VARCHAR(255) NOT NULL,
+    method VARCHAR(10) NOT NULL,
+    route VARCHAR(255) NOT NULL,
+    path VARCHAR(500) NOT NULL,
+    entity_type VARCHAR(50) NOT NULL DEFAULT '',
+    entity_id VARCHAR(100) NOT NULL DEFAULT '',
+    status_code INTEGER NOT NULL,
+    client_ip VARCHAR(64) NOT NULL DEFAULT '',
+    before_state JSONB DEFAULT NULL,
+    after_state JSONB DEFAULT NULL,
+    changes JSONB DEFAULT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX idx_audit_log_entity ON audit_log (entity_type, entity_id);
+CREATE INDEX idx_audit_log_actor ON audit_log (actor);
+CREATE INDEX idx_audit_log_created_at ON audit_log (created_at);
+
+COMMENT ON COLUMN audit_log.actor IS 'user:<用户名>、apikey:<名称>、X-Actor 请求头或客户端 IP';
+COMMENT ON COLUMN audit_log.route IS '路由模板，如 /api/v1/wallets/:id';
+COMMENT ON COLUMN audit_log.changes IS '发生变化的字段：{"字段": {"from": 旧值, "to": 新值}}';
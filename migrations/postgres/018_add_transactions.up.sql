@@ -0,0 +1,41 @@
+-- 地址的交易历史
+CREATE TABLE transactions (
+    id BIGSERIAL PRIMARY KEY,
+    address_id BIGINT NOT NULL REFERENCES addresses(id) ON DELETE CASCADE,
+    chain_id VARCHAR(50) NOT NULL,
+    tx_hash VARCHAR(100) NOT NULL,
+    block_number BIGINT NOT NULL DEFAULT 0,
+    block_time TIMESTAMPTZ NOT NULL,
+    counterparty VARCHAR(255) DEFAULT NULL,
+    category VARCHAR(50) DEFAULT NULL,
+    protocol_id VARCHAR(255) DEFAULT NULL,
+    transfers_in JSONB DEFAULT NULL,
+    transfers_out JSONB DEFAULT NULL,
+    fee NUMERIC(40,18) DEFAULT NULL,
+    fee_usd NUMERIC(30,6) DEFAULT NULL,
+    failed BOOLEAN NOT NULL DEFAULT FALSE,
+    source VARCHAR(20) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+CREATE UNIQUE INDEX uk_transactions_address_chain_hash ON transactions (address_id, chain_id, tx_hash);
+CREATE INDEX idx_transactions_address_time ON transactions (address_id, block_time);
+
+COMMENT ON COLUMN transactions.block_number IS '0 表示提供者未返回';
+COMMENT ON COLUMN transactions.category IS 'send、receive、approve 等，空表示一般合约调用';
+COMMENT ON COLUMN transactions.transfers_in IS '转入的代币：[{"token_id","symbol","amount","counterparty"}]';
+COMMENT ON COLUMN transactions.fee IS '原生代币计的手续费，只在地址发起交易时记录';
+COMMENT ON COLUMN transactions.source IS 'debank 或 rpc';
+
+-- 每个地址和链的交易同步游标
+CREATE TABLE transaction_cursors (
+    id BIGSERIAL PRIMARY KEY,
+    address_id BIGINT NOT NULL REFERENCES addresses(id) ON DELETE CASCADE,
+    chain_id VARCHAR(50) NOT NULL,
+    source VARCHAR(20) NOT NULL,
+    next_cursor VARCHAR(100) NOT NULL,
+    synced_at TIMESTAMPTZ NOT NULL
+);
+CREATE UNIQUE INDEX uk_transaction_cursors_address_chain ON transaction_cursors (address_id, chain_id);
+
+COMMENT ON COLUMN transaction_cursors.source IS '生成游标的提供者，切换提供者后重新回溯';
+COMMENT ON COLUMN transaction_cursors.next_cursor IS '提供者定义的游标，如最新交易时间或已扫描的区块';
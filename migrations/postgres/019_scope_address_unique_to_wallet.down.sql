@@ -0,0 +1,3 @@
+-- 恢复全局唯一；如果多个钱包已包含同一地址，回滚会因唯一键冲突失败，需要先手动清理
+ALTER TABLE addresses DROP CONSTRAINT uk_addresses_wallet_address_chain;
+ALTER TABLE addresses ADD CONSTRAINT uk_address_chain UNIQUE (address, chain_type);
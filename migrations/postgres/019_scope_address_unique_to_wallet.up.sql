@@ -0,0 +1,3 @@
+-- 地址改为在同一钱包内唯一，不同用户可以各自跟踪同一地址
+ALTER TABLE addresses DROP CONSTRAINT uk_address_chain;
+ALTER TABLE addresses ADD CONSTRAINT uk_addresses_wallet_address_chain UNIQUE (wallet_id, address, chain_type);
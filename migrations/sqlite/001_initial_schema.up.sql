@@ -0,0 +1,106 @@
+-- Rotki Demo 数据库模式（SQLite）
+
+-- Wallets 表：存储钱包信息
+CREATE TABLE wallets (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    description TEXT,
+    tags TEXT DEFAULT NULL, -- JSON：用户自定义标签
+    enabled_chains TEXT DEFAULT NULL, -- JSON：启用的链 ID 列表，NULL 表示所有链
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Addresses 表：存储与钱包关联的区块链地址
+CREATE TABLE addresses (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    wallet_id INTEGER NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    address VARCHAR(255) NOT NULL,
+    chain_type VARCHAR(50) NOT NULL DEFAULT 'EVM',
+    label VARCHAR(255),
+    tags TEXT DEFAULT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    last_synced_at DATETIME NULL,
+    UNIQUE (address, chain_type)
+);
+CREATE INDEX idx_addresses_wallet_id ON addresses (wallet_id);
+CREATE INDEX idx_addresses_address ON addresses (address);
+
+-- Asset snapshots 表：存储每个地址的定期资产快照
+CREATE TABLE asset_snapshots (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    address_id INTEGER NOT NULL REFERENCES addresses(id) ON DELETE CASCADE,
+    snapshot_time DATETIME NOT NULL,
+    total_usd_value REAL DEFAULT 0,
+    data_source VARCHAR(50) NOT NULL DEFAULT 'debank',
+    raw_data TEXT,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_address_time ON asset_snapshots (address_id, snapshot_time);
+CREATE INDEX idx_snapshot_time ON asset_snapshots (snapshot_time);
+
+-- Chains 表：存储支持的区块链信息
+CREATE TABLE chains (
+    id VARCHAR(50) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    chain_type VARCHAR(50) NOT NULL DEFAULT 'EVM',
+    logo_url VARCHAR(512),
+    native_token_id VARCHAR(100),
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_chain_type ON chains (chain_type);
+
+-- Tokens 表：存储代币信息以供快速查找
+CREATE TABLE tokens (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    address_id INTEGER NOT NULL REFERENCES addresses(id) ON DELETE CASCADE,
+    chain_id VARCHAR(50) NOT NULL REFERENCES chains(id),
+    token_id VARCHAR(255) NOT NULL,
+    symbol VARCHAR(50),
+    name VARCHAR(255),
+    decimals INTEGER,
+    logo_url VARCHAR(512),
+    balance TEXT, -- 存储为字符串以处理超大值，可以是负数（debt）
+    price REAL,
+    usd_value REAL,
+    protocol_id VARCHAR(100) DEFAULT NULL,
+    is_debt BOOLEAN DEFAULT FALSE,
+    last_updated DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX uk_address_chain_token ON tokens (address_id, chain_id, token_id);
+CREATE INDEX idx_tokens_address_id ON tokens (address_id);
+CREATE INDEX idx_tokens_chain_id ON tokens (chain_id);
+CREATE INDEX idx_tokens_protocol_id ON tokens (protocol_id);
+
+-- API rate limiting 表：API 速率限制跟踪
+CREATE TABLE api_rate_limits (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    provider VARCHAR(50) NOT NULL,
+    endpoint VARCHAR(255) NOT NULL,
+    request_count INTEGER DEFAULT 0,
+    window_start DATETIME NOT NULL,
+    window_end DATETIME NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_provider_window ON api_rate_limits (provider, window_start, window_end);
+
+-- Sync jobs 表：跟踪后台同步操作
+CREATE TABLE sync_jobs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    address_id INTEGER REFERENCES addresses(id) ON DELETE CASCADE,
+    wallet_id INTEGER REFERENCES wallets(id) ON DELETE CASCADE,
+    job_type VARCHAR(50) NOT NULL,
+    status VARCHAR(50) NOT NULL,
+    started_at DATETIME NULL,
+    completed_at DATETIME NULL,
+    error_message TEXT,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_sync_jobs_status ON sync_jobs (status);
+CREATE INDEX idx_sync_jobs_address_id ON sync_jobs (address_id);
+CREATE INDEX idx_sync_jobs_wallet_id ON sync_jobs (wallet_id);
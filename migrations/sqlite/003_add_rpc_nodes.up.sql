@@ -0,0 +1,18 @@
+-- 添加 RPC 节点表
+CREATE TABLE rpc_nodes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    chain_id VARCHAR(50) NOT NULL REFERENCES chains(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    url VARCHAR(500) NOT NULL,
+    weight INTEGER NOT NULL DEFAULT 100,
+    is_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    is_connected BOOLEAN NOT NULL DEFAULT FALSE,
+    last_checked DATETIME DEFAULT NULL,
+    priority INTEGER NOT NULL DEFAULT 0,
+    timeout INTEGER NOT NULL DEFAULT 30,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+CREATE INDEX idx_rpc_nodes_chain_id ON rpc_nodes (chain_id);
+CREATE INDEX idx_rpc_nodes_enabled ON rpc_nodes (is_enabled);
+CREATE INDEX idx_rpc_nodes_chain_enabled ON rpc_nodes (chain_id, is_enabled);
@@ -0,0 +1,2 @@
+DROP INDEX uk_address_chain_token_protocol;
+CREATE UNIQUE INDEX uk_address_chain_token ON tokens (address_id, chain_id, token_id);
@@ -0,0 +1,3 @@
+-- 更新 tokens 表的唯一约束以包含 protocol_id
+DROP INDEX uk_address_chain_token;
+CREATE UNIQUE INDEX uk_address_chain_token_protocol ON tokens (address_id, chain_id, token_id, protocol_id);
@@ -0,0 +1,8 @@
+-- 为 rpc_nodes 表添加能力探测字段
+ALTER TABLE rpc_nodes ADD COLUMN ws_url VARCHAR(500) DEFAULT NULL;
+ALTER TABLE rpc_nodes ADD COLUMN is_archive BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE rpc_nodes ADD COLUMN supports_trace BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE rpc_nodes ADD COLUMN supports_debug BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE rpc_nodes ADD COLUMN supports_websocket BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE rpc_nodes ADD COLUMN max_logs_block_range INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE rpc_nodes ADD COLUMN capabilities_checked_at DATETIME DEFAULT NULL;
@@ -0,0 +1,3 @@
+UPDATE chains SET is_active = TRUE;
+ALTER TABLE chains DROP COLUMN explorer_url;
+ALTER TABLE chains DROP COLUMN network_id;
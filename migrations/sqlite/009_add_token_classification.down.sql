@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS token_rules;
+DROP INDEX IF EXISTS idx_tokens_classification;
+ALTER TABLE tokens DROP COLUMN is_core;
+ALTER TABLE tokens DROP COLUMN is_verified;
+ALTER TABLE tokens DROP COLUMN classification;
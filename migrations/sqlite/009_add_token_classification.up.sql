@@ -0,0 +1,26 @@
+-- 代币分类：垃圾代币不再丢弃，而是记录分类并默认隐藏
+ALTER TABLE tokens ADD COLUMN classification VARCHAR(20) NOT NULL DEFAULT 'normal'; -- normal、spam、receipt、hidden
+ALTER TABLE tokens ADD COLUMN is_verified BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE tokens ADD COLUMN is_core BOOLEAN NOT NULL DEFAULT FALSE;
+CREATE INDEX idx_tokens_classification ON tokens (classification);
+
+-- 代币分类规则表
+CREATE TABLE token_rules (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(100) NOT NULL,
+    classification VARCHAR(20) NOT NULL,
+    priority INTEGER NOT NULL DEFAULT 0, -- 越小越先匹配
+    chain_id VARCHAR(50) DEFAULT NULL,
+    symbol_pattern VARCHAR(255) DEFAULT NULL,
+    name_pattern VARCHAR(255) DEFAULT NULL,
+    text_pattern VARCHAR(255) DEFAULT NULL, -- 符号或名称任一匹配
+    token_ids TEXT DEFAULT NULL, -- JSON：合约地址列表
+    max_price DECIMAL(30,6) DEFAULT NULL,
+    max_usd_value DECIMAL(30,6) DEFAULT NULL,
+    is_verified BOOLEAN DEFAULT NULL,
+    is_core BOOLEAN DEFAULT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+CREATE INDEX idx_token_rules_enabled ON token_rules (enabled);
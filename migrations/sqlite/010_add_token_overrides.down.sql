@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS token_override_audits;
+DROP TABLE IF EXISTS token_overrides;
@@ -0,0 +1,31 @@
+-- 用户管理的代币忽略/白名单，wallet_id 为 0 表示全局
+CREATE TABLE token_overrides (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    chain_id VARCHAR(50) NOT NULL,
+    token_id VARCHAR(255) NOT NULL,
+    wallet_id INTEGER NOT NULL DEFAULT 0,
+    action VARCHAR(20) NOT NULL, -- ignore 或 show
+    reason VARCHAR(500) DEFAULT NULL,
+    updated_by VARCHAR(255) DEFAULT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    CONSTRAINT uk_token_override UNIQUE (chain_id, token_id, wallet_id)
+);
+CREATE INDEX idx_token_overrides_wallet_id ON token_overrides (wallet_id);
+
+-- 忽略/白名单变更审计
+CREATE TABLE token_override_audits (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    override_id INTEGER NOT NULL,
+    chain_id VARCHAR(50) NOT NULL,
+    token_id VARCHAR(255) NOT NULL,
+    wallet_id INTEGER NOT NULL DEFAULT 0,
+    operation VARCHAR(20) NOT NULL, -- create、update、delete
+    old_action VARCHAR(20) DEFAULT NULL,
+    new_action VARCHAR(20) DEFAULT NULL,
+    reason VARCHAR(500) DEFAULT NULL,
+    actor VARCHAR(255) NOT NULL,
+    created_at DATETIME NOT NULL
+);
+CREATE INDEX idx_token_override_audits_token ON token_override_audits (chain_id, token_id);
+CREATE INDEX idx_token_override_audits_created_at ON token_override_audits (created_at);
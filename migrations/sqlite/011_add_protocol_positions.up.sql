@@ -0,0 +1,38 @@
+-- 协议持仓明细：每个 portfolio item 一行
+CREATE TABLE protocol_positions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    address_id INTEGER NOT NULL REFERENCES addresses(id) ON DELETE CASCADE,
+    protocol_id VARCHAR(255) NOT NULL,
+    chain_id VARCHAR(50) NOT NULL,
+    name VARCHAR(255) DEFAULT NULL,
+    position_type VARCHAR(50) NOT NULL DEFAULT 'unknown', -- lending、staking、liquidity 等
+    detail_types TEXT DEFAULT NULL, -- JSON：提供者返回的所有类型
+    pool_id VARCHAR(255) DEFAULT NULL,
+    net_usd_value DECIMAL(30,6) NOT NULL DEFAULT 0,
+    asset_usd_value DECIMAL(30,6) NOT NULL DEFAULT 0,
+    debt_usd_value DECIMAL(30,6) NOT NULL DEFAULT 0,
+    health_rate DECIMAL(30,6) DEFAULT NULL, -- NULL 表示不适用
+    last_updated DATETIME DEFAULT NULL
+);
+CREATE INDEX idx_protocol_positions_address_id ON protocol_positions (address_id);
+CREATE INDEX idx_protocol_positions_protocol_id ON protocol_positions (protocol_id);
+CREATE INDEX idx_protocol_positions_chain_id ON protocol_positions (chain_id);
+CREATE INDEX idx_protocol_positions_position_type ON protocol_positions (position_type);
+
+-- 持仓中的代币：supply、borrow、reward
+CREATE TABLE protocol_position_tokens (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    position_id INTEGER NOT NULL REFERENCES protocol_positions(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL,
+    chain_id VARCHAR(50) NOT NULL,
+    token_id VARCHAR(255) NOT NULL,
+    symbol VARCHAR(100) DEFAULT NULL,
+    name VARCHAR(255) DEFAULT NULL,
+    decimals INTEGER NOT NULL DEFAULT 0,
+    logo_url VARCHAR(500) DEFAULT NULL,
+    amount DECIMAL(40,18) NOT NULL DEFAULT 0,
+    price DECIMAL(30,6) NOT NULL DEFAULT 0,
+    usd_value DECIMAL(30,6) NOT NULL DEFAULT 0
+);
+CREATE INDEX idx_protocol_position_tokens_position_id ON protocol_position_tokens (position_id);
+CREATE INDEX idx_protocol_position_tokens_token ON protocol_position_tokens (chain_id, token_id);
@@ -0,0 +1,32 @@
+-- 出站 webhook 端点
+CREATE TABLE webhook_endpoints (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(255) NOT NULL,
+    url VARCHAR(1000) NOT NULL,
+    secret VARCHAR(255) NOT NULL, -- 用于 HMAC-SHA256 签名
+    events TEXT DEFAULT NULL, -- JSON：订阅的事件类型，为空表示全部
+    wallet_id INTEGER NOT NULL DEFAULT 0, -- 只接收该钱包的事件，0 表示全部
+    value_change_percent DECIMAL(10,4) NOT NULL DEFAULT 0, -- wallet.value_changed 的阈值，0 表示使用配置默认值
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+
+-- webhook 投递记录，同时作为投递队列和死信列表
+CREATE TABLE webhook_deliveries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    endpoint_id INTEGER NOT NULL REFERENCES webhook_endpoints(id) ON DELETE CASCADE,
+    event_id VARCHAR(64) NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    payload TEXT NOT NULL, -- 签名时使用的原始 JSON
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending、delivered、dead
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME NOT NULL,
+    last_error VARCHAR(1000) DEFAULT NULL,
+    response_status INTEGER NOT NULL DEFAULT 0,
+    delivered_at DATETIME DEFAULT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+CREATE INDEX idx_webhook_deliveries_endpoint_id ON webhook_deliveries (endpoint_id);
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries (status, next_attempt_at);
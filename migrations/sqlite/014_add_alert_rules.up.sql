@@ -0,0 +1,35 @@
+-- 余额变化告警规则
+CREATE TABLE alert_rules (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(255) NOT NULL,
+    rule_type VARCHAR(50) NOT NULL, -- net_worth_drop、token_balance_change、token_disappeared、new_debt
+    scope_type VARCHAR(20) NOT NULL DEFAULT 'all', -- all、wallet、address、tag
+    scope_id INTEGER NOT NULL DEFAULT 0, -- scope_type 为 wallet/address 时的 ID
+    scope_tag VARCHAR(255) NOT NULL DEFAULT '', -- scope_type 为 tag 时的标签
+    chain_id VARCHAR(50) NOT NULL DEFAULT '',
+    token_id VARCHAR(255) NOT NULL DEFAULT '',
+    threshold_percent DECIMAL(10,4) DEFAULT NULL,
+    threshold_usd DECIMAL(30,6) DEFAULT NULL,
+    cooldown_minutes INTEGER NOT NULL DEFAULT 60, -- 同一地址两次告警的最小间隔
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+CREATE INDEX idx_alert_rules_enabled ON alert_rules (enabled);
+
+-- 已触发的余额变化告警
+CREATE TABLE alert_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    rule_id INTEGER NOT NULL REFERENCES alert_rules(id) ON DELETE CASCADE,
+    wallet_id INTEGER NOT NULL,
+    address_id INTEGER NOT NULL REFERENCES addresses(id) ON DELETE CASCADE,
+    rule_type VARCHAR(50) NOT NULL,
+    message VARCHAR(1000) NOT NULL,
+    details TEXT DEFAULT NULL, -- JSON
+    delivered BOOLEAN NOT NULL DEFAULT FALSE,
+    delivery_error VARCHAR(1000) DEFAULT NULL,
+    created_at DATETIME NOT NULL
+);
+CREATE INDEX idx_alert_events_rule_address ON alert_events (rule_id, address_id, created_at);
+CREATE INDEX idx_alert_events_wallet_id ON alert_events (wallet_id);
+CREATE INDEX idx_alert_events_created_at ON alert_events (created_at);
@@ -0,0 +1,14 @@
+-- API key，只保存 SHA-256 哈希
+CREATE TABLE api_keys (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(255) NOT NULL,
+    key_prefix VARCHAR(16) NOT NULL, -- 明文 key 的前缀，用于识别
+    key_hash VARCHAR(64) NOT NULL, -- SHA-256(key) 的十六进制
+    role VARCHAR(20) NOT NULL, -- read_only、operator、admin
+    expires_at DATETIME DEFAULT NULL,
+    revoked_at DATETIME DEFAULT NULL,
+    last_used_at DATETIME DEFAULT NULL,
+    created_at DATETIME NOT NULL
+);
+
+CREATE UNIQUE INDEX uk_api_keys_key_hash ON api_keys (key_hash);
@@ -0,0 +1,39 @@
+PRAGMA foreign_keys = OFF;
+
+CREATE TABLE api_keys_old (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(255) NOT NULL,
+    key_prefix VARCHAR(16) NOT NULL,
+    key_hash VARCHAR(64) NOT NULL,
+    role VARCHAR(20) NOT NULL,
+    expires_at DATETIME DEFAULT NULL,
+    revoked_at DATETIME DEFAULT NULL,
+    last_used_at DATETIME DEFAULT NULL,
+    created_at DATETIME NOT NULL
+);
+INSERT INTO api_keys_old (id, name, key_prefix, key_hash, role, expires_at, revoked_at, last_used_at, created_at)
+SELECT id, name, key_prefix, key_hash, role, expires_at, revoked_at, last_used_at, created_at FROM api_keys;
+DROP TABLE api_keys;
+ALTER TABLE api_keys_old RENAME TO api_keys;
+CREATE UNIQUE INDEX uk_api_keys_key_hash ON api_keys (key_hash);
+
+CREATE TABLE wallets_old (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    description TEXT,
+    tags TEXT DEFAULT NULL,
+    enabled_chains TEXT DEFAULT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    status VARCHAR(20) NOT NULL DEFAULT 'Enabled'
+);
+INSERT INTO wallets_old (id, name, description, tags, enabled_chains, created_at, updated_at, status)
+SELECT id, name, description, tags, enabled_chains, created_at, updated_at, status FROM wallets;
+DROP TABLE wallets;
+ALTER TABLE wallets_old RENAME TO wallets;
+
+PRAGMA foreign_keys = ON;
+
+DROP TABLE IF EXISTS wallet_shares;
+DROP TABLE IF EXISTS user_sessions;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,65 @@
+-- 本地用户账号
+CREATE TABLE users (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    username VARCHAR(100) NOT NULL,
+    password_hash VARCHAR(255) NOT NULL, -- bcrypt 哈希
+    role VARCHAR(20) NOT NULL DEFAULT 'operator', -- read_only、operator、admin
+    disabled BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+
+CREATE UNIQUE INDEX uk_users_username ON users (username);
+
+-- 登录会话，只保存 token 的 SHA-256 哈希
+CREATE TABLE user_sessions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL,
+    expires_at DATETIME NOT NULL,
+    last_used_at DATETIME DEFAULT NULL,
+    created_at DATETIME NOT NULL
+);
+
+CREATE UNIQUE INDEX uk_user_sessions_token_hash ON user_sessions (token_hash);
+CREATE INDEX idx_user_sessions_user_id ON user_sessions (user_id);
+
+-- 钱包共享
+CREATE TABLE wallet_shares (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    wallet_id INTEGER NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    permission VARCHAR(10) NOT NULL, -- view、edit
+    created_at DATETIME NOT NULL
+);
+
+CREATE UNIQUE INDEX uk_wallet_shares_wallet_user ON wallet_shares (wallet_id, user_id);
+CREATE INDEX idx_wallet_shares_user_id ON wallet_shares (user_id);
+
+-- 钱包所有者，钱包名称改为在同一所有者内唯一
+-- SQLite 无法删除列上的 UNIQUE 约束，按官方步骤重建表；关闭外键检查避免删除旧表时级联删除地址
+PRAGMA foreign_keys = OFF;
+
+CREATE TABLE wallets_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    tags TEXT DEFAULT NULL, -- JSON：用户自定义标签
+    enabled_chains TEXT DEFAULT NULL, -- JSON：启用的链 ID 列表，NULL 表示所有链
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    status VARCHAR(20) NOT NULL DEFAULT 'Enabled',
+    owner_id INTEGER DEFAULT NULL REFERENCES users(id) ON DELETE SET NULL -- 所有者，NULL 表示升级前创建的钱包，只有 admin 可见
+);
+
+INSERT INTO wallets_new (id, name, description, tags, enabled_chains, created_at, updated_at, status)
+SELECT id, name, description, tags, enabled_chains, created_at, updated_at, status FROM wallets;
+
+DROP TABLE wallets;
+ALTER TABLE wallets_new RENAME TO wallets;
+CREATE UNIQUE INDEX uk_wallets_owner_name ON wallets (owner_id, name);
+
+PRAGMA foreign_keys = ON;
+
+-- API key 可以属于某个用户，NULL 表示不限钱包的服务 key
+ALTER TABLE api_keys ADD COLUMN user_id INTEGER DEFAULT NULL REFERENCES users(id) ON DELETE CASCADE;
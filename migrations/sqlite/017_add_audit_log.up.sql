@@ -0,0 +1,19 @@
+-- 修改类 API 请求的审计日志
+CREATE TABLE audit_log (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    actor VARCHAR(255) NOT NULL, -- user:<用户名>、apikey:<名称>、X-Actor 请求头或客户端 IP
+    method VARCHAR(10) NOT NULL,
+    route VARCHAR(255) NOT NULL, -- 路由模板，如 /api/v1/wallets/:id
+    path VARCHAR(500) NOT NULL,
+    entity_type VARCHAR(50) NOT NULL DEFAULT '',
+    entity_id VARCHAR(100) NOT NULL DEFAULT '',
+    status_code INTEGER NOT NULL,
+    client_ip VARCHAR(64) NOT NULL DEFAULT '',
+    before_state TEXT DEFAULT NULL, -- JSON，修改前的实体
+    after_state TEXT DEFAULT NULL, -- JSON，修改后的实体
+    changes TEXT DEFAULT NULL, -- JSON，{"字段": {"from": 旧值, "to": 新值}}
+    created_at DATETIME NOT NULL
+);
+CREATE INDEX idx_audit_log_entity ON audit_log (entity_type, entity_id);
+CREATE INDEX idx_audit_log_actor ON audit_log (actor);
+CREATE INDEX idx_audit_log_created_at ON audit_log (created_at);
@@ -0,0 +1,32 @@
+-- 地址的交易历史
+CREATE TABLE transactions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    address_id INTEGER NOT NULL REFERENCES addresses(id) ON DELETE CASCADE,
+    chain_id VARCHAR(50) NOT NULL,
+    tx_hash VARCHAR(100) NOT NULL,
+    block_number INTEGER NOT NULL DEFAULT 0, -- 0 表示提供者未返回
+    block_time DATETIME NOT NULL,
+    counterparty VARCHAR(255) DEFAULT NULL,
+    category VARCHAR(50) DEFAULT NULL, -- send、receive、approve 等，空表示一般合约调用
+    protocol_id VARCHAR(255) DEFAULT NULL,
+    transfers_in TEXT DEFAULT NULL, -- JSON：[{"token_id","symbol","amount","counterparty"}]
+    transfers_out TEXT DEFAULT NULL, -- JSON：转出的代币
+    fee DECIMAL(40,18) DEFAULT NULL, -- 原生代币计的手续费，只在地址发起交易时记录
+    fee_usd DECIMAL(30,6) DEFAULT NULL,
+    failed BOOLEAN NOT NULL DEFAULT FALSE,
+    source VARCHAR(20) NOT NULL, -- debank 或 rpc
+    created_at DATETIME NOT NULL
+);
+CREATE UNIQUE INDEX uk_transactions_address_chain_hash ON transactions (address_id, chain_id, tx_hash);
+CREATE INDEX idx_transactions_address_time ON transactions (address_id, block_time);
+
+-- 每个地址和链的交易同步游标
+CREATE TABLE transaction_cursors (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    address_id INTEGER NOT NULL REFERENCES addresses(id) ON DELETE CASCADE,
+    chain_id VARCHAR(50) NOT NULL,
+    source VARCHAR(20) NOT NULL, -- 生成游标的提供者，切换提供者后重新回溯
+    next_cursor VARCHAR(100) NOT NULL, -- 提供者定义的游标，如最新交易时间或已扫描的区块
+    synced_at DATETIME NOT NULL
+);
+CREATE UNIQUE INDEX uk_transaction_cursors_address_chain ON transaction_cursors (address_id, chain_id);
@@ -0,0 +1,4 @@
+-- 恢复全局唯一；如果多个钱包已包含同一地址，回滚会因唯一键冲突失败，需要先手动清理
+-- 升级后的唯一约束是独立索引，回滚不需要重建表
+DROP INDEX IF EXISTS uk_addresses_wallet_address_chain;
+CREATE UNIQUE INDEX uk_address_chain ON addresses (address, chain_type);
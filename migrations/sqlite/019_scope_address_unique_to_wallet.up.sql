@@ -0,0 +1,26 @@
+-- 地址改为在同一钱包内唯一，不同用户可以各自跟踪同一地址
+-- SQLite 无法删除表内定义的 UNIQUE 约束，按官方步骤重建表；关闭外键检查避免删除旧表时级联删除快照、代币等数据
+PRAGMA foreign_keys = OFF;
+
+CREATE TABLE addresses_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    wallet_id INTEGER NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    address VARCHAR(255) NOT NULL,
+    chain_type VARCHAR(50) NOT NULL DEFAULT 'EVM',
+    label VARCHAR(255),
+    tags TEXT DEFAULT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    last_synced_at DATETIME NULL
+);
+
+INSERT INTO addresses_new (id, wallet_id, address, chain_type, label, tags, created_at, updated_at, last_synced_at)
+SELECT id, wallet_id, address, chain_type, label, tags, created_at, updated_at, last_synced_at FROM addresses;
+
+DROP TABLE addresses;
+ALTER TABLE addresses_new RENAME TO addresses;
+CREATE INDEX idx_addresses_wallet_id ON addresses (wallet_id);
+CREATE INDEX idx_addresses_address ON addresses (address);
+CREATE UNIQUE INDEX uk_addresses_wallet_address_chain ON addresses (wallet_id, address, chain_type);
+
+PRAGMA foreign_keys = ON;